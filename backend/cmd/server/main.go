@@ -9,11 +9,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/lazycatapps/trivy/backend/internal/handler"
+	"github.com/lazycatapps/trivy/backend/internal/middleware"
 	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
 	"github.com/lazycatapps/trivy/backend/internal/repository"
 	"github.com/lazycatapps/trivy/backend/internal/router"
@@ -41,7 +43,19 @@ func init() {
 	rootCmd.Flags().String("default-registry", "docker.io/", "Default image registry prefix")
 	rootCmd.Flags().StringSlice("cors-allowed-origins", []string{"*"}, "CORS allowed origins")
 	rootCmd.Flags().String("config-dir", "./configs", "Directory for storing configuration files")
-	rootCmd.Flags().String("reports-dir", "./reports", "Directory for storing scan reports and results")
+	rootCmd.PersistentFlags().String("reports-dir", "./reports", "Directory for storing scan reports and results")
+	rootCmd.Flags().String("storage-backend", "file", "Scan task storage backend: file or sqlite")
+	rootCmd.Flags().String("report-storage", "file", "Report file storage: file (under reports-dir) or s3")
+	rootCmd.Flags().String("s3-endpoint", "", "S3-compatible endpoint of the s3 report storage (e.g., minio:9000)")
+	rootCmd.Flags().String("s3-bucket", "", "Bucket storing the reports (created if missing)")
+	rootCmd.Flags().String("s3-region", "us-east-1", "Bucket region")
+	rootCmd.Flags().String("s3-access-key", "", "S3 access key ID")
+	rootCmd.Flags().String("s3-secret-key", "", "S3 secret access key")
+	rootCmd.Flags().Bool("s3-use-ssl", true, "Connect to the S3 endpoint over HTTPS")
+	rootCmd.Flags().String("s3-prefix", "", "Key prefix of all reports in the bucket (optional)")
+	rootCmd.Flags().Int64("s3-presign-min-size", 10*1024*1024, "Reports of at least this size in bytes are downloaded from presigned S3 URLs (0 = always stream through the server)")
+	rootCmd.Flags().Int("s3-presign-expiry", 900, "Validity of presigned report download URLs in seconds")
+	rootCmd.PersistentFlags().String("sqlite-path", "", "SQLite database file of the sqlite storage backend (default: {reports-dir}/trivy.db)")
 	rootCmd.Flags().Bool("allow-password-save", false, "Allow saving passwords in configuration files")
 	rootCmd.Flags().Int64("max-config-size", 4096, "Maximum configuration file size in bytes")
 	rootCmd.Flags().Int("max-config-files", 1000, "Maximum number of configuration files per user")
@@ -51,9 +65,37 @@ func init() {
 	rootCmd.Flags().String("oidc-client-secret", "", "OIDC client secret")
 	rootCmd.Flags().String("oidc-issuer", "", "OIDC issuer URL")
 	rootCmd.Flags().String("oidc-redirect-url", "", "OIDC redirect URL")
+	rootCmd.Flags().String("oidc-post-logout-redirect-url", "", "URL the OIDC provider redirects to after logout (must be registered at the provider)")
+	rootCmd.Flags().StringSlice("oidc-admin-groups", []string{"ADMIN"}, "OIDC groups granted the admin role (all users' tasks, purge, global settings)")
+	rootCmd.Flags().StringSlice("oidc-scanner-groups", []string{}, "OIDC groups granted the scanner role (create scans)")
+	rootCmd.Flags().StringSlice("oidc-viewer-groups", []string{}, "OIDC groups granted the viewer role (read only)")
+	rootCmd.Flags().String("oidc-default-role", "scanner", "Role of users in none of the role groups: viewer, scanner, admin or none")
+	rootCmd.Flags().Bool("oidc-offline-access", false, "Request the offline_access scope, so sessions can be renewed with a refresh token")
+	rootCmd.Flags().Int("session-idle-timeout", 168, "Hours without requests after which a session ends")
+	rootCmd.Flags().Int("session-max-age", 720, "Hours after which a session must be renewed with the OIDC provider (0 = unlimited)")
+	rootCmd.Flags().String("session-secret", "", "Secret encrypting the OIDC refresh and ID tokens stored with sessions (stored in plaintext if empty)")
 	rootCmd.Flags().Bool("enable-docker-scan", false, "Enable Docker socket access for scanning local images (requires Docker socket mount)")
+	rootCmd.Flags().Int64("max-upload-size", 512*1024*1024, "Maximum size of an uploaded scan target (SBOM, tarball, VM image) in bytes")
+	rootCmd.Flags().Int64("user-storage-quota", 0, "Maximum storage per user (reports, metadata and uploads) in bytes (0 = unlimited)")
+	rootCmd.Flags().String("public-url", "", "Public base URL of the web UI, used for report links in notifications (e.g., https://trivy.example.com)")
+	rootCmd.Flags().Int("webhook-max-retries", 3, "Maximum retries of a failed webhook delivery")
+	rootCmd.Flags().Int("webhook-retry-backoff", 5, "Delay before the first webhook retry in seconds (doubled for each further retry)")
+	rootCmd.Flags().Int("webhook-timeout", 10, "Timeout of a webhook request in seconds")
+	rootCmd.Flags().Bool("webhook-allow-private", false, "Allow webhooks to loopback, link-local and private network addresses")
+	rootCmd.Flags().String("smtp-host", "", "SMTP server host for email digests (empty = email disabled)")
+	rootCmd.Flags().Int("smtp-port", 587, "SMTP server port")
+	rootCmd.Flags().String("smtp-username", "", "SMTP username (optional)")
+	rootCmd.Flags().String("smtp-password", "", "SMTP password")
+	rootCmd.Flags().String("smtp-from", "", "Sender address of emails (e.g., \"Trivy <trivy@example.com>\")")
+	rootCmd.Flags().String("smtp-tls", "starttls", "SMTP transport security: starttls, tls or none")
+	rootCmd.Flags().Int("digest-hour", 8, "Hour of the day (0-23, server local time) email digests are sent at")
+	rootCmd.Flags().String("log-format", "logfmt", "Log output format: logfmt or json")
+	rootCmd.Flags().String("log-level", "info", "Minimum log level: debug, info or error")
 
 	viper.BindPFlags(rootCmd.Flags())
+	viper.BindPFlags(rootCmd.PersistentFlags())
+
+	rootCmd.AddCommand(migrateStorageCmd)
 
 	// Set environment variable prefix to "TRIVY"
 	viper.SetEnvPrefix("TRIVY")
@@ -69,6 +111,8 @@ func runServer(cmd *cobra.Command, args []string) {
 	oidcClientSecret := viper.GetString("oidc-client-secret")
 	oidcIssuer := viper.GetString("oidc-issuer")
 	oidcRedirectURL := viper.GetString("oidc-redirect-url")
+	smtpHost := viper.GetString("smtp-host")
+	smtpFrom := viper.GetString("smtp-from")
 
 	cfg := &types.Config{
 		Server: types.ServerConfig{
@@ -85,6 +129,8 @@ func runServer(cmd *cobra.Command, args []string) {
 			MaxWorkers:        viper.GetInt("max-workers"),
 			ScanRetentionDays: viper.GetInt("scan-retention-days"),
 			EnableDockerScan:  viper.GetBool("enable-docker-scan"),
+			MaxUploadSize:     viper.GetInt64("max-upload-size"),
+			UserStorageQuota:  viper.GetInt64("user-storage-quota"),
 		},
 		CORS: types.CORSConfig{
 			AllowedOrigins: viper.GetStringSlice("cors-allowed-origins"),
@@ -92,6 +138,42 @@ func runServer(cmd *cobra.Command, args []string) {
 		Storage: types.StorageConfig{
 			ConfigDir:  viper.GetString("config-dir"),
 			ReportsDir: viper.GetString("reports-dir"),
+			Backend:    viper.GetString("storage-backend"),
+			SQLitePath: sqlitePath(),
+
+			ReportBackend: viper.GetString("report-storage"),
+			S3: types.S3Config{
+				Endpoint:       viper.GetString("s3-endpoint"),
+				Bucket:         viper.GetString("s3-bucket"),
+				Region:         viper.GetString("s3-region"),
+				AccessKey:      viper.GetString("s3-access-key"),
+				SecretKey:      viper.GetString("s3-secret-key"),
+				UseSSL:         viper.GetBool("s3-use-ssl"),
+				Prefix:         viper.GetString("s3-prefix"),
+				PresignMinSize: viper.GetInt64("s3-presign-min-size"),
+				PresignExpiry:  time.Duration(viper.GetInt("s3-presign-expiry")) * time.Second,
+			},
+		},
+		Notification: types.NotificationConfig{
+			PublicURL:           viper.GetString("public-url"),
+			WebhookMaxRetries:   viper.GetInt("webhook-max-retries"),
+			WebhookRetryBackoff: viper.GetInt("webhook-retry-backoff"),
+			WebhookTimeout:      viper.GetInt("webhook-timeout"),
+			WebhookAllowPrivate: viper.GetBool("webhook-allow-private"),
+			DigestHour:          viper.GetInt("digest-hour"),
+		},
+		SMTP: types.SMTPConfig{
+			Host:     smtpHost,
+			Port:     viper.GetInt("smtp-port"),
+			Username: viper.GetString("smtp-username"),
+			Password: viper.GetString("smtp-password"),
+			From:     smtpFrom,
+			TLS:      viper.GetString("smtp-tls"),
+			Enabled:  smtpHost != "" && smtpFrom != "",
+		},
+		Log: types.LogConfig{
+			Format: viper.GetString("log-format"),
+			Level:  viper.GetString("log-level"),
 		},
 		OIDC: types.OIDCConfig{
 			ClientID:     oidcClientID,
@@ -99,11 +181,27 @@ func runServer(cmd *cobra.Command, args []string) {
 			Issuer:       oidcIssuer,
 			RedirectURL:  oidcRedirectURL,
 			Enabled:      oidcClientID != "" && oidcClientSecret != "" && oidcIssuer != "",
+
+			AdminGroups:   viper.GetStringSlice("oidc-admin-groups"),
+			ScannerGroups: viper.GetStringSlice("oidc-scanner-groups"),
+			ViewerGroups:  viper.GetStringSlice("oidc-viewer-groups"),
+			DefaultRole:   viper.GetString("oidc-default-role"),
+
+			OfflineAccess:      viper.GetBool("oidc-offline-access"),
+			SessionIdleTimeout: time.Duration(viper.GetInt("session-idle-timeout")) * time.Hour,
+			SessionMaxAge:      time.Duration(viper.GetInt("session-max-age")) * time.Hour,
+			SessionSecret:      viper.GetString("session-secret"),
+
+			PostLogoutRedirectURL: viper.GetString("oidc-post-logout-redirect-url"),
 		},
 	}
 
 	// Initialize logger
-	log := logger.New()
+	log, err := logger.NewStructured(logger.Options{Format: cfg.Log.Format, Level: cfg.Log.Level})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid logging configuration: %v\n", err)
+		os.Exit(1)
+	}
 
 	log.Info("Starting Trivy Web UI server")
 	log.Info("=================================")
@@ -116,6 +214,22 @@ func runServer(cmd *cobra.Command, args []string) {
 	log.Info("  Scan Retention: %d days", cfg.Trivy.ScanRetentionDays)
 	log.Info("  Allow Password Save: %v", cfg.Trivy.AllowPasswordSave)
 	log.Info("  Enable Docker Scan: %v", cfg.Trivy.EnableDockerScan)
+	log.Info("  Max Upload Size: %d bytes", cfg.Trivy.MaxUploadSize)
+	log.Info("  User Storage Quota: %d bytes", cfg.Trivy.UserStorageQuota)
+
+	// Log notification configuration
+	log.Info("Notification Configuration:")
+	log.Info("  Public URL: %s", cfg.Notification.PublicURL)
+	log.Info("  Webhook Max Retries: %d", cfg.Notification.WebhookMaxRetries)
+	log.Info("  Webhook Retry Backoff: %d seconds", cfg.Notification.WebhookRetryBackoff)
+	log.Info("  Webhook Timeout: %d seconds", cfg.Notification.WebhookTimeout)
+	log.Info("  Webhook Allow Private: %v", cfg.Notification.WebhookAllowPrivate)
+	if cfg.SMTP.Enabled {
+		log.Info("  Email Digests: ENABLED (%s:%d, %s, from %s, sent at %02d:00)",
+			cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.TLS, cfg.SMTP.From, cfg.Notification.DigestHour)
+	} else {
+		log.Info("  Email Digests: DISABLED (SMTP not configured)")
+	}
 
 	// Log OIDC configuration status
 	if cfg.OIDC.Enabled {
@@ -123,23 +237,78 @@ func runServer(cmd *cobra.Command, args []string) {
 		log.Info("  Issuer: %s", cfg.OIDC.Issuer)
 		log.Info("  Client ID: %s", cfg.OIDC.ClientID)
 		log.Info("  Redirect URL: %s", cfg.OIDC.RedirectURL)
+		log.Info("  Post Logout Redirect URL: %s", cfg.OIDC.PostLogoutRedirectURL)
+		log.Info("  Admin Groups: %v", cfg.OIDC.AdminGroups)
+		log.Info("  Scanner Groups: %v", cfg.OIDC.ScannerGroups)
+		log.Info("  Viewer Groups: %v", cfg.OIDC.ViewerGroups)
+		log.Info("  Default Role: %s", cfg.OIDC.DefaultRole)
+		log.Info("  Offline Access: %v", cfg.OIDC.OfflineAccess)
+		log.Info("  Session Idle Timeout: %s", cfg.OIDC.SessionIdleTimeout)
+		log.Info("  Session Max Age: %s", cfg.OIDC.SessionMaxAge)
+		log.Info("  Session Token Encryption: %v", cfg.OIDC.SessionSecret != "")
 	} else {
-		log.Info("OIDC authentication: DISABLED")
+		log.Info("OIDC authentication: DISABLED (all requests have the admin role)")
+	}
+
+	// Map OIDC groups to roles (viewer, scanner, admin)
+	roleMapping, err := middleware.NewRoleMapping(cfg.OIDC.AdminGroups, cfg.OIDC.ScannerGroups, cfg.OIDC.ViewerGroups, cfg.OIDC.DefaultRole)
+	if err != nil {
+		log.Error("Invalid role configuration: %v", err)
+		return
 	}
 
-	// Initialize repository (file-based task storage with persistence)
+	// Initialize repository (file-based or SQLite task storage with persistence)
 	log.Info("Initializing scan repository...")
+	log.Info("  Storage backend: %s", cfg.Storage.Backend)
 	log.Info("  Reports directory: %s", cfg.Storage.ReportsDir)
-	scanRepo, err := repository.NewFileBasedScanRepository(cfg.Storage.ReportsDir)
+	var scanRepo repository.ScanRepository
+	switch cfg.Storage.Backend {
+	case "file":
+		scanRepo, err = repository.NewFileBasedScanRepository(cfg.Storage.ReportsDir)
+	case "sqlite":
+		log.Info("  SQLite database: %s", cfg.Storage.SQLitePath)
+		var sqliteRepo *repository.SQLiteScanRepository
+		sqliteRepo, err = repository.NewSQLiteScanRepository(cfg.Storage.SQLitePath)
+		if err == nil {
+			defer sqliteRepo.Close()
+			scanRepo = sqliteRepo
+		}
+	default:
+		err = fmt.Errorf("unsupported storage backend %q (expected file or sqlite)", cfg.Storage.Backend)
+	}
 	if err != nil {
 		log.Error("Failed to initialize scan repository: %v", err)
 		return
 	}
 	log.Info("Scan repository initialized successfully")
 
+	// Initialize report store (local disk or S3-compatible object storage)
+	log.Info("Initializing report store...")
+	log.Info("  Report storage: %s", cfg.Storage.ReportBackend)
+	var reportStore repository.ReportStore
+	switch cfg.Storage.ReportBackend {
+	case "file":
+		reportStore = repository.NewLocalReportStore(filepath.Join(cfg.Storage.ReportsDir, "reports"))
+	case "s3":
+		log.Info("  S3 endpoint: %s (bucket: %s, SSL: %v)", cfg.Storage.S3.Endpoint, cfg.Storage.S3.Bucket, cfg.Storage.S3.UseSSL)
+		reportStore, err = repository.NewS3ReportStore(repository.S3Options{
+			Endpoint:  cfg.Storage.S3.Endpoint,
+			Bucket:    cfg.Storage.S3.Bucket,
+			Region:    cfg.Storage.S3.Region,
+			AccessKey: cfg.Storage.S3.AccessKey,
+			SecretKey: cfg.Storage.S3.SecretKey,
+			UseSSL:    cfg.Storage.S3.UseSSL,
+			Prefix:    cfg.Storage.S3.Prefix,
+		})
+	default:
+		err = fmt.Errorf("unsupported report storage %q (expected file or s3)", cfg.Storage.ReportBackend)
+	}
+	if err != nil {
+		log.Error("Failed to initialize report store: %v", err)
+		return
+	}
+
 	// Initialize services
-	scanService := service.NewScanService(scanRepo, &cfg.Trivy, cfg.Storage.ReportsDir, log)
-	reportService := service.NewReportService(scanRepo, cfg.Storage.ReportsDir, log)
 	configService := service.NewConfigService(
 		cfg.Storage.ConfigDir,
 		cfg.Trivy.AllowPasswordSave,
@@ -147,26 +316,87 @@ func runServer(cmd *cobra.Command, args []string) {
 		cfg.Trivy.MaxConfigFiles,
 		log,
 	)
-	sessionService := service.NewSessionService(7 * 24 * time.Hour) // 7 days session TTL
+	scheduleRepo, err := repository.NewFileScheduleRepository(cfg.Storage.ConfigDir)
+	if err != nil {
+		log.Error("Failed to initialize schedule repository: %v", err)
+		return
+	}
+	webhookRepo, err := repository.NewFileWebhookRepository(cfg.Storage.ConfigDir)
+	if err != nil {
+		log.Error("Failed to initialize webhook repository: %v", err)
+		return
+	}
+	webhookService := service.NewWebhookService(webhookRepo, &cfg.Notification, log)
+	scanService := service.NewScanService(scanRepo, &cfg.Trivy, cfg.Storage.ReportsDir, log,
+		service.WithConfigService(configService),
+		service.WithScheduleRepository(scheduleRepo),
+		service.WithNotifier(webhookService),
+		service.WithReportStore(reportStore),
+	)
+	digestRepo, err := repository.NewFileDigestRepository(cfg.Storage.ConfigDir)
+	if err != nil {
+		log.Error("Failed to initialize digest repository: %v", err)
+		return
+	}
+	var mailer service.Mailer
+	if cfg.SMTP.Enabled {
+		mailer = service.NewSMTPMailer(&cfg.SMTP)
+	}
+	digestService := service.NewDigestService(digestRepo, scanService, mailer, &cfg.Notification, log)
+	reportService := service.NewReportService(scanRepo, reportStore, log,
+		service.WithPresignedDownloads(cfg.Storage.S3.PresignMinSize, cfg.Storage.S3.PresignExpiry),
+	)
+	sessionRepo, err := repository.NewFileSessionRepository(cfg.Storage.ConfigDir)
+	if err != nil {
+		log.Error("Failed to initialize session repository: %v", err)
+		return
+	}
+	sessionService := service.NewSessionService(sessionRepo, cfg.OIDC.SessionIdleTimeout, cfg.OIDC.SessionMaxAge, log)
+	if cfg.OIDC.SessionSecret != "" {
+		if err := sessionService.SetSecret(cfg.OIDC.SessionSecret); err != nil {
+			log.Error("Failed to set session secret: %v", err)
+			return
+		}
+	}
+	tokenRepo, err := repository.NewFileTokenRepository(cfg.Storage.ConfigDir)
+	if err != nil {
+		log.Error("Failed to initialize API token repository: %v", err)
+		return
+	}
+	tokenService := service.NewTokenService(tokenRepo, log)
+	// Token roles follow the groups of the owner's latest login, and tokens end with revoked users
+	sessionService.SetIdentityListener(tokenService)
 
-	// Start scan service worker pool
+	// Start scan service worker pool. Deferred calls run in reverse order, so the
+	// scans are stopped before pending webhook deliveries are flushed.
+	defer webhookService.Stop()
 	scanService.Start()
 	defer scanService.Stop()
+	digestService.Start()
+	defer digestService.Stop()
 
 	// Initialize HTTP handlers
 	scanHandler := handler.NewScanHandler(scanService, log)
 	reportHandler := handler.NewReportHandler(reportService, log)
 	configHandler := handler.NewConfigHandler(configService, cfg.Trivy.EnableDockerScan, log)
+	scheduleHandler := handler.NewScheduleHandler(scanService, log)
+	webhookHandler := handler.NewWebhookHandler(webhookService, log)
+	digestHandler := handler.NewDigestHandler(digestService, log)
+	tokenHandler := handler.NewTokenHandler(tokenService, log)
 
 	// Initialize auth handler
-	authHandler, err := handler.NewAuthHandler(&cfg.OIDC, sessionService, log)
+	authHandler, err := handler.NewAuthHandler(&cfg.OIDC, roleMapping, sessionService, log)
 	if err != nil {
 		log.Error("Failed to initialize auth handler: %v", err)
 		return
 	}
+	if cfg.OIDC.Enabled {
+		// Renew sessions reaching the maximum age with their refresh token
+		sessionService.SetRefresher(authHandler)
+	}
 
 	// Set up router and middleware
-	r := router.New(scanHandler, reportHandler, configHandler, authHandler, sessionService)
+	r := router.New(scanHandler, reportHandler, configHandler, scheduleHandler, webhookHandler, digestHandler, tokenHandler, authHandler, sessionService, tokenService, roleMapping, log)
 	engine := r.Setup(cfg)
 
 	// Set up graceful shutdown
@@ -192,6 +422,47 @@ func runServer(cmd *cobra.Command, args []string) {
 	log.Info("Goodbye!")
 }
 
+// migrateStorageCmd imports the file-based scan history into the SQLite database.
+var migrateStorageCmd = &cobra.Command{
+	Use:   "migrate-storage",
+	Short: "Import file-based scan history into the SQLite database",
+	Long: `Imports every scan task stored under {reports-dir}/scans into the SQLite database
+used by --storage-backend sqlite. The files are left untouched and tasks that are
+already in the database are skipped, so the command can be run again.
+Stop the server before migrating.`,
+	Args:         cobra.NoArgs,
+	RunE:         runMigrateStorage,
+	SilenceUsage: true,
+}
+
+// sqlitePath returns the configured SQLite database file ({reports-dir}/trivy.db by default).
+func sqlitePath() string {
+	if path := viper.GetString("sqlite-path"); path != "" {
+		return path
+	}
+	return filepath.Join(viper.GetString("reports-dir"), "trivy.db")
+}
+
+// runMigrateStorage executes the migrate-storage command.
+func runMigrateStorage(cmd *cobra.Command, args []string) error {
+	reportsDir := viper.GetString("reports-dir")
+	dbPath := sqlitePath()
+
+	repo, err := repository.NewSQLiteScanRepository(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	defer repo.Close()
+
+	fmt.Printf("Importing scan tasks from %s into %s...\n", reportsDir, dbPath)
+	result, err := repository.MigrateFileTasksToSQLite(reportsDir, repo)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d tasks (%d already present)\n", result.Imported, result.Skipped)
+	return nil
+}
+
 // main is the application entry point.
 func main() {
 	if err := rootCmd.Execute(); err != nil {
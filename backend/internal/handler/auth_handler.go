@@ -8,8 +8,17 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"net/http"
-
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/middleware"
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	apperrors "github.com/lazycatapps/trivy/backend/internal/pkg/errors"
 	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
 	"github.com/lazycatapps/trivy/backend/internal/service"
 	"github.com/lazycatapps/trivy/backend/internal/types"
@@ -19,21 +28,40 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// sessionCookieMaxAge is the lifetime of the session cookie in seconds, the longest
+// browsers accept. Sessions expire on the server (idle timeout and maximum age).
+const sessionCookieMaxAge = 400 * 24 * 3600
+
+// loginCookieMaxAge is the lifetime in seconds of the cookies (state, nonce, PKCE verifier)
+// that carry a login from Login to Callback.
+const loginCookieMaxAge = 600
+
+// backchannelLogoutEvent is the event a back-channel logout token must contain.
+const backchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
 // AuthHandler handles OIDC authentication requests.
 type AuthHandler struct {
 	config         *types.OIDCConfig
+	roleMapping    *middleware.RoleMapping
 	sessionService *service.SessionService
 	provider       *oidc.Provider
 	oauth2Config   *oauth2.Config
+	verifier       *oidc.IDTokenVerifier // Verifies ID tokens and back-channel logout tokens
+	endSessionURL  string                // end_session_endpoint of the provider (empty if not supported)
 	log            logger.Logger
+
+	logoutTokensMu sync.Mutex
+	logoutTokens   map[string]time.Time // jti -> expiry of accepted logout tokens, to reject replays
 }
 
 // NewAuthHandler creates a new auth handler.
-func NewAuthHandler(cfg *types.OIDCConfig, sessionService *service.SessionService, log logger.Logger) (*AuthHandler, error) {
+// The role mapping resolves the role reported by UserInfo from the session groups.
+func NewAuthHandler(cfg *types.OIDCConfig, roleMapping *middleware.RoleMapping, sessionService *service.SessionService, log logger.Logger) (*AuthHandler, error) {
 	// If OIDC is not enabled, return handler without initialization
 	if !cfg.Enabled {
 		return &AuthHandler{
 			config:         cfg,
+			roleMapping:    roleMapping,
 			sessionService: sessionService,
 			log:            log,
 		}, nil
@@ -46,26 +74,49 @@ func NewAuthHandler(cfg *types.OIDCConfig, sessionService *service.SessionServic
 		return nil, err
 	}
 
-	// Configure OAuth2
+	// Discover RP-initiated logout support and the supported scopes
+	var metadata struct {
+		EndSessionEndpoint string   `json:"end_session_endpoint"`
+		ScopesSupported    []string `json:"scopes_supported"`
+	}
+	if err := provider.Claims(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse provider metadata: %w", err)
+	}
+
+	// Configure OAuth2, requesting a refresh token to renew sessions if enabled and
+	// not ruled out by the provider (scopes_supported is optional and may be incomplete)
+	scopes := []string{oidc.ScopeOpenID, "profile", "email", "groups"}
+	if cfg.OfflineAccess {
+		if len(metadata.ScopesSupported) > 0 && !slices.Contains(metadata.ScopesSupported, oidc.ScopeOfflineAccess) {
+			log.Info("OIDC provider does not advertise the %s scope, sessions will not be renewed with refresh tokens", oidc.ScopeOfflineAccess)
+		} else {
+			scopes = append(scopes, oidc.ScopeOfflineAccess)
+		}
+	}
 	oauth2Config := &oauth2.Config{
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
 		RedirectURL:  cfg.RedirectURL,
 		Endpoint:     provider.Endpoint(),
-		Scopes:       []string{oidc.ScopeOpenID, "profile", "email", "groups"},
+		Scopes:       scopes,
 	}
 
 	return &AuthHandler{
 		config:         cfg,
+		roleMapping:    roleMapping,
 		sessionService: sessionService,
 		provider:       provider,
 		oauth2Config:   oauth2Config,
+		verifier:       provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		endSessionURL:  metadata.EndSessionEndpoint,
 		log:            log,
+		logoutTokens:   make(map[string]time.Time),
 	}, nil
 }
 
 // Login redirects to OIDC provider for authentication.
 func (h *AuthHandler) Login(c *gin.Context) {
+	log := requestLogger(c, h.log)
 	if !h.config.Enabled {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "OIDC authentication is not enabled"})
 		return
@@ -74,21 +125,33 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// Generate random state
 	state, err := generateState()
 	if err != nil {
-		h.log.Error("Failed to generate state: %v", err)
+		log.Error("Failed to generate state: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate state"})
 		return
 	}
 
-	// Store state in cookie for verification
-	c.SetCookie("oauth_state", state, 600, "/", "", true, true)
+	// Generate the nonce bound to the ID token and the PKCE code verifier
+	nonce, err := generateState()
+	if err != nil {
+		log.Error("Failed to generate nonce: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate nonce"})
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	// Store state, nonce and verifier in cookies for verification
+	c.SetCookie("oauth_state", state, loginCookieMaxAge, "/", "", true, true)
+	c.SetCookie("oauth_nonce", nonce, loginCookieMaxAge, "/", "", true, true)
+	c.SetCookie("oauth_verifier", verifier, loginCookieMaxAge, "/", "", true, true)
 
 	// Redirect to OIDC provider
-	authURL := h.oauth2Config.AuthCodeURL(state)
+	authURL := h.oauth2Config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
 	c.Redirect(http.StatusFound, authURL)
 }
 
 // Callback handles the OIDC callback.
 func (h *AuthHandler) Callback(c *gin.Context) {
+	log := requestLogger(c, h.log)
 	if !h.config.Enabled {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "OIDC authentication is not enabled"})
 		return
@@ -97,89 +160,202 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 	// Verify state
 	stateCookie, err := c.Cookie("oauth_state")
 	if err != nil {
-		h.log.Error("Missing state cookie: %v", err)
+		log.Error("Missing state cookie: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing state"})
 		return
 	}
 
 	state := c.Query("state")
 	if state != stateCookie {
-		h.log.Error("State mismatch: expected %s, got %s", stateCookie, state)
+		log.Error("State mismatch: expected %s, got %s", stateCookie, state)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "State mismatch"})
 		return
 	}
 
-	// Clear state cookie
+	nonce, err := c.Cookie("oauth_nonce")
+	if err != nil || nonce == "" {
+		log.Error("Missing nonce cookie: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing nonce"})
+		return
+	}
+	verifier, err := c.Cookie("oauth_verifier")
+	if err != nil || verifier == "" {
+		log.Error("Missing PKCE verifier cookie: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing code verifier"})
+		return
+	}
+
+	// Clear login cookies
 	c.SetCookie("oauth_state", "", -1, "/", "", true, true)
+	c.SetCookie("oauth_nonce", "", -1, "/", "", true, true)
+	c.SetCookie("oauth_verifier", "", -1, "/", "", true, true)
 
-	// Exchange code for token
+	// Exchange code for token, proving possession of the PKCE verifier
 	code := c.Query("code")
 	ctx := context.Background()
-	oauth2Token, err := h.oauth2Config.Exchange(ctx, code)
+	oauth2Token, err := h.oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
 	if err != nil {
-		h.log.Error("Failed to exchange token: %v", err)
+		log.Error("Failed to exchange token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to exchange token"})
 		return
 	}
 
-	// Extract ID token
-	rawIDToken, ok := oauth2Token.Extra("id_token").(string)
-	if !ok {
-		h.log.Error("No id_token in token response")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "No id_token"})
-		return
-	}
-
-	// Verify ID token
-	verifier := h.provider.Verifier(&oidc.Config{ClientID: h.config.ClientID})
-	idToken, err := verifier.Verify(ctx, rawIDToken)
+	// Verify the ID token, including the nonce, and extract the user identity
+	identity, err := h.identityFromToken(ctx, oauth2Token, nonce)
 	if err != nil {
-		h.log.Error("Failed to verify ID token: %v", err)
+		log.Error("Failed to verify ID token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify token"})
 		return
 	}
 
-	// Extract claims
-	var claims struct {
-		Sub    string   `json:"sub"`
-		Email  string   `json:"email"`
-		Groups []string `json:"groups"`
-	}
-	if err := idToken.Claims(&claims); err != nil {
-		h.log.Error("Failed to extract claims: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to extract claims"})
-		return
-	}
-
 	// Create session
-	sessionID, err := h.sessionService.CreateSession(claims.Sub, claims.Email, claims.Groups)
+	sessionID, err := h.sessionService.CreateSession(identity, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
-		h.log.Error("Failed to create session: %v", err)
+		log.Error("Failed to create session: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
 		return
 	}
 
 	// Set session cookie
-	c.SetCookie("session", sessionID, 86400*7, "/", "", true, true)
+	c.SetCookie("session", sessionID, sessionCookieMaxAge, "/", "", true, true)
 
-	h.log.Info("User authenticated: %s (%s)", claims.Email, claims.Sub)
+	log.Info("User authenticated: %s (%s)", identity.Email, identity.Subject)
 
 	// Redirect to home page
 	c.Redirect(http.StatusFound, "/")
 }
 
-// Logout logs out the user.
+// Logout logs out the user. If the provider supports RP-initiated logout, the response
+// contains the logoutUrl the browser must navigate to for ending the provider session too.
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Get session cookie
+	var session *models.Session
 	sessionCookie, err := c.Cookie("session")
 	if err == nil && sessionCookie != "" {
-		h.sessionService.DeleteSession(sessionCookie)
+		session = h.sessionService.DeleteSession(sessionCookie)
 	}
 
 	// Clear session cookie
 	c.SetCookie("session", "", -1, "/", "", true, true)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+	response := gin.H{"message": "Logged out successfully"}
+	if logoutURL := h.endSessionLogoutURL(requestLogger(c, h.log), session); logoutURL != "" {
+		response["logoutUrl"] = logoutURL
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// endSessionLogoutURL returns the RP-initiated logout URL of the provider for a session,
+// or an empty string if the provider has no end_session_endpoint.
+func (h *AuthHandler) endSessionLogoutURL(log logger.Logger, session *models.Session) string {
+	if !h.config.Enabled || h.endSessionURL == "" {
+		return ""
+	}
+
+	logoutURL, err := url.Parse(h.endSessionURL)
+	if err != nil {
+		log.Error("Invalid end_session_endpoint %q: %v", h.endSessionURL, err)
+		return ""
+	}
+
+	query := logoutURL.Query()
+	query.Set("client_id", h.config.ClientID)
+	if session != nil && session.IDToken != "" {
+		query.Set("id_token_hint", session.IDToken)
+	}
+	if h.config.PostLogoutRedirectURL != "" {
+		query.Set("post_logout_redirect_uri", h.config.PostLogoutRedirectURL)
+	}
+	logoutURL.RawQuery = query.Encode()
+	return logoutURL.String()
+}
+
+// BackchannelLogout handles POST /api/v1/auth/backchannel-logout - OIDC back-channel logout.
+// The provider posts a signed logout token (form parameter logout_token) when a user logs
+// out there; the sessions of the provider session (sid) or of the user (sub) are ended.
+func (h *AuthHandler) BackchannelLogout(c *gin.Context) {
+	log := requestLogger(c, h.log)
+	c.Header("Cache-Control", "no-store")
+
+	if !h.config.Enabled {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "OIDC authentication is not enabled"})
+		return
+	}
+
+	rawToken := c.PostForm("logout_token")
+	if rawToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing logout_token"})
+		return
+	}
+
+	claims, err := h.verifyLogoutToken(c.Request.Context(), rawToken)
+	if err != nil {
+		log.Error("Rejected back-channel logout token: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid logout token: %v", err)})
+		return
+	}
+
+	revoked, err := h.sessionService.RevokeOIDCSessions(claims.Sub, claims.Sid)
+	if err != nil {
+		log.Error("Failed to end sessions for back-channel logout: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end sessions"})
+		return
+	}
+
+	log.Info("Back-channel logout for subject %q (provider session %q) ended %d sessions", claims.Sub, claims.Sid, revoked)
+	c.Status(http.StatusOK)
+}
+
+// logoutTokenClaims are the claims of a back-channel logout token.
+type logoutTokenClaims struct {
+	Sub    string                     `json:"sub"`
+	Sid    string                     `json:"sid"`
+	Jti    string                     `json:"jti"`
+	Nonce  *string                    `json:"nonce"`
+	Events map[string]json.RawMessage `json:"events"`
+}
+
+// verifyLogoutToken validates a back-channel logout token as required by OpenID Connect
+// Back-Channel Logout 1.0: signature, issuer, audience and expiry as for ID tokens, the
+// logout event, a sub or sid claim, no nonce, and a jti that was not used before.
+func (h *AuthHandler) verifyLogoutToken(ctx context.Context, rawToken string) (*logoutTokenClaims, error) {
+	token, err := h.verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims logoutTokenClaims
+	if err := token.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to extract claims: %w", err)
+	}
+	if _, ok := claims.Events[backchannelLogoutEvent]; !ok {
+		return nil, fmt.Errorf("missing back-channel logout event")
+	}
+	if claims.Sub == "" && claims.Sid == "" {
+		return nil, fmt.Errorf("missing sub and sid claims")
+	}
+	if claims.Nonce != nil {
+		return nil, fmt.Errorf("logout token must not contain a nonce")
+	}
+	if claims.Jti == "" {
+		return nil, fmt.Errorf("missing jti claim")
+	}
+
+	h.logoutTokensMu.Lock()
+	defer h.logoutTokensMu.Unlock()
+
+	now := time.Now()
+	for jti, expiry := range h.logoutTokens {
+		if now.After(expiry) {
+			delete(h.logoutTokens, jti)
+		}
+	}
+	if _, used := h.logoutTokens[claims.Jti]; used {
+		return nil, fmt.Errorf("logout token was already used")
+	}
+	h.logoutTokens[claims.Jti] = token.Expiry
+
+	return &claims, nil
 }
 
 // UserInfo returns current user information.
@@ -188,6 +364,7 @@ func (h *AuthHandler) UserInfo(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"authenticated": false,
 			"oidc_enabled":  false,
+			"role":          middleware.RoleAdmin,
 		})
 		return
 	}
@@ -212,14 +389,8 @@ func (h *AuthHandler) UserInfo(c *gin.Context) {
 		return
 	}
 
-	// Check if user is admin
-	isAdmin := false
-	for _, group := range session.Groups {
-		if group == "ADMIN" {
-			isAdmin = true
-			break
-		}
-	}
+	// Resolve the role from the session groups
+	role := h.roleMapping.Resolve(session.Groups)
 
 	c.JSON(http.StatusOK, gin.H{
 		"authenticated": true,
@@ -227,10 +398,135 @@ func (h *AuthHandler) UserInfo(c *gin.Context) {
 		"user_id":       session.UserID,
 		"email":         session.Email,
 		"groups":        session.Groups,
-		"is_admin":      isAdmin,
+		"role":          role,
+		"is_admin":      role == middleware.RoleAdmin,
 	})
 }
 
+// identityClaims are the ID token and userinfo claims a session is created from.
+type identityClaims struct {
+	Sub    string   `json:"sub"`
+	Email  string   `json:"email"`
+	Groups []string `json:"groups"`
+	Sid    string   `json:"sid"`
+}
+
+// identity returns the session identity of the claims.
+func (cl *identityClaims) identity(refreshToken, rawIDToken string) *service.SessionIdentity {
+	return &service.SessionIdentity{
+		Subject:      cl.Sub,
+		Email:        cl.Email,
+		Groups:       cl.Groups,
+		RefreshToken: refreshToken,
+		IDToken:      rawIDToken,
+		SessionID:    cl.Sid,
+	}
+}
+
+// identityFromToken verifies the ID token of a token response and returns the user identity.
+// The nonce of the ID token must match nonce unless it is empty (refreshed tokens).
+func (h *AuthHandler) identityFromToken(ctx context.Context, oauth2Token *oauth2.Token, nonce string) (*service.SessionIdentity, error) {
+	rawIDToken, ok := oauth2Token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("no id_token in token response")
+	}
+
+	idToken, err := h.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if nonce != "" && idToken.Nonce != nonce {
+		return nil, fmt.Errorf("nonce mismatch")
+	}
+
+	var claims identityClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to extract claims: %w", err)
+	}
+	return claims.identity(oauth2Token.RefreshToken, rawIDToken), nil
+}
+
+// RenewIdentity redeems a refresh token with the OIDC provider and returns the current
+// identity of the user. It implements service.SessionRefresher.
+func (h *AuthHandler) RenewIdentity(ctx context.Context, refreshToken string) (*service.SessionIdentity, error) {
+	if !h.config.Enabled {
+		return nil, fmt.Errorf("OIDC authentication is not enabled")
+	}
+
+	oauth2Token, err := h.oauth2Config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := oauth2Token.Extra("id_token").(string); ok {
+		return h.identityFromToken(ctx, oauth2Token, "")
+	}
+
+	// Providers may omit the ID token when a refresh token is redeemed
+	userInfo, err := h.provider.UserInfo(ctx, oauth2.StaticTokenSource(oauth2Token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+	var claims identityClaims
+	if err := userInfo.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to extract claims: %w", err)
+	}
+	return claims.identity(oauth2Token.RefreshToken, ""), nil
+}
+
+// ListSessions handles GET /api/v1/admin/sessions - List the active sessions of all users
+// or, with the userId query parameter, of one user (admin).
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	log := requestLogger(c, h.log)
+	sessions, err := h.sessionService.ListSessions(c.Query("userId"))
+	if err != nil {
+		log.Error("Failed to list sessions: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.SessionListResponse{Sessions: sessions})
+}
+
+// RevokeSession handles DELETE /api/v1/admin/sessions/:id - End a session (admin).
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	log := requestLogger(c, h.log)
+	if err := h.sessionService.RevokeSession(c.Param("id")); err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			log.Error("Failed to revoke session: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeUserSessions handles DELETE /api/v1/admin/sessions?userId= - End all sessions of a user (admin).
+func (h *AuthHandler) RevokeUserSessions(c *gin.Context) {
+	log := requestLogger(c, h.log)
+	revoked, err := h.sessionService.RevokeUserSessions(c.Query("userId"))
+	if err != nil {
+		log.Error("Failed to revoke sessions: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.SessionRevokeResponse{Revoked: revoked})
+}
+
 // generateState generates a random state string for CSRF protection.
 func generateState() (string, error) {
 	b := make([]byte, 32)
@@ -0,0 +1,318 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/middleware"
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/service"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+// TestAuthHandlerSessions tests listing and revoking sessions through the admin API
+func TestAuthHandlerSessions(t *testing.T) {
+	repo, err := repository.NewFileSessionRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	sessionService := service.NewSessionService(repo, time.Hour, 0, &mockLogger{})
+	handler, err := NewAuthHandler(&types.OIDCConfig{}, &middleware.RoleMapping{}, sessionService, &mockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	router := setupTestRouter()
+	router.GET("/admin/sessions", handler.ListSessions)
+	router.DELETE("/admin/sessions", handler.RevokeUserSessions)
+	router.DELETE("/admin/sessions/:id", handler.RevokeSession)
+
+	alice := &service.SessionIdentity{Subject: "user-1", Email: "alice@example.com"}
+	bob := &service.SessionIdentity{Subject: "user-2", Email: "bob@example.com"}
+	aliceSession, _ := sessionService.CreateSession(alice, "", "")
+	sessionService.CreateSession(alice, "", "")
+	sessionService.CreateSession(bob, "", "")
+
+	listSessions := func(query string) []*models.Session {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/sessions"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for list, got %d (body: %s)", w.Code, w.Body.String())
+		}
+		var response models.SessionListResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return response.Sessions
+	}
+
+	if sessions := listSessions(""); len(sessions) != 3 {
+		t.Fatalf("Expected 3 sessions, got %d", len(sessions))
+	}
+	sessions := listSessions("?userId=alice@example.com_user-1")
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions of alice, got %d", len(sessions))
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"Revoke session", "/admin/sessions/" + sessions[1].ID, http.StatusOK},
+		{"Revoke unknown session", "/admin/sessions/unknown", http.StatusNotFound},
+		{"Revoke without user", "/admin/sessions", http.StatusBadRequest},
+		{"Revoke user sessions", "/admin/sessions?userId=bob@example.com_user-2", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, tt.path, nil))
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	remaining := listSessions("")
+	if len(remaining) != 1 || remaining[0].ID != sessions[0].ID {
+		t.Errorf("Expected only alice's first session to remain, got %+v", remaining)
+	}
+	if _, ok := sessionService.GetSession(aliceSession); !ok {
+		t.Error("Expected the remaining session to stay valid")
+	}
+}
+
+// mockIdentityProvider is a minimal OIDC provider signing tokens with an RSA key.
+type mockIdentityProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string // PKCE code challenge of the last authorization request
+	nonce     string // Nonce put into issued ID tokens
+	sid       string // Provider session ID put into issued ID tokens
+}
+
+func newMockIdentityProvider(t *testing.T) *mockIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	idp := &mockIdentityProvider{key: key, sid: "sid-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/auth",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"jwks_uri":                              idp.server.URL + "/keys",
+			"end_session_endpoint":                  idp.server.URL + "/logout",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		digest := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(digest[:]) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-token",
+			"token_type":    "Bearer",
+			"refresh_token": "refresh-token",
+			"expires_in":    3600,
+			"id_token": idp.sign(t, map[string]interface{}{
+				"sub":    "user-1",
+				"email":  "alice@example.com",
+				"groups": []string{"dev"},
+				"nonce":  idp.nonce,
+				"sid":    idp.sid,
+			}),
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// sign returns a JWT issued by the provider for the test client with the given claims.
+func (idp *mockIdentityProvider) sign(t *testing.T, claims map[string]interface{}) string {
+	payload := map[string]interface{}{
+		"iss": idp.server.URL,
+		"aud": "trivy",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}
+	for k, v := range claims {
+		payload[k] = v
+	}
+	data, _ := json.Marshal(payload)
+
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":"test","typ":"JWT"}`)) +
+		"." + base64.RawURLEncoding.EncodeToString(data)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// TestAuthHandlerOIDC tests the login flow with PKCE and nonce, RP-initiated logout
+// and back-channel logout against a mock provider
+func TestAuthHandlerOIDC(t *testing.T) {
+	idp := newMockIdentityProvider(t)
+	repo, err := repository.NewFileSessionRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	sessionService := service.NewSessionService(repo, time.Hour, 0, &mockLogger{})
+	cfg := &types.OIDCConfig{
+		ClientID:              "trivy",
+		ClientSecret:          "secret",
+		Issuer:                idp.server.URL,
+		RedirectURL:           "https://trivy.example.com/api/v1/auth/callback",
+		PostLogoutRedirectURL: "https://trivy.example.com/",
+		Enabled:               true,
+	}
+	handler, err := NewAuthHandler(cfg, &middleware.RoleMapping{}, sessionService, &mockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	router := setupTestRouter()
+	router.GET("/auth/login", handler.Login)
+	router.GET("/auth/callback", handler.Callback)
+	router.POST("/auth/logout", handler.Logout)
+	router.POST("/auth/backchannel-logout", handler.BackchannelLogout)
+
+	// login runs the authorization code flow and returns the session cookie
+	// (empty if the callback fails) and the callback status
+	login := func(tamperNonce bool) (string, int) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+		if w.Code != http.StatusFound {
+			t.Fatalf("Expected status 302 for login, got %d", w.Code)
+		}
+		authURL, _ := url.Parse(w.Header().Get("Location"))
+		query := authURL.Query()
+		if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" || query.Get("nonce") == "" {
+			t.Fatalf("Expected PKCE and nonce parameters, got %s", authURL)
+		}
+		idp.challenge = query.Get("code_challenge")
+		idp.nonce = query.Get("nonce")
+		if tamperNonce {
+			idp.nonce = "other"
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/callback?code=code-1&state="+url.QueryEscape(query.Get("state")), nil)
+		for _, cookie := range w.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == "session" {
+				value, _ := url.QueryUnescape(cookie.Value)
+				return value, w.Code
+			}
+		}
+		return "", w.Code
+	}
+
+	if cookie, status := login(true); cookie != "" || status != http.StatusInternalServerError {
+		t.Fatalf("Expected an ID token with another nonce to be rejected, got status %d", status)
+	}
+
+	sessionCookie, status := login(false)
+	if sessionCookie == "" || status != http.StatusFound {
+		t.Fatalf("Expected a session after login, got status %d", status)
+	}
+	if _, ok := sessionService.GetSession(sessionCookie); !ok {
+		t.Fatal("Expected the session to be valid")
+	}
+
+	// Logout returns the provider logout URL with the ID token as hint
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: sessionCookie})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var logout struct {
+		LogoutURL string `json:"logoutUrl"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &logout)
+	logoutURL, _ := url.Parse(logout.LogoutURL)
+	if !strings.HasPrefix(logout.LogoutURL, idp.server.URL+"/logout?") ||
+		logoutURL.Query().Get("id_token_hint") == "" ||
+		logoutURL.Query().Get("post_logout_redirect_uri") != "https://trivy.example.com/" {
+		t.Errorf("Unexpected logout URL: %q", logout.LogoutURL)
+	}
+	if _, ok := sessionService.GetSession(sessionCookie); ok {
+		t.Error("Expected the session to end on logout")
+	}
+
+	// Back-channel logout ends the sessions of the provider session
+	sessionCookie, _ = login(false)
+	event := map[string]interface{}{"http://schemas.openid.net/event/backchannel-logout": map[string]interface{}{}}
+	tests := []struct {
+		name           string
+		claims         map[string]interface{}
+		expectedStatus int
+	}{
+		{"Missing event", map[string]interface{}{"sid": "sid-1", "jti": "jti-1"}, http.StatusBadRequest},
+		{"Contains nonce", map[string]interface{}{"sid": "sid-1", "jti": "jti-2", "events": event, "nonce": "n"}, http.StatusBadRequest},
+		{"Missing sub and sid", map[string]interface{}{"jti": "jti-3", "events": event}, http.StatusBadRequest},
+		{"Wrong audience", map[string]interface{}{"sid": "sid-1", "jti": "jti-4", "events": event, "aud": "other"}, http.StatusBadRequest},
+		{"Valid", map[string]interface{}{"sid": "sid-1", "jti": "jti-5", "events": event}, http.StatusOK},
+		{"Replayed", map[string]interface{}{"sid": "sid-1", "jti": "jti-5", "events": event}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"logout_token": {idp.sign(t, tt.claims)}}
+			req := httptest.NewRequest(http.MethodPost, "/auth/backchannel-logout", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			_, valid := sessionService.GetSession(sessionCookie)
+			if valid != (tt.expectedStatus != http.StatusOK && tt.name != "Replayed") {
+				t.Errorf("Unexpected session validity %v after %s", valid, tt.name)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package handler
 import (
 	"net/http"
 
+	"github.com/lazycatapps/trivy/backend/internal/middleware"
 	"github.com/lazycatapps/trivy/backend/internal/models"
 	apperrors "github.com/lazycatapps/trivy/backend/internal/pkg/errors"
 	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
@@ -37,6 +38,27 @@ func getUserIdentifier(c *gin.Context) string {
 	return session.Email + "_" + session.UserID // Could also be: session.UserID, session.Username, etc.
 }
 
+// getPrincipal returns the identity used to authorize access to scan tasks:
+// the user identifier (task owner), the OIDC groups of the session (task sharing) and
+// whether the role resolved by the RBAC middleware is admin (read access to all tasks).
+func getPrincipal(c *gin.Context) models.Principal {
+	principal := models.Principal{
+		UserID: getUserIdentifier(c),
+		Admin:  middleware.GetRole(c) == middleware.RoleAdmin,
+	}
+	if sessionInfo, exists := c.Get("session"); exists {
+		if session, ok := sessionInfo.(*service.SessionInfo); ok {
+			principal.Groups = session.Groups
+		}
+	}
+	return principal
+}
+
+// requestLogger returns a logger tagging messages with the correlation ID of the request.
+func requestLogger(c *gin.Context, log logger.Logger) logger.Logger {
+	return logger.With(log, "request_id", middleware.GetRequestID(c))
+}
+
 // ConfigHandler handles HTTP requests for user configuration management.
 type ConfigHandler struct {
 	configService    *service.ConfigService
@@ -168,6 +190,296 @@ func (h *ConfigHandler) GetLastUsedConfig(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"name": name})
 }
 
+// ListPolicies handles GET /api/v1/policies
+// Returns a list of all saved policy names for the current user
+func (h *ConfigHandler) ListPolicies(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+
+	policies, err := h.configService.ListPolicies(userIdentifier)
+	if err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"policies": policies})
+}
+
+// GetPolicy handles GET /api/v1/policy/:name
+// Retrieves a saved policy by name for the current user
+func (h *ConfigHandler) GetPolicy(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "policy name is required"})
+		return
+	}
+
+	policy, err := h.configService.GetPolicy(userIdentifier, name)
+	if err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// SavePolicy handles POST /api/v1/policy/:name
+// Validates and saves a policy with the given name for the current user
+func (h *ConfigHandler) SavePolicy(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "policy name is required"})
+		return
+	}
+
+	var policy models.Policy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	if err := h.configService.SavePolicy(userIdentifier, name, &policy); err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy saved successfully"})
+}
+
+// DeletePolicy handles DELETE /api/v1/policy/:name
+// Deletes a saved policy by name for the current user
+func (h *ConfigHandler) DeletePolicy(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "policy name is required"})
+		return
+	}
+
+	if err := h.configService.DeletePolicy(userIdentifier, name); err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy deleted successfully"})
+}
+
+// ListCheckBundles handles GET /api/v1/checks
+// Returns all custom Rego check bundles of the current user with their versions
+func (h *ConfigHandler) ListCheckBundles(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+
+	bundles, err := h.configService.ListCheckBundles(userIdentifier)
+	if err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, bundles)
+}
+
+// GetCheckBundle handles GET /api/v1/checks/:name
+// Returns the versions of a custom Rego check bundle of the current user
+func (h *ConfigHandler) GetCheckBundle(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	name := c.Param("name")
+
+	bundle, err := h.configService.GetCheckBundle(userIdentifier, name)
+	if err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, bundle)
+}
+
+// UploadCheckBundle handles POST /api/v1/checks/:name
+// Expects multipart/form-data with a "file" part (.rego module or .tar/.tar.gz/.tgz of modules)
+// and stores it as the next version of the bundle
+func (h *ConfigHandler) UploadCheckBundle(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+	name := c.Param("name")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Error("Failed to open uploaded check bundle: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	version, err := h.configService.UploadCheckBundle(userIdentifier, name, fileHeader.Filename, file)
+	if err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// DeleteCheckBundle handles DELETE /api/v1/checks/:name
+// Deletes all versions of a custom Rego check bundle of the current user
+func (h *ConfigHandler) DeleteCheckBundle(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	name := c.Param("name")
+
+	if err := h.configService.DeleteCheckBundle(userIdentifier, name); err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Check bundle deleted successfully"})
+}
+
+// ListSuppressions handles GET /api/v1/suppressions
+// Returns the global suppression rules and the rules of the current user
+func (h *ConfigHandler) ListSuppressions(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+
+	suppressions, err := h.configService.ListSuppressions(userIdentifier)
+	if err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, suppressions)
+}
+
+// AddSuppression handles POST /api/v1/suppressions
+// Creates a suppression rule for the current user
+func (h *ConfigHandler) AddSuppression(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+
+	var req models.SuppressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rule, err := h.configService.AddSuppression(userIdentifier, &req)
+	if err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteSuppression handles DELETE /api/v1/suppressions/:id
+// Deletes a suppression rule of the current user
+func (h *ConfigHandler) DeleteSuppression(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+
+	if err := h.configService.DeleteSuppression(userIdentifier, c.Param("id")); err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Suppression rule deleted successfully"})
+}
+
+// AddGlobalSuppression handles POST /api/v1/admin/suppressions
+// Creates a global suppression rule applying to the scans of all users (admin)
+func (h *ConfigHandler) AddGlobalSuppression(c *gin.Context) {
+	var req models.SuppressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rule, err := h.configService.AddGlobalSuppression(&req)
+	if err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteGlobalSuppression handles DELETE /api/v1/admin/suppressions/:id
+// Deletes a global suppression rule (admin)
+func (h *ConfigHandler) DeleteGlobalSuppression(c *gin.Context) {
+	if err := h.configService.DeleteGlobalSuppression(c.Param("id")); err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Global suppression rule deleted successfully"})
+}
+
 // GetSystemConfig handles GET /api/v1/system/config
 // Returns system-level configuration flags that control frontend behavior
 func (h *ConfigHandler) GetSystemConfig(c *gin.Context) {
@@ -6,6 +6,7 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -297,6 +298,261 @@ func TestSaveConfig(t *testing.T) {
 	}
 }
 
+// TestSavePolicy tests the SavePolicy handler
+func TestSavePolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policyName     string
+		requestBody    interface{}
+		expectedStatus int
+	}{
+		{
+			name:       "Save policy successfully",
+			policyName: "no-critical",
+			requestBody: models.Policy{
+				Description: "Block fixable CRITICAL vulnerabilities",
+				Rules: []models.PolicyRule{
+					{Kind: models.FindingKindVulnerability, Severities: []string{"CRITICAL"}, FixAvailable: true},
+				},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Policy without rules",
+			policyName:     "empty",
+			requestBody:    models.Policy{},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unsupported finding kind",
+			policyName: "bad-kind",
+			requestBody: models.Policy{
+				Rules: []models.PolicyRule{{Kind: "malware"}},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid JSON body",
+			policyName:     "no-critical",
+			requestBody:    "invalid json",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configService := service.NewConfigService(t.TempDir(), false, 4096, 1000, &mockLogger{})
+			handler := NewConfigHandler(configService, false, &mockLogger{})
+			router := setupTestRouter()
+			router.POST("/policy/:name", handler.SavePolicy)
+			router.GET("/policy/:name", handler.GetPolicy)
+
+			var body []byte
+			if str, ok := tt.requestBody.(string); ok {
+				body = []byte(str)
+			} else {
+				body, _ = json.Marshal(tt.requestBody)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/policy/"+tt.policyName, bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			// The saved policy can be read back
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/policy/"+tt.policyName, nil))
+			var policy models.Policy
+			if err := json.Unmarshal(w.Body.Bytes(), &policy); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if len(policy.Rules) != 1 || !policy.Rules[0].FixAvailable {
+				t.Errorf("Unexpected policy: %+v", policy)
+			}
+		})
+	}
+}
+
+// TestUploadCheckBundle tests the UploadCheckBundle handler
+func TestUploadCheckBundle(t *testing.T) {
+	const validCheck = "package user.docker.no_root\n\ndeny[msg] {\n\tinput.user == \"root\"\n\tmsg := \"runs as root\"\n}\n"
+
+	tests := []struct {
+		name           string
+		filename       string
+		content        string
+		expectedStatus int
+	}{
+		{"Upload module successfully", "no_root.rego", validCheck, http.StatusOK},
+		{"Invalid module", "broken.rego", "deny[msg] {", http.StatusBadRequest},
+		{"Unsupported file type", "checks.zip", "PK", http.StatusBadRequest},
+		{"Missing file", "", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configService := service.NewConfigService(t.TempDir(), false, 4096, 1000, &mockLogger{})
+			handler := NewConfigHandler(configService, false, &mockLogger{})
+			router := setupTestRouter()
+			router.POST("/checks/:name", handler.UploadCheckBundle)
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			if tt.filename != "" {
+				part, _ := writer.CreateFormFile("file", tt.filename)
+				part.Write([]byte(tt.content))
+			}
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/checks/docker", &body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var version models.CheckBundleVersion
+			if err := json.Unmarshal(w.Body.Bytes(), &version); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if version.Version != 1 || len(version.Namespaces) != 1 || version.Namespaces[0] != "user" {
+				t.Errorf("Unexpected version: %+v", version)
+			}
+		})
+	}
+}
+
+// TestAddSuppression tests the POST /suppressions and DELETE /suppressions/:id endpoints
+func TestAddSuppression(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		expectedStatus int
+	}{
+		{
+			name: "Add suppression successfully",
+			requestBody: models.SuppressionRequest{
+				VulnerabilityID: "CVE-2023-0001",
+				Package:         "pkg:npm/lodash",
+				Justification:   "Only used in build tooling",
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Missing justification",
+			requestBody:    map[string]string{"vulnerabilityId": "CVE-2023-0001"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid package",
+			requestBody: models.SuppressionRequest{
+				VulnerabilityID: "CVE-2023-0001",
+				Package:         "lodash",
+				Justification:   "Only used in build tooling",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configService := service.NewConfigService(t.TempDir(), false, 4096, 1000, &mockLogger{})
+			handler := NewConfigHandler(configService, false, &mockLogger{})
+			router := setupTestRouter()
+			router.POST("/suppressions", handler.AddSuppression)
+			router.DELETE("/suppressions/:id", handler.DeleteSuppression)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/suppressions", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusCreated {
+				return
+			}
+
+			var rule models.SuppressionRule
+			if err := json.Unmarshal(w.Body.Bytes(), &rule); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if rule.ID == "" || rule.Scope != models.SuppressionScopeUser {
+				t.Errorf("Unexpected rule: %+v", rule)
+			}
+
+			// The rule can be deleted once
+			for _, expected := range []int{http.StatusOK, http.StatusNotFound} {
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/suppressions/"+rule.ID, nil))
+				if w.Code != expected {
+					t.Errorf("Expected status %d on delete, got %d", expected, w.Code)
+				}
+			}
+		})
+	}
+}
+
+// TestGlobalSuppressions tests the admin handlers managing global suppression rules
+func TestGlobalSuppressions(t *testing.T) {
+	configService := service.NewConfigService(t.TempDir(), false, 4096, 1000, &mockLogger{})
+	handler := NewConfigHandler(configService, false, &mockLogger{})
+	router := setupTestRouter()
+	router.GET("/suppressions", handler.ListSuppressions)
+	router.POST("/admin/suppressions", handler.AddGlobalSuppression)
+	router.DELETE("/admin/suppressions/:id", handler.DeleteGlobalSuppression)
+
+	body, _ := json.Marshal(models.SuppressionRequest{VulnerabilityID: "CVE-2023-0001", Justification: "Accepted company-wide"})
+	req := httptest.NewRequest(http.MethodPost, "/admin/suppressions", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var rule models.SuppressionRule
+	if err := json.Unmarshal(w.Body.Bytes(), &rule); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if rule.Scope != models.SuppressionScopeGlobal {
+		t.Errorf("Expected a global rule, got %+v", rule)
+	}
+
+	// Global rules are listed for every user
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/suppressions", nil))
+	var list models.SuppressionListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(list.Suppressions) != 1 || list.Suppressions[0].ID != rule.ID {
+		t.Errorf("Expected the global rule to be listed, got %+v", list.Suppressions)
+	}
+
+	for _, expected := range []int{http.StatusOK, http.StatusNotFound} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/suppressions/"+rule.ID, nil))
+		if w.Code != expected {
+			t.Errorf("Expected status %d on delete, got %d", expected, w.Code)
+		}
+	}
+}
+
 // TestDeleteConfig tests the DeleteConfig handler
 func TestDeleteConfig(t *testing.T) {
 	const testUserID = "user-123"
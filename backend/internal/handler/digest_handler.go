@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	apperrors "github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+	"github.com/lazycatapps/trivy/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DigestHandler handles HTTP requests for email digest subscriptions.
+type DigestHandler struct {
+	digestService service.DigestService
+	logger        logger.Logger
+}
+
+// NewDigestHandler creates a new digest handler.
+func NewDigestHandler(digestService service.DigestService, log logger.Logger) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+		logger:        log,
+	}
+}
+
+// GetSubscription handles GET /api/v1/digest - Get the current user's digest subscription.
+func (h *DigestHandler) GetSubscription(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	subscription, err := h.digestService.GetSubscription(userIdentifier)
+	if err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			log.Error("Failed to get digest subscription: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get digest subscription"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// UpdateSubscription handles PUT /api/v1/digest - Create or update the digest subscription.
+func (h *DigestHandler) UpdateSubscription(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	var req models.DigestSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error("Invalid digest subscription request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	subscription, err := h.digestService.UpdateSubscription(userIdentifier, &req)
+	if err != nil {
+		log.Error("Failed to update digest subscription: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update digest subscription"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteSubscription handles DELETE /api/v1/digest - Unsubscribe from email digests.
+func (h *DigestHandler) DeleteSubscription(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	if err := h.digestService.DeleteSubscription(userIdentifier); err != nil {
+		log.Error("Failed to delete digest subscription: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete digest subscription"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Digest subscription deleted"})
+}
+
+// SendDigest handles POST /api/v1/digest/send - Send the digest of the last period now.
+func (h *DigestHandler) SendDigest(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	digest, err := h.digestService.SendDigest(userIdentifier)
+	if err != nil {
+		log.Error("Failed to send digest: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send digest"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
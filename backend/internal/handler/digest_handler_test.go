@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/service"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+// TestDigestHandler tests the digest endpoints without SMTP configured
+func TestDigestHandler(t *testing.T) {
+	repo, err := repository.NewFileDigestRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	digestService := service.NewDigestService(repo, &mockScanService{}, nil, &types.NotificationConfig{}, &mockLogger{})
+
+	handler := NewDigestHandler(digestService, &mockLogger{})
+	router := setupTestRouter()
+	router.GET("/digest", handler.GetSubscription)
+	router.PUT("/digest", handler.UpdateSubscription)
+	router.POST("/digest/send", handler.SendDigest)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"No subscription", http.MethodGet, "/digest", "", http.StatusNotFound},
+		{"Missing email", http.MethodPut, "/digest", `{"frequency": "weekly"}`, http.StatusBadRequest},
+		{"SMTP not configured", http.MethodPut, "/digest", `{"email": "lead@example.com"}`, http.StatusBadRequest},
+		{"Send without SMTP", http.MethodPost, "/digest/send", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
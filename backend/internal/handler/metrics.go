@@ -0,0 +1,21 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package handler provides the Prometheus metrics of the HTTP handlers.
+package handler
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/lazycatapps/trivy/backend/internal/pkg/metrics"
+)
+
+// sseListeners tracks the open SSE log streams.
+var sseListeners = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "trivy_sse_listeners",
+	Help: "Number of open SSE log streams.",
+})
+
+func init() {
+	metrics.Register(sseListeners)
+}
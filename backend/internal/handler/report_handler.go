@@ -31,6 +31,7 @@ func NewReportHandler(reportService service.ReportService, logger logger.Logger)
 
 // DownloadReport handles GET /api/v1/scan/:id/report/:format - Download scan report.
 func (h *ReportHandler) DownloadReport(c *gin.Context) {
+	log := requestLogger(c, h.logger)
 	taskID := c.Param("id")
 	format := c.Param("format")
 
@@ -45,15 +46,33 @@ func (h *ReportHandler) DownloadReport(c *gin.Context) {
 	}
 
 	if !validFormats[format] {
-		h.logger.Error("Invalid report format: %s", format)
+		log.Error("Invalid report format: %s", format)
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported format: %s", format)})
 		return
 	}
 
+	// Generate filename
+	timestamp := time.Now().Format("20060102-150405")
+	ext := format
+	if format == "cyclonedx" || format == "spdx" {
+		ext = format + ".json"
+	} else if format == "sarif" {
+		ext = "sarif"
+	} else if format == "table" {
+		ext = "txt"
+	}
+	// The filename is needed before the task is looked up (presigned URLs embed it),
+	// so the ID may be shorter than the usual prefix
+	idPrefix := taskID
+	if len(idPrefix) > 8 {
+		idPrefix = idPrefix[:8]
+	}
+	filename := fmt.Sprintf("trivy-report-%s-%s.%s", idPrefix, timestamp, ext)
+
 	// Get report
-	data, mimeType, err := h.reportService.GetReport(taskID, format)
+	report, err := h.reportService.OpenReport(getPrincipal(c), taskID, format, filename)
 	if err != nil {
-		h.logger.Error("Failed to get report for task %s (format: %s): %v", taskID, format, err)
+		log.Error("Failed to get report for task %s (format: %s): %v", taskID, format, err)
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
 		} else if strings.Contains(err.Error(), "not completed") {
@@ -64,25 +83,59 @@ func (h *ReportHandler) DownloadReport(c *gin.Context) {
 		return
 	}
 
-	// Generate filename
-	timestamp := time.Now().Format("20060102-150405")
-	ext := format
-	if format == "cyclonedx" || format == "spdx" {
-		ext = format + ".json"
-	} else if format == "sarif" {
-		ext = "sarif"
-	} else if format == "table" {
-		ext = "txt"
+	// Large reports in object storage are downloaded directly from a presigned URL
+	if report.RedirectURL != "" {
+		log.Info("Redirecting report download for task %s (format: %s, size: %d bytes)", taskID, format, report.Size)
+		c.Redirect(http.StatusFound, report.RedirectURL)
+		return
 	}
-	filename := fmt.Sprintf("trivy-report-%s-%s.%s", taskID[:8], timestamp, ext)
+	defer report.Body.Close()
+
+	log.Info("Serving report for task %s (format: %s, size: %d bytes)", taskID, format, report.Size)
+
+	// Stream the report with download headers
+	c.DataFromReader(http.StatusOK, report.Size, report.ContentType, report.Body, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", filename),
+	})
+}
 
-	h.logger.Info("Serving report for task %s (format: %s, size: %d bytes)", taskID, format, len(data))
+// DownloadArchive handles GET /api/v1/scan/:id/report/archive - Download all report formats as a zip archive.
+// The archive is streamed to the client without buffering the reports in memory.
+func (h *ReportHandler) DownloadArchive(c *gin.Context) {
+	log := requestLogger(c, h.logger)
+	taskID := c.Param("id")
 
-	// Set response headers
-	c.Header("Content-Type", mimeType)
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
-	c.Header("Content-Length", fmt.Sprintf("%d", len(data)))
+	// Optional comma-separated list of formats (default: all)
+	var formats []string
+	if param := c.Query("formats"); param != "" {
+		for _, format := range strings.Split(param, ",") {
+			if format = strings.TrimSpace(format); format != "" {
+				formats = append(formats, format)
+			}
+		}
+	}
 
-	// Send file
-	c.Data(http.StatusOK, mimeType, data)
+	archive, err := h.reportService.PrepareArchive(getPrincipal(c), taskID, formats)
+	if err != nil {
+		log.Error("Failed to prepare report archive for task %s: %v", taskID, err)
+		if strings.Contains(err.Error(), "unsupported format") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "not completed") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report archive"})
+		}
+		return
+	}
+
+	log.Info("Streaming report archive for task %s", taskID)
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", archive.Filename()))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure can only be logged (the client sees a truncated zip)
+	if err := archive.Stream(c.Writer); err != nil {
+		log.Error("Failed to stream report archive for task %s: %v", taskID, err)
+	}
 }
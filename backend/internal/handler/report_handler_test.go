@@ -5,30 +5,58 @@ package handler
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/service"
 )
 
 // mockReportService implements service.ReportService for testing
 type mockReportService struct {
-	getReportFunc     func(taskID, format string) ([]byte, string, error)
-	getReportPathFunc func(taskID, format string) (string, error)
+	openReportFunc     func(taskID, format, filename string) (*service.Report, error)
+	prepareArchiveFunc func(taskID string, formats []string) (service.ReportArchive, error)
 }
 
-func (m *mockReportService) GetReport(taskID, format string) ([]byte, string, error) {
-	if m.getReportFunc != nil {
-		return m.getReportFunc(taskID, format)
+func (m *mockReportService) OpenReport(principal models.Principal, taskID, format, filename string) (*service.Report, error) {
+	if m.openReportFunc != nil {
+		return m.openReportFunc(taskID, format, filename)
 	}
-	return nil, "", fmt.Errorf("not implemented")
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockReportService) PrepareArchive(principal models.Principal, taskID string, formats []string) (service.ReportArchive, error) {
+	if m.prepareArchiveFunc != nil {
+		return m.prepareArchiveFunc(taskID, formats)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+// mockReportArchive implements service.ReportArchive for testing
+type mockReportArchive struct {
+	filename string
+	content  string
+}
+
+func (a *mockReportArchive) Filename() string {
+	return a.filename
+}
+
+func (a *mockReportArchive) Stream(w io.Writer) error {
+	_, err := io.WriteString(w, a.content)
+	return err
 }
 
-func (m *mockReportService) GetReportPath(taskID, format string) (string, error) {
-	if m.getReportPathFunc != nil {
-		return m.getReportPathFunc(taskID, format)
+// testReport returns a report streaming the given content
+func testReport(content, contentType string) *service.Report {
+	return &service.Report{
+		Body:        io.NopCloser(strings.NewReader(content)),
+		Size:        int64(len(content)),
+		ContentType: contentType,
 	}
-	return "", fmt.Errorf("not implemented")
 }
 
 // contains checks if a string contains a substring
@@ -42,7 +70,7 @@ func TestDownloadReport(t *testing.T) {
 		name           string
 		taskID         string
 		format         string
-		mockGetReport  func(taskID, format string) ([]byte, string, error)
+		mockOpenReport func(taskID, format, filename string) (*service.Report, error)
 		expectedStatus int
 		checkHeaders   func(*testing.T, http.Header)
 		checkBody      func(*testing.T, []byte)
@@ -51,8 +79,8 @@ func TestDownloadReport(t *testing.T) {
 			name:   "Download JSON report successfully",
 			taskID: "task-123",
 			format: "json",
-			mockGetReport: func(taskID, format string) ([]byte, string, error) {
-				return []byte(`{"test": "data"}`), "application/json", nil
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				return testReport(`{"test": "data"}`, "application/json"), nil
 			},
 			expectedStatus: http.StatusOK,
 			checkHeaders: func(t *testing.T, headers http.Header) {
@@ -74,8 +102,8 @@ func TestDownloadReport(t *testing.T) {
 			name:   "Download HTML report successfully",
 			taskID: "task-456",
 			format: "html",
-			mockGetReport: func(taskID, format string) ([]byte, string, error) {
-				return []byte("<html></html>"), "text/html", nil
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				return testReport("<html></html>", "text/html"), nil
 			},
 			expectedStatus: http.StatusOK,
 			checkHeaders: func(t *testing.T, headers http.Header) {
@@ -88,7 +116,7 @@ func TestDownloadReport(t *testing.T) {
 			name:           "Invalid format",
 			taskID:         "task-789",
 			format:         "invalid",
-			mockGetReport:  nil, // Should not be called
+			mockOpenReport: nil, // Should not be called
 			expectedStatus: http.StatusBadRequest,
 			checkBody: func(t *testing.T, body []byte) {
 				// Response should contain error message
@@ -102,8 +130,17 @@ func TestDownloadReport(t *testing.T) {
 			name:   "Report not found",
 			taskID: "non-existent",
 			format: "json",
-			mockGetReport: func(taskID, format string) ([]byte, string, error) {
-				return nil, "", fmt.Errorf("task not found")
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				return nil, fmt.Errorf("task not found")
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Report of a short unknown ID not found",
+			taskID: "abc",
+			format: "json",
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				return nil, fmt.Errorf("task not found")
 			},
 			expectedStatus: http.StatusNotFound,
 		},
@@ -111,8 +148,8 @@ func TestDownloadReport(t *testing.T) {
 			name:   "Scan not completed",
 			taskID: "task-pending",
 			format: "json",
-			mockGetReport: func(taskID, format string) ([]byte, string, error) {
-				return nil, "", fmt.Errorf("scan not completed")
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				return nil, fmt.Errorf("scan not completed")
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
@@ -120,8 +157,8 @@ func TestDownloadReport(t *testing.T) {
 			name:   "Internal server error",
 			taskID: "task-error",
 			format: "json",
-			mockGetReport: func(taskID, format string) ([]byte, string, error) {
-				return nil, "", fmt.Errorf("internal error")
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				return nil, fmt.Errorf("internal error")
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -129,8 +166,8 @@ func TestDownloadReport(t *testing.T) {
 			name:   "Download CycloneDX report successfully",
 			taskID: "task-cyclonedx",
 			format: "cyclonedx",
-			mockGetReport: func(taskID, format string) ([]byte, string, error) {
-				return []byte(`{"bomFormat": "CycloneDX"}`), "application/vnd.cyclonedx+json", nil
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				return testReport(`{"bomFormat": "CycloneDX"}`, "application/vnd.cyclonedx+json"), nil
 			},
 			expectedStatus: http.StatusOK,
 			checkHeaders: func(t *testing.T, headers http.Header) {
@@ -151,8 +188,8 @@ func TestDownloadReport(t *testing.T) {
 			name:   "Download SPDX report successfully",
 			taskID: "task-spdx",
 			format: "spdx",
-			mockGetReport: func(taskID, format string) ([]byte, string, error) {
-				return []byte(`{"spdxVersion": "SPDX-2.3"}`), "application/spdx+json", nil
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				return testReport(`{"spdxVersion": "SPDX-2.3"}`, "application/spdx+json"), nil
 			},
 			expectedStatus: http.StatusOK,
 			checkHeaders: func(t *testing.T, headers http.Header) {
@@ -173,8 +210,8 @@ func TestDownloadReport(t *testing.T) {
 			name:   "Download SARIF report successfully",
 			taskID: "task-sarif",
 			format: "sarif",
-			mockGetReport: func(taskID, format string) ([]byte, string, error) {
-				return []byte(`{"version": "2.1.0", "$schema": "https://json.schemastore.org/sarif-2.1.0.json"}`), "application/sarif+json", nil
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				return testReport(`{"version": "2.1.0", "$schema": "https://json.schemastore.org/sarif-2.1.0.json"}`, "application/sarif+json"), nil
 			},
 			expectedStatus: http.StatusOK,
 			checkHeaders: func(t *testing.T, headers http.Header) {
@@ -195,8 +232,8 @@ func TestDownloadReport(t *testing.T) {
 			name:   "Download table report successfully",
 			taskID: "task-table",
 			format: "table",
-			mockGetReport: func(taskID, format string) ([]byte, string, error) {
-				return []byte("CVE-2023-1234  HIGH  nginx  1.21.0  1.21.6"), "text/plain", nil
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				return testReport("CVE-2023-1234  HIGH  nginx  1.21.0  1.21.6", "text/plain"), nil
 			},
 			expectedStatus: http.StatusOK,
 			checkHeaders: func(t *testing.T, headers http.Header) {
@@ -213,13 +250,30 @@ func TestDownloadReport(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:   "Large report is redirected to a presigned URL",
+			taskID: "task-large",
+			format: "json",
+			mockOpenReport: func(taskID, format, filename string) (*service.Report, error) {
+				if !strings.HasPrefix(filename, "trivy-report-task-lar-") {
+					return nil, fmt.Errorf("unexpected filename %s", filename)
+				}
+				return &service.Report{Size: 64 << 20, ContentType: "application/json", RedirectURL: "https://s3.example.com/reports/x?X-Amz-Signature=abc"}, nil
+			},
+			expectedStatus: http.StatusFound,
+			checkHeaders: func(t *testing.T, headers http.Header) {
+				if location := headers.Get("Location"); location != "https://s3.example.com/reports/x?X-Amz-Signature=abc" {
+					t.Errorf("Unexpected Location: %s", location)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockService := &mockReportService{
-				getReportFunc: tt.mockGetReport,
+				openReportFunc: tt.mockOpenReport,
 			}
 			handler := NewReportHandler(mockService, &mockLogger{})
 			router := setupTestRouter()
@@ -240,7 +294,7 @@ func TestDownloadReport(t *testing.T) {
 			}
 
 			// Check headers
-			if tt.checkHeaders != nil && w.Code == http.StatusOK {
+			if tt.checkHeaders != nil && (w.Code == http.StatusOK || w.Code == http.StatusFound) {
 				tt.checkHeaders(t, w.Header())
 			}
 
@@ -251,3 +305,83 @@ func TestDownloadReport(t *testing.T) {
 		})
 	}
 }
+
+// TestDownloadArchive tests the DownloadArchive handler
+func TestDownloadArchive(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockPrepare    func(taskID string, formats []string) (service.ReportArchive, error)
+		expectedStatus int
+		checkHeaders   func(*testing.T, http.Header)
+	}{
+		{
+			name: "Stream archive with all formats",
+			mockPrepare: func(taskID string, formats []string) (service.ReportArchive, error) {
+				if len(formats) != 0 {
+					return nil, fmt.Errorf("expected default formats, got %v", formats)
+				}
+				return &mockReportArchive{filename: "trivy-reports-nginx-20251002-120000.zip", content: "PK"}, nil
+			},
+			expectedStatus: http.StatusOK,
+			checkHeaders: func(t *testing.T, headers http.Header) {
+				if contentType := headers.Get("Content-Type"); contentType != "application/zip" {
+					t.Errorf("Expected Content-Type 'application/zip', got %s", contentType)
+				}
+				if disposition := headers.Get("Content-Disposition"); !contains(disposition, "trivy-reports-nginx-20251002-120000.zip") {
+					t.Errorf("Unexpected Content-Disposition: %s", disposition)
+				}
+			},
+		},
+		{
+			name:  "Selected formats",
+			query: "?formats=json, sarif",
+			mockPrepare: func(taskID string, formats []string) (service.ReportArchive, error) {
+				if strings.Join(formats, ",") != "json,sarif" {
+					return nil, fmt.Errorf("unexpected formats %v", formats)
+				}
+				return &mockReportArchive{filename: "a.zip"}, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Unsupported format",
+			query: "?formats=pdf",
+			mockPrepare: func(taskID string, formats []string) (service.ReportArchive, error) {
+				return nil, fmt.Errorf("unsupported format: pdf")
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Task not completed",
+			mockPrepare: func(taskID string, formats []string) (service.ReportArchive, error) {
+				return nil, fmt.Errorf("task not completed")
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockReportService{
+				prepareArchiveFunc: tt.mockPrepare,
+			}
+			handler := NewReportHandler(mockService, &mockLogger{})
+			router := setupTestRouter()
+			router.GET("/scan/:id/report/archive", handler.DownloadArchive)
+
+			req := httptest.NewRequest(http.MethodGet, "/scan/task-123/report/archive"+strings.ReplaceAll(tt.query, " ", "%20"), nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.checkHeaders != nil {
+				tt.checkHeaders(t, w.Header())
+			}
+		})
+	}
+}
@@ -5,15 +5,25 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/trivy/backend/internal/middleware"
 	"github.com/lazycatapps/trivy/backend/internal/models"
+	apperrors "github.com/lazycatapps/trivy/backend/internal/pkg/errors"
 	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
 	"github.com/lazycatapps/trivy/backend/internal/service"
 )
 
+// uploadFormOverhead is the room left for the multipart boundaries, headers and
+// form fields of an upload on top of the upload size limit.
+const uploadFormOverhead = 1024 * 1024
+
 // ScanHandler handles scan-related HTTP requests.
 type ScanHandler struct {
 	scanService service.ScanService
@@ -32,11 +42,12 @@ func NewScanHandler(scanService service.ScanService, logger logger.Logger) *Scan
 func (h *ScanHandler) CreateScan(c *gin.Context) {
 	// Get user identifier from session (email, userID, etc.)
 	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
 
 	// Parse request body
 	var req models.ScanRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid scan request: %v", err)
+		log.Error("Invalid scan request: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
 		return
 	}
@@ -47,15 +58,96 @@ func (h *ScanHandler) CreateScan(c *gin.Context) {
 		return
 	}
 
-	// Create scan task
+	// Create scan task (the request ID is propagated to the task logs)
+	req.RequestID = middleware.GetRequestID(c)
 	task, err := h.scanService.CreateScanTask(userIdentifier, &req)
 	if err != nil {
-		h.logger.Error("Failed to create scan task: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create scan task"})
+		log.Error("Failed to create scan task: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create scan task"})
+		}
+		return
+	}
+
+	log.Info("Created scan task %s for user %s", task.ID, userIdentifier)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scan started",
+		"id":      task.ID,
+	})
+}
+
+// UploadScan handles POST /api/v1/scan/upload - Scan an uploaded image archive, SBOM, filesystem tarball or VM image.
+// Expects multipart/form-data with a "file" part, a "targetType" field and optional
+// "options" (JSON with the same scan options as POST /api/v1/scan).
+func (h *ScanHandler) UploadScan(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	// Reject uploads over the size limit or quota before the body is spooled to disk
+	limit, err := h.scanService.UploadLimit(userIdentifier)
+	if err != nil {
+		log.Error("Upload rejected for user %s: %v", userIdentifier, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create scan task"})
+		}
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+uploadFormOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		log.Error("Invalid upload request: %v", err)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Upload exceeds maximum size of %d bytes", limit)})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		}
+		return
+	}
+
+	// Parse optional scan options
+	var req models.ScanRequest
+	if options := c.PostForm("options"); options != "" {
+		if err := json.Unmarshal([]byte(options), &req); err != nil {
+			log.Error("Invalid upload scan options: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid options: %v", err)})
+			return
+		}
+	}
+	if targetType := c.PostForm("targetType"); targetType != "" {
+		req.TargetType = models.TargetType(targetType)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Error("Failed to open uploaded file: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read uploaded file"})
 		return
 	}
+	defer file.Close()
 
-	h.logger.Info("Created scan task %s for user %s", task.ID, userIdentifier)
+	req.RequestID = middleware.GetRequestID(c)
+	task, err := h.scanService.CreateUploadScanTask(userIdentifier, &req, fileHeader.Filename, file)
+	if err != nil {
+		log.Error("Failed to create upload scan task: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create scan task"})
+		}
+		return
+	}
+
+	log.Info("Created %s upload scan task %s for user %s (%d bytes)", req.TargetType, task.ID, userIdentifier, fileHeader.Size)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Scan started",
@@ -63,33 +155,89 @@ func (h *ScanHandler) CreateScan(c *gin.Context) {
 	})
 }
 
+// sharedTaskResponse hides the registry credentials of a task from group members it is shared with.
+type sharedTaskResponse struct {
+	*models.ScanTask
+	ScanConfig *models.ScanConfig `json:"scanConfig,omitempty"`
+}
+
+// respondTaskError writes the error response of a failed task authorization.
+func respondTaskError(c *gin.Context, err error) {
+	appErr, ok := err.(*apperrors.AppError)
+	if ok {
+		c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get task"})
+	}
+}
+
 // GetScan handles GET /api/v1/scan/:id - Get scan task details.
 func (h *ScanHandler) GetScan(c *gin.Context) {
+	log := requestLogger(c, h.logger)
 	taskID := c.Param("id")
+	principal := getPrincipal(c)
 
-	// Get task
-	task, err := h.scanService.GetTask(taskID)
+	// Get task (owned by the user or shared with one of the user's groups)
+	task, err := h.scanService.AuthorizeTask(principal, taskID, models.SharePermissionRead)
 	if err != nil {
-		h.logger.Error("Failed to get task %s: %v", taskID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		log.Error("Failed to get task %s: %v", taskID, err)
+		respondTaskError(c, err)
 		return
 	}
 
-	// TODO: Check if user owns this task (for OIDC multi-tenancy)
-	// For now, allow any user to view any task
+	if task.UserID != principal.UserID && task.ScanConfig != nil {
+		config := *task.ScanConfig
+		config.Username = ""
+		config.Password = ""
+		c.JSON(http.StatusOK, sharedTaskResponse{ScanTask: task, ScanConfig: &config})
+		return
+	}
 
 	c.JSON(http.StatusOK, task)
 }
 
+// ShareScan handles PUT /api/v1/scan/:id/shares - Replace the OIDC groups a task is shared with.
+func (h *ScanHandler) ShareScan(c *gin.Context) {
+	log := requestLogger(c, h.logger)
+	taskID := c.Param("id")
+	principal := getPrincipal(c)
+
+	var req models.ShareTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error("Invalid share request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	task, err := h.scanService.ShareTask(principal, taskID, &req)
+	if err != nil {
+		log.Error("Failed to share task %s: %v", taskID, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share task"})
+		}
+		return
+	}
+
+	log.Info("Updated shares of task %s for user %s", taskID, principal.UserID)
+	c.JSON(http.StatusOK, gin.H{
+		"id":     task.ID,
+		"shares": task.Shares,
+	})
+}
+
 // ListScans handles GET /api/v1/scan - List scan tasks with pagination.
 func (h *ScanHandler) ListScans(c *gin.Context) {
 	// Get user identifier from session
 	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
 
 	// Parse query parameters
 	var req models.TaskListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		h.logger.Error("Invalid list request: %v", err)
+		log.Error("Invalid list request: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
 		return
 	}
@@ -97,28 +245,122 @@ func (h *ScanHandler) ListScans(c *gin.Context) {
 	// List tasks
 	response, err := h.scanService.ListTasks(userIdentifier, &req)
 	if err != nil {
-		h.logger.Error("Failed to list tasks: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tasks"})
+		log.Error("Failed to list tasks: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tasks"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportScans handles GET /api/v1/scan/export - Export scan history as CSV, JSON or XLSX.
+func (h *ScanHandler) ExportScans(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	var req models.ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Error("Invalid export request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	data, mimeType, err := h.scanService.ExportTasks(userIdentifier, &req)
+	if err != nil {
+		log.Error("Failed to export scan history: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export scan history"})
+		}
+		return
+	}
+
+	// Generate filename ("excel" is accepted as an alias of xlsx)
+	ext := strings.ToLower(req.Format)
+	if ext == "excel" {
+		ext = "xlsx"
+	}
+	filename := fmt.Sprintf("scan-history-%s.%s", time.Now().Format("20060102"), ext)
+
+	log.Info("Exported scan history for user %s (format: %s, size: %d bytes)", userIdentifier, ext, len(data))
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, mimeType, data)
+}
+
+// ListFindings handles GET /api/v1/scan/:id/findings - List the structured findings of a task.
+func (h *ScanHandler) ListFindings(c *gin.Context) {
+	log := requestLogger(c, h.logger)
+	taskID := c.Param("id")
+
+	var req models.FindingListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Error("Invalid findings request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	response, err := h.scanService.ListFindings(getPrincipal(c), taskID, &req)
+	if err != nil {
+		log.Error("Failed to list findings of task %s: %v", taskID, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list findings"})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// DiffScans handles GET /api/v1/scan/diff - Compare the vulnerabilities of two tasks.
+func (h *ScanHandler) DiffScans(c *gin.Context) {
+	log := requestLogger(c, h.logger)
+
+	var req models.DiffRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Error("Invalid diff request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	diff, err := h.scanService.DiffTasks(getPrincipal(c), req.Base, req.Target)
+	if err != nil {
+		log.Error("Failed to compare tasks %s and %s: %v", req.Base, req.Target, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare tasks"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
 // StreamLogs handles GET /api/v1/scan/:id/logs - Stream scan logs via SSE.
 func (h *ScanHandler) StreamLogs(c *gin.Context) {
 	taskID := c.Param("id")
+	log := requestLogger(c, h.logger)
 
-	// Get task
-	task, err := h.scanService.GetTask(taskID)
+	// Get task (owned by the user or shared with one of the user's groups)
+	task, err := h.scanService.AuthorizeTask(getPrincipal(c), taskID, models.SharePermissionRead)
 	if err != nil {
-		h.logger.Error("Failed to get task %s: %v", taskID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		log.Error("Failed to get task %s: %v", taskID, err)
+		respondTaskError(c, err)
 		return
 	}
 
-	// TODO: Check if user owns this task
-
 	// Set SSE headers
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
@@ -128,14 +370,21 @@ func (h *ScanHandler) StreamLogs(c *gin.Context) {
 	// Create log listener
 	logChan := task.AddLogListener()
 	defer task.RemoveLogListener(logChan)
+	sseListeners.Inc()
+	defer sseListeners.Dec()
 
 	// Send existing logs first
 	existingLogs := task.GetLogLines()
-	for _, log := range existingLogs {
-		c.SSEvent("message", log)
+	for _, line := range existingLogs {
+		c.SSEvent("message", line)
 		c.Writer.Flush()
 	}
 
+	// Finished tasks (completed, failed or cancelled) produce no new logs
+	if task.Status.IsFinished() {
+		return
+	}
+
 	// Stream new logs
 	clientGone := c.Request.Context().Done()
 
@@ -143,15 +392,15 @@ func (h *ScanHandler) StreamLogs(c *gin.Context) {
 		select {
 		case <-clientGone:
 			// Client disconnected
-			h.logger.Info("Client disconnected from log stream for task %s", taskID)
+			log.Info("Client disconnected from log stream for task %s", taskID)
 			return
-		case log, ok := <-logChan:
+		case line, ok := <-logChan:
 			if !ok {
 				// Channel closed, task completed
-				h.logger.Info("Log stream closed for task %s", taskID)
+				log.Info("Log stream closed for task %s", taskID)
 				return
 			}
-			c.SSEvent("message", log)
+			c.SSEvent("message", line)
 			c.Writer.Flush()
 		}
 	}
@@ -161,11 +410,12 @@ func (h *ScanHandler) StreamLogs(c *gin.Context) {
 func (h *ScanHandler) GetQueueStatus(c *gin.Context) {
 	// Get user identifier from session
 	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
 
 	// Get queue status
 	status, err := h.scanService.GetQueueStatus(userIdentifier)
 	if err != nil {
-		h.logger.Error("Failed to get queue status: %v", err)
+		log.Error("Failed to get queue status: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get queue status"})
 		return
 	}
@@ -175,11 +425,12 @@ func (h *ScanHandler) GetQueueStatus(c *gin.Context) {
 
 // ListDockerImages handles GET /api/v1/docker/images - List Docker images from host.
 func (h *ScanHandler) ListDockerImages(c *gin.Context) {
-	h.logger.Info("Listing Docker images from host")
+	log := requestLogger(c, h.logger)
+	log.Info("Listing Docker images from host")
 
 	images, err := h.scanService.ListDockerImages()
 	if err != nil {
-		h.logger.Error("Failed to list Docker images: %v", err)
+		log.Error("Failed to list Docker images: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to list Docker images: %v", err)})
 		return
 	}
@@ -191,11 +442,12 @@ func (h *ScanHandler) ListDockerImages(c *gin.Context) {
 
 // ListDockerContainers handles GET /api/v1/docker/containers - List running Docker containers from host.
 func (h *ScanHandler) ListDockerContainers(c *gin.Context) {
-	h.logger.Info("Listing Docker containers from host")
+	log := requestLogger(c, h.logger)
+	log.Info("Listing Docker containers from host")
 
 	containers, err := h.scanService.ListDockerContainers()
 	if err != nil {
-		h.logger.Error("Failed to list Docker containers: %v", err)
+		log.Error("Failed to list Docker containers: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to list Docker containers: %v", err)})
 		return
 	}
@@ -207,60 +459,178 @@ func (h *ScanHandler) ListDockerContainers(c *gin.Context) {
 
 // DeleteScan handles DELETE /api/v1/scan/:id - Delete scan task.
 func (h *ScanHandler) DeleteScan(c *gin.Context) {
+	log := requestLogger(c, h.logger)
 	taskID := c.Param("id")
 
-	// Get task first to check ownership
-	_, err := h.scanService.GetTask(taskID)
-	if err != nil {
-		h.logger.Error("Failed to get task %s: %v", taskID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	// Get task first to check the user may manage it
+	if _, err := h.scanService.AuthorizeTask(getPrincipal(c), taskID, models.SharePermissionManage); err != nil {
+		log.Error("Failed to get task %s: %v", taskID, err)
+		respondTaskError(c, err)
 		return
 	}
 
-	// TODO: Check if user owns this task (for OIDC multi-tenancy)
-	// For now, allow any user to delete any task
-
 	// Delete the task
 	if err := h.scanService.DeleteTask(taskID); err != nil {
-		h.logger.Error("Failed to delete task %s: %v", taskID, err)
+		log.Error("Failed to delete task %s: %v", taskID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
 		return
 	}
 
-	h.logger.Info("Deleted scan task %s", taskID)
+	log.Info("Deleted scan task %s", taskID)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Task deleted successfully",
 	})
 }
 
+// RescanScan handles POST /api/v1/scan/:id/rescan - Rescan with the parameters of an existing task.
+func (h *ScanHandler) RescanScan(c *gin.Context) {
+	principal := getPrincipal(c)
+	taskID := c.Param("id")
+	log := requestLogger(c, h.logger)
+
+	// Request body is optional (only needed to re-supply credentials)
+	var req models.RescanRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error("Invalid rescan request: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+			return
+		}
+	}
+
+	req.RequestID = middleware.GetRequestID(c)
+	task, err := h.scanService.Rescan(principal, taskID, &req)
+	if err != nil {
+		log.Error("Failed to rescan task %s: %v", taskID, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create scan task"})
+		}
+		return
+	}
+
+	log.Info("Created rescan task %s of task %s for user %s", task.ID, taskID, principal.UserID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Scan started",
+		"id":           task.ID,
+		"parentTaskId": task.ParentTaskID,
+	})
+}
+
+// CancelScan handles DELETE /api/v1/scan/:id/cancel - Cancel a queued or running scan task.
+func (h *ScanHandler) CancelScan(c *gin.Context) {
+	log := requestLogger(c, h.logger)
+	taskID := c.Param("id")
+
+	if _, err := h.scanService.AuthorizeTask(getPrincipal(c), taskID, models.SharePermissionManage); err != nil {
+		log.Error("Failed to get task %s: %v", taskID, err)
+		respondTaskError(c, err)
+		return
+	}
+
+	if err := h.scanService.CancelTask(taskID); err != nil {
+		log.Error("Failed to cancel task %s: %v", taskID, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel task"})
+		}
+		return
+	}
+
+	log.Info("Cancelled scan task %s", taskID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scan task cancelled successfully",
+	})
+}
+
 // DeleteAllScans handles DELETE /api/v1/scan - Delete all scan tasks for current user.
 func (h *ScanHandler) DeleteAllScans(c *gin.Context) {
 	// Get user identifier from session
 	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
 
-	h.logger.Info("Deleting all scan tasks for user %s", userIdentifier)
+	log.Info("Deleting all scan tasks for user %s", userIdentifier)
 
 	// Delete all tasks
 	if err := h.scanService.DeleteAllTasks(userIdentifier); err != nil {
-		h.logger.Error("Failed to delete all tasks for user %s: %v", userIdentifier, err)
+		log.Error("Failed to delete all tasks for user %s: %v", userIdentifier, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete all tasks"})
 		return
 	}
 
-	h.logger.Info("Deleted all scan tasks for user %s", userIdentifier)
+	log.Info("Deleted all scan tasks for user %s", userIdentifier)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "All tasks deleted successfully",
 	})
 }
 
+// ListAllScans handles GET /api/v1/admin/scans - List the scan tasks of all users (admin).
+// Accepts the same query parameters as ListScans.
+func (h *ScanHandler) ListAllScans(c *gin.Context) {
+	log := requestLogger(c, h.logger)
+	var req models.TaskListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Error("Invalid list request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	req.AllUsers = true
+
+	response, err := h.scanService.ListTasks(getUserIdentifier(c), &req)
+	if err != nil {
+		log.Error("Failed to list tasks of all users: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tasks"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PurgeScans handles POST /api/v1/admin/purge - Delete old finished tasks of all users (admin).
+func (h *ScanHandler) PurgeScans(c *gin.Context) {
+	log := requestLogger(c, h.logger)
+	var req models.PurgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request body: %v", err)})
+		return
+	}
+
+	log.Info("User %s purging tasks older than %d days", getUserIdentifier(c), req.OlderThanDays)
+
+	response, err := h.scanService.PurgeTasks(&req)
+	if err != nil {
+		log.Error("Failed to purge tasks: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge tasks"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // GetTrivyVersion handles GET /api/v1/trivy/version - Get Trivy Server version information.
 func (h *ScanHandler) GetTrivyVersion(c *gin.Context) {
-	h.logger.Info("Fetching Trivy Server version")
+	log := requestLogger(c, h.logger)
+	log.Info("Fetching Trivy Server version")
 
 	// Get version information from service
 	version, err := h.scanService.GetTrivyVersion(c.Request.Context())
 	if err != nil {
-		h.logger.Error("Failed to get Trivy Server version: %v", err)
+		log.Error("Failed to get Trivy Server version: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get Trivy Server version: %v", err)})
 		return
 	}
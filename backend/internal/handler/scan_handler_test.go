@@ -8,12 +8,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lazycatapps/trivy/backend/internal/models"
+	apperrors "github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/service"
 )
 
 // mockScanService implements service.ScanService for testing
@@ -23,6 +28,20 @@ type mockScanService struct {
 	listTasksFunc      func(userID string, req *models.TaskListRequest) (*models.TaskListResponse, error)
 	getQueueStatusFunc func(userID string) (*models.QueueStatusResponse, error)
 	getTrivyVersionFunc func(ctx context.Context) (*models.TrivyVersion, error)
+	cancelTaskFunc      func(taskID string) error
+	rescanFunc          func(principal models.Principal, taskID string, req *models.RescanRequest) (*models.ScanTask, error)
+	exportTasksFunc     func(userID string, req *models.ExportRequest) ([]byte, string, error)
+	createUploadFunc    func(userID string, req *models.ScanRequest, filename string, content io.Reader) (*models.ScanTask, error)
+	uploadLimitFunc     func(userID string) (int64, error)
+	createScheduleFunc  func(userID string, req *models.ScheduleRequest) (*models.Schedule, error)
+	listSchedulesFunc   func(userID string) ([]*models.Schedule, error)
+	setPausedFunc       func(userID, scheduleID string, paused bool) (*models.Schedule, error)
+	deleteScheduleFunc  func(userID, scheduleID string) error
+	diffTasksFunc       func(principal models.Principal, baseTaskID, targetTaskID string) (*models.ScanDiff, error)
+	listFindingsFunc    func(principal models.Principal, taskID string, req *models.FindingListRequest) (*models.FindingListResponse, error)
+	authorizeTaskFunc   func(principal models.Principal, taskID string, required models.SharePermission) (*models.ScanTask, error)
+	shareTaskFunc       func(principal models.Principal, taskID string, req *models.ShareTaskRequest) (*models.ScanTask, error)
+	purgeTasksFunc      func(req *models.PurgeRequest) (*models.PurgeResponse, error)
 }
 
 func (m *mockScanService) CreateScanTask(userID string, req *models.ScanRequest) (*models.ScanTask, error) {
@@ -39,6 +58,20 @@ func (m *mockScanService) GetTask(taskID string) (*models.ScanTask, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
+func (m *mockScanService) AuthorizeTask(principal models.Principal, taskID string, required models.SharePermission) (*models.ScanTask, error) {
+	if m.authorizeTaskFunc != nil {
+		return m.authorizeTaskFunc(principal, taskID, required)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) ShareTask(principal models.Principal, taskID string, req *models.ShareTaskRequest) (*models.ScanTask, error) {
+	if m.shareTaskFunc != nil {
+		return m.shareTaskFunc(principal, taskID, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
 func (m *mockScanService) ListTasks(userID string, req *models.TaskListRequest) (*models.TaskListResponse, error) {
 	if m.listTasksFunc != nil {
 		return m.listTasksFunc(userID, req)
@@ -76,6 +109,90 @@ func (m *mockScanService) DeleteAllTasks(userID string) error {
 	return fmt.Errorf("not implemented")
 }
 
+func (m *mockScanService) PurgeTasks(req *models.PurgeRequest) (*models.PurgeResponse, error) {
+	if m.purgeTasksFunc != nil {
+		return m.purgeTasksFunc(req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) CancelTask(taskID string) error {
+	if m.cancelTaskFunc != nil {
+		return m.cancelTaskFunc(taskID)
+	}
+	return fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) Rescan(principal models.Principal, taskID string, req *models.RescanRequest) (*models.ScanTask, error) {
+	if m.rescanFunc != nil {
+		return m.rescanFunc(principal, taskID, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) ListFindings(principal models.Principal, taskID string, req *models.FindingListRequest) (*models.FindingListResponse, error) {
+	if m.listFindingsFunc != nil {
+		return m.listFindingsFunc(principal, taskID, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) DiffTasks(principal models.Principal, baseTaskID, targetTaskID string) (*models.ScanDiff, error) {
+	if m.diffTasksFunc != nil {
+		return m.diffTasksFunc(principal, baseTaskID, targetTaskID)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) ExportTasks(userID string, req *models.ExportRequest) ([]byte, string, error) {
+	if m.exportTasksFunc != nil {
+		return m.exportTasksFunc(userID, req)
+	}
+	return nil, "", fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) CreateUploadScanTask(userID string, req *models.ScanRequest, filename string, content io.Reader) (*models.ScanTask, error) {
+	if m.createUploadFunc != nil {
+		return m.createUploadFunc(userID, req, filename, content)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) UploadLimit(userID string) (int64, error) {
+	if m.uploadLimitFunc != nil {
+		return m.uploadLimitFunc(userID)
+	}
+	return 1024, nil
+}
+
+func (m *mockScanService) CreateSchedule(userID string, req *models.ScheduleRequest) (*models.Schedule, error) {
+	if m.createScheduleFunc != nil {
+		return m.createScheduleFunc(userID, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) ListSchedules(userID string) ([]*models.Schedule, error) {
+	if m.listSchedulesFunc != nil {
+		return m.listSchedulesFunc(userID)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) SetSchedulePaused(userID, scheduleID string, paused bool) (*models.Schedule, error) {
+	if m.setPausedFunc != nil {
+		return m.setPausedFunc(userID, scheduleID, paused)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockScanService) DeleteSchedule(userID, scheduleID string) error {
+	if m.deleteScheduleFunc != nil {
+		return m.deleteScheduleFunc(userID, scheduleID)
+	}
+	return fmt.Errorf("not implemented")
+}
+
 func (m *mockScanService) Start() {}
 func (m *mockScanService) Stop()  {}
 
@@ -250,15 +367,18 @@ func TestGetScan(t *testing.T) {
 	tests := []struct {
 		name           string
 		taskID         string
-		mockGetTask    func(taskID string) (*models.ScanTask, error)
+		mockAuthorize  func(principal models.Principal, taskID string, required models.SharePermission) (*models.ScanTask, error)
 		expectedStatus int
 		checkResponse  func(*testing.T, *models.ScanTask)
 	}{
 		{
 			name:   "Get existing task",
 			taskID: "task-123",
-			mockGetTask: func(taskID string) (*models.ScanTask, error) {
-				task := models.NewScanTask(taskID, "user-1", "alpine:latest", &models.ScanConfig{})
+			mockAuthorize: func(principal models.Principal, taskID string, required models.SharePermission) (*models.ScanTask, error) {
+				if required != models.SharePermissionRead {
+					return nil, fmt.Errorf("expected read permission, got %s", required)
+				}
+				task := models.NewScanTask(taskID, principal.UserID, "alpine:latest", &models.ScanConfig{Username: "deployer", Password: "secret"})
 				task.Status = models.ScanStatusCompleted
 				return task, nil
 			},
@@ -270,13 +390,34 @@ func TestGetScan(t *testing.T) {
 				if task.Status != models.ScanStatusCompleted {
 					t.Errorf("Expected status completed, got %s", task.Status)
 				}
+				if task.ScanConfig.Password != "secret" {
+					t.Error("Expected the owner to see the task credentials")
+				}
+			},
+		},
+		{
+			name:   "Task shared with a group of the user",
+			taskID: "task-123",
+			mockAuthorize: func(principal models.Principal, taskID string, required models.SharePermission) (*models.ScanTask, error) {
+				task := models.NewScanTask(taskID, "owner", "alpine:latest", &models.ScanConfig{Username: "deployer", Password: "secret", Format: "json"})
+				task.Shares = []models.TaskShare{{Group: "dev", Permission: models.SharePermissionRead}}
+				return task, nil
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, task *models.ScanTask) {
+				if task.ID != "task-123" || task.ScanConfig.Format != "json" || len(task.Shares) != 1 {
+					t.Errorf("Unexpected shared task: %+v", task)
+				}
+				if task.ScanConfig.Username != "" || task.ScanConfig.Password != "" {
+					t.Error("Registry credentials must be hidden from group members")
+				}
 			},
 		},
 		{
 			name:   "Task not found",
 			taskID: "non-existent",
-			mockGetTask: func(taskID string) (*models.ScanTask, error) {
-				return nil, fmt.Errorf("task not found")
+			mockAuthorize: func(principal models.Principal, taskID string, required models.SharePermission) (*models.ScanTask, error) {
+				return nil, apperrors.ErrTaskNotFound
 			},
 			expectedStatus: http.StatusNotFound,
 		},
@@ -286,7 +427,7 @@ func TestGetScan(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockService := &mockScanService{
-				getTaskFunc: tt.mockGetTask,
+				authorizeTaskFunc: tt.mockAuthorize,
 			}
 			handler := NewScanHandler(mockService, &mockLogger{})
 			router := setupTestRouter()
@@ -559,3 +700,642 @@ func TestGetQueueStatus(t *testing.T) {
 		})
 	}
 }
+
+// TestCancelScan tests the CancelScan handler
+func TestCancelScan(t *testing.T) {
+	tests := []struct {
+		name           string
+		taskID         string
+		readOnly       bool // Whether the user may only read the task
+		mockCancelTask func(taskID string) error
+		expectedStatus int
+	}{
+		{
+			name:   "Cancel task successfully",
+			taskID: "task-123",
+			mockCancelTask: func(taskID string) error {
+				if taskID != "task-123" {
+					return fmt.Errorf("unexpected task ID %s", taskID)
+				}
+				return nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Task not found",
+			taskID: "missing",
+			mockCancelTask: func(taskID string) error {
+				return apperrors.ErrTaskNotFound
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Read-only access to a shared task",
+			taskID:         "task-123",
+			readOnly:       true,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Task already finished",
+			taskID: "task-123",
+			mockCancelTask: func(taskID string) error {
+				return apperrors.NewInvalidInput("Cannot cancel task: task is already finished")
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Unexpected service error",
+			taskID: "task-123",
+			mockCancelTask: func(taskID string) error {
+				return fmt.Errorf("service error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockScanService{
+				cancelTaskFunc: tt.mockCancelTask,
+				authorizeTaskFunc: func(principal models.Principal, taskID string, required models.SharePermission) (*models.ScanTask, error) {
+					if tt.readOnly && required == models.SharePermissionManage {
+						return nil, apperrors.ErrForbidden
+					}
+					return models.NewScanTask(taskID, principal.UserID, "alpine:latest", &models.ScanConfig{}), nil
+				},
+			}
+			handler := NewScanHandler(mockService, &mockLogger{})
+			router := setupTestRouter()
+			router.DELETE("/scan/:id/cancel", handler.CancelScan)
+
+			req := httptest.NewRequest(http.MethodDelete, "/scan/"+tt.taskID+"/cancel", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestShareScan tests the ShareScan handler
+func TestShareScan(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockShare      func(principal models.Principal, taskID string, req *models.ShareTaskRequest) (*models.ScanTask, error)
+		expectedStatus int
+	}{
+		{
+			name: "Share with groups",
+			body: `{"shares": [{"group": "dev", "permission": "read"}]}`,
+			mockShare: func(principal models.Principal, taskID string, req *models.ShareTaskRequest) (*models.ScanTask, error) {
+				if principal.UserID != "alice@example.com_user-1" || len(principal.Groups) != 1 || principal.Groups[0] != "admins" {
+					return nil, fmt.Errorf("unexpected principal %+v", principal)
+				}
+				task := models.NewScanTask(taskID, principal.UserID, "alpine:latest", &models.ScanConfig{})
+				task.Shares = req.Shares
+				return task, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid JSON body",
+			body:           `{invalid`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Not the owner",
+			body: `{"shares": []}`,
+			mockShare: func(principal models.Principal, taskID string, req *models.ShareTaskRequest) (*models.ScanTask, error) {
+				return nil, apperrors.NewForbidden("Only the task owner can change sharing")
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewScanHandler(&mockScanService{shareTaskFunc: tt.mockShare}, &mockLogger{})
+			router := setupTestRouter()
+			router.PUT("/scan/:id/shares", func(c *gin.Context) {
+				c.Set("session", &service.SessionInfo{UserID: "user-1", Email: "alice@example.com", Groups: []string{"admins"}})
+				handler.ShareScan(c)
+			})
+
+			req := httptest.NewRequest(http.MethodPut, "/scan/task-123/shares", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestListAllScans tests the admin handler listing the tasks of all users
+func TestListAllScans(t *testing.T) {
+	var received *models.TaskListRequest
+	mockService := &mockScanService{
+		listTasksFunc: func(userID string, req *models.TaskListRequest) (*models.TaskListResponse, error) {
+			received = req
+			return &models.TaskListResponse{Total: 0, Page: req.Page, PageSize: req.PageSize, Tasks: []*models.TaskSummary{}}, nil
+		},
+	}
+	handler := NewScanHandler(mockService, &mockLogger{})
+	router := setupTestRouter()
+	router.GET("/admin/scans", handler.ListAllScans)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/scans?status=completed&allUsers=false", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if received == nil || !received.AllUsers || received.Status != "completed" {
+		t.Errorf("Expected an all-users request with the status filter, got %+v", received)
+	}
+}
+
+// TestPurgeScans tests the admin purge handler
+func TestPurgeScans(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockPurge      func(req *models.PurgeRequest) (*models.PurgeResponse, error)
+		expectedStatus int
+	}{
+		{
+			name: "Purge old tasks",
+			body: `{"olderThanDays": 30, "userId": "user-1"}`,
+			mockPurge: func(req *models.PurgeRequest) (*models.PurgeResponse, error) {
+				if req.OlderThanDays != 30 || req.UserID != "user-1" {
+					return nil, fmt.Errorf("unexpected request %+v", req)
+				}
+				return &models.PurgeResponse{Deleted: 3, FreedBytes: 1024}, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid JSON body",
+			body:           `{invalid`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Negative age",
+			body: `{"olderThanDays": -1}`,
+			mockPurge: func(req *models.PurgeRequest) (*models.PurgeResponse, error) {
+				return nil, apperrors.NewInvalidInput("olderThanDays must not be negative")
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewScanHandler(&mockScanService{purgeTasksFunc: tt.mockPurge}, &mockLogger{})
+			router := setupTestRouter()
+			router.POST("/admin/purge", handler.PurgeScans)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/purge", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestRescanScan tests the RescanScan handler
+func TestRescanScan(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockRescan     func(principal models.Principal, taskID string, req *models.RescanRequest) (*models.ScanTask, error)
+		expectedStatus int
+		checkResponse  func(*testing.T, map[string]interface{})
+	}{
+		{
+			name: "Rescan without body",
+			mockRescan: func(principal models.Principal, taskID string, req *models.RescanRequest) (*models.ScanTask, error) {
+				task := models.NewScanTask("task-456", principal.UserID, "alpine:latest", &models.ScanConfig{})
+				task.ParentTaskID = taskID
+				return task, nil
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, resp map[string]interface{}) {
+				if resp["id"] != "task-456" {
+					t.Errorf("Expected task ID 'task-456', got %v", resp["id"])
+				}
+				if resp["parentTaskId"] != "task-123" {
+					t.Errorf("Expected parentTaskId 'task-123', got %v", resp["parentTaskId"])
+				}
+			},
+		},
+		{
+			name: "Rescan with saved config",
+			body: `{"configName": "prod"}`,
+			mockRescan: func(principal models.Principal, taskID string, req *models.RescanRequest) (*models.ScanTask, error) {
+				if req.ConfigName != "prod" {
+					return nil, fmt.Errorf("expected configName 'prod', got '%s'", req.ConfigName)
+				}
+				return models.NewScanTask("task-456", principal.UserID, "alpine:latest", &models.ScanConfig{}), nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid JSON body",
+			body:           `{invalid`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Original task not found",
+			mockRescan: func(principal models.Principal, taskID string, req *models.RescanRequest) (*models.ScanTask, error) {
+				return nil, apperrors.ErrTaskNotFound
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Missing credentials",
+			mockRescan: func(principal models.Principal, taskID string, req *models.RescanRequest) (*models.ScanTask, error) {
+				return nil, apperrors.NewInvalidInput("Registry password is required")
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockScanService{
+				rescanFunc: tt.mockRescan,
+			}
+			handler := NewScanHandler(mockService, &mockLogger{})
+			router := setupTestRouter()
+			router.POST("/scan/:id/rescan", handler.RescanScan)
+
+			req := httptest.NewRequest(http.MethodPost, "/scan/task-123/rescan", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.checkResponse != nil && w.Code == http.StatusOK {
+				var response map[string]interface{}
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				tt.checkResponse(t, response)
+			}
+		})
+	}
+}
+
+// TestUploadScan tests the UploadScan handler
+func TestUploadScan(t *testing.T) {
+	tests := []struct {
+		name           string
+		filename       string
+		fields         map[string]string
+		content        string
+		mockLimit      func(userID string) (int64, error)
+		mockUpload     func(userID string, req *models.ScanRequest, filename string, content io.Reader) (*models.ScanTask, error)
+		expectedStatus int
+	}{
+		{
+			name:     "Valid SBOM upload",
+			filename: "bom.cdx.json",
+			fields: map[string]string{
+				"targetType": "sbom",
+				"options":    `{"severity": ["CRITICAL"]}`,
+			},
+			mockUpload: func(userID string, req *models.ScanRequest, filename string, content io.Reader) (*models.ScanTask, error) {
+				if req.TargetType != models.TargetTypeSBOM {
+					return nil, fmt.Errorf("expected sbom target type, got %s", req.TargetType)
+				}
+				if len(req.Severity) != 1 || req.Severity[0] != "CRITICAL" {
+					return nil, fmt.Errorf("expected options to be applied, got %v", req.Severity)
+				}
+				data, _ := io.ReadAll(content)
+				if filename != "bom.cdx.json" || string(data) != "file-content" {
+					return nil, fmt.Errorf("unexpected upload %s: %q", filename, data)
+				}
+				return models.NewScanTask("task-123", userID, filename, &models.ScanConfig{}), nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing file",
+			fields:         map[string]string{"targetType": "sbom"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid options",
+			filename:       "bom.cdx.json",
+			fields:         map[string]string{"targetType": "sbom", "options": "{invalid"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "Upload too large",
+			filename: "disk.vmdk",
+			fields:   map[string]string{"targetType": "vm"},
+			mockUpload: func(userID string, req *models.ScanRequest, filename string, content io.Reader) (*models.ScanTask, error) {
+				return nil, apperrors.NewPayloadTooLarge("Upload exceeds maximum size")
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "Storage quota exceeded",
+			filename: "app.tar",
+			fields:   map[string]string{"targetType": "image"},
+			mockUpload: func(userID string, req *models.ScanRequest, filename string, content io.Reader) (*models.ScanTask, error) {
+				if req.TargetType != models.TargetTypeImage {
+					return nil, fmt.Errorf("expected image target type, got %s", req.TargetType)
+				}
+				return nil, apperrors.NewQuotaExceeded("Storage quota exceeded")
+			},
+			expectedStatus: http.StatusInsufficientStorage,
+		},
+		{
+			name:     "Body over the upload limit",
+			filename: "disk.vmdk",
+			fields:   map[string]string{"targetType": "vm"},
+			content:  strings.Repeat("x", 2*uploadFormOverhead),
+			mockUpload: func(userID string, req *models.ScanRequest, filename string, content io.Reader) (*models.ScanTask, error) {
+				return nil, fmt.Errorf("upload should be rejected before it is parsed")
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "Storage quota used up before the upload",
+			filename: "app.tar",
+			fields:   map[string]string{"targetType": "image"},
+			mockLimit: func(userID string) (int64, error) {
+				return 0, apperrors.NewQuotaExceeded("Storage quota exceeded")
+			},
+			mockUpload: func(userID string, req *models.ScanRequest, filename string, content io.Reader) (*models.ScanTask, error) {
+				return nil, fmt.Errorf("upload should be rejected before it is parsed")
+			},
+			expectedStatus: http.StatusInsufficientStorage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockScanService{
+				createUploadFunc: tt.mockUpload,
+				uploadLimitFunc:  tt.mockLimit,
+			}
+			handler := NewScanHandler(mockService, &mockLogger{})
+			router := setupTestRouter()
+			router.POST("/scan/upload", handler.UploadScan)
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			for key, value := range tt.fields {
+				writer.WriteField(key, value)
+			}
+			if tt.filename != "" {
+				part, _ := writer.CreateFormFile("file", tt.filename)
+				content := tt.content
+				if content == "" {
+					content = "file-content"
+				}
+				part.Write([]byte(content))
+			}
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/scan/upload", &body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestExportScans tests the ExportScans handler
+func TestExportScans(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		mockExport       func(userID string, req *models.ExportRequest) ([]byte, string, error)
+		expectedStatus   int
+		expectedFilename string
+	}{
+		{
+			name:  "Default CSV export with filters",
+			query: "?status=completed&startDate=2025-10-01",
+			mockExport: func(userID string, req *models.ExportRequest) ([]byte, string, error) {
+				if req.Format != "csv" || req.Status != "completed" || req.StartDate != "2025-10-01" {
+					return nil, "", fmt.Errorf("unexpected request: %+v", req)
+				}
+				return []byte("Task ID,Image\n"), "text/csv; charset=utf-8", nil
+			},
+			expectedStatus:   http.StatusOK,
+			expectedFilename: ".csv",
+		},
+		{
+			name:  "Excel alias",
+			query: "?format=excel",
+			mockExport: func(userID string, req *models.ExportRequest) ([]byte, string, error) {
+				return []byte("PK"), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+			},
+			expectedStatus:   http.StatusOK,
+			expectedFilename: ".xlsx",
+		},
+		{
+			name:  "Unsupported format",
+			query: "?format=pdf",
+			mockExport: func(userID string, req *models.ExportRequest) ([]byte, string, error) {
+				return nil, "", apperrors.NewInvalidInput("Unsupported export format: pdf")
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Internal error",
+			query: "?format=json",
+			mockExport: func(userID string, req *models.ExportRequest) ([]byte, string, error) {
+				return nil, "", fmt.Errorf("disk error")
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockScanService{
+				exportTasksFunc: tt.mockExport,
+			}
+			handler := NewScanHandler(mockService, &mockLogger{})
+			router := setupTestRouter()
+			router.GET("/scan/export", handler.ExportScans)
+
+			req := httptest.NewRequest(http.MethodGet, "/scan/export"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.expectedFilename != "" {
+				disposition := w.Header().Get("Content-Disposition")
+				if !strings.Contains(disposition, "scan-history-") || !strings.Contains(disposition, tt.expectedFilename+"\"") {
+					t.Errorf("Unexpected Content-Disposition: %s", disposition)
+				}
+			}
+		})
+	}
+}
+
+// TestDiffScans tests the DiffScans handler
+func TestDiffScans(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockDiff       func(principal models.Principal, baseTaskID, targetTaskID string) (*models.ScanDiff, error)
+		expectedStatus int
+	}{
+		{
+			name:  "Valid comparison",
+			query: "?base=task-1&target=task-2",
+			mockDiff: func(principal models.Principal, baseTaskID, targetTaskID string) (*models.ScanDiff, error) {
+				diff := &models.ScanDiff{BaseTaskID: baseTaskID, TargetTaskID: targetTaskID}
+				diff.Added.Vulnerabilities = []models.DiffVulnerability{{VulnerabilityID: "CVE-1", Severity: "HIGH"}}
+				diff.Added.Summary.Add("HIGH")
+				return diff, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing target",
+			query:          "?base=task-1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Task not found",
+			query: "?base=task-1&target=missing",
+			mockDiff: func(principal models.Principal, baseTaskID, targetTaskID string) (*models.ScanDiff, error) {
+				return nil, apperrors.ErrTaskNotFound
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewScanHandler(&mockScanService{diffTasksFunc: tt.mockDiff}, &mockLogger{})
+			router := setupTestRouter()
+			router.GET("/scan/diff", handler.DiffScans)
+
+			req := httptest.NewRequest(http.MethodGet, "/scan/diff"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if w.Code == http.StatusOK {
+				var diff models.ScanDiff
+				if err := json.Unmarshal(w.Body.Bytes(), &diff); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if diff.BaseTaskID != "task-1" || diff.TargetTaskID != "task-2" || diff.Added.Summary.High != 1 {
+					t.Errorf("Unexpected diff in response: %+v", diff)
+				}
+			}
+		})
+	}
+}
+
+// TestListFindings tests the ListFindings handler
+func TestListFindings(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockList       func(principal models.Principal, taskID string, req *models.FindingListRequest) (*models.FindingListResponse, error)
+		expectedStatus int
+	}{
+		{
+			name:  "Default kind and paging",
+			query: "",
+			mockList: func(principal models.Principal, taskID string, req *models.FindingListRequest) (*models.FindingListResponse, error) {
+				if req.Kind != models.FindingKindVulnerability || req.Page != 1 || req.PageSize != 50 {
+					return nil, apperrors.NewInvalidInput("unexpected defaults")
+				}
+				return &models.FindingListResponse{TaskID: taskID, Kind: req.Kind, Total: 1, Page: 1, PageSize: 50,
+					Findings: []models.Finding{{Target: "debian", Vulnerability: &models.TrivyVulnerability{VulnerabilityID: "CVE-1"}}}}, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Filters are passed through",
+			query: "?kind=secret&severity=HIGH,CRITICAL&q=aws&fixAvailable=true&page=2&pageSize=10",
+			mockList: func(principal models.Principal, taskID string, req *models.FindingListRequest) (*models.FindingListResponse, error) {
+				if req.Kind != "secret" || req.Severity != "HIGH,CRITICAL" || req.Search != "aws" || !req.FixAvailable || req.Page != 2 || req.PageSize != 10 {
+					return nil, apperrors.NewInvalidInput("unexpected filters")
+				}
+				return &models.FindingListResponse{TaskID: taskID, Kind: req.Kind, Findings: []models.Finding{}}, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid page",
+			query:          "?page=abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Task not found",
+			query: "",
+			mockList: func(principal models.Principal, taskID string, req *models.FindingListRequest) (*models.FindingListResponse, error) {
+				return nil, apperrors.ErrTaskNotFound
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewScanHandler(&mockScanService{listFindingsFunc: tt.mockList}, &mockLogger{})
+			router := setupTestRouter()
+			router.GET("/scan/:id/findings", handler.ListFindings)
+
+			req := httptest.NewRequest(http.MethodGet, "/scan/task-123/findings"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if w.Code == http.StatusOK {
+				var response models.FindingListResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if response.TaskID != "task-123" {
+					t.Errorf("Expected task-123, got %s", response.TaskID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	apperrors "github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+	"github.com/lazycatapps/trivy/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleHandler handles HTTP requests for scheduled scans.
+type ScheduleHandler struct {
+	scanService service.ScanService
+	logger      logger.Logger
+}
+
+// NewScheduleHandler creates a new schedule handler.
+func NewScheduleHandler(scanService service.ScanService, log logger.Logger) *ScheduleHandler {
+	return &ScheduleHandler{
+		scanService: scanService,
+		logger:      log,
+	}
+}
+
+// ListSchedules handles GET /api/v1/schedules - List the current user's schedules.
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	schedules, err := h.scanService.ListSchedules(userIdentifier)
+	if err != nil {
+		log.Error("Failed to list schedules: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list schedules"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.ScheduleListResponse{Schedules: schedules})
+}
+
+// CreateSchedule handles POST /api/v1/schedules - Create a scheduled scan.
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	var req models.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error("Invalid schedule request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	schedule, err := h.scanService.CreateSchedule(userIdentifier, &req)
+	if err != nil {
+		log.Error("Failed to create schedule: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// PauseSchedule handles POST /api/v1/schedules/:id/pause - Pause a schedule.
+func (h *ScheduleHandler) PauseSchedule(c *gin.Context) {
+	h.setPaused(c, true)
+}
+
+// ResumeSchedule handles POST /api/v1/schedules/:id/resume - Resume a paused schedule.
+func (h *ScheduleHandler) ResumeSchedule(c *gin.Context) {
+	h.setPaused(c, false)
+}
+
+// setPaused pauses or resumes the schedule in the request path.
+func (h *ScheduleHandler) setPaused(c *gin.Context, paused bool) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+	scheduleID := c.Param("id")
+
+	schedule, err := h.scanService.SetSchedulePaused(userIdentifier, scheduleID, paused)
+	if err != nil {
+		log.Error("Failed to update schedule %s: %v", scheduleID, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule handles DELETE /api/v1/schedules/:id - Delete a schedule.
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+	scheduleID := c.Param("id")
+
+	if err := h.scanService.DeleteSchedule(userIdentifier, scheduleID); err != nil {
+		log.Error("Failed to delete schedule %s: %v", scheduleID, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted"})
+}
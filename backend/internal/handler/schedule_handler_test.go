@@ -0,0 +1,178 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	apperrors "github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+)
+
+// newScheduleTestRouter registers the schedule routes on a test router.
+func newScheduleTestRouter(mockService *mockScanService) http.Handler {
+	handler := NewScheduleHandler(mockService, &mockLogger{})
+	router := setupTestRouter()
+	router.GET("/schedules", handler.ListSchedules)
+	router.POST("/schedules", handler.CreateSchedule)
+	router.POST("/schedules/:id/pause", handler.PauseSchedule)
+	router.POST("/schedules/:id/resume", handler.ResumeSchedule)
+	router.DELETE("/schedules/:id", handler.DeleteSchedule)
+	return router
+}
+
+// TestCreateSchedule tests the CreateSchedule handler
+func TestCreateSchedule(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockCreate     func(userID string, req *models.ScheduleRequest) (*models.Schedule, error)
+		expectedStatus int
+	}{
+		{
+			name: "Valid schedule",
+			body: `{"image": "alpine:latest", "cron": "0 2 * * *"}`,
+			mockCreate: func(userID string, req *models.ScheduleRequest) (*models.Schedule, error) {
+				nextRun := time.Now().Add(time.Hour)
+				return &models.Schedule{ID: "schedule-1", UserID: userID, Image: req.Image, Cron: req.Cron, NextRunAt: &nextRun}, nil
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Missing cron expression",
+			body:           `{"image": "alpine:latest"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid cron expression",
+			body: `{"image": "alpine:latest", "cron": "every day"}`,
+			mockCreate: func(userID string, req *models.ScheduleRequest) (*models.Schedule, error) {
+				return nil, apperrors.NewInvalidInput("invalid cron expression")
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newScheduleTestRouter(&mockScanService{createScheduleFunc: tt.mockCreate})
+
+			req := httptest.NewRequest(http.MethodPost, "/schedules", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if w.Code == http.StatusCreated {
+				var schedule models.Schedule
+				if err := json.Unmarshal(w.Body.Bytes(), &schedule); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if schedule.ID != "schedule-1" || schedule.NextRunAt == nil {
+					t.Errorf("Unexpected schedule in response: %+v", schedule)
+				}
+			}
+		})
+	}
+}
+
+// TestListSchedules tests the ListSchedules handler
+func TestListSchedules(t *testing.T) {
+	lastRun := time.Now().Add(-time.Hour)
+	router := newScheduleTestRouter(&mockScanService{
+		listSchedulesFunc: func(userID string) ([]*models.Schedule, error) {
+			return []*models.Schedule{
+				{ID: "schedule-1", UserID: userID, ConfigName: "prod", Cron: "@daily", LastRunAt: &lastRun, LastTaskID: "task-1"},
+				{ID: "schedule-2", UserID: userID, Image: "nginx:latest", Cron: "0 * * * *", Paused: true},
+			}, nil
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/schedules", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response models.ScheduleListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(response.Schedules) != 2 {
+		t.Fatalf("Expected 2 schedules, got %d", len(response.Schedules))
+	}
+	if response.Schedules[0].LastTaskID != "task-1" || !response.Schedules[1].Paused {
+		t.Errorf("Unexpected schedules in response: %+v", response.Schedules)
+	}
+}
+
+// TestPauseResumeDeleteSchedule tests pausing, resuming and deleting schedules
+func TestPauseResumeDeleteSchedule(t *testing.T) {
+	mockService := &mockScanService{
+		setPausedFunc: func(userID, scheduleID string, paused bool) (*models.Schedule, error) {
+			if scheduleID != "schedule-1" {
+				return nil, apperrors.ErrScheduleNotFound
+			}
+			return &models.Schedule{ID: scheduleID, UserID: userID, Paused: paused}, nil
+		},
+		deleteScheduleFunc: func(userID, scheduleID string) error {
+			if scheduleID != "schedule-1" {
+				return apperrors.ErrScheduleNotFound
+			}
+			return nil
+		},
+	}
+	router := newScheduleTestRouter(mockService)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedPaused *bool
+	}{
+		{"Pause", http.MethodPost, "/schedules/schedule-1/pause", http.StatusOK, boolPtr(true)},
+		{"Resume", http.MethodPost, "/schedules/schedule-1/resume", http.StatusOK, boolPtr(false)},
+		{"Pause missing schedule", http.MethodPost, "/schedules/missing/pause", http.StatusNotFound, nil},
+		{"Delete", http.MethodDelete, "/schedules/schedule-1", http.StatusOK, nil},
+		{"Delete missing schedule", http.MethodDelete, "/schedules/missing", http.StatusNotFound, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.expectedPaused != nil {
+				var schedule models.Schedule
+				if err := json.Unmarshal(w.Body.Bytes(), &schedule); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if schedule.Paused != *tt.expectedPaused {
+					t.Errorf("Expected paused=%v, got %v", *tt.expectedPaused, schedule.Paused)
+				}
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/lazycatapps/trivy/backend/internal/middleware"
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	apperrors "github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+	"github.com/lazycatapps/trivy/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandler handles HTTP requests for personal API tokens.
+type TokenHandler struct {
+	tokenService service.TokenService
+	logger       logger.Logger
+}
+
+// NewTokenHandler creates a new API token handler.
+func NewTokenHandler(tokenService service.TokenService, log logger.Logger) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+		logger:       log,
+	}
+}
+
+// ListTokens handles GET /api/v1/tokens - List the current user's API tokens.
+func (h *TokenHandler) ListTokens(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	tokens, err := h.tokenService.ListTokens(userIdentifier)
+	if err != nil {
+		log.Error("Failed to list API tokens: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API tokens"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APITokenListResponse{Tokens: tokens})
+}
+
+// CreateToken handles POST /api/v1/tokens - Create an API token acting as the current user.
+// Tokens can only be created from a browser session, not with another token, and their
+// scope cannot exceed the role of the user.
+func (h *TokenHandler) CreateToken(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	sessionInfo, _ := c.Get("session")
+	session, ok := sessionInfo.(*service.SessionInfo)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "API tokens require OIDC authentication"})
+		return
+	}
+	if session.TokenID != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API tokens cannot create other API tokens"})
+		return
+	}
+
+	var req models.APITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error("Invalid API token request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if req.Scope != "" {
+		scope, err := middleware.ParseRole(req.Scope)
+		if err == nil && !middleware.GetRole(c).Includes(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Token scope %s exceeds your role", req.Scope)})
+			return
+		}
+	}
+
+	created, err := h.tokenService.CreateToken(userIdentifier, session, &req)
+	if err != nil {
+		log.Error("Failed to create API token: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// RevokeToken handles DELETE /api/v1/tokens/:id - Revoke an API token of the current user.
+func (h *TokenHandler) RevokeToken(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	if err := h.tokenService.RevokeToken(userIdentifier, c.Param("id")); err != nil {
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			log.Error("Failed to revoke API token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked successfully"})
+}
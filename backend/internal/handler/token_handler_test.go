@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lazycatapps/trivy/backend/internal/middleware"
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/service"
+)
+
+// TestTokenHandler tests creating, listing and revoking API tokens through the API
+func TestTokenHandler(t *testing.T) {
+	repo, err := repository.NewFileTokenRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	handler := NewTokenHandler(service.NewTokenService(repo, &mockLogger{}), &mockLogger{})
+
+	// withSession authenticates requests as a scanner, optionally through an API token
+	withSession := func(tokenID string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("session", &service.SessionInfo{UserID: "user-1", Email: "alice@example.com", TokenID: tokenID})
+			c.Set("role", middleware.RoleScanner)
+			c.Next()
+		}
+	}
+
+	router := setupTestRouter()
+	router.POST("/anonymous/tokens", handler.CreateToken)
+	router.POST("/token/tokens", withSession("token-1"), handler.CreateToken)
+	user := router.Group("/user", withSession(""))
+	user.GET("/tokens", handler.ListTokens)
+	user.POST("/tokens", handler.CreateToken)
+	user.DELETE("/tokens/:id", handler.RevokeToken)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"Without OIDC session", "/anonymous/tokens", `{"name": "ci"}`, http.StatusBadRequest},
+		{"From an API token", "/token/tokens", `{"name": "ci"}`, http.StatusForbidden},
+		{"Missing name", "/user/tokens", `{"scope": "viewer"}`, http.StatusBadRequest},
+		{"Scope above role", "/user/tokens", `{"name": "ci", "scope": "admin"}`, http.StatusForbidden},
+		{"Unknown scope", "/user/tokens", `{"name": "ci", "scope": "root"}`, http.StatusBadRequest},
+		{"Valid token", "/user/tokens", `{"name": "ci", "scope": "scanner", "expiresInDays": 30}`, http.StatusCreated},
+	}
+
+	var created models.APITokenCreateResponse
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusCreated {
+				if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if created.Token == "" || created.SecretHash != "" || created.Scope != models.TokenScopeScanner {
+					t.Errorf("Unexpected created token: %s", w.Body.String())
+				}
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user/tokens", nil))
+	var list models.APITokenListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(list.Tokens) != 1 || list.Tokens[0].ID != created.ID {
+		t.Fatalf("Expected the created token to be listed, got %s", w.Body.String())
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte(created.Token)) {
+		t.Error("Expected the secret not to be listed")
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/user/tokens/"+created.ID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for revoke, got %d (body: %s)", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/user/tokens/"+created.ID, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a revoked token, got %d", w.Code)
+	}
+}
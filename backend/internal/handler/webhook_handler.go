@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	apperrors "github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+	"github.com/lazycatapps/trivy/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles HTTP requests for webhook notifications.
+type WebhookHandler struct {
+	webhookService service.WebhookService
+	logger         logger.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler.
+func NewWebhookHandler(webhookService service.WebhookService, log logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		logger:         log,
+	}
+}
+
+// ListWebhooks handles GET /api/v1/webhooks - List the current user's webhooks.
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	webhooks, err := h.webhookService.ListWebhooks(userIdentifier)
+	if err != nil {
+		log.Error("Failed to list webhooks: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhooks"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.WebhookListResponse{Webhooks: webhooks})
+}
+
+// CreateWebhook handles POST /api/v1/webhooks - Create a webhook.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+
+	var req models.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error("Invalid webhook request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(userIdentifier, &req)
+	if err != nil {
+		log.Error("Failed to create webhook: %v", err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// PauseWebhook handles POST /api/v1/webhooks/:id/pause - Pause a webhook.
+func (h *WebhookHandler) PauseWebhook(c *gin.Context) {
+	h.setPaused(c, true)
+}
+
+// ResumeWebhook handles POST /api/v1/webhooks/:id/resume - Resume a paused webhook.
+func (h *WebhookHandler) ResumeWebhook(c *gin.Context) {
+	h.setPaused(c, false)
+}
+
+// setPaused pauses or resumes the webhook in the request path.
+func (h *WebhookHandler) setPaused(c *gin.Context, paused bool) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+	webhookID := c.Param("id")
+
+	webhook, err := h.webhookService.SetWebhookPaused(userIdentifier, webhookID, paused)
+	if err != nil {
+		log.Error("Failed to update webhook %s: %v", webhookID, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook handles DELETE /api/v1/webhooks/:id - Delete a webhook.
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+	webhookID := c.Param("id")
+
+	if err := h.webhookService.DeleteWebhook(userIdentifier, webhookID); err != nil {
+		log.Error("Failed to delete webhook %s: %v", webhookID, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// ListDeliveries handles GET /api/v1/webhooks/:id/deliveries - List the recent deliveries of a webhook.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+	webhookID := c.Param("id")
+
+	deliveries, err := h.webhookService.ListDeliveries(userIdentifier, webhookID)
+	if err != nil {
+		log.Error("Failed to list deliveries of webhook %s: %v", webhookID, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list deliveries"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.WebhookDeliveryListResponse{Deliveries: deliveries})
+}
+
+// TestWebhook handles POST /api/v1/webhooks/:id/test - Send a test event to a webhook.
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	userIdentifier := getUserIdentifier(c)
+	log := requestLogger(c, h.logger)
+	webhookID := c.Param("id")
+
+	delivery, err := h.webhookService.TestWebhook(userIdentifier, webhookID)
+	if err != nil {
+		log.Error("Failed to test webhook %s: %v", webhookID, err)
+		appErr, ok := err.(*apperrors.AppError)
+		if ok {
+			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to test webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
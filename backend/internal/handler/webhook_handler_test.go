@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/service"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+// TestWebhookHandler tests creating, testing and deleting a webhook through the API
+func TestWebhookHandler(t *testing.T) {
+	repo, err := repository.NewFileWebhookRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	webhookService := service.NewWebhookService(repo, &types.NotificationConfig{WebhookAllowPrivate: true}, &mockLogger{})
+	defer webhookService.Stop()
+
+	handler := NewWebhookHandler(webhookService, &mockLogger{})
+	router := setupTestRouter()
+	router.POST("/webhooks", handler.CreateWebhook)
+	router.POST("/webhooks/:id/test", handler.TestWebhook)
+	router.GET("/webhooks/:id/deliveries", handler.ListDeliveries)
+	router.DELETE("/webhooks/:id", handler.DeleteWebhook)
+
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer endpoint.Close()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"Missing URL", `{"format": "slack"}`, http.StatusBadRequest},
+		{"Invalid URL", `{"url": "not a url"}`, http.StatusBadRequest},
+		{"Valid webhook", `{"url": "` + endpoint.URL + `", "secret": "s3cret"}`, http.StatusCreated},
+	}
+
+	var webhook models.Webhook
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d (body: %s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusCreated {
+				if err := json.Unmarshal(w.Body.Bytes(), &webhook); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if webhook.Secret != "" || !webhook.HasSecret {
+					t.Errorf("Expected the secret to be hidden, got %+v", webhook)
+				}
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhooks/"+webhook.ID+"/test", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for test delivery, got %d (body: %s)", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/webhooks/"+webhook.ID+"/deliveries", nil))
+	var deliveries models.WebhookDeliveryListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &deliveries); err != nil || len(deliveries.Deliveries) != 1 || !deliveries.Deliveries[0].Success {
+		t.Errorf("Expected one successful delivery, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/webhooks/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown webhook, got %d", w.Code)
+	}
+}
@@ -25,7 +25,9 @@ type SessionInfo interface {
 
 // Auth is a middleware that validates OIDC authentication.
 // It checks for a valid session cookie and redirects to login if not authenticated.
-func Auth(oidcEnabled bool, sessionValidator SessionValidator) gin.HandlerFunc {
+// Non-browser clients authenticate with a personal API token in the
+// "Authorization: Bearer" header instead, validated by tokenValidator.
+func Auth(oidcEnabled bool, sessionValidator SessionValidator, tokenValidator SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip authentication if OIDC is not enabled
 		if !oidcEnabled {
@@ -39,6 +41,23 @@ func Auth(oidcEnabled bool, sessionValidator SessionValidator) gin.HandlerFunc {
 			return
 		}
 
+		// API tokens take precedence over the session cookie
+		if token, ok := bearerToken(c); ok {
+			var sessionInfo interface{}
+			exists := false
+			if tokenValidator != nil {
+				sessionInfo, exists = tokenValidator.GetSession(token)
+			}
+			if !exists {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API token"})
+				c.Abort()
+				return
+			}
+			setSession(c, sessionInfo)
+			c.Next()
+			return
+		}
+
 		// Check for session cookie
 		sessionCookie, err := c.Cookie("session")
 		if err != nil || sessionCookie == "" {
@@ -67,25 +86,40 @@ func Auth(oidcEnabled bool, sessionValidator SessionValidator) gin.HandlerFunc {
 				c.Abort()
 				return
 			}
-			// Store session info in context for handlers to use
-			c.Set("session", sessionInfo)
-
-			// Extract and set userID for handlers
-			if si, ok := sessionInfo.(SessionInfo); ok {
-				c.Set("userID", si.GetUserID())
-			}
+			setSession(c, sessionInfo)
 		}
 
 		c.Next()
 	}
 }
 
+// setSession stores the session info and user ID in the context for handlers to use.
+func setSession(c *gin.Context, sessionInfo interface{}) {
+	c.Set("session", sessionInfo)
+
+	// Extract and set userID for handlers
+	if si, ok := sessionInfo.(SessionInfo); ok {
+		c.Set("userID", si.GetUserID())
+	}
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(header[7:])
+	return token, token != ""
+}
+
 // isPublicEndpoint checks if the endpoint is public (no auth required).
 func isPublicEndpoint(path string) bool {
 	publicPaths := []string{
 		"/api/v1/health",
 		"/api/v1/auth/login",
 		"/api/v1/auth/callback",
+		"/api/v1/auth/backchannel-logout",
 		"/api/v1/auth/userinfo",
 	}
 
@@ -99,6 +133,11 @@ func isPublicEndpoint(path string) bool {
 
 // isAPIRequest checks if the request is an API request based on headers
 func isAPIRequest(c *gin.Context) bool {
+	// Metrics are fetched by scrapers, not browsers
+	if c.FullPath() == "/metrics" {
+		return true
+	}
+
 	// Check Accept header
 	accept := c.GetHeader("Accept")
 	if strings.Contains(accept, "application/json") {
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scopedTestSession is a session of an API token limited to a role.
+type scopedTestSession struct {
+	testSession
+	scope string
+}
+
+func (s scopedTestSession) GetScope() string { return s.scope }
+
+// mapValidator validates sessions or tokens from a map.
+type mapValidator map[string]interface{}
+
+func (v mapValidator) GetSession(id string) (interface{}, bool) {
+	session, exists := v[id]
+	return session, exists
+}
+
+func TestAuthBearerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessions := mapValidator{"cookie-1": testSession{groups: []string{"ADMIN"}}}
+	tokens := mapValidator{
+		"token-viewer": scopedTestSession{testSession{groups: []string{"ADMIN"}}, "viewer"},
+		"token-admin":  scopedTestSession{testSession{groups: []string{"devs"}}, "admin"},
+	}
+	mapping := &RoleMapping{AdminGroups: []string{"ADMIN"}, ScannerGroups: []string{"devs"}}
+
+	router := gin.New()
+	router.Use(Auth(true, sessions, tokens), Roles(true, mapping))
+	router.GET("/api/v1/scan", func(c *gin.Context) {
+		c.String(http.StatusOK, string(GetRole(c)))
+	})
+
+	tests := []struct {
+		name         string
+		header       string
+		cookie       string
+		expectedCode int
+		expectedRole Role
+	}{
+		{name: "Session cookie", cookie: "cookie-1", expectedCode: http.StatusOK, expectedRole: RoleAdmin},
+		{name: "Token scope caps the role", header: "Bearer token-viewer", expectedCode: http.StatusOK, expectedRole: RoleViewer},
+		{name: "Token scope does not raise the role", header: "bearer token-admin", expectedCode: http.StatusOK, expectedRole: RoleScanner},
+		{name: "Unknown token", header: "Bearer nope", cookie: "cookie-1", expectedCode: http.StatusUnauthorized},
+		{name: "Other authorization scheme", header: "Basic dXNlcjpwYXNz", expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/scan", nil)
+			req.Header.Set("Accept", "application/json")
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+			if tt.expectedCode == http.StatusOK && Role(w.Body.String()) != tt.expectedRole {
+				t.Errorf("Expected role %q, got %q", tt.expectedRole, w.Body.String())
+			}
+		})
+	}
+}
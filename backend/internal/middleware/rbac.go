@@ -0,0 +1,171 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package middleware provides role-based access control driven by OIDC group claims.
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Role is the access level of a user. Each role includes the permissions of the roles below it.
+type Role string
+
+const (
+	RoleNone    Role = ""        // No access to protected endpoints
+	RoleViewer  Role = "viewer"  // Read scans, reports and settings
+	RoleScanner Role = "scanner" // Additionally create scans and manage personal settings
+	RoleAdmin   Role = "admin"   // Additionally see all users' tasks, purge storage and change global settings
+)
+
+// roleKey is the context key of the resolved role.
+const roleKey = "role"
+
+// level returns the rank of a role (higher roles include lower ones).
+func (r Role) level() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleScanner:
+		return 2
+	case RoleAdmin:
+		return 3
+	}
+	return 0
+}
+
+// Includes reports whether the role grants the permissions of the required role.
+func (r Role) Includes(required Role) bool {
+	return r.level() >= required.level()
+}
+
+// ParseRole parses a role name. "none" and the empty string map to RoleNone.
+func ParseRole(name string) (Role, error) {
+	switch Role(name) {
+	case RoleViewer, RoleScanner, RoleAdmin:
+		return Role(name), nil
+	case RoleNone, "none":
+		return RoleNone, nil
+	}
+	return RoleNone, fmt.Errorf("invalid role %q (must be viewer, scanner, admin or none)", name)
+}
+
+// RoleMapping maps OIDC group names to roles.
+type RoleMapping struct {
+	AdminGroups   []string // Groups granted the admin role
+	ScannerGroups []string // Groups granted the scanner role
+	ViewerGroups  []string // Groups granted the viewer role
+	DefaultRole   Role     // Role of authenticated users in none of the groups
+}
+
+// NewRoleMapping creates a role mapping from the configured group names and default role.
+func NewRoleMapping(adminGroups, scannerGroups, viewerGroups []string, defaultRole string) (*RoleMapping, error) {
+	role, err := ParseRole(strings.TrimSpace(defaultRole))
+	if err != nil {
+		return nil, fmt.Errorf("invalid default role: %w", err)
+	}
+
+	return &RoleMapping{
+		AdminGroups:   cleanGroups(adminGroups),
+		ScannerGroups: cleanGroups(scannerGroups),
+		ViewerGroups:  cleanGroups(viewerGroups),
+		DefaultRole:   role,
+	}, nil
+}
+
+// cleanGroups trims group names and drops empty ones.
+func cleanGroups(groups []string) []string {
+	var cleaned []string
+	for _, group := range groups {
+		if group = strings.TrimSpace(group); group != "" {
+			cleaned = append(cleaned, group)
+		}
+	}
+	return cleaned
+}
+
+// Resolve returns the highest role granted by the groups, or the default role.
+func (m *RoleMapping) Resolve(groups []string) Role {
+	role := m.DefaultRole
+	for _, group := range groups {
+		for _, candidate := range []struct {
+			role   Role
+			groups []string
+		}{
+			{RoleAdmin, m.AdminGroups},
+			{RoleScanner, m.ScannerGroups},
+			{RoleViewer, m.ViewerGroups},
+		} {
+			if candidate.role.level() > role.level() && containsGroup(candidate.groups, group) {
+				role = candidate.role
+			}
+		}
+	}
+	return role
+}
+
+// containsGroup reports whether group is in groups.
+func containsGroup(groups []string, group string) bool {
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopedSession is implemented by sessions whose role is limited, such as API token sessions.
+type ScopedSession interface {
+	GetScope() string // Highest role of the session (empty if unlimited)
+}
+
+// Roles is a middleware resolving the role of the session user and storing it in the context.
+// It must run after Auth. The role of API token sessions is capped by the token scope.
+// Without OIDC, the server is used by a single anonymous user who has the admin role.
+func Roles(oidcEnabled bool, mapping *RoleMapping) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := RoleAdmin
+		if oidcEnabled {
+			role = RoleNone
+			if sessionInfo, exists := c.Get("session"); exists {
+				if si, ok := sessionInfo.(SessionInfo); ok {
+					role = mapping.Resolve(si.GetGroups())
+				}
+				if scoped, ok := sessionInfo.(ScopedSession); ok && scoped.GetScope() != "" {
+					// Invalid scopes parse as RoleNone, denying access
+					scope, _ := ParseRole(scoped.GetScope())
+					if !scope.Includes(role) {
+						role = scope
+					}
+				}
+			}
+		}
+		c.Set(roleKey, role)
+
+		c.Next()
+	}
+}
+
+// RequireRole is a middleware rejecting requests whose role does not include the required role.
+func RequireRole(required Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !GetRole(c).Includes(required) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Permission denied: %s role required", required)})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetRole returns the role resolved by the Roles middleware (RoleNone without it).
+func GetRole(c *gin.Context) Role {
+	role, _ := c.Get(roleKey)
+	r, _ := role.(Role)
+	return r
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testSession struct {
+	groups []string
+}
+
+func (s testSession) GetUserID() string   { return "user" }
+func (s testSession) GetEmail() string    { return "user@example.com" }
+func (s testSession) GetGroups() []string { return s.groups }
+
+func TestRoleMappingResolve(t *testing.T) {
+	mapping := &RoleMapping{
+		AdminGroups:   []string{"ADMIN"},
+		ScannerGroups: []string{"devs"},
+		ViewerGroups:  []string{"auditors"},
+		DefaultRole:   RoleNone,
+	}
+
+	tests := []struct {
+		name   string
+		groups []string
+		want   Role
+	}{
+		{name: "No groups uses default", groups: nil, want: RoleNone},
+		{name: "Viewer group", groups: []string{"auditors"}, want: RoleViewer},
+		{name: "Scanner group", groups: []string{"devs"}, want: RoleScanner},
+		{name: "Highest role wins", groups: []string{"auditors", "ADMIN", "devs"}, want: RoleAdmin},
+		{name: "Unknown group uses default", groups: []string{"other"}, want: RoleNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mapping.Resolve(tt.groups); got != tt.want {
+				t.Errorf("Resolve(%v) = %q, want %q", tt.groups, got, tt.want)
+			}
+		})
+	}
+
+	mapping.DefaultRole = RoleScanner
+	if got := mapping.Resolve([]string{"auditors"}); got != RoleScanner {
+		t.Errorf("Expected the default role to apply when it is higher than the group role, got %q", got)
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for _, name := range []string{"viewer", "scanner", "admin", "none", ""} {
+		if _, err := ParseRole(name); err != nil {
+			t.Errorf("ParseRole(%q) returned error: %v", name, err)
+		}
+	}
+	if _, err := ParseRole("root"); err == nil {
+		t.Error("Expected an error for an unknown role")
+	}
+}
+
+func TestNewRoleMapping(t *testing.T) {
+	mapping, err := NewRoleMapping([]string{" ADMIN ", ""}, nil, []string{"auditors"}, "viewer")
+	if err != nil {
+		t.Fatalf("NewRoleMapping returned error: %v", err)
+	}
+	if len(mapping.AdminGroups) != 1 || mapping.AdminGroups[0] != "ADMIN" {
+		t.Errorf("Expected trimmed admin groups, got %v", mapping.AdminGroups)
+	}
+	if mapping.DefaultRole != RoleViewer {
+		t.Errorf("Expected default role viewer, got %q", mapping.DefaultRole)
+	}
+
+	if _, err := NewRoleMapping(nil, nil, nil, "superuser"); err == nil {
+		t.Error("Expected an error for an invalid default role")
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mapping := &RoleMapping{
+		AdminGroups:   []string{"ADMIN"},
+		ScannerGroups: []string{"devs"},
+		ViewerGroups:  []string{"auditors"},
+	}
+
+	tests := []struct {
+		name         string
+		oidcEnabled  bool
+		groups       []string
+		required     Role
+		expectedCode int
+	}{
+		{name: "OIDC disabled is admin", oidcEnabled: false, required: RoleAdmin, expectedCode: http.StatusOK},
+		{name: "Viewer can read", oidcEnabled: true, groups: []string{"auditors"}, required: RoleViewer, expectedCode: http.StatusOK},
+		{name: "Viewer cannot scan", oidcEnabled: true, groups: []string{"auditors"}, required: RoleScanner, expectedCode: http.StatusForbidden},
+		{name: "Scanner can read", oidcEnabled: true, groups: []string{"devs"}, required: RoleViewer, expectedCode: http.StatusOK},
+		{name: "Scanner is not admin", oidcEnabled: true, groups: []string{"devs"}, required: RoleAdmin, expectedCode: http.StatusForbidden},
+		{name: "Admin", oidcEnabled: true, groups: []string{"ADMIN"}, required: RoleAdmin, expectedCode: http.StatusOK},
+		{name: "No role", oidcEnabled: true, groups: []string{"other"}, required: RoleViewer, expectedCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.oidcEnabled {
+					c.Set("session", testSession{groups: tt.groups})
+				}
+				c.Next()
+			})
+			router.Use(Roles(tt.oidcEnabled, mapping))
+			router.GET("/test", RequireRole(tt.required), func(c *gin.Context) {
+				c.String(http.StatusOK, string(GetRole(c)))
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+		})
+	}
+}
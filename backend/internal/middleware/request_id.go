@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package middleware provides request correlation IDs and structured access logs.
+package middleware
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the header carrying the request correlation ID.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key of the request ID.
+const requestIDKey = "requestID"
+
+// validRequestID matches request IDs accepted from clients and proxies.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID is a middleware assigning a correlation ID to every request.
+// A valid X-Request-ID sent by the client (or a proxy) is reused, otherwise a
+// new UUID is generated. The ID is returned in the X-Request-ID response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// GetRequestID returns the correlation ID of the request (empty without the RequestID middleware).
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// Logger is a middleware writing one structured access log entry per request.
+// It replaces gin.Logger() and must run after RequestID.
+func Logger(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		logger.With(log,
+			"request_id", GetRequestID(c),
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		).Info("HTTP request")
+	}
+}
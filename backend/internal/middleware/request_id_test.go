@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	log, err := logger.NewStructured(logger.Options{Format: logger.FormatLogfmt, Output: &buf})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(RequestID(), Logger(log))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, GetRequestID(c))
+	})
+
+	tests := []struct {
+		name      string
+		requestID string
+		reused    bool
+	}{
+		{name: "Client ID is reused", requestID: "trace-123", reused: true},
+		{name: "Missing ID is generated", requestID: ""},
+		{name: "Invalid ID is replaced", requestID: "bad id\nwith newline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if id == "" || id != w.Body.String() {
+				t.Fatalf("Expected the response header and handler to see the same ID, got %q and %q", id, w.Body.String())
+			}
+			if (id == tt.requestID) != tt.reused {
+				t.Errorf("Unexpected request ID %q for %q", id, tt.requestID)
+			}
+			if !strings.Contains(buf.String(), "request_id="+id) || !strings.Contains(buf.String(), "status=200") {
+				t.Errorf("Expected access log with request ID, got %s", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// API token scopes. A scope is the highest role a request authenticated with the
+// token can act with; the role of the owner still applies if it is lower.
+const (
+	TokenScopeViewer  = "viewer"  // Read scans, reports and settings (poll verdicts)
+	TokenScopeScanner = "scanner" // Additionally create scans
+	TokenScopeAdmin   = "admin"   // Additionally use the admin endpoints
+)
+
+// APIToken is a named, scoped and expiring credential for non-browser clients such as
+// CI/CD pipelines. Only the SHA-256 hash of the secret is stored.
+type APIToken struct {
+	ID         string     `json:"id"`                   // Unique token identifier (UUID)
+	UserID     string     `json:"userId"`               // Owner (user identifier of the scans the token creates)
+	Name       string     `json:"name"`                 // Display name (e.g. "jenkins-release")
+	Scope      string     `json:"scope"`                // Highest role of requests with the token (viewer, scanner, admin)
+	Prefix     string     `json:"prefix"`               // First characters of the secret, to recognize the token
+	SecretHash string     `json:"secretHash,omitempty"` // Hex SHA-256 hash of the secret (never returned by the API)
+	Subject    string     `json:"subject,omitempty"`    // OIDC user ID of the owner (never returned by the API)
+	Email      string     `json:"email,omitempty"`      // Email of the owner (never returned by the API)
+	Groups     []string   `json:"groups,omitempty"`     // OIDC groups of the owner at the last login or session renewal (never returned by the API)
+	CreatedAt  time.Time  `json:"createdAt"`            // Token creation timestamp
+	ExpiresAt  time.Time  `json:"expiresAt"`            // Time the token stops being accepted
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"` // Last time the token authenticated a request
+}
+
+// Clone returns a copy of the token that shares no slices or pointers with the original.
+func (t *APIToken) Clone() *APIToken {
+	clone := *t
+	clone.Groups = append([]string(nil), t.Groups...)
+	if t.LastUsedAt != nil {
+		lastUsed := *t.LastUsedAt
+		clone.LastUsedAt = &lastUsed
+	}
+	return &clone
+}
+
+// IsExpired reports whether the token has expired at the given time.
+func (t *APIToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// APITokenRequest represents the request body for creating an API token.
+type APITokenRequest struct {
+	Name          string `json:"name" binding:"required"` // Display name (required, unique per user)
+	Scope         string `json:"scope"`                   // viewer, scanner or admin (default: viewer)
+	ExpiresInDays int    `json:"expiresInDays"`           // Validity in days (default: 90, max: 365)
+}
+
+// APITokenCreateResponse is returned once when a token is created. The secret cannot be retrieved later.
+type APITokenCreateResponse struct {
+	*APIToken
+	Token string `json:"token"` // Secret to send in the Authorization: Bearer header
+}
+
+// APITokenListResponse represents the response for listing API tokens.
+type APITokenListResponse struct {
+	Tokens []*APIToken `json:"tokens"` // Tokens ordered by creation time, including expired tokens
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// CheckBundle is a named bundle of custom Rego checks for the misconfiguration scanner.
+// Every upload creates a new version; scans use the latest version unless one is requested.
+type CheckBundle struct {
+	Name     string               `json:"name"`     // Bundle name
+	Versions []CheckBundleVersion `json:"versions"` // Stored versions, oldest first
+}
+
+// CheckBundleVersion describes one uploaded version of a check bundle.
+// Stored as JSON file on the server, next to the Rego modules of the version.
+type CheckBundleVersion struct {
+	Version    int       `json:"version"`    // Version number (1 for the first upload)
+	Files      []string  `json:"files"`      // Rego modules of the version (relative paths)
+	Namespaces []string  `json:"namespaces"` // Top-level namespaces of the checks (passed to --check-namespaces)
+	Size       int64     `json:"size"`       // Total size of the modules in bytes
+	UploadedAt time.Time `json:"uploadedAt"` // Upload timestamp
+}
+
+// CheckBundleListResponse represents the response for listing check bundles.
+type CheckBundleListResponse struct {
+	Bundles []*CheckBundle `json:"bundles"` // Bundles ordered by name
+}
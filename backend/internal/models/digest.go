@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// Digest frequencies.
+const (
+	DigestFrequencyDaily  = "daily"  // Sent every day
+	DigestFrequencyWeekly = "weekly" // Sent every Monday
+)
+
+// DigestSubscription holds the email digest preferences of a user.
+type DigestSubscription struct {
+	UserID     string     `json:"userId"`               // Owner (for OIDC multi-tenancy)
+	Email      string     `json:"email"`                // Recipient address
+	Frequency  string     `json:"frequency"`            // Digest frequency (daily, weekly)
+	Enabled    bool       `json:"enabled"`              // Disabled subscriptions are kept but not sent
+	UpdatedAt  time.Time  `json:"updatedAt"`            // Last change of the preferences
+	LastSentAt *time.Time `json:"lastSentAt,omitempty"` // End of the period covered by the last digest
+	NextSendAt *time.Time `json:"nextSendAt,omitempty"` // Next scheduled digest (nil if disabled)
+	LastError  string     `json:"lastError,omitempty"`  // Error of the last failed send
+}
+
+// DigestSubscriptionRequest represents the request body for updating digest preferences.
+type DigestSubscriptionRequest struct {
+	Email     string `json:"email" binding:"required"` // Recipient address (required)
+	Frequency string `json:"frequency"`                // Digest frequency (default: daily)
+	Enabled   *bool  `json:"enabled"`                  // Whether digests are sent (default: true)
+}
+
+// Digest summarizes the scans of a user over one period.
+type Digest struct {
+	Frequency   string              `json:"frequency"`   // Digest frequency
+	PeriodStart time.Time           `json:"periodStart"` // Start of the covered period
+	PeriodEnd   time.Time           `json:"periodEnd"`   // End of the covered period
+	Images      []*DigestImage      `json:"images"`      // Images scanned in the period, most new findings first
+	FailedScans []*DigestFailedScan `json:"failedScans"` // Scans that failed in the period
+	NewCritical int                 `json:"newCritical"` // New CRITICAL vulnerabilities over all images
+	NewHigh     int                 `json:"newHigh"`     // New HIGH vulnerabilities over all images
+}
+
+// DigestImage summarizes the scans of one image in a digest period.
+type DigestImage struct {
+	Image           string                `json:"image"`                     // Scanned image, repository or uploaded file
+	TaskID          string                `json:"taskId"`                    // Latest completed scan of the image in the period
+	BaseTaskID      string                `json:"baseTaskId,omitempty"`      // Latest scan before the period the findings are compared to
+	Scans           int                   `json:"scans"`                     // Completed scans of the image in the period
+	Summary         *VulnerabilitySummary `json:"summary,omitempty"`         // Findings of the latest scan
+	NewCritical     int                   `json:"newCritical"`               // CRITICAL vulnerabilities not in the base scan
+	NewHigh         int                   `json:"newHigh"`                   // HIGH vulnerabilities not in the base scan
+	NewFindings     []DiffVulnerability   `json:"newFindings,omitempty"`     // New CRITICAL/HIGH vulnerabilities (truncated)
+	NewFindingsMore int                   `json:"newFindingsMore,omitempty"` // New CRITICAL/HIGH vulnerabilities left out of the list
+	ReportURL       string                `json:"reportUrl,omitempty"`       // Report link (requires the public URL setting)
+}
+
+// DigestFailedScan describes a scan that failed in a digest period.
+type DigestFailedScan struct {
+	TaskID    string    `json:"taskId"`          // Failed scan task
+	Image     string    `json:"image"`           // Scanned image, repository or uploaded file
+	Error     string    `json:"error,omitempty"` // Error output of the scan
+	StartTime time.Time `json:"startTime"`       // Scan start time
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Policy is a named set of rules a scan must satisfy, saved per user.
+// Stored as JSON file on the server, next to the saved scan configurations.
+type Policy struct {
+	Description string       `json:"description,omitempty"` // Human-readable description (optional)
+	Rules       []PolicyRule `json:"rules"`                 // Rules that must all hold for the scan to pass
+}
+
+// PolicyRule limits the number of findings of one kind. Findings are counted after
+// applying the optional severity, fix and category filters; the rule fails when the
+// count exceeds Max. For example:
+//
+//	{"kind": "vulnerability", "severities": ["CRITICAL"], "fixAvailable": true, "max": 0}
+//	{"kind": "vulnerability", "severities": ["HIGH"], "max": 5}
+//	{"kind": "secret", "max": 0}
+type PolicyRule struct {
+	Kind         string   `json:"kind"`                   // Finding kind (vulnerability, misconfiguration, secret, license)
+	Severities   []string `json:"severities,omitempty"`   // Severities counted (empty = all)
+	FixAvailable bool     `json:"fixAvailable,omitempty"` // Only count vulnerabilities with a fixed version
+	Categories   []string `json:"categories,omitempty"`   // Secret rule categories or license classifications counted (empty = all)
+	Max          int      `json:"max"`                    // Maximum number of findings allowed (0 = none)
+}
+
+// Validate checks that the rule is well-formed.
+func (r *PolicyRule) Validate() error {
+	switch r.Kind {
+	case FindingKindVulnerability, FindingKindMisconfiguration, FindingKindSecret, FindingKindLicense:
+	default:
+		return fmt.Errorf("unsupported finding kind: %q", r.Kind)
+	}
+	for _, severity := range r.Severities {
+		switch severity {
+		case "CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN":
+		default:
+			return fmt.Errorf("unsupported severity: %q", severity)
+		}
+	}
+	if r.FixAvailable && r.Kind != FindingKindVulnerability {
+		return fmt.Errorf("fixAvailable only applies to vulnerabilities")
+	}
+	if len(r.Categories) > 0 && r.Kind != FindingKindSecret && r.Kind != FindingKindLicense {
+		return fmt.Errorf("categories only apply to secrets and licenses")
+	}
+	if r.Max < 0 {
+		return fmt.Errorf("max cannot be negative")
+	}
+	return nil
+}
+
+// String describes the findings counted by the rule, e.g. "CRITICAL vulnerabilities with a fix available".
+func (r *PolicyRule) String() string {
+	var b strings.Builder
+	if len(r.Severities) > 0 {
+		b.WriteString(strings.Join(r.Severities, "/"))
+		b.WriteString(" ")
+	}
+	switch r.Kind {
+	case FindingKindVulnerability:
+		b.WriteString("vulnerabilities")
+	case FindingKindMisconfiguration:
+		b.WriteString("misconfigurations")
+	case FindingKindSecret:
+		b.WriteString("secrets")
+	case FindingKindLicense:
+		b.WriteString("licenses")
+	default:
+		b.WriteString(r.Kind)
+	}
+	if len(r.Categories) > 0 {
+		b.WriteString(" in categories ")
+		b.WriteString(strings.Join(r.Categories, ", "))
+	}
+	if r.FixAvailable {
+		b.WriteString(" with a fix available")
+	}
+	return b.String()
+}
+
+// Count returns the number of findings of a report matched by the rule.
+// Only failed misconfiguration checks are counted.
+func (r *PolicyRule) Count(report *TrivyReport) int {
+	matchesSeverity := func(severity string) bool {
+		if len(r.Severities) == 0 {
+			return true
+		}
+		for _, s := range r.Severities {
+			if strings.EqualFold(s, severity) {
+				return true
+			}
+		}
+		return false
+	}
+	matchesCategory := func(category string) bool {
+		if len(r.Categories) == 0 {
+			return true
+		}
+		for _, c := range r.Categories {
+			if strings.EqualFold(c, categoryOrUnknown(category)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	count := 0
+	for _, result := range report.Results {
+		switch r.Kind {
+		case FindingKindVulnerability:
+			for _, vuln := range result.Vulnerabilities {
+				if matchesSeverity(vuln.Severity) && (!r.FixAvailable || vuln.FixedVersion != "") {
+					count++
+				}
+			}
+		case FindingKindMisconfiguration:
+			for _, misconf := range result.Misconfigurations {
+				if (misconf.Status == "FAIL" || misconf.Status == "") && matchesSeverity(misconf.Severity) {
+					count++
+				}
+			}
+		case FindingKindSecret:
+			for _, secret := range result.Secrets {
+				if matchesSeverity(secret.Severity) && matchesCategory(secret.Category) {
+					count++
+				}
+			}
+		case FindingKindLicense:
+			for _, license := range result.Licenses {
+				if matchesSeverity(license.Severity) && matchesCategory(license.Category) {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// Validate checks that the policy has at least one rule and that all rules are well-formed.
+func (p *Policy) Validate() error {
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("policy must have at least one rule")
+	}
+	for i := range p.Rules {
+		if err := p.Rules[i].Validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Clone returns a copy of the policy that shares no slices with the original.
+func (p *Policy) Clone() *Policy {
+	clone := *p
+	clone.Rules = make([]PolicyRule, len(p.Rules))
+	for i, rule := range p.Rules {
+		rule.Severities = append([]string(nil), rule.Severities...)
+		rule.Categories = append([]string(nil), rule.Categories...)
+		clone.Rules[i] = rule
+	}
+	return &clone
+}
+
+// PolicyVerdict is the outcome of evaluating a policy against the findings of a scan.
+type PolicyVerdict struct {
+	Policy      string    `json:"policy"`            // Name of the evaluated policy
+	Passed      bool      `json:"passed"`            // Whether all rules hold
+	Reasons     []string  `json:"reasons,omitempty"` // Violated rules (empty when passed)
+	EvaluatedAt time.Time `json:"evaluatedAt"`       // Evaluation timestamp
+}
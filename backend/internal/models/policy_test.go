@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "testing"
+
+// TestPolicyValidate tests validation of policy rules
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{
+			name: "Valid rules",
+			policy: Policy{Rules: []PolicyRule{
+				{Kind: FindingKindVulnerability, Severities: []string{"CRITICAL"}, FixAvailable: true},
+				{Kind: FindingKindVulnerability, Severities: []string{"HIGH"}, Max: 5},
+				{Kind: FindingKindSecret},
+				{Kind: FindingKindLicense, Categories: []string{"forbidden", "restricted"}},
+			}},
+		},
+		{name: "No rules", policy: Policy{}, wantErr: true},
+		{name: "Unknown kind", policy: Policy{Rules: []PolicyRule{{Kind: "malware"}}}, wantErr: true},
+		{name: "Unknown severity", policy: Policy{Rules: []PolicyRule{{Kind: FindingKindVulnerability, Severities: []string{"SEVERE"}}}}, wantErr: true},
+		{name: "Fix filter on secrets", policy: Policy{Rules: []PolicyRule{{Kind: FindingKindSecret, FixAvailable: true}}}, wantErr: true},
+		{name: "Categories on vulnerabilities", policy: Policy{Rules: []PolicyRule{{Kind: FindingKindVulnerability, Categories: []string{"AWS"}}}}, wantErr: true},
+		{name: "Negative max", policy: Policy{Rules: []PolicyRule{{Kind: FindingKindSecret, Max: -1}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestPolicyRuleCount tests counting the findings matched by a rule
+func TestPolicyRuleCount(t *testing.T) {
+	report := &TrivyReport{Results: []TrivyResult{
+		{Target: "debian", Vulnerabilities: []TrivyVulnerability{
+			{VulnerabilityID: "CVE-1", Severity: "CRITICAL", FixedVersion: "1.2"},
+			{VulnerabilityID: "CVE-2", Severity: "CRITICAL"},
+			{VulnerabilityID: "CVE-3", Severity: "HIGH", FixedVersion: "2.0"},
+		}},
+		{Target: "Dockerfile", Misconfigurations: []TrivyMisconfiguration{
+			{ID: "DS002", Severity: "HIGH", Status: "FAIL"},
+			{ID: "DS001", Severity: "HIGH", Status: "PASS"},
+		}},
+		{Target: "/app/.env", Secrets: []TrivySecret{
+			{RuleID: "aws-access-key-id", Category: "AWS", Severity: "CRITICAL"},
+			{RuleID: "generic", Severity: "LOW"},
+		}},
+		{Target: "OS Packages", Licenses: []TrivyLicense{
+			{Name: "GPL-3.0", Category: "restricted", Severity: "HIGH"},
+			{Name: "MIT", Category: "notice", Severity: "LOW"},
+		}},
+	}}
+
+	tests := []struct {
+		name string
+		rule PolicyRule
+		want int
+	}{
+		{"All vulnerabilities", PolicyRule{Kind: FindingKindVulnerability}, 3},
+		{"Fixable critical vulnerabilities", PolicyRule{Kind: FindingKindVulnerability, Severities: []string{"CRITICAL"}, FixAvailable: true}, 1},
+		{"Failed misconfigurations", PolicyRule{Kind: FindingKindMisconfiguration}, 1},
+		{"Secrets by category", PolicyRule{Kind: FindingKindSecret, Categories: []string{"aws"}}, 1},
+		{"Uncategorized secrets", PolicyRule{Kind: FindingKindSecret, Categories: []string{"unknown"}}, 1},
+		{"Restricted licenses", PolicyRule{Kind: FindingKindLicense, Categories: []string{"forbidden", "restricted"}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Count(report); got != tt.want {
+				t.Errorf("Count() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
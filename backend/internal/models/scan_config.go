@@ -16,6 +16,8 @@ type SavedScanConfig struct {
 	DetectionPriority string   `json:"detectionPriority,omitempty"` // Detection priority
 	PkgTypes          []string `json:"pkgTypes,omitempty"`          // Package types
 	Format            string   `json:"format,omitempty"`            // Output format
+	Policy            string   `json:"policy,omitempty"`            // Saved policy evaluated after each scan
+	CheckBundle       string   `json:"checkBundle,omitempty"`       // Custom Rego check bundle (latest version)
 }
 
 // ConfigListResponse represents the response for listing saved configurations.
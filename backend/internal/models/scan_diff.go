@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+// DiffVulnerability is a vulnerability found in one or both of the compared scans.
+// Vulnerabilities are matched by VulnerabilityID + PkgName + InstalledVersion.
+type DiffVulnerability struct {
+	VulnerabilityID  string `json:"vulnerabilityId"`            // CVE or advisory identifier
+	PkgName          string `json:"pkgName"`                    // Affected package
+	InstalledVersion string `json:"installedVersion"`           // Installed package version
+	FixedVersion     string `json:"fixedVersion,omitempty"`     // Version fixing the vulnerability (if any)
+	Severity         string `json:"severity"`                   // Severity (CRITICAL, HIGH, MEDIUM, LOW, UNKNOWN)
+	PreviousSeverity string `json:"previousSeverity,omitempty"` // Severity in the base scan, when it changed
+	Title            string `json:"title,omitempty"`            // Short description
+	Target           string `json:"target,omitempty"`           // Scanned target (OS, lock file, ...)
+}
+
+// DiffBucket groups the vulnerabilities of one diff category with their severity counts.
+type DiffBucket struct {
+	Summary         VulnerabilitySummary `json:"summary"`         // Severity counts of the bucket
+	Vulnerabilities []DiffVulnerability  `json:"vulnerabilities"` // Vulnerabilities, most severe first
+}
+
+// ScanDiff compares the vulnerabilities of two completed scan tasks.
+type ScanDiff struct {
+	BaseTaskID   string     `json:"baseTaskId"`   // Earlier scan the target is compared against
+	BaseImage    string     `json:"baseImage"`    // Image (or target) of the base scan
+	TargetTaskID string     `json:"targetTaskId"` // Newer scan
+	TargetImage  string     `json:"targetImage"`  // Image (or target) of the newer scan
+	Added        DiffBucket `json:"added"`        // Only in the target scan (new vulnerabilities)
+	Removed      DiffBucket `json:"removed"`      // Only in the base scan (fixed vulnerabilities)
+	Unchanged    DiffBucket `json:"unchanged"`    // In both scans
+}
+
+// DiffRequest represents query parameters for comparing two scan tasks.
+type DiffRequest struct {
+	Base   string `form:"base" binding:"required"`   // Base task ID
+	Target string `form:"target" binding:"required"` // Target task ID
+}
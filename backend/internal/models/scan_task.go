@@ -5,6 +5,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -17,21 +19,57 @@ const (
 	ScanStatusRunning   ScanStatus = "running"   // Task is currently executing
 	ScanStatusCompleted ScanStatus = "completed" // Task completed successfully
 	ScanStatusFailed    ScanStatus = "failed"    // Task failed with error
+	ScanStatusCancelled ScanStatus = "cancelled" // Task cancelled by user
 )
 
+// TargetType represents the kind of artifact Trivy scans (maps to a trivy subcommand).
+type TargetType string
+
+const (
+	TargetTypeImage      TargetType = "image"  // Container image pulled from a registry
+	TargetTypeFilesystem TargetType = "fs"     // Uploaded project/filesystem tarball
+	TargetTypeRepository TargetType = "repo"   // Remote git repository
+	TargetTypeSBOM       TargetType = "sbom"   // Uploaded SBOM (CycloneDX or SPDX)
+	TargetTypeRootfs     TargetType = "rootfs" // Uploaded root filesystem tarball
+	TargetTypeVM         TargetType = "vm"     // Uploaded VM disk image
+)
+
+// IsValid reports whether the target type is supported.
+func (t TargetType) IsValid() bool {
+	switch t {
+	case TargetTypeImage, TargetTypeFilesystem, TargetTypeRepository, TargetTypeSBOM, TargetTypeRootfs, TargetTypeVM:
+		return true
+	}
+	return false
+}
+
+// IsUpload reports whether targets of this type are uploaded files rather than remote references.
+func (t TargetType) IsUpload() bool {
+	return t == TargetTypeFilesystem || t == TargetTypeSBOM || t == TargetTypeRootfs || t == TargetTypeVM
+}
+
+// IsFinished reports whether the status is terminal (the task will not run again).
+func (s ScanStatus) IsFinished() bool {
+	return s == ScanStatusCompleted || s == ScanStatusFailed || s == ScanStatusCancelled
+}
+
 // ScanTask represents a Trivy security scan task.
 // It tracks task metadata, status, logs, scan results, and provides real-time log streaming.
 type ScanTask struct {
 	ID        string     `json:"id"`                // Unique task identifier (UUID)
 	UserID    string     `json:"userId"`            // User ID (for OIDC multi-tenancy)
-	Image     string     `json:"image"`             // Container image to scan
+	Image     string     `json:"image"`             // Scan target: image reference, repository URL or uploaded file name
 	Status    ScanStatus `json:"status"`            // Current task status
 	Message   string     `json:"message"`           // Human-readable status message
 	StartTime time.Time  `json:"startTime"`         // Task creation timestamp
 	EndTime   *time.Time `json:"endTime,omitempty"` // Task completion timestamp
 
 	// Scan configuration
-	ScanConfig *ScanConfig `json:"scanConfig,omitempty"` // Scan parameters
+	ScanConfig   *ScanConfig `json:"scanConfig,omitempty"`   // Scan parameters
+	ParentTaskID string      `json:"parentTaskId,omitempty"` // Task this one is a rescan of (empty for new scans)
+	ScheduleID   string      `json:"scheduleId,omitempty"`   // Schedule that started this task (empty for manual scans)
+	RequestID    string      `json:"requestId,omitempty"`    // Correlation ID of the HTTP request that created the task
+	Shares       []TaskShare `json:"shares,omitempty"`       // OIDC groups the task is shared with
 
 	// Queue information
 	QueuePosition int `json:"queuePosition,omitempty"` // Position in queue (0 = running)
@@ -42,23 +80,75 @@ type ScanTask struct {
 	ErrorOutput  string        `json:"errorOutput,omitempty"`  // Error message (if failed)
 	TrivyVersion *TrivyVersion `json:"trivyVersion,omitempty"` // Trivy Server version info at scan time
 
-	// Log streaming
-	LogLines     []string      `json:"-"` // In-memory log lines (not serialized)
-	LogListeners []chan string `json:"-"` // Active log stream subscribers (SSE)
+	// Policy gate
+	PolicyVerdict *PolicyVerdict `json:"policyVerdict,omitempty"` // Outcome of the scan's policy (only when a policy was requested)
+
+	// Log streaming (shared by all copies of the task, see Clone)
+	*TaskLog `json:"-"`
+}
+
+// TaskLog holds the in-memory log of a scan task and its stream subscribers.
+type TaskLog struct {
+	LogLines     []string      // In-memory log lines (not serialized)
+	LogListeners []chan string // Active log stream subscribers (SSE)
+	closed       bool          // Set once the task finished; later listeners are closed immediately
 	logMu        sync.Mutex    // Mutex for thread-safe log operations
 }
 
+// NewTaskLog creates an empty task log.
+func NewTaskLog() *TaskLog {
+	return &TaskLog{
+		LogLines:     []string{},
+		LogListeners: []chan string{},
+	}
+}
+
 // ScanConfig represents scan configuration parameters.
 type ScanConfig struct {
-	Username          string   `json:"username,omitempty"`          // Registry username
-	Password          string   `json:"password,omitempty"`          // Registry password (masked in logs)
-	TLSVerify         bool     `json:"tlsVerify"`                   // Enable TLS certificate verification
-	Severity          []string `json:"severity,omitempty"`          // Vulnerability severity filter
-	IgnoreUnfixed     bool     `json:"ignoreUnfixed"`               // Ignore unfixed vulnerabilities
-	Scanners          []string `json:"scanners,omitempty"`          // Scanner types (vuln, misconfig, secret, license)
-	DetectionPriority string   `json:"detectionPriority,omitempty"` // Detection priority (precise, comprehensive)
-	PkgTypes          []string `json:"pkgTypes,omitempty"`          // Package types (os, library)
-	Format            string   `json:"format,omitempty"`            // Output format (json, table, sarif, etc.)
+	TargetType        TargetType         `json:"targetType,omitempty"`        // Kind of scan target (empty = image)
+	ImageArchive      bool               `json:"imageArchive,omitempty"`      // Image target is an uploaded tarball (docker save / OCI layout)
+	Username          string             `json:"username,omitempty"`          // Registry username
+	Password          string             `json:"password,omitempty"`          // Registry password (masked in logs)
+	TLSVerify         bool               `json:"tlsVerify"`                   // Enable TLS certificate verification
+	Severity          []string           `json:"severity,omitempty"`          // Vulnerability severity filter
+	IgnoreUnfixed     bool               `json:"ignoreUnfixed"`               // Ignore unfixed vulnerabilities
+	Scanners          []string           `json:"scanners,omitempty"`          // Scanner types (vuln, misconfig, secret, license)
+	DetectionPriority string             `json:"detectionPriority,omitempty"` // Detection priority (precise, comprehensive)
+	PkgTypes          []string           `json:"pkgTypes,omitempty"`          // Package types (os, library)
+	Format            string             `json:"format,omitempty"`            // Output format (json, table, sarif, etc.)
+	PolicyName        string             `json:"policyName,omitempty"`        // Saved policy evaluated after the scan (optional)
+	Policy            *Policy            `json:"policy,omitempty"`            // Snapshot of the policy taken when the task was created
+	CheckBundle       string             `json:"checkBundle,omitempty"`       // Custom Rego check bundle (misconfig scanner)
+	CheckVersion      int                `json:"checkVersion,omitempty"`      // Bundle version resolved when the task was created
+	CheckNamespaces   []string           `json:"checkNamespaces,omitempty"`   // Namespaces of the bundle's checks
+	Suppressions      []*SuppressionRule `json:"suppressions,omitempty"`      // Suppression rules applied when the scan started
+}
+
+// Clone returns a copy of the configuration that shares no slices with the original.
+func (c *ScanConfig) Clone() *ScanConfig {
+	clone := *c
+	clone.Severity = append([]string(nil), c.Severity...)
+	clone.Scanners = append([]string(nil), c.Scanners...)
+	clone.PkgTypes = append([]string(nil), c.PkgTypes...)
+	clone.CheckNamespaces = append([]string(nil), c.CheckNamespaces...)
+	clone.Suppressions = append([]*SuppressionRule(nil), c.Suppressions...)
+	if c.Policy != nil {
+		clone.Policy = c.Policy.Clone()
+	}
+	return &clone
+}
+
+// Target returns the configured target type, defaulting to image for older tasks.
+func (c *ScanConfig) Target() TargetType {
+	if c.TargetType == "" {
+		return TargetTypeImage
+	}
+	return c.TargetType
+}
+
+// HasUpload reports whether the scan target is a file uploaded with the task.
+func (c *ScanConfig) HasUpload() bool {
+	return c.Target().IsUpload() || (c.Target() == TargetTypeImage && c.ImageArchive)
 }
 
 // ScanResult represents parsed scan results from Trivy JSON output.
@@ -69,33 +159,137 @@ type ScanResult struct {
 }
 
 // VulnerabilitySummary represents aggregated vulnerability statistics.
+// Summaries of the other scanners are set only when the scanner was enabled.
 type VulnerabilitySummary struct {
-	Total    int `json:"total"`    // Total number of vulnerabilities
-	Critical int `json:"critical"` // Number of CRITICAL vulnerabilities
-	High     int `json:"high"`     // Number of HIGH vulnerabilities
-	Medium   int `json:"medium"`   // Number of MEDIUM vulnerabilities
-	Low      int `json:"low"`      // Number of LOW vulnerabilities
-	Unknown  int `json:"unknown"`  // Number of UNKNOWN severity vulnerabilities
+	Total      int `json:"total"`                // Total number of vulnerabilities
+	Critical   int `json:"critical"`             // Number of CRITICAL vulnerabilities
+	High       int `json:"high"`                 // Number of HIGH vulnerabilities
+	Medium     int `json:"medium"`               // Number of MEDIUM vulnerabilities
+	Low        int `json:"low"`                  // Number of LOW vulnerabilities
+	Unknown    int `json:"unknown"`              // Number of UNKNOWN severity vulnerabilities
+	Suppressed int `json:"suppressed,omitempty"` // Vulnerabilities suppressed by ignore rules (not counted above)
+
+	Misconfigurations *MisconfigurationSummary `json:"misconfigurations,omitempty"` // Misconfiguration checks (misconfig scanner)
+	Secrets           *SecretSummary           `json:"secrets,omitempty"`           // Detected secrets (secret scanner)
+	Licenses          *LicenseSummary          `json:"licenses,omitempty"`          // License findings (license scanner)
+}
+
+// MisconfigurationSummary counts misconfiguration checks by outcome and failed checks by severity.
+type MisconfigurationSummary struct {
+	Successes  int `json:"successes"`  // Passed checks
+	Failures   int `json:"failures"`   // Failed checks
+	Exceptions int `json:"exceptions"` // Checks skipped by an exception
+	Critical   int `json:"critical"`   // Failed CRITICAL checks
+	High       int `json:"high"`       // Failed HIGH checks
+	Medium     int `json:"medium"`     // Failed MEDIUM checks
+	Low        int `json:"low"`        // Failed LOW checks
+	Unknown    int `json:"unknown"`    // Failed checks of UNKNOWN severity
+}
+
+// SecretSummary counts detected secrets by rule category (AWS, GitHub, ...).
+type SecretSummary struct {
+	Total      int            `json:"total"`      // Total number of secrets
+	Categories map[string]int `json:"categories"` // Secrets per rule category
+}
+
+// LicenseSummary counts license findings by classification (forbidden, restricted, notice, ...).
+type LicenseSummary struct {
+	Total      int            `json:"total"`      // Total number of license findings
+	Categories map[string]int `json:"categories"` // Findings per classification
+}
+
+// Add counts a vulnerability of the given severity. Unrecognized severities count as unknown.
+func (s *VulnerabilitySummary) Add(severity string) {
+	s.Total++
+	switch severity {
+	case "CRITICAL":
+		s.Critical++
+	case "HIGH":
+		s.High++
+	case "MEDIUM":
+		s.Medium++
+	case "LOW":
+		s.Low++
+	default:
+		s.Unknown++
+	}
+}
+
+// addFailure counts a failed check of the given severity. Unrecognized severities count as unknown.
+func (s *MisconfigurationSummary) addFailure(severity string) {
+	switch severity {
+	case "CRITICAL":
+		s.Critical++
+	case "HIGH":
+		s.High++
+	case "MEDIUM":
+		s.Medium++
+	case "LOW":
+		s.Low++
+	default:
+		s.Unknown++
+	}
+}
+
+// Count returns the number of findings of a kind (failed checks for misconfigurations).
+// Kinds whose scanner was not enabled count as zero.
+func (s *VulnerabilitySummary) Count(kind string) int {
+	switch kind {
+	case FindingKindVulnerability:
+		return s.Total
+	case FindingKindMisconfiguration:
+		if s.Misconfigurations != nil {
+			return s.Misconfigurations.Failures
+		}
+	case FindingKindSecret:
+		if s.Secrets != nil {
+			return s.Secrets.Total
+		}
+	case FindingKindLicense:
+		if s.Licenses != nil {
+			return s.Licenses.Total
+		}
+	}
+	return 0
 }
 
 // NewScanTask creates a new scan task with initial queued status.
 func NewScanTask(id, userID, image string, config *ScanConfig) *ScanTask {
 	return &ScanTask{
-		ID:           id,
-		UserID:       userID,
-		Image:        image,
-		Status:       ScanStatusQueued,
-		Message:      "Task created and queued",
-		StartTime:    time.Now(),
-		ScanConfig:   config,
-		LogLines:     []string{},
-		LogListeners: []chan string{},
+		ID:         id,
+		UserID:     userID,
+		Image:      image,
+		Status:     ScanStatusQueued,
+		Message:    "Task created and queued",
+		StartTime:  time.Now(),
+		ScanConfig: config,
+		TaskLog:    NewTaskLog(),
 	}
 }
 
+// Clone returns a copy of the task that can be modified without affecting the original.
+// The result is copied one level deep (its summary is replaced, never modified) and
+// the log is shared, so lines added through any copy reach every listener.
+func (t *ScanTask) Clone() *ScanTask {
+	clone := *t
+	if t.EndTime != nil {
+		endTime := *t.EndTime
+		clone.EndTime = &endTime
+	}
+	if t.ScanConfig != nil {
+		clone.ScanConfig = t.ScanConfig.Clone()
+	}
+	if t.Result != nil {
+		result := *t.Result
+		clone.Result = &result
+	}
+	clone.Shares = append([]TaskShare(nil), t.Shares...)
+	return &clone
+}
+
 // AddLog appends a log line to the task and broadcasts it to all active listeners.
 // Thread-safe for concurrent access.
-func (t *ScanTask) AddLog(line string) {
+func (t *TaskLog) AddLog(line string) {
 	t.logMu.Lock()
 	defer t.logMu.Unlock()
 
@@ -113,18 +307,23 @@ func (t *ScanTask) AddLog(line string) {
 
 // AddLogListener creates a new log listener channel for SSE streaming.
 // Returns a buffered channel (100 messages) that will receive new log lines.
-func (t *ScanTask) AddLogListener() chan string {
+// The channel of a finished task is returned closed.
+func (t *TaskLog) AddLogListener() chan string {
 	t.logMu.Lock()
 	defer t.logMu.Unlock()
 
 	ch := make(chan string, 100)
+	if t.closed {
+		close(ch)
+		return ch
+	}
 	t.LogListeners = append(t.LogListeners, ch)
 	return ch
 }
 
 // RemoveLogListener removes and closes a log listener channel.
 // Should be called when an SSE client disconnects.
-func (t *ScanTask) RemoveLogListener(ch chan string) {
+func (t *TaskLog) RemoveLogListener(ch chan string) {
 	t.logMu.Lock()
 	defer t.logMu.Unlock()
 
@@ -139,7 +338,7 @@ func (t *ScanTask) RemoveLogListener(ch chan string) {
 
 // CloseAllLogListeners closes all active log listener channels.
 // Called when task completes to notify all SSE clients.
-func (t *ScanTask) CloseAllLogListeners() {
+func (t *TaskLog) CloseAllLogListeners() {
 	t.logMu.Lock()
 	defer t.logMu.Unlock()
 
@@ -147,11 +346,12 @@ func (t *ScanTask) CloseAllLogListeners() {
 		close(ch)
 	}
 	t.LogListeners = []chan string{}
+	t.closed = true
 }
 
 // GetLogLines returns a copy of all log lines.
 // Thread-safe for concurrent access.
-func (t *ScanTask) GetLogLines() []string {
+func (t *TaskLog) GetLogLines() []string {
 	t.logMu.Lock()
 	defer t.logMu.Unlock()
 
@@ -164,12 +364,16 @@ func (t *ScanTask) GetLogLines() []string {
 func (t *ScanTask) ToSummary() *TaskSummary {
 	summary := &TaskSummary{
 		ID:            t.ID,
+		UserID:        t.UserID,
 		Image:         t.Image,
 		Status:        string(t.Status),
 		Message:       t.Message,
 		StartTime:     t.StartTime,
 		EndTime:       t.EndTime,
 		QueuePosition: t.QueuePosition,
+		ParentTaskID:  t.ParentTaskID,
+		ScheduleID:    t.ScheduleID,
+		PolicyVerdict: t.PolicyVerdict,
 	}
 
 	// Only include summary if result exists
@@ -182,28 +386,47 @@ func (t *ScanTask) ToSummary() *TaskSummary {
 
 // ScanRequest represents the request body for creating a scan task.
 type ScanRequest struct {
-	Image             string   `json:"image" binding:"required"` // Container image to scan (required)
-	Username          string   `json:"username"`                 // Registry username (optional)
-	Password          string   `json:"password"`                 // Registry password (optional)
-	TLSVerify         *bool    `json:"tlsVerify"`                // TLS verification (optional, default: true)
-	Severity          []string `json:"severity"`                 // Vulnerability severity filter (optional)
-	IgnoreUnfixed     bool     `json:"ignoreUnfixed"`            // Ignore unfixed vulnerabilities (optional)
-	Scanners          []string `json:"scanners"`                 // Scanner types (optional, default: ["vuln"])
-	DetectionPriority string   `json:"detectionPriority"`        // Detection priority (optional, default: "precise")
-	PkgTypes          []string `json:"pkgTypes"`                 // Package types (optional)
-	Format            string   `json:"format"`                   // Output format (optional, default: "json")
+	TargetType        TargetType `json:"targetType"`               // Kind of scan target (optional, default: "image")
+	Image             string     `json:"image" binding:"required"` // Image reference or repository URL to scan (required)
+	Username          string     `json:"username"`                 // Registry username (optional)
+	Password          string     `json:"password"`                 // Registry password (optional)
+	TLSVerify         *bool      `json:"tlsVerify"`                // TLS verification (optional, default: true)
+	Severity          []string   `json:"severity"`                 // Vulnerability severity filter (optional)
+	IgnoreUnfixed     bool       `json:"ignoreUnfixed"`            // Ignore unfixed vulnerabilities (optional)
+	Scanners          []string   `json:"scanners"`                 // Scanner types (optional, default: ["vuln"])
+	DetectionPriority string     `json:"detectionPriority"`        // Detection priority (optional, default: "precise")
+	PkgTypes          []string   `json:"pkgTypes"`                 // Package types (optional)
+	Format            string     `json:"format"`                   // Output format (optional, default: "json")
+	Policy            string     `json:"policy"`                   // Saved policy to evaluate (optional, requires JSON format)
+	CheckBundle       string     `json:"checkBundle"`              // Custom Rego check bundle (optional, requires the misconfig scanner)
+	CheckVersion      int        `json:"checkVersion"`             // Bundle version (optional, default: latest)
+	RequestID         string     `json:"-"`                        // Correlation ID of the HTTP request (set by the handler)
 }
 
 // TaskSummary represents a summarized view of a scan task (for list queries).
 type TaskSummary struct {
 	ID            string                `json:"id"`
+	UserID        string                `json:"userId,omitempty"` // Owner of the task
 	Image         string                `json:"image"`
 	Status        string                `json:"status"`
 	Message       string                `json:"message"`
 	StartTime     time.Time             `json:"startTime"`
 	EndTime       *time.Time            `json:"endTime,omitempty"`
 	QueuePosition int                   `json:"queuePosition,omitempty"` // Position in queue (only for queued tasks)
+	ParentTaskID  string                `json:"parentTaskId,omitempty"`  // Task this one is a rescan of
+	ScheduleID    string                `json:"scheduleId,omitempty"`    // Schedule that started this task
 	Summary       *VulnerabilitySummary `json:"summary,omitempty"`       // Vulnerability statistics
+	PolicyVerdict *PolicyVerdict        `json:"policyVerdict,omitempty"` // Policy gate outcome
+}
+
+// RescanRequest represents the optional request body for rescanning a task.
+// Registry credentials are resolved in this order: explicit username/password,
+// the named saved configuration, then the credentials stored with the original task.
+type RescanRequest struct {
+	Username   string `json:"username"`   // Registry username (optional)
+	Password   string `json:"password"`   // Registry password (optional)
+	ConfigName string `json:"configName"` // Saved configuration to resolve credentials from (optional)
+	RequestID  string `json:"-"`          // Correlation ID of the HTTP request (set by the handler)
 }
 
 // TaskListRequest represents query parameters for listing scan tasks.
@@ -213,6 +436,121 @@ type TaskListRequest struct {
 	Status    string `form:"status"`                   // Filter by status (optional)
 	SortBy    string `form:"sortBy,default=startTime"` // Sort field (default: startTime)
 	SortOrder string `form:"sortOrder,default=desc"`   // Sort order: asc/desc (default: desc)
+	StartDate string `form:"startDate"`                // Only tasks started at or after this date (optional, RFC 3339 or YYYY-MM-DD)
+	EndDate   string `form:"endDate"`                  // Only tasks started at or before this date (optional, RFC 3339 or YYYY-MM-DD)
+
+	// HasFindings lists finding kinds the task must have (comma-separated, optional):
+	// vulnerability, misconfiguration (failed checks), secret, license
+	HasFindings string `form:"hasFindings"`
+
+	// AllUsers lists the tasks of all users instead of the given user (admin listing, not bound from the query)
+	AllUsers bool `form:"-"`
+}
+
+// FindingKinds parses the HasFindings filter.
+func (r *TaskListRequest) FindingKinds() ([]string, error) {
+	var kinds []string
+	for _, kind := range strings.Split(r.HasFindings, ",") {
+		kind = strings.TrimSpace(kind)
+		switch kind {
+		case "":
+			continue
+		case FindingKindVulnerability, FindingKindMisconfiguration, FindingKindSecret, FindingKindLicense:
+			kinds = append(kinds, kind)
+		default:
+			return nil, fmt.Errorf("invalid hasFindings: unsupported finding kind %q", kind)
+		}
+	}
+	return kinds, nil
+}
+
+// DateRange parses the StartDate/EndDate filters.
+// A zero time means the bound is not set. A date-only EndDate covers the whole day.
+func (r *TaskListRequest) DateRange() (from, to time.Time, err error) {
+	if r.StartDate != "" {
+		if from, err = parseFilterDate(r.StartDate, false); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid startDate: %w", err)
+		}
+	}
+	if r.EndDate != "" {
+		if to, err = parseFilterDate(r.EndDate, true); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid endDate: %w", err)
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("endDate is before startDate")
+	}
+	return from, to, nil
+}
+
+// HasFindings reports whether the task result has findings of every given kind.
+func (t *ScanTask) HasFindings(kinds []string) bool {
+	for _, kind := range kinds {
+		if t.Result == nil || t.Result.Summary == nil || t.Result.Summary.Count(kind) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SummaryCount returns the finding count used by the summary sort fields
+// (vulnerabilities, critical, high, misconfigurations, secrets, licenses).
+// Tasks without a summary count as zero.
+func (t *ScanTask) SummaryCount(field string) int {
+	if t.Result == nil || t.Result.Summary == nil {
+		return 0
+	}
+	summary := t.Result.Summary
+	switch field {
+	case "vulnerabilities":
+		return summary.Total
+	case "critical":
+		return summary.Critical
+	case "high":
+		return summary.High
+	case "misconfigurations":
+		return summary.Count(FindingKindMisconfiguration)
+	case "secrets":
+		return summary.Count(FindingKindSecret)
+	case "licenses":
+		return summary.Count(FindingKindLicense)
+	}
+	return 0
+}
+
+// MatchesDateRange reports whether the task start time falls within [from, to].
+// Zero bounds are ignored.
+func (t *ScanTask) MatchesDateRange(from, to time.Time) bool {
+	if !from.IsZero() && t.StartTime.Before(from) {
+		return false
+	}
+	if !to.IsZero() && t.StartTime.After(to) {
+		return false
+	}
+	return true
+}
+
+// parseFilterDate parses an RFC 3339 timestamp or a YYYY-MM-DD date (UTC).
+// For date-only values, endOfDay moves the result to the last instant of that day.
+func parseFilterDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 or YYYY-MM-DD, got %q", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// ExportRequest represents query parameters for exporting the scan history.
+// It accepts the same filters as TaskListRequest; pagination is ignored.
+type ExportRequest struct {
+	TaskListRequest
+	Format string `form:"format,default=csv"` // Export format: csv, json, xlsx (default: csv)
 }
 
 // TaskListResponse represents the response for task list queries.
@@ -225,6 +563,22 @@ type TaskListResponse struct {
 
 // QueueStatusResponse represents the current queue status.
 type QueueStatusResponse struct {
-	QueueLength     int     `json:"queueLength"`     // Number of tasks waiting in queue
-	AverageWaitTime float64 `json:"averageWaitTime"` // Average wait time in seconds
+	QueueLength     int     `json:"queueLength"`     // Number of the user's tasks waiting in queue
+	TotalQueued     int     `json:"totalQueued"`     // Number of tasks waiting in queue across all users
+	RunningTasks    int     `json:"runningTasks"`    // Number of tasks currently executing
+	MaxWorkers      int     `json:"maxWorkers"`      // Maximum number of concurrent scans
+	AverageWaitTime float64 `json:"averageWaitTime"` // Estimated wait time in seconds until the user's last queued task starts
+	AverageScanTime float64 `json:"averageScanTime"` // Average scan duration in seconds (recent completed scans)
+}
+
+// PurgeRequest represents the request body for purging finished tasks (admin).
+type PurgeRequest struct {
+	OlderThanDays int    `json:"olderThanDays"` // Purge tasks finished more than this many days ago (0 = all finished tasks)
+	UserID        string `json:"userId"`        // Only purge the tasks of this user (optional)
+}
+
+// PurgeResponse reports the result of a purge.
+type PurgeResponse struct {
+	Deleted    int   `json:"deleted"`    // Number of deleted tasks
+	FreedBytes int64 `json:"freedBytes"` // Size of the deleted report files and uploads in bytes
 }
@@ -150,6 +150,32 @@ func TestCloseAllLogListeners(t *testing.T) {
 	}
 }
 
+// TestClone tests that task copies share only the log
+func TestClone(t *testing.T) {
+	task := NewScanTask("task-1", "user-1", "alpine:latest", &ScanConfig{Severity: []string{"HIGH"}})
+	task.Shares = []TaskShare{{Group: "security", Permission: SharePermissionRead}}
+	listener := task.AddLogListener()
+
+	clone := task.Clone()
+	clone.Status = ScanStatusRunning
+	clone.ScanConfig.Severity[0] = "LOW"
+	clone.Shares[0].Group = "dev"
+	clone.AddLog("Line 1")
+
+	if task.Status != ScanStatusQueued || task.ScanConfig.Severity[0] != "HIGH" || task.Shares[0].Group != "security" {
+		t.Errorf("Expected the original task to be unchanged, got %+v", task)
+	}
+	if line := <-listener; line != "Line 1" {
+		t.Errorf("Expected the clone's log line on the original's listener, got %q", line)
+	}
+
+	// Listeners added after the task finished are closed immediately
+	clone.CloseAllLogListeners()
+	if _, ok := <-task.AddLogListener(); ok {
+		t.Error("Expected a closed listener for a finished task")
+	}
+}
+
 // TestGetLogLines tests getting log lines copy
 func TestGetLogLines(t *testing.T) {
 	task := NewScanTask("task-1", "user-1", "alpine:latest", &ScanConfig{})
@@ -289,3 +315,93 @@ func TestLogListenerBufferOverflow(t *testing.T) {
 		}
 	}
 }
+
+// TestDateRange tests parsing of the startDate/endDate list filters
+func TestDateRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   string
+		wantFrom  time.Time
+		wantTo    time.Time
+		wantErr   bool
+	}{
+		{
+			name: "No filters",
+		},
+		{
+			name:      "Date-only values cover whole days",
+			startDate: "2025-10-01",
+			endDate:   "2025-10-02",
+			wantFrom:  time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:    time.Date(2025, 10, 2, 23, 59, 59, 999999999, time.UTC),
+		},
+		{
+			name:      "RFC 3339 timestamps",
+			startDate: "2025-10-01T08:00:00Z",
+			endDate:   "2025-10-01T09:00:00Z",
+			wantFrom:  time.Date(2025, 10, 1, 8, 0, 0, 0, time.UTC),
+			wantTo:    time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "Invalid date",
+			startDate: "01/10/2025",
+			wantErr:   true,
+		},
+		{
+			name:      "End before start",
+			startDate: "2025-10-02",
+			endDate:   "2025-10-01",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &TaskListRequest{StartDate: tt.startDate, EndDate: tt.endDate}
+			from, to, err := req.DateRange()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DateRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("DateRange() = (%v, %v), want (%v, %v)", from, to, tt.wantFrom, tt.wantTo)
+			}
+
+			task := &ScanTask{StartTime: time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)}
+			if !task.MatchesDateRange(from, to) {
+				t.Errorf("Expected task at %v to match range", task.StartTime)
+			}
+		})
+	}
+}
+
+// TestFindingKinds tests parsing the hasFindings filter
+func TestFindingKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"Empty", "", 0, false},
+		{"Single kind", "secret", 1, false},
+		{"Multiple kinds with spaces", "vulnerability, misconfiguration,license", 3, false},
+		{"Unsupported kind", "secret,malware", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &TaskListRequest{HasFindings: tt.value}
+			kinds, err := req.FindingKinds()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindingKinds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(kinds) != tt.want {
+				t.Errorf("Expected %d kinds, got %v", tt.want, kinds)
+			}
+		})
+	}
+}
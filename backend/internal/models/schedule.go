@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// Schedule represents a recurring image scan driven by a cron expression.
+// The scan target is an image reference, a saved configuration, or both
+// (the image then overrides the image prefix of the configuration).
+type Schedule struct {
+	ID         string     `json:"id"`                   // Unique schedule identifier (UUID)
+	UserID     string     `json:"userId"`               // Owner (for OIDC multi-tenancy)
+	Name       string     `json:"name,omitempty"`       // Display name (optional)
+	Image      string     `json:"image,omitempty"`      // Image to scan (optional when ConfigName is set)
+	ConfigName string     `json:"configName,omitempty"` // Saved configuration supplying scan options and credentials
+	Cron       string     `json:"cron"`                 // Cron expression (5 fields or descriptor such as @daily)
+	Paused     bool       `json:"paused"`               // Paused schedules do not start scans
+	CreatedAt  time.Time  `json:"createdAt"`            // Schedule creation timestamp
+	LastRunAt  *time.Time `json:"lastRunAt,omitempty"`  // Last time the schedule fired
+	LastTaskID string     `json:"lastTaskId,omitempty"` // Task started by the last run
+	LastError  string     `json:"lastError,omitempty"`  // Error of the last run (empty on success)
+	NextRunAt  *time.Time `json:"nextRunAt,omitempty"`  // Next time the schedule fires (unset while paused)
+}
+
+// Clone returns a copy of the schedule that shares no pointers with the original.
+func (s *Schedule) Clone() *Schedule {
+	clone := *s
+	if s.LastRunAt != nil {
+		lastRun := *s.LastRunAt
+		clone.LastRunAt = &lastRun
+	}
+	if s.NextRunAt != nil {
+		nextRun := *s.NextRunAt
+		clone.NextRunAt = &nextRun
+	}
+	return &clone
+}
+
+// ScheduleRequest represents the request body for creating a schedule.
+type ScheduleRequest struct {
+	Name       string `json:"name"`                    // Display name (optional)
+	Image      string `json:"image"`                   // Image to scan (required unless configName is set)
+	ConfigName string `json:"configName"`              // Saved configuration to use (optional)
+	Cron       string `json:"cron" binding:"required"` // Cron expression (required)
+}
+
+// ScheduleListResponse represents the response for listing schedules.
+type ScheduleListResponse struct {
+	Schedules []*Schedule `json:"schedules"` // Schedules ordered by creation time
+}
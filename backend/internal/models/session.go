@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// Session is a persisted browser login. The session cookie itself is never stored,
+// only its SHA-256 hash, so a leaked session file cannot be used to log in.
+type Session struct {
+	ID              string    `json:"id"`                      // Unique session identifier (UUID, not the cookie value)
+	TokenHash       string    `json:"tokenHash,omitempty"`     // Hex SHA-256 hash of the session cookie (never returned by the API)
+	UserID          string    `json:"userId"`                  // User identifier of the owner (email_subject)
+	Subject         string    `json:"subject"`                 // OIDC user ID
+	Email           string    `json:"email"`                   // Email of the user
+	Groups          []string  `json:"groups"`                  // OIDC groups, updated when the session is renewed
+	RefreshToken    string    `json:"refreshToken,omitempty"`  // OIDC refresh token used to renew the session (never returned by the API)
+	IDToken         string    `json:"idToken,omitempty"`       // Latest raw ID token, sent as id_token_hint on logout (never returned by the API)
+	OIDCSessionID   string    `json:"oidcSessionId,omitempty"` // Session ID at the provider (sid claim), matched by back-channel logout
+	IPAddress       string    `json:"ipAddress,omitempty"`     // Client IP address at login
+	UserAgent       string    `json:"userAgent,omitempty"`     // Browser user agent at login
+	CreatedAt       time.Time `json:"createdAt"`               // Login timestamp
+	AuthenticatedAt time.Time `json:"authenticatedAt"`         // Last login or renewal with the identity provider
+	LastSeenAt      time.Time `json:"lastSeenAt"`              // Last request with the session (updated at most once per minute)
+	ExpiresAt       time.Time `json:"expiresAt"`               // Time the session ends without further activity
+}
+
+// Clone returns a copy of the session that shares no slices with the original.
+func (s *Session) Clone() *Session {
+	clone := *s
+	clone.Groups = append([]string(nil), s.Groups...)
+	return &clone
+}
+
+// IsExpired reports whether the session has expired at the given time.
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// SessionListResponse represents the response for listing sessions.
+type SessionListResponse struct {
+	Sessions []*Session `json:"sessions"` // Active sessions ordered by login time
+}
+
+// SessionRevokeResponse represents the response for revoking the sessions of a user.
+type SessionRevokeResponse struct {
+	Revoked int `json:"revoked"` // Number of sessions revoked
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Suppression scopes.
+const (
+	SuppressionScopeUser   = "user"   // Rule owned by the user, applies to the user's scans
+	SuppressionScopeGlobal = "global" // Rule of the server, applies to all scans
+)
+
+// vulnerabilityIDRegex matches vulnerability identifiers such as CVE-2023-1234 or GHSA-xxxx-xxxx-xxxx.
+var vulnerabilityIDRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]{0,127}$`)
+
+// SuppressionRule accepts the risk of a vulnerability. While the rule applies, Trivy reports
+// matching vulnerabilities as suppressed instead of as findings; expired rules no longer apply.
+type SuppressionRule struct {
+	ID              string     `json:"id"`                  // Unique rule identifier (UUID)
+	VulnerabilityID string     `json:"vulnerabilityId"`     // Suppressed vulnerability (e.g. CVE-2023-1234)
+	Package         string     `json:"package,omitempty"`   // Package URL limiting the rule (e.g. pkg:npm/lodash, empty = all packages)
+	ExpiresAt       *time.Time `json:"expiresAt,omitempty"` // Time the rule stops applying (nil = never)
+	Justification   string     `json:"justification"`       // Why the risk is accepted (shown with suppressed findings)
+	Scope           string     `json:"scope"`               // user or global
+	CreatedAt       time.Time  `json:"createdAt"`           // Rule creation timestamp
+	Expired         bool       `json:"expired,omitempty"`   // Whether the rule had expired when it was listed
+}
+
+// Validate checks that the rule is well-formed.
+func (r *SuppressionRule) Validate() error {
+	if !vulnerabilityIDRegex.MatchString(r.VulnerabilityID) {
+		return fmt.Errorf("invalid vulnerability ID: %q", r.VulnerabilityID)
+	}
+	if r.Package != "" && (!strings.HasPrefix(r.Package, "pkg:") || !strings.Contains(r.Package, "/") || strings.ContainsAny(r.Package, " \t\r\n")) {
+		return fmt.Errorf("package must be a package URL such as pkg:npm/lodash: %q", r.Package)
+	}
+	if strings.TrimSpace(r.Justification) == "" {
+		return fmt.Errorf("justification is required")
+	}
+	if len(r.Justification) > 1024 {
+		return fmt.Errorf("justification is too long (max 1024 characters)")
+	}
+	return nil
+}
+
+// IsExpired reports whether the rule has expired at the given time.
+func (r *SuppressionRule) IsExpired(now time.Time) bool {
+	return r.ExpiresAt != nil && !now.Before(*r.ExpiresAt)
+}
+
+// SuppressionRequest represents the request body for creating a suppression rule.
+type SuppressionRequest struct {
+	VulnerabilityID string     `json:"vulnerabilityId" binding:"required"` // Vulnerability to suppress (required)
+	Package         string     `json:"package"`                            // Package URL limiting the rule (optional)
+	ExpiresAt       *time.Time `json:"expiresAt"`                          // Expiry time (optional, RFC 3339)
+	Justification   string     `json:"justification" binding:"required"`   // Why the risk is accepted (required)
+}
+
+// SuppressionListResponse represents the response for listing suppression rules.
+type SuppressionListResponse struct {
+	Suppressions []*SuppressionRule `json:"suppressions"` // Global rules first, then the user's rules by creation time
+}
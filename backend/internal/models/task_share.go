@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SharePermission is the access a user has to a scan task.
+type SharePermission string
+
+const (
+	SharePermissionRead   SharePermission = "read"   // View the task, its logs, findings and reports
+	SharePermissionManage SharePermission = "manage" // Additionally cancel, rescan and delete the task
+)
+
+// maxTaskShares limits the number of groups a task can be shared with.
+const maxTaskShares = 32
+
+// IsValid reports whether the permission is supported.
+func (p SharePermission) IsValid() bool {
+	return p == SharePermissionRead || p == SharePermissionManage
+}
+
+// Allows reports whether the permission grants the required permission
+// (manage includes read; the empty permission grants nothing).
+func (p SharePermission) Allows(required SharePermission) bool {
+	switch p {
+	case SharePermissionManage:
+		return required == SharePermissionRead || required == SharePermissionManage
+	case SharePermissionRead:
+		return required == SharePermissionRead
+	}
+	return false
+}
+
+// TaskShare grants the members of an OIDC group access to a scan task.
+type TaskShare struct {
+	Group      string          `json:"group"`      // OIDC group name (from the groups claim)
+	Permission SharePermission `json:"permission"` // read or manage
+}
+
+// ShareTaskRequest represents the request body for replacing the shares of a task.
+type ShareTaskRequest struct {
+	Shares []TaskShare `json:"shares"` // Groups the task is shared with (empty = owner only)
+}
+
+// Validate checks the shares and removes duplicate groups (the last share of a group wins).
+func (r *ShareTaskRequest) Validate() error {
+	if len(r.Shares) > maxTaskShares {
+		return fmt.Errorf("a task can be shared with at most %d groups", maxTaskShares)
+	}
+
+	index := make(map[string]int)
+	shares := make([]TaskShare, 0, len(r.Shares))
+	for _, share := range r.Shares {
+		share.Group = strings.TrimSpace(share.Group)
+		if share.Group == "" {
+			return fmt.Errorf("group is required")
+		}
+		if !share.Permission.IsValid() {
+			return fmt.Errorf("invalid permission for group %s: %q (must be read or manage)", share.Group, share.Permission)
+		}
+		if i, ok := index[share.Group]; ok {
+			shares[i] = share
+			continue
+		}
+		index[share.Group] = len(shares)
+		shares = append(shares, share)
+	}
+	r.Shares = shares
+
+	return nil
+}
+
+// Principal identifies the user a request acts for.
+type Principal struct {
+	UserID string   // User identifier (owner of the tasks the user creates)
+	Groups []string // OIDC groups of the user
+	Admin  bool     // Whether the user has the admin role (read access to all tasks)
+}
+
+// Permission returns the access the principal has to the task: manage for the owner,
+// otherwise the highest permission of the groups the task is shared with, at least read
+// for admins (empty if none).
+func (t *ScanTask) Permission(principal Principal) SharePermission {
+	if t.UserID == principal.UserID {
+		return SharePermissionManage
+	}
+
+	var permission SharePermission
+	if principal.Admin {
+		permission = SharePermissionRead
+	}
+	for _, share := range t.Shares {
+		for _, group := range principal.Groups {
+			if share.Group != group {
+				continue
+			}
+			if share.Permission == SharePermissionManage {
+				return SharePermissionManage
+			}
+			permission = share.Permission
+		}
+	}
+	return permission
+}
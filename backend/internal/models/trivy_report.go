@@ -0,0 +1,267 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TrivyReport is the typed model of Trivy's JSON report (--format json).
+// JSON tags are camelCase versions of Trivy's field names; encoding/json matches
+// field names case-insensitively, so Trivy output decodes directly into these types
+// while the API serves them in the same style as the rest of the API.
+type TrivyReport struct {
+	SchemaVersion int           `json:"schemaVersion"`          // Report schema version
+	CreatedAt     *time.Time    `json:"createdAt,omitempty"`    // Report creation time
+	ArtifactName  string        `json:"artifactName,omitempty"` // Scanned image, path or repository
+	ArtifactType  string        `json:"artifactType,omitempty"` // container_image, filesystem, repository, ...
+	Results       []TrivyResult `json:"results"`                // Findings per scanned target
+}
+
+// TrivyResult groups the findings of one scanned target (OS packages, a lock file, a config file, ...).
+type TrivyResult struct {
+	Target            string                  `json:"target"`                                 // Target name (e.g. "alpine:3.19 (alpine 3.19.1)", "package-lock.json")
+	Class             string                  `json:"class,omitempty"`                        // os-pkgs, lang-pkgs, config, secret, license, ...
+	Type              string                  `json:"type,omitempty"`                         // alpine, npm, dockerfile, ...
+	Vulnerabilities   []TrivyVulnerability    `json:"vulnerabilities,omitempty"`              // Vulnerabilities (vuln scanner)
+	MisconfSummary    *TrivyMisconfSummary    `json:"misconfSummary,omitempty"`               // Check counts (misconfig scanner)
+	Misconfigurations []TrivyMisconfiguration `json:"misconfigurations,omitempty"`            // Misconfigurations (misconfig scanner)
+	Secrets           []TrivySecret           `json:"secrets,omitempty"`                      // Detected secrets (secret scanner)
+	Licenses          []TrivyLicense          `json:"licenses,omitempty"`                     // License findings (license scanner)
+	ModifiedFindings  []TrivyModifiedFinding  `json:"experimentalModifiedFindings,omitempty"` // Suppressed findings (--show-suppressed)
+}
+
+// TrivyVulnerability is a vulnerability detected in a package.
+type TrivyVulnerability struct {
+	VulnerabilityID  string               `json:"vulnerabilityId"`            // CVE or advisory identifier
+	PkgID            string               `json:"pkgId,omitempty"`            // Package identifier (name@version)
+	PkgName          string               `json:"pkgName"`                    // Package name
+	PkgIdentifier    *TrivyPkgIdentifier  `json:"pkgIdentifier,omitempty"`    // Package URL of the package
+	PkgPath          string               `json:"pkgPath,omitempty"`          // File path of the package (language packages)
+	InstalledVersion string               `json:"installedVersion"`           // Installed package version
+	FixedVersion     string               `json:"fixedVersion,omitempty"`     // Versions fixing the vulnerability (empty if no fix)
+	Status           string               `json:"status,omitempty"`           // fixed, affected, will_not_fix, ...
+	Severity         string               `json:"severity"`                   // CRITICAL, HIGH, MEDIUM, LOW or UNKNOWN
+	SeveritySource   string               `json:"severitySource,omitempty"`   // Source of the severity rating
+	PrimaryURL       string               `json:"primaryUrl,omitempty"`       // Advisory URL
+	Title            string               `json:"title,omitempty"`            // Short description
+	Description      string               `json:"description,omitempty"`      // Full description
+	CweIDs           []string             `json:"cweIds,omitempty"`           // CWE identifiers
+	CVSS             map[string]TrivyCVSS `json:"cvss,omitempty"`             // CVSS scores by source (nvd, redhat, ...)
+	References       []string             `json:"references,omitempty"`       // Reference URLs
+	PublishedDate    *time.Time           `json:"publishedDate,omitempty"`    // Publication date
+	LastModifiedDate *time.Time           `json:"lastModifiedDate,omitempty"` // Last modification date
+}
+
+// TrivyPkgIdentifier identifies a package across ecosystems.
+type TrivyPkgIdentifier struct {
+	PURL string `json:"purl,omitempty"` // Package URL (e.g. pkg:npm/lodash@4.17.20)
+	UID  string `json:"uid,omitempty"`  // Unique identifier of the package in the report
+}
+
+// TrivyCVSS holds the CVSS vectors and scores of one source.
+type TrivyCVSS struct {
+	V2Vector  string  `json:"v2Vector,omitempty"`
+	V3Vector  string  `json:"v3Vector,omitempty"`
+	V40Vector string  `json:"v40Vector,omitempty"`
+	V2Score   float64 `json:"v2Score,omitempty"`
+	V3Score   float64 `json:"v3Score,omitempty"`
+	V40Score  float64 `json:"v40Score,omitempty"`
+}
+
+// TrivyMisconfSummary counts the misconfiguration checks of a target.
+type TrivyMisconfSummary struct {
+	Successes  int `json:"successes"`
+	Failures   int `json:"failures"`
+	Exceptions int `json:"exceptions"`
+}
+
+// TrivyMisconfiguration is the outcome of a misconfiguration check.
+type TrivyMisconfiguration struct {
+	Type          string              `json:"type,omitempty"`          // Check type (e.g. Dockerfile Security Check)
+	ID            string              `json:"id"`                      // Check ID (e.g. DS002)
+	AVDID         string              `json:"avdId,omitempty"`         // Aqua vulnerability database ID
+	Title         string              `json:"title,omitempty"`         // Check title
+	Description   string              `json:"description,omitempty"`   // Check description
+	Message       string              `json:"message,omitempty"`       // Finding message
+	Namespace     string              `json:"namespace,omitempty"`     // Rego namespace of the check
+	Resolution    string              `json:"resolution,omitempty"`    // How to fix the finding
+	Severity      string              `json:"severity"`                // CRITICAL, HIGH, MEDIUM, LOW or UNKNOWN
+	PrimaryURL    string              `json:"primaryUrl,omitempty"`    // Documentation URL
+	References    []string            `json:"references,omitempty"`    // Reference URLs
+	Status        string              `json:"status,omitempty"`        // PASS, FAIL or EXCEPTION
+	CauseMetadata *TrivyCauseMetadata `json:"causeMetadata,omitempty"` // Location of the finding
+}
+
+// TrivyCauseMetadata locates a misconfiguration in the scanned file.
+type TrivyCauseMetadata struct {
+	Resource  string `json:"resource,omitempty"`
+	Provider  string `json:"provider,omitempty"`
+	Service   string `json:"service,omitempty"`
+	StartLine int    `json:"startLine,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
+}
+
+// TrivySecret is a secret detected in a file.
+type TrivySecret struct {
+	RuleID    string `json:"ruleId"`              // Detection rule (e.g. aws-access-key-id)
+	Category  string `json:"category,omitempty"`  // Rule category (e.g. AWS)
+	Severity  string `json:"severity"`            // CRITICAL, HIGH, MEDIUM, LOW or UNKNOWN
+	Title     string `json:"title,omitempty"`     // Rule title
+	StartLine int    `json:"startLine,omitempty"` // First line of the match
+	EndLine   int    `json:"endLine,omitempty"`   // Last line of the match
+	Match     string `json:"match,omitempty"`     // Matched line, with the secret masked by Trivy
+}
+
+// TrivyModifiedFinding is a finding suppressed by an ignore file or a VEX document.
+// Trivy lists these apart from the regular findings of a result when --show-suppressed is set.
+type TrivyModifiedFinding struct {
+	Type      string          `json:"type"`                // vulnerability, misconfiguration, secret or license
+	Status    string          `json:"status"`              // ignored, not_affected, fixed, ...
+	Statement string          `json:"statement,omitempty"` // Justification of the suppression
+	Source    string          `json:"source,omitempty"`    // Ignore file or VEX document suppressing the finding
+	Finding   json.RawMessage `json:"finding"`             // Suppressed finding, in the format of its type
+}
+
+// TrivyLicense is a license detected in a package or file.
+type TrivyLicense struct {
+	Severity   string  `json:"severity"`             // Severity derived from the classification
+	Category   string  `json:"category,omitempty"`   // forbidden, restricted, reciprocal, notice, permissive, unencumbered, unknown
+	PkgName    string  `json:"pkgName,omitempty"`    // Package declaring the license
+	FilePath   string  `json:"filePath,omitempty"`   // File containing the license
+	Name       string  `json:"name"`                 // License name (SPDX identifier when known)
+	Confidence float64 `json:"confidence,omitempty"` // Detection confidence
+	Link       string  `json:"link,omitempty"`       // License URL
+}
+
+// Summary counts the findings of all results. Vulnerabilities are always counted;
+// the misconfiguration, secret and license summaries are set for the given
+// scanners (misconfig, secret, license) and whenever the report contains such findings.
+func (r *TrivyReport) Summary(scanners []string) *VulnerabilitySummary {
+	summary := &VulnerabilitySummary{}
+	for _, scanner := range scanners {
+		switch scanner {
+		case "misconfig":
+			summary.Misconfigurations = &MisconfigurationSummary{}
+		case "secret":
+			summary.Secrets = &SecretSummary{Categories: map[string]int{}}
+		case "license":
+			summary.Licenses = &LicenseSummary{Categories: map[string]int{}}
+		}
+	}
+
+	for _, result := range r.Results {
+		for _, vuln := range result.Vulnerabilities {
+			summary.Add(vuln.Severity)
+		}
+		for _, modified := range result.ModifiedFindings {
+			if modified.Type == FindingKindVulnerability {
+				summary.Suppressed++
+			}
+		}
+
+		if result.MisconfSummary != nil || len(result.Misconfigurations) > 0 {
+			if summary.Misconfigurations == nil {
+				summary.Misconfigurations = &MisconfigurationSummary{}
+			}
+			misconf := summary.Misconfigurations
+			for _, check := range result.Misconfigurations {
+				if check.Status == "FAIL" || check.Status == "" {
+					misconf.addFailure(check.Severity)
+				}
+			}
+			if result.MisconfSummary != nil {
+				// Trivy's per-target counts include passed checks, which are not listed by default
+				misconf.Successes += result.MisconfSummary.Successes
+				misconf.Failures += result.MisconfSummary.Failures
+				misconf.Exceptions += result.MisconfSummary.Exceptions
+			} else {
+				for _, check := range result.Misconfigurations {
+					switch check.Status {
+					case "PASS":
+						misconf.Successes++
+					case "EXCEPTION":
+						misconf.Exceptions++
+					default:
+						misconf.Failures++
+					}
+				}
+			}
+		}
+
+		if len(result.Secrets) > 0 && summary.Secrets == nil {
+			summary.Secrets = &SecretSummary{Categories: map[string]int{}}
+		}
+		for _, secret := range result.Secrets {
+			summary.Secrets.Total++
+			summary.Secrets.Categories[categoryOrUnknown(secret.Category)]++
+		}
+
+		if len(result.Licenses) > 0 && summary.Licenses == nil {
+			summary.Licenses = &LicenseSummary{Categories: map[string]int{}}
+		}
+		for _, license := range result.Licenses {
+			summary.Licenses.Total++
+			summary.Licenses.Categories[categoryOrUnknown(license.Category)]++
+		}
+	}
+
+	return summary
+}
+
+// categoryOrUnknown returns the category, or "unknown" when it is empty.
+func categoryOrUnknown(category string) string {
+	if category == "" {
+		return "unknown"
+	}
+	return category
+}
+
+// Finding kinds served by the findings endpoint.
+const (
+	FindingKindVulnerability    = "vulnerability"
+	FindingKindMisconfiguration = "misconfiguration"
+	FindingKindSecret           = "secret"
+	FindingKindLicense          = "license"
+)
+
+// Finding is a single finding of a report together with the target it was found in.
+// Exactly one of the detail fields is set, according to the requested kind.
+type Finding struct {
+	Target           string                 `json:"target"`                     // Scanned target
+	Class            string                 `json:"class,omitempty"`            // Target class
+	Type             string                 `json:"type,omitempty"`             // Target type
+	Vulnerability    *TrivyVulnerability    `json:"vulnerability,omitempty"`    // Set for kind=vulnerability
+	Misconfiguration *TrivyMisconfiguration `json:"misconfiguration,omitempty"` // Set for kind=misconfiguration
+	Secret           *TrivySecret           `json:"secret,omitempty"`           // Set for kind=secret
+	License          *TrivyLicense          `json:"license,omitempty"`          // Set for kind=license
+	Suppressed       bool                   `json:"suppressed,omitempty"`       // Suppressed by an ignore rule (vulnerabilities only)
+	Justification    string                 `json:"justification,omitempty"`    // Justification of the suppression
+}
+
+// FindingListRequest represents query parameters for listing the findings of a task.
+type FindingListRequest struct {
+	Kind           string `form:"kind,default=vulnerability"` // Finding kind (vulnerability, misconfiguration, secret, license)
+	Severity       string `form:"severity"`                   // Comma-separated severities (optional)
+	Target         string `form:"target"`                     // Exact target name (optional)
+	Search         string `form:"q"`                          // Case-insensitive search in IDs, packages, titles and files (optional)
+	Status         string `form:"status"`                     // Vulnerability or misconfiguration status (optional)
+	FixAvailable   bool   `form:"fixAvailable"`               // Only vulnerabilities with a fixed version (optional)
+	HideSuppressed bool   `form:"hideSuppressed"`             // Leave out suppressed vulnerabilities (optional)
+	Page           int    `form:"page,default=1"`             // Page number (default: 1)
+	PageSize       int    `form:"pageSize,default=50"`        // Items per page (default: 50, max: 500)
+}
+
+// FindingListResponse represents a page of findings.
+type FindingListResponse struct {
+	TaskID       string    `json:"taskId"`       // Task the findings belong to
+	ArtifactName string    `json:"artifactName"` // Scanned artifact
+	Kind         string    `json:"kind"`         // Finding kind
+	Targets      []string  `json:"targets"`      // All targets of the report (for filtering)
+	Total        int       `json:"total"`        // Number of findings matching the filters
+	Page         int       `json:"page"`         // Current page number
+	PageSize     int       `json:"pageSize"`     // Items per page
+	Findings     []Finding `json:"findings"`     // Findings on this page, most severe first
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestTrivyReportSummary tests counting findings of all scanners
+func TestTrivyReportSummary(t *testing.T) {
+	output := `{"Results": [
+		{"Target": "debian", "Class": "os-pkgs", "Vulnerabilities": [
+			{"VulnerabilityID": "CVE-1", "Severity": "CRITICAL"},
+			{"VulnerabilityID": "CVE-2", "Severity": "LOW"}
+		]},
+		{"Target": "Dockerfile", "Class": "config",
+		 "MisconfSummary": {"Successes": 20, "Failures": 2, "Exceptions": 1},
+		 "Misconfigurations": [
+			{"ID": "DS002", "Severity": "HIGH", "Status": "FAIL"},
+			{"ID": "DS026", "Severity": "LOW", "Status": "FAIL"}
+		]},
+		{"Target": "/app/.env", "Class": "secret", "Secrets": [
+			{"RuleID": "aws-access-key-id", "Category": "AWS", "Severity": "CRITICAL"},
+			{"RuleID": "aws-secret-access-key", "Category": "AWS", "Severity": "CRITICAL"},
+			{"RuleID": "github-pat", "Category": "GitHub", "Severity": "CRITICAL"}
+		]},
+		{"Target": "OS Packages", "Class": "license", "Licenses": [
+			{"Name": "GPL-3.0", "Category": "restricted", "Severity": "HIGH"},
+			{"Name": "MIT", "Category": "notice", "Severity": "LOW"},
+			{"Name": "Custom", "Severity": "UNKNOWN"}
+		]}
+	]}`
+
+	var report TrivyReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+
+	summary := report.Summary([]string{"vuln", "misconfig", "secret", "license"})
+	if summary.Total != 2 || summary.Critical != 1 || summary.Low != 1 {
+		t.Errorf("Unexpected vulnerability counts: %+v", summary)
+	}
+
+	misconf := summary.Misconfigurations
+	if misconf == nil || misconf.Successes != 20 || misconf.Failures != 2 || misconf.Exceptions != 1 || misconf.High != 1 || misconf.Low != 1 {
+		t.Errorf("Unexpected misconfiguration summary: %+v", misconf)
+	}
+
+	secrets := summary.Secrets
+	if secrets == nil || secrets.Total != 3 || secrets.Categories["AWS"] != 2 || secrets.Categories["GitHub"] != 1 {
+		t.Errorf("Unexpected secret summary: %+v", secrets)
+	}
+
+	licenses := summary.Licenses
+	if licenses == nil || licenses.Total != 3 || licenses.Categories["restricted"] != 1 || licenses.Categories["unknown"] != 1 {
+		t.Errorf("Unexpected license summary: %+v", licenses)
+	}
+
+	if summary.Count(FindingKindMisconfiguration) != 2 || summary.Count(FindingKindSecret) != 3 || summary.Count(FindingKindLicense) != 3 {
+		t.Error("Unexpected finding counts")
+	}
+}
+
+// TestTrivyReportSummaryScanners tests which summaries are set for clean scans
+func TestTrivyReportSummaryScanners(t *testing.T) {
+	report := &TrivyReport{Results: []TrivyResult{{Target: "alpine", Class: "os-pkgs"}}}
+
+	// Only the vulnerability scanner: no other summaries
+	summary := report.Summary([]string{"vuln"})
+	if summary.Misconfigurations != nil || summary.Secrets != nil || summary.Licenses != nil {
+		t.Errorf("Expected only vulnerability counts, got %+v", summary)
+	}
+
+	// Enabled scanners without findings report zero counts
+	summary = report.Summary([]string{"vuln", "secret", "license"})
+	if summary.Secrets == nil || summary.Secrets.Total != 0 || summary.Licenses == nil || summary.Misconfigurations != nil {
+		t.Errorf("Expected empty secret and license summaries, got %+v", summary)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// Webhook payload formats.
+const (
+	WebhookFormatGeneric = "generic" // Signed JSON payload (WebhookPayload)
+	WebhookFormatSlack   = "slack"   // Slack incoming webhook message
+	WebhookFormatTeams   = "teams"   // Microsoft Teams incoming webhook message card
+)
+
+// Webhook events.
+const (
+	WebhookEventCompleted = "completed" // A scan completed without exceeding the threshold
+	WebhookEventFailed    = "failed"    // A scan failed
+	WebhookEventThreshold = "threshold" // A completed scan found vulnerabilities at or above the threshold
+	WebhookEventTest      = "test"      // Test delivery triggered by the user
+)
+
+// Webhook is an outbound notification endpoint called when scans of its owner finish.
+type Webhook struct {
+	ID         string             `json:"id"`                   // Unique webhook identifier (UUID)
+	UserID     string             `json:"userId"`               // Owner (for OIDC multi-tenancy)
+	Name       string             `json:"name,omitempty"`       // Display name (optional)
+	URL        string             `json:"url"`                  // Endpoint receiving the POST requests
+	Format     string             `json:"format"`               // Payload format (generic, slack, teams)
+	Secret     string             `json:"secret,omitempty"`     // HMAC-SHA256 signing key (never returned by the API)
+	HasSecret  bool               `json:"hasSecret"`            // Whether deliveries are signed
+	Events     []string           `json:"events"`               // Subscribed events (completed, failed, threshold)
+	Threshold  string             `json:"threshold,omitempty"`  // Minimum severity of the threshold event (CRITICAL, HIGH, MEDIUM, LOW)
+	Paused     bool               `json:"paused"`               // Paused webhooks are not called
+	CreatedAt  time.Time          `json:"createdAt"`            // Webhook creation timestamp
+	Deliveries []*WebhookDelivery `json:"deliveries,omitempty"` // Most recent deliveries, newest first
+}
+
+// Clone returns a copy of the webhook that shares no slices or pointers with the original.
+func (w *Webhook) Clone() *Webhook {
+	clone := *w
+	clone.Events = append([]string(nil), w.Events...)
+	clone.Deliveries = make([]*WebhookDelivery, len(w.Deliveries))
+	for i, delivery := range w.Deliveries {
+		d := *delivery
+		clone.Deliveries[i] = &d
+	}
+	return &clone
+}
+
+// Subscribes reports whether the webhook is subscribed to the given event.
+func (w *Webhook) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records one notification sent to a webhook, including its retries.
+type WebhookDelivery struct {
+	ID         string    `json:"id"`                   // Unique delivery identifier (sent in the X-Trivy-Webhook-Delivery header)
+	Event      string    `json:"event"`                // Delivered event
+	TaskID     string    `json:"taskId,omitempty"`     // Task the event is about (empty for test deliveries)
+	Attempts   int       `json:"attempts"`             // Number of requests sent
+	StatusCode int       `json:"statusCode,omitempty"` // HTTP status of the last attempt (0 if no response)
+	Success    bool      `json:"success"`              // Whether the endpoint accepted the delivery (2xx)
+	Error      string    `json:"error,omitempty"`      // Error of the last failed attempt
+	CreatedAt  time.Time `json:"createdAt"`            // Time of the first attempt
+	Duration   int64     `json:"duration"`             // Total delivery time including backoff, in milliseconds
+}
+
+// WebhookRequest represents the request body for creating a webhook.
+type WebhookRequest struct {
+	Name      string   `json:"name"`                   // Display name (optional)
+	URL       string   `json:"url" binding:"required"` // Endpoint URL (http or https, required)
+	Format    string   `json:"format"`                 // Payload format (default: generic)
+	Secret    string   `json:"secret"`                 // HMAC signing key (optional)
+	Events    []string `json:"events"`                 // Subscribed events (default: completed and failed)
+	Threshold string   `json:"threshold"`              // Minimum severity of the threshold event
+}
+
+// WebhookListResponse represents the response for listing webhooks.
+type WebhookListResponse struct {
+	Webhooks []*Webhook `json:"webhooks"` // Webhooks ordered by creation time (without deliveries)
+}
+
+// WebhookDeliveryListResponse represents the response for listing the deliveries of a webhook.
+type WebhookDeliveryListResponse struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"` // Most recent deliveries, newest first
+}
+
+// WebhookPayload is the body of generic webhook deliveries.
+type WebhookPayload struct {
+	Event         string                `json:"event"`                   // Delivered event
+	TaskID        string                `json:"taskId,omitempty"`        // Scan task ID
+	Image         string                `json:"image,omitempty"`         // Scanned image, repository or uploaded file
+	TargetType    TargetType            `json:"targetType,omitempty"`    // Kind of scan target
+	Status        ScanStatus            `json:"status,omitempty"`        // Final task status
+	Message       string                `json:"message,omitempty"`       // Status message
+	Error         string                `json:"error,omitempty"`         // Error output of failed scans
+	Summary       *VulnerabilitySummary `json:"summary,omitempty"`       // Findings summary (JSON format only)
+	Threshold     string                `json:"threshold,omitempty"`     // Threshold of the webhook (threshold event only)
+	PolicyVerdict *PolicyVerdict        `json:"policyVerdict,omitempty"` // Policy gate outcome (if a policy was evaluated)
+	ReportURL     string                `json:"reportUrl,omitempty"`     // Report download URL (requires the public URL setting)
+	StartTime     time.Time             `json:"startTime"`               // Scan start time
+	EndTime       *time.Time            `json:"endTime,omitempty"`       // Scan end time
+	Timestamp     time.Time             `json:"timestamp"`               // Delivery creation time
+}
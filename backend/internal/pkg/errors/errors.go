@@ -54,10 +54,17 @@ func Wrap(err error, code, message string, statusCode int) *AppError {
 
 // Predefined error instances for common error scenarios.
 var (
-	ErrTaskNotFound  = New("TASK_NOT_FOUND", "Task not found", http.StatusNotFound)
-	ErrInvalidInput  = New("INVALID_INPUT", "Invalid input parameters", http.StatusBadRequest)
-	ErrInternal      = New("INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
-	ErrCommandFailed = New("COMMAND_FAILED", "Command execution failed", http.StatusInternalServerError)
+	ErrTaskNotFound        = New("TASK_NOT_FOUND", "Task not found", http.StatusNotFound)
+	ErrScheduleNotFound    = New("SCHEDULE_NOT_FOUND", "Schedule not found", http.StatusNotFound)
+	ErrSuppressionNotFound = New("SUPPRESSION_NOT_FOUND", "Suppression rule not found", http.StatusNotFound)
+	ErrWebhookNotFound     = New("WEBHOOK_NOT_FOUND", "Webhook not found", http.StatusNotFound)
+	ErrDigestNotFound      = New("DIGEST_NOT_FOUND", "Digest subscription not found", http.StatusNotFound)
+	ErrTokenNotFound       = New("TOKEN_NOT_FOUND", "API token not found", http.StatusNotFound)
+	ErrSessionNotFound     = New("SESSION_NOT_FOUND", "Session not found", http.StatusNotFound)
+	ErrInvalidInput        = New("INVALID_INPUT", "Invalid input parameters", http.StatusBadRequest)
+	ErrForbidden           = New("FORBIDDEN", "Permission denied", http.StatusForbidden)
+	ErrInternal            = New("INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+	ErrCommandFailed       = New("COMMAND_FAILED", "Command execution failed", http.StatusInternalServerError)
 )
 
 // WrapTaskNotFound wraps an error as a task not found error (404).
@@ -70,6 +77,21 @@ func NewInvalidInput(message string) *AppError {
 	return New("INVALID_INPUT", message, http.StatusBadRequest)
 }
 
+// NewForbidden creates a new permission denied error (403) without wrapping.
+func NewForbidden(message string) *AppError {
+	return New("FORBIDDEN", message, http.StatusForbidden)
+}
+
+// NewPayloadTooLarge creates a new payload too large error (413) without wrapping.
+func NewPayloadTooLarge(message string) *AppError {
+	return New("PAYLOAD_TOO_LARGE", message, http.StatusRequestEntityTooLarge)
+}
+
+// NewQuotaExceeded creates a new storage quota exceeded error (507) without wrapping.
+func NewQuotaExceeded(message string) *AppError {
+	return New("QUOTA_EXCEEDED", message, http.StatusInsufficientStorage)
+}
+
 // WrapInvalidInput wraps an error as an invalid input error (400).
 func WrapInvalidInput(err error, message string) *AppError {
 	return Wrap(err, "INVALID_INPUT", message, http.StatusBadRequest)
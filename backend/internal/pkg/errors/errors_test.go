@@ -51,12 +51,24 @@ func TestPredefinedErrors(t *testing.T) {
 			expectedCode:   "TASK_NOT_FOUND",
 			expectedStatus: http.StatusNotFound,
 		},
+		{
+			name:           "ErrScheduleNotFound",
+			err:            ErrScheduleNotFound,
+			expectedCode:   "SCHEDULE_NOT_FOUND",
+			expectedStatus: http.StatusNotFound,
+		},
 		{
 			name:           "ErrInvalidInput",
 			err:            ErrInvalidInput,
 			expectedCode:   "INVALID_INPUT",
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "ErrForbidden",
+			err:            ErrForbidden,
+			expectedCode:   "FORBIDDEN",
+			expectedStatus: http.StatusForbidden,
+		},
 		{
 			name:           "ErrInternal",
 			err:            ErrInternal,
@@ -194,3 +206,39 @@ func TestNewInvalidInput(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, err.StatusCode)
 	}
 }
+
+func TestNewPayloadTooLarge(t *testing.T) {
+	message := "Upload exceeds maximum size"
+
+	err := NewPayloadTooLarge(message)
+
+	if err.Code != "PAYLOAD_TOO_LARGE" {
+		t.Errorf("Expected code PAYLOAD_TOO_LARGE, got %s", err.Code)
+	}
+
+	if err.Message != message {
+		t.Errorf("Expected message %s, got %s", message, err.Message)
+	}
+
+	if err.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status code %d, got %d", http.StatusRequestEntityTooLarge, err.StatusCode)
+	}
+}
+
+func TestNewQuotaExceeded(t *testing.T) {
+	message := "Storage quota exceeded"
+
+	err := NewQuotaExceeded(message)
+
+	if err.Code != "QUOTA_EXCEEDED" {
+		t.Errorf("Expected code QUOTA_EXCEEDED, got %s", err.Code)
+	}
+
+	if err.Message != message {
+		t.Errorf("Expected message %s, got %s", message, err.Message)
+	}
+
+	if err.StatusCode != http.StatusInsufficientStorage {
+		t.Errorf("Expected status code %d, got %d", http.StatusInsufficientStorage, err.StatusCode)
+	}
+}
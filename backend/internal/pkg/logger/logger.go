@@ -5,8 +5,10 @@
 package logger
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 // Logger defines the logging interface with three severity levels.
@@ -48,3 +50,65 @@ func (l *StandardLogger) Error(format string, args ...interface{}) {
 func (l *StandardLogger) Debug(format string, args ...interface{}) {
 	l.debugLogger.Printf(format, args...)
 }
+
+// FieldLogger is a Logger that can attach key-value fields to its messages.
+type FieldLogger interface {
+	Logger
+
+	// With returns a logger adding the key-value pairs to every message.
+	With(keyvals ...interface{}) Logger
+}
+
+// With returns a logger adding the key-value pairs to every message of l.
+// Loggers that do not implement FieldLogger (e.g. test mocks) get the fields
+// appended to the message text as key=value.
+func With(l Logger, keyvals ...interface{}) Logger {
+	if fl, ok := l.(FieldLogger); ok {
+		return fl.With(keyvals...)
+	}
+	return &fieldLogger{Logger: l, fields: formatFields(keyvals)}
+}
+
+// formatFields formats key-value pairs as " key=value key=value".
+func formatFields(keyvals []interface{}) string {
+	var fields strings.Builder
+	for i := 0; i < len(keyvals); i += 2 {
+		var value interface{} = "(missing)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fmt.Fprintf(&fields, " %v=%v", keyvals[i], value)
+	}
+	return fields.String()
+}
+
+// fieldLogger appends fields to the messages of a logger without field support.
+type fieldLogger struct {
+	Logger
+	fields string
+}
+
+// With returns a logger adding more fields after the existing ones.
+func (l *fieldLogger) With(keyvals ...interface{}) Logger {
+	return &fieldLogger{Logger: l.Logger, fields: l.fields + formatFields(keyvals)}
+}
+
+// Info logs an informational message with the fields.
+func (l *fieldLogger) Info(format string, args ...interface{}) {
+	l.Logger.Info(format+"%s", l.args(args)...)
+}
+
+// Error logs an error message with the fields.
+func (l *fieldLogger) Error(format string, args ...interface{}) {
+	l.Logger.Error(format+"%s", l.args(args)...)
+}
+
+// Debug logs a debug message with the fields.
+func (l *fieldLogger) Debug(format string, args ...interface{}) {
+	l.Logger.Debug(format+"%s", l.args(args)...)
+}
+
+// args returns the message arguments followed by the fields.
+func (l *fieldLogger) args(args []interface{}) []interface{} {
+	return append(append(make([]interface{}, 0, len(args)+1), args...), l.fields)
+}
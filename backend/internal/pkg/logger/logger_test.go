@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordingLogger records formatted messages (a Logger without field support).
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Info(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+func (l *recordingLogger) Error(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+func (l *recordingLogger) Debug(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func TestStructuredLogger_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewStructured(Options{Format: FormatJSON, Level: "info", Output: &buf})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	With(log, "request_id", "req-1").Info("Created scan task %s", "task-1")
+	log.Debug("Hidden below the info level")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected one log line, got %d: %s", len(lines), buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Expected a JSON log line, got %s", lines[0])
+	}
+	if entry["level"] != "INFO" || entry["msg"] != "Created scan task task-1" || entry["request_id"] != "req-1" {
+		t.Errorf("Unexpected log entry: %v", entry)
+	}
+}
+
+func TestStructuredLogger_Logfmt(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewStructured(Options{Format: FormatLogfmt, Level: "debug", Output: &buf})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	With(log, "task_id", "task-1").Debug("Executing trivy")
+
+	if !strings.Contains(buf.String(), `level=DEBUG msg="Executing trivy" task_id=task-1`) {
+		t.Errorf("Unexpected logfmt output: %s", buf.String())
+	}
+}
+
+func TestNewStructured_Invalid(t *testing.T) {
+	if _, err := NewStructured(Options{Format: "xml"}); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	if _, err := NewStructured(Options{Level: "verbose"}); err == nil {
+		t.Error("Expected error for unsupported level")
+	}
+}
+
+func TestWith_PlainLogger(t *testing.T) {
+	log := &recordingLogger{}
+
+	scoped := With(With(log, "request_id", "req-1"), "task_id", "task-1")
+	scoped.Info("Scan %d%% done", 50)
+
+	if len(log.messages) != 1 || log.messages[0] != "Scan 50% done request_id=req-1 task_id=task-1" {
+		t.Errorf("Unexpected messages: %v", log.messages)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package logger provides a structured, leveled logger with JSON or logfmt output.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Supported output formats of the structured logger.
+const (
+	FormatJSON   = "json"   // One JSON object per line
+	FormatLogfmt = "logfmt" // key=value pairs per line
+)
+
+// Options configures a StructuredLogger.
+type Options struct {
+	Format string    // Output format: json or logfmt (default: logfmt)
+	Level  string    // Minimum level: debug, info or error (default: info)
+	Output io.Writer // Destination of log lines (default: stdout)
+}
+
+// StructuredLogger implements FieldLogger on top of log/slog.
+// Messages are formatted printf-style; fields are attached with With.
+type StructuredLogger struct {
+	logger *slog.Logger
+}
+
+// NewStructured creates a structured logger with the given options.
+func NewStructured(opts Options) (*StructuredLogger, error) {
+	level, err := parseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	output := opts.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(output, handlerOpts)
+	case FormatLogfmt, "":
+		handler = slog.NewTextHandler(output, handlerOpts)
+	default:
+		return nil, fmt.Errorf("unsupported log format %q (expected json or logfmt)", opts.Format)
+	}
+
+	return &StructuredLogger{logger: slog.New(handler)}, nil
+}
+
+// parseLevel converts a level name into a slog level.
+func parseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unsupported log level %q (expected debug, info or error)", level)
+	}
+}
+
+// Info logs an informational message.
+func (l *StructuredLogger) Info(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, format, args)
+}
+
+// Error logs an error message.
+func (l *StructuredLogger) Error(format string, args ...interface{}) {
+	l.log(slog.LevelError, format, args)
+}
+
+// Debug logs a debug message.
+func (l *StructuredLogger) Debug(format string, args ...interface{}) {
+	l.log(slog.LevelDebug, format, args)
+}
+
+// With returns a logger adding the key-value pairs to every message.
+func (l *StructuredLogger) With(keyvals ...interface{}) Logger {
+	return &StructuredLogger{logger: l.logger.With(keyvals...)}
+}
+
+// log formats and writes a message if the level is enabled.
+func (l *StructuredLogger) log(level slog.Level, format string, args []interface{}) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	l.logger.Log(ctx, level, fmt.Sprintf(format, args...))
+}
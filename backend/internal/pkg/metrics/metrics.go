@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package metrics provides the Prometheus registry exposed by the /metrics endpoint.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds the metrics exposed by the /metrics endpoint: the application
+// metrics added with Register, and the Go runtime and process metrics.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Register adds collectors to the registry. A collector replaces any previously
+// registered collector of the same metrics, so that services created more than
+// once (e.g. in tests) can register their gauges again.
+func Register(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		Registry.Unregister(c)
+		Registry.MustRegister(c)
+	}
+}
+
+// Handler returns an HTTP handler serving the registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegister(t *testing.T) {
+	newQueueLength := func(value float64) prometheus.GaugeFunc {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "test_queue_length",
+			Help: "Queued scans.",
+		}, func() float64 { return value })
+	}
+
+	// Registering the same metric again replaces the previous collector
+	Register(newQueueLength(1))
+	Register(newQueueLength(2))
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, line := range []string{
+		"# TYPE test_queue_length gauge\n",
+		"test_queue_length 2\n",
+		"go_goroutines ",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+}
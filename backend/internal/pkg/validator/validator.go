@@ -5,19 +5,29 @@
 package validator
 
 import (
+	"bytes"
 	"fmt"
+	"net/url"
+	"path"
 	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
+
+	"github.com/open-policy-agent/opa/v1/ast"
 )
 
 const (
 	// Maximum input lengths to prevent DoS
-	MaxImageNameLength    = 512
-	MaxUsernameLength     = 256
-	MaxPasswordLength     = 512
-	MaxArchitectureLength = 64
-	MaxConfigNameLength   = 64
+	MaxImageNameLength     = 512
+	MaxUsernameLength      = 256
+	MaxPasswordLength      = 512
+	MaxArchitectureLength  = 64
+	MaxConfigNameLength    = 64
+	MaxRepositoryURLLength = 1024
+	MaxUploadNameLength    = 255
+	MaxArchivePathLength   = 4096
+	MaxRegoModuleSize      = 256 * 1024
 )
 
 // Image name validation regex patterns
@@ -36,8 +46,15 @@ var (
 	// Valid config name format: alphanumeric, dash, underscore, dot
 	// Examples: default, prod-env, my.config, dev_1
 	configNameRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+	// Valid uploaded file name format: alphanumeric, dash, underscore, dot, plus
+	// Examples: bom.cdx.json, rootfs.tar.gz, disk-1.vmdk
+	uploadNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._+-]*$`)
 )
 
+// Supported VM disk image extensions for trivy vm
+var vmImageExtensions = []string{".vmdk", ".img", ".raw"}
+
 // ValidationError represents an input validation error.
 type ValidationError struct {
 	Field   string
@@ -224,3 +241,236 @@ func ValidateConfigName(name string) error {
 
 	return nil
 }
+
+// ValidateRepositoryURL validates a remote git repository URL for trivy repo.
+// Only http(s) URLs without embedded credentials are accepted.
+func ValidateRepositoryURL(repoURL string) error {
+	if repoURL == "" {
+		return &ValidationError{
+			Field:   "repository",
+			Message: "repository URL cannot be empty",
+		}
+	}
+
+	if len(repoURL) > MaxRepositoryURLLength {
+		return &ValidationError{
+			Field:   "repository",
+			Message: fmt.Sprintf("repository URL exceeds maximum length of %d characters", MaxRepositoryURLLength),
+		}
+	}
+
+	// Reject whitespace and control characters
+	for _, r := range repoURL {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return &ValidationError{
+				Field:   "repository",
+				Message: "repository URL contains invalid character",
+			}
+		}
+	}
+
+	parsed, err := url.Parse(repoURL)
+	if err != nil || parsed.Host == "" {
+		return &ValidationError{
+			Field:   "repository",
+			Message: "repository URL format is invalid",
+		}
+	}
+
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return &ValidationError{
+			Field:   "repository",
+			Message: "repository URL must use http or https",
+		}
+	}
+
+	if parsed.User != nil {
+		return &ValidationError{
+			Field:   "repository",
+			Message: "repository URL must not contain credentials",
+		}
+	}
+
+	return nil
+}
+
+// ValidateUploadFilename validates the name of an uploaded scan target.
+// Only plain file names are accepted (no directories), which prevents path traversal.
+func ValidateUploadFilename(name string) error {
+	if name == "" {
+		return &ValidationError{
+			Field:   "file",
+			Message: "file name cannot be empty",
+		}
+	}
+
+	if len(name) > MaxUploadNameLength {
+		return &ValidationError{
+			Field:   "file",
+			Message: fmt.Sprintf("file name exceeds maximum length of %d characters", MaxUploadNameLength),
+		}
+	}
+
+	if strings.Contains(name, "/") || strings.Contains(name, "\\") || strings.Contains(name, "..") {
+		return &ValidationError{
+			Field:   "file",
+			Message: "file name cannot contain path separators or '..'",
+		}
+	}
+
+	if !uploadNameRegex.MatchString(name) {
+		return &ValidationError{
+			Field:   "file",
+			Message: "file name can only contain letters, numbers, dash, underscore, plus, and dot",
+		}
+	}
+
+	return nil
+}
+
+// ValidateVMImageName validates the file name of an uploaded VM disk image.
+func ValidateVMImageName(name string) error {
+	if err := ValidateUploadFilename(name); err != nil {
+		return err
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	for _, allowed := range vmImageExtensions {
+		if ext == allowed {
+			return nil
+		}
+	}
+
+	return &ValidationError{
+		Field:   "file",
+		Message: fmt.Sprintf("unsupported VM image type (expected one of: %s)", strings.Join(vmImageExtensions, ", ")),
+	}
+}
+
+// ValidateSBOM checks that data (the beginning of an uploaded file) looks like
+// an SBOM that trivy sbom can read: CycloneDX JSON, SPDX JSON or SPDX tag-value.
+func ValidateSBOM(data []byte) error {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return &ValidationError{
+			Field:   "file",
+			Message: "SBOM file is empty",
+		}
+	}
+
+	switch {
+	case trimmed[0] == '{':
+		if bytes.Contains(trimmed, []byte(`"bomFormat"`)) && bytes.Contains(trimmed, []byte(`"CycloneDX"`)) {
+			return nil
+		}
+		if bytes.Contains(trimmed, []byte(`"spdxVersion"`)) {
+			return nil
+		}
+	case bytes.HasPrefix(trimmed, []byte("SPDXVersion:")):
+		return nil
+	}
+
+	return &ValidationError{
+		Field:   "file",
+		Message: "file is not a CycloneDX JSON, SPDX JSON or SPDX tag-value SBOM",
+	}
+}
+
+// ValidateImageArchive checks that data (the beginning of an uploaded file) looks
+// like an image tarball that trivy image --input can read: a tar archive
+// (docker save or OCI layout), optionally gzip-compressed.
+func ValidateImageArchive(data []byte) error {
+	// gzip magic bytes
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		return nil
+	}
+
+	// POSIX, GNU and PAX tar headers carry the "ustar" magic at offset 257
+	if len(data) >= 262 && bytes.Equal(data[257:262], []byte("ustar")) {
+		return nil
+	}
+
+	return &ValidationError{
+		Field:   "file",
+		Message: "file is not a tar or tar.gz image archive",
+	}
+}
+
+// ValidateArchiveEntryPath validates the path of an entry in an uploaded tarball.
+// Absolute paths and entries escaping the extraction directory are rejected.
+func ValidateArchiveEntryPath(name string) error {
+	if name == "" {
+		return &ValidationError{
+			Field:   "archive",
+			Message: "archive entry has an empty name",
+		}
+	}
+
+	if len(name) > MaxArchivePathLength {
+		return &ValidationError{
+			Field:   "archive",
+			Message: fmt.Sprintf("archive entry path exceeds maximum length of %d characters", MaxArchivePathLength),
+		}
+	}
+
+	if strings.ContainsRune(name, 0) || strings.Contains(name, "\\") {
+		return &ValidationError{
+			Field:   "archive",
+			Message: fmt.Sprintf("archive entry %q contains invalid character", name),
+		}
+	}
+
+	cleaned := path.Clean(name)
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return &ValidationError{
+			Field:   "archive",
+			Message: fmt.Sprintf("archive entry %q escapes the extraction directory", name),
+		}
+	}
+
+	return nil
+}
+
+// ValidateRegoModule parses a Rego module (a custom Trivy check) and returns it.
+// Both Rego v1 and the older v0 syntax are accepted, like Trivy does. The package
+// must be outside Trivy's reserved "builtin" namespace. The module is compiled by
+// Trivy when it is used in a scan.
+func ValidateRegoModule(name string, data []byte) (*ast.Module, error) {
+	invalid := func(format string, args ...interface{}) (*ast.Module, error) {
+		return nil, &ValidationError{
+			Field:   "check",
+			Message: fmt.Sprintf("%s: %s", name, fmt.Sprintf(format, args...)),
+		}
+	}
+
+	if len(data) > MaxRegoModuleSize {
+		return invalid("module exceeds maximum size of %d bytes", MaxRegoModuleSize)
+	}
+	if !utf8.Valid(data) {
+		return invalid("module is not valid UTF-8 text")
+	}
+
+	module, err := ast.ParseModuleWithOpts(name, string(data), ast.ParserOptions{RegoVersion: ast.RegoV1})
+	if err != nil {
+		// Fall back to v0 syntax, but report the v1 error if neither parses
+		var errV0 error
+		if module, errV0 = ast.ParseModuleWithOpts(name, string(data), ast.ParserOptions{RegoVersion: ast.RegoV0}); errV0 != nil {
+			return invalid("%v", err)
+		}
+	}
+	if module == nil {
+		return invalid("module must start with a package declaration")
+	}
+
+	pkg := RegoPackage(module)
+	if pkg == "builtin" || strings.HasPrefix(pkg, "builtin.") {
+		return invalid("package %s is in the reserved builtin namespace", pkg)
+	}
+
+	return module, nil
+}
+
+// RegoPackage returns the package name of a parsed Rego module, e.g. "user.docker.no_root".
+func RegoPackage(module *ast.Module) string {
+	return strings.TrimPrefix(module.Package.Path.String(), ast.DefaultRootDocument.String()+".")
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package repository provides data access layer for email digest subscriptions.
+package repository
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// digestFileName is the per-user file the digest subscription is persisted to.
+const digestFileName = "digest.json"
+
+// DigestRepository defines the interface for digest subscription persistence.
+// Implementations return copies, so callers may modify returned subscriptions freely.
+type DigestRepository interface {
+	// Save creates or replaces the subscription of a user.
+	Save(subscription *models.DigestSubscription) error
+
+	// Get retrieves the subscription of a user.
+	// Returns nil if the user has no subscription.
+	Get(userID string) (*models.DigestSubscription, error)
+
+	// ListAll retrieves the subscriptions of all users, ordered by user.
+	ListAll() ([]*models.DigestSubscription, error)
+
+	// Delete removes the subscription of a user. Deleting a missing subscription is not an error.
+	Delete(userID string) error
+}
+
+// FileDigestRepository implements DigestRepository using one JSON file per user,
+// stored next to the user's saved configurations:
+//
+//	{configDir}/digest.json                  (shared, no user)
+//	{configDir}/users/{userID}/digest.json
+type FileDigestRepository struct {
+	store jsonUserStore[*models.DigestSubscription] // keyed by user ID
+	mu    sync.RWMutex
+}
+
+// NewFileDigestRepository creates a file-based digest repository and loads
+// all persisted subscriptions from configDir.
+func NewFileDigestRepository(configDir string) (*FileDigestRepository, error) {
+	userID := func(s *models.DigestSubscription) string { return s.UserID }
+	repo := &FileDigestRepository{
+		store: jsonUserStore[*models.DigestSubscription]{
+			baseDir:  configDir,
+			fileName: digestFileName,
+			name:     "digest",
+			id:       userID,
+			owner:    userID,
+			less:     func(a, b *models.DigestSubscription) bool { return a.UserID < b.UserID },
+			clone:    cloneSubscription,
+		},
+	}
+
+	if err := repo.store.load(); err != nil {
+		return nil, fmt.Errorf("failed to load digest subscriptions: %w", err)
+	}
+
+	return repo, nil
+}
+
+// cloneSubscription returns a copy of a subscription that shares no pointers with the original.
+func cloneSubscription(subscription *models.DigestSubscription) *models.DigestSubscription {
+	clone := *subscription
+	if subscription.LastSentAt != nil {
+		lastSentAt := *subscription.LastSentAt
+		clone.LastSentAt = &lastSentAt
+	}
+	if subscription.NextSendAt != nil {
+		nextSendAt := *subscription.NextSendAt
+		clone.NextSendAt = &nextSendAt
+	}
+	return &clone
+}
+
+// Save creates or replaces the subscription of a user.
+func (r *FileDigestRepository) Save(subscription *models.DigestSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.store.put(subscription)
+}
+
+// Get retrieves the subscription of a user.
+func (r *FileDigestRepository) Get(userID string) (*models.DigestSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, _ := r.store.get(userID)
+	return subscription, nil
+}
+
+// ListAll retrieves the subscriptions of all users.
+func (r *FileDigestRepository) ListAll() ([]*models.DigestSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.store.list(func(*models.DigestSubscription) bool { return true }), nil
+}
+
+// Delete removes the subscription of a user.
+func (r *FileDigestRepository) Delete(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.store.remove(func(s *models.DigestSubscription) bool { return s.UserID == userID })
+	return err
+}
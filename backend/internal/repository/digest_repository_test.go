@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// TestFileDigestRepository tests persisting digest subscriptions
+func TestFileDigestRepository(t *testing.T) {
+	configDir := t.TempDir()
+	repo, err := NewFileDigestRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	nextSendAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	for _, userID := range []string{"user@example.com", ""} {
+		subscription := &models.DigestSubscription{
+			UserID:     userID,
+			Email:      "security@example.com",
+			Frequency:  models.DigestFrequencyWeekly,
+			Enabled:    true,
+			NextSendAt: &nextSendAt,
+		}
+		if err := repo.Save(subscription); err != nil {
+			t.Fatalf("Failed to save subscription: %v", err)
+		}
+	}
+
+	// Returned subscriptions are copies
+	retrieved, _ := repo.Get("user@example.com")
+	retrieved.NextSendAt = nil
+	if again, _ := repo.Get("user@example.com"); again.NextSendAt == nil {
+		t.Error("Expected the cached subscription not to change")
+	}
+
+	// Subscriptions survive a restart
+	repo2, err := NewFileDigestRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to reload repository: %v", err)
+	}
+	all, _ := repo2.ListAll()
+	if len(all) != 2 || all[1].UserID != "user@example.com" || !all[1].NextSendAt.Equal(nextSendAt) {
+		t.Fatalf("Unexpected reloaded subscriptions: %+v", all)
+	}
+
+	if err := repo2.Delete("user@example.com"); err != nil {
+		t.Fatalf("Failed to delete subscription: %v", err)
+	}
+	if err := repo2.Delete("user@example.com"); err != nil {
+		t.Errorf("Expected deleting a missing subscription to succeed, got %v", err)
+	}
+	if subscription, _ := repo2.Get("user@example.com"); subscription != nil {
+		t.Errorf("Expected no subscription after deletion, got %+v", subscription)
+	}
+}
@@ -96,6 +96,7 @@ func (r *FileScanRepository) loadTaskFromFile(filePath string) (*models.ScanTask
 	if err := json.Unmarshal(data, &task); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal task: %w", err)
 	}
+	task.TaskLog = models.NewTaskLog()
 
 	return &task, nil
 }
@@ -147,7 +148,7 @@ func (r *FileScanRepository) Create(task *models.ScanTask) error {
 	}
 
 	// Update in-memory cache
-	r.tasks[task.ID] = task
+	r.tasks[task.ID] = task.Clone()
 	return nil
 }
 
@@ -161,7 +162,7 @@ func (r *FileScanRepository) GetByID(id string) (*models.ScanTask, error) {
 		return nil, nil // Task not found
 	}
 
-	return task, nil
+	return task.Clone(), nil
 }
 
 // List retrieves scan tasks with pagination and filtering.
@@ -169,11 +170,20 @@ func (r *FileScanRepository) List(userID string, filter *models.TaskListRequest)
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Filter tasks by user ID and status
+	from, to, err := filter.DateRange()
+	if err != nil {
+		return nil, 0, err
+	}
+	kinds, err := filter.FindingKinds()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Filter tasks by user ID, status, date range and findings
 	var filtered []*models.ScanTask
 	for _, task := range r.tasks {
 		// Filter by user ID
-		if task.UserID != userID {
+		if !filter.AllUsers && task.UserID != userID {
 			continue
 		}
 
@@ -182,6 +192,16 @@ func (r *FileScanRepository) List(userID string, filter *models.TaskListRequest)
 			continue
 		}
 
+		// Filter by start date range (optional)
+		if !task.MatchesDateRange(from, to) {
+			continue
+		}
+
+		// Filter by finding kinds (optional)
+		if !task.HasFindings(kinds) {
+			continue
+		}
+
 		filtered = append(filtered, task)
 	}
 
@@ -202,7 +222,7 @@ func (r *FileScanRepository) List(userID string, filter *models.TaskListRequest)
 		end = total
 	}
 
-	return filtered[start:end], total, nil
+	return cloneTasks(filtered[start:end]), total, nil
 }
 
 // Update updates an existing scan task.
@@ -220,7 +240,7 @@ func (r *FileScanRepository) Update(task *models.ScanTask) error {
 	}
 
 	// Update in-memory cache
-	r.tasks[task.ID] = task
+	r.tasks[task.ID] = task.Clone()
 	return nil
 }
 
@@ -252,7 +272,7 @@ func (r *FileScanRepository) GetQueuedTasks(userID string) ([]*models.ScanTask,
 	var queued []*models.ScanTask
 	for _, task := range r.tasks {
 		if task.UserID == userID && task.Status == models.ScanStatusQueued {
-			queued = append(queued, task)
+			queued = append(queued, task.Clone())
 		}
 	}
 
@@ -264,6 +284,22 @@ func (r *FileScanRepository) GetQueuedTasks(userID string) ([]*models.ScanTask,
 	return queued, nil
 }
 
+// GetAllQueuedTasks retrieves all tasks in queued status across all users.
+func (r *FileScanRepository) GetAllQueuedTasks() ([]*models.ScanTask, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var queued []*models.ScanTask
+	for _, task := range r.tasks {
+		if task.Status == models.ScanStatusQueued {
+			queued = append(queued, task.Clone())
+		}
+	}
+
+	sortQueuedTasks(queued)
+	return queued, nil
+}
+
 // GetRunningTask retrieves the currently running task for a user.
 func (r *FileScanRepository) GetRunningTask(userID string) (*models.ScanTask, error) {
 	r.mu.RLock()
@@ -271,7 +307,7 @@ func (r *FileScanRepository) GetRunningTask(userID string) (*models.ScanTask, er
 
 	for _, task := range r.tasks {
 		if task.UserID == userID && task.Status == models.ScanStatusRunning {
-			return task, nil
+			return task.Clone(), nil
 		}
 	}
 
@@ -286,7 +322,7 @@ func (r *FileScanRepository) GetAllRunningTasks() ([]*models.ScanTask, error) {
 	var runningTasks []*models.ScanTask
 	for _, task := range r.tasks {
 		if task.Status == models.ScanStatusRunning {
-			runningTasks = append(runningTasks, task)
+			runningTasks = append(runningTasks, task.Clone())
 		}
 	}
 
@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// jsonUserStore keeps records in memory and persists them as one JSON array per
+// user, stored next to the user's saved configurations:
+//
+//	{baseDir}/{fileName}                  (shared, no user)
+//	{baseDir}/users/{userID}/{fileName}
+//
+// Records are stored and returned as copies. The store is not safe for concurrent
+// use: repositories guard it with their own lock, which also covers any indexes
+// they keep next to it.
+type jsonUserStore[T any] struct {
+	baseDir  string
+	fileName string
+	name     string // Record name used in error messages, e.g. "schedule"
+
+	id    func(T) string    // Unique key of a record
+	owner func(T) string    // User whose file stores the record ("" for the shared file)
+	less  func(a, b T) bool // Order of listed and persisted records
+	clone func(T) T         // Copy sharing no pointers with the original
+
+	records map[string]T // record ID -> record
+}
+
+// load creates the base directory and reads the shared and all per-user files.
+func (s *jsonUserStore[T]) load() error {
+	if err := os.MkdirAll(s.baseDir, 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	s.records = make(map[string]T)
+	files, err := filepath.Glob(filepath.Join(s.baseDir, "users", "*", s.fileName))
+	if err != nil {
+		return err
+	}
+	files = append(files, filepath.Join(s.baseDir, s.fileName))
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		var records []T
+		if err := json.Unmarshal(data, &records); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		for _, record := range records {
+			s.records[s.id(record)] = record
+		}
+	}
+
+	return nil
+}
+
+// file returns the file path of a user.
+func (s *jsonUserStore[T]) file(userID string) string {
+	if userID == "" {
+		return filepath.Join(s.baseDir, s.fileName)
+	}
+	return filepath.Join(s.baseDir, "users", sanitizeUserIdentifier(userID), s.fileName)
+}
+
+// get returns a copy of a record.
+func (s *jsonUserStore[T]) get(id string) (T, bool) {
+	record, exists := s.records[id]
+	if !exists {
+		return record, false
+	}
+	return s.clone(record), true
+}
+
+// list returns copies of the records matching keep, in store order.
+func (s *jsonUserStore[T]) list(keep func(T) bool) []T {
+	records := make([]T, 0)
+	for _, record := range s.records {
+		if keep(record) {
+			records = append(records, s.clone(record))
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return s.less(records[i], records[j])
+	})
+
+	return records
+}
+
+// put creates or replaces a record and persists its owner's file.
+// The previous record (if any) is restored when the file cannot be written.
+func (s *jsonUserStore[T]) put(record T) error {
+	id := s.id(record)
+	previous, exists := s.records[id]
+
+	s.records[id] = s.clone(record)
+	if err := s.save(s.owner(record)); err != nil {
+		// Keep the records consistent with the files on disk
+		if exists {
+			s.records[id] = previous
+		} else {
+			delete(s.records, id)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// remove deletes the records matching a predicate, persists the files of their
+// owners and returns the removed records. Nothing is removed if a file cannot be written.
+func (s *jsonUserStore[T]) remove(match func(T) bool) ([]T, error) {
+	removed := make([]T, 0)
+	owners := make(map[string]bool)
+	for id, record := range s.records {
+		if match(record) {
+			removed = append(removed, record)
+			owners[s.owner(record)] = true
+			delete(s.records, id)
+		}
+	}
+
+	for owner := range owners {
+		if err := s.save(owner); err != nil {
+			for _, record := range removed {
+				s.records[s.id(record)] = record
+			}
+			// Rewrite files that were already saved without the records
+			for other := range owners {
+				if other != owner {
+					s.save(other)
+				}
+			}
+			return nil, err
+		}
+	}
+
+	return removed, nil
+}
+
+// save writes all records of a user to disk atomically, removing the file when
+// the user has none left.
+func (s *jsonUserStore[T]) save(userID string) error {
+	records := s.list(func(record T) bool { return s.owner(record) == userID })
+	path := s.file(userID)
+
+	if len(records) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s file: %w", s.name, err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", s.name, err)
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %ss: %w", s.name, err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s file: %w", s.name, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %s file: %w", s.name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package repository provides storage of scan report files.
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrReportNotFound is returned by a ReportStore when a report does not exist.
+var ErrReportNotFound = errors.New("report not found")
+
+// ErrPresignNotSupported is returned by stores that cannot issue download URLs.
+var ErrPresignNotSupported = errors.New("presigned downloads not supported")
+
+// ReportObject describes a stored report.
+type ReportObject struct {
+	Size    int64     // Size in bytes
+	ModTime time.Time // Last modification time
+}
+
+// ReportStore stores report files: original Trivy output, converted formats and parsed findings.
+// Keys are slash-separated paths relative to the store root (see ReportKey).
+// Implementations must be safe for concurrent use, and readers must never observe
+// a partially written report.
+type ReportStore interface {
+	// Put stores a report of the given size, replacing any existing report with the same key.
+	Put(key string, r io.Reader, size int64) error
+
+	// Open opens a report for streaming reads. The caller must close the reader.
+	// Returns ErrReportNotFound if the report does not exist.
+	Open(key string) (io.ReadCloser, *ReportObject, error)
+
+	// Stat returns the size and modification time of a report.
+	// Returns ErrReportNotFound if the report does not exist.
+	Stat(key string) (*ReportObject, error)
+
+	// Delete removes a report and returns its size (0 if it did not exist).
+	Delete(key string) (int64, error)
+
+	// Usage returns the total size in bytes of the reports whose keys start with prefix.
+	Usage(prefix string) (int64, error)
+
+	// PresignGet returns a time-limited URL the client can download the report from directly.
+	// The download is served with the given file name and content type.
+	// Returns ErrPresignNotSupported if the store is not reachable by clients.
+	PresignGet(key, filename, contentType string, expiry time.Duration) (string, error)
+}
+
+// ReportKey returns the key of a task's report file with the given extension:
+// users/{userID}/{taskID}.{ext}.
+func ReportKey(userID, taskID, ext string) string {
+	return UserReportPrefix(userID) + fmt.Sprintf("%s.%s", taskID, ext)
+}
+
+// UserReportPrefix returns the key prefix of all reports of a user.
+func UserReportPrefix(userID string) string {
+	return "users/" + userID + "/"
+}
+
+// LocalReportStore implements ReportStore on the local file system.
+// Keys map to files below the root directory ({reports-dir}/reports).
+type LocalReportStore struct {
+	rootDir string
+}
+
+// NewLocalReportStore creates a report store rooted at rootDir.
+func NewLocalReportStore(rootDir string) *LocalReportStore {
+	return &LocalReportStore{rootDir: rootDir}
+}
+
+// path returns the file path of a key, rejecting keys that escape the root directory.
+func (s *LocalReportStore) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || cleaned != "/"+key {
+		return "", fmt.Errorf("invalid report key: %s", key)
+	}
+	return filepath.Join(s.rootDir, filepath.FromSlash(key)), nil
+}
+
+// Put stores a report, writing it to a temporary file first.
+func (s *LocalReportStore) Put(key string, r io.Reader, size int64) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	// Write atomically so that concurrent readers never see a partial file
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), ".report-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := io.Copy(tmpFile, r); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+
+	return nil
+}
+
+// Open opens a report file for reading.
+func (s *LocalReportStore) Open(key string) (io.ReadCloser, *ReportObject, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrReportNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open report: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("failed to stat report: %w", err)
+	}
+
+	return f, &ReportObject{Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// Stat returns the size and modification time of a report file.
+func (s *LocalReportStore) Stat(key string) (*ReportObject, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to stat report: %w", err)
+	}
+
+	return &ReportObject{Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// Delete removes a report file.
+func (s *LocalReportStore) Delete(key string) (int64, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if err := os.Remove(filePath); err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+// Usage returns the total size of the report files below a key prefix.
+// The prefix must name a directory (end with "/").
+func (s *LocalReportStore) Usage(prefix string) (int64, error) {
+	dir := filepath.Join(s.rootDir, filepath.FromSlash(strings.TrimSuffix(prefix, "/")))
+
+	var total int64
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to compute report storage size: %w", err)
+	}
+
+	return total, nil
+}
+
+// PresignGet is not supported: local reports are served by the application.
+func (s *LocalReportStore) PresignGet(key, filename, contentType string, expiry time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is an in-memory stand-in for an S3-compatible server (path-style requests).
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte // bucket -> object name -> content
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{buckets: make(map[string]map[string][]byte)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") == "" && r.URL.Query().Get("X-Amz-Signature") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	bucket, object, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objects, bucketExists := f.buckets[bucket]
+
+	if object == "" {
+		switch {
+		case r.Method == http.MethodHead:
+			if !bucketExists {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case r.Method == http.MethodPut:
+			f.buckets[bucket] = make(map[string][]byte)
+		case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+			f.list(w, objects, r.URL.Query().Get("prefix"))
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+		return
+	}
+
+	data, objectExists := objects[object]
+	switch r.Method {
+	case http.MethodPut:
+		body, err := readS3Body(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		objects[object] = body
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodGet, http.MethodHead:
+		if !objectExists {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			}
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(objects, object)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// list writes a ListObjectsV2 response.
+func (f *fakeS3) list(w http.ResponseWriter, objects map[string][]byte, prefix string) {
+	type content struct {
+		Key  string
+		Size int
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		IsTruncated bool
+		Contents    []content
+	}{}
+	for name, data := range objects {
+		if strings.HasPrefix(name, prefix) {
+			result.Contents = append(result.Contents, content{Key: name, Size: len(data)})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+// readS3Body returns the payload of a PUT request, decoding aws-chunked
+// uploads (streaming signatures are used over plain HTTP).
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+		reader.ReadString('\n') // Chunk terminator
+	}
+}
+
+// testReportStores returns a local store and an S3 store backed by a fake server.
+func testReportStores(t *testing.T) map[string]ReportStore {
+	_, server := newFakeS3(t)
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+	s3Store, err := NewS3ReportStore(S3Options{
+		Endpoint:  endpoint,
+		Bucket:    "reports",
+		AccessKey: "access",
+		SecretKey: "secret",
+		Prefix:    "/trivy/",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create S3 report store: %v", err)
+	}
+
+	return map[string]ReportStore{
+		"local": NewLocalReportStore(t.TempDir()),
+		"s3":    s3Store,
+	}
+}
+
+func TestReportStore(t *testing.T) {
+	for name, store := range testReportStores(t) {
+		t.Run(name, func(t *testing.T) {
+			key := ReportKey("user1", "task-1", "json")
+			if key != "users/user1/task-1.json" {
+				t.Fatalf("Unexpected key %s", key)
+			}
+
+			if _, err := store.Stat(key); err != ErrReportNotFound {
+				t.Errorf("Expected ErrReportNotFound before Put, got %v", err)
+			}
+			if _, _, err := store.Open(key); err != ErrReportNotFound {
+				t.Errorf("Expected ErrReportNotFound on Open, got %v", err)
+			}
+
+			content := `{"Results":[]}`
+			if err := store.Put(key, strings.NewReader(content), int64(len(content))); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+			other := ReportKey("user1", "task-2", "txt")
+			if err := store.Put(other, strings.NewReader("table"), 5); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+			store.Put(ReportKey("user2", "task-3", "json"), strings.NewReader("{}"), 2)
+
+			rc, obj, err := store.Open(key)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			if string(data) != content || obj.Size != int64(len(content)) {
+				t.Errorf("Unexpected report %q (size %d)", data, obj.Size)
+			}
+
+			if usage, err := store.Usage(UserReportPrefix("user1")); err != nil || usage != int64(len(content)+5) {
+				t.Errorf("Expected usage %d, got %d (err: %v)", len(content)+5, usage, err)
+			}
+
+			size, err := store.Delete(key)
+			if err != nil || size != int64(len(content)) {
+				t.Errorf("Expected to delete %d bytes, got %d (err: %v)", len(content), size, err)
+			}
+			if size, err := store.Delete(key); err != nil || size != 0 {
+				t.Errorf("Deleting a missing report should be a no-op, got %d (err: %v)", size, err)
+			}
+		})
+	}
+}
+
+func TestReportStore_PresignGet(t *testing.T) {
+	stores := testReportStores(t)
+
+	if _, err := stores["local"].PresignGet("users/u/t.json", "r.json", "application/json", time.Minute); err != ErrPresignNotSupported {
+		t.Errorf("Expected ErrPresignNotSupported for local store, got %v", err)
+	}
+
+	s3Store := stores["s3"]
+	s3Store.Put("users/u/t.json", strings.NewReader("{}"), 2)
+	rawURL, err := s3Store.PresignGet("users/u/t.json", "report.json", "application/json", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignGet failed: %v", err)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("Invalid URL %s: %v", rawURL, err)
+	}
+	query := u.Query()
+	if u.Path != "/reports/trivy/users/u/t.json" || query.Get("X-Amz-Expires") != "60" ||
+		query.Get("response-content-disposition") != `attachment; filename=report.json` {
+		t.Errorf("Unexpected presigned URL: %s", rawURL)
+	}
+
+	// The URL downloads the report without further credentials
+	resp, err := http.Get(rawURL)
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); resp.StatusCode != http.StatusOK || string(body) != "{}" {
+		t.Errorf("Unexpected download: %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestLocalReportStore_InvalidKey(t *testing.T) {
+	store := NewLocalReportStore(t.TempDir())
+	for _, key := range []string{"../escape.json", "users/../../escape.json", "/abs.json", ""} {
+		if err := store.Put(key, strings.NewReader("x"), 1); err == nil {
+			t.Errorf("Expected error for key %q", key)
+		}
+	}
+}
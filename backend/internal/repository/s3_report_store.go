@@ -0,0 +1,213 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package repository provides storage of scan report files in S3-compatible object storage.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3RequestTimeout bounds S3 requests that do not transfer report content.
+const s3RequestTimeout = 30 * time.Second
+
+// S3Options configures an S3ReportStore.
+type S3Options struct {
+	Endpoint  string // Host and optional port of the S3 API (e.g., "s3.amazonaws.com", "minio:9000")
+	Bucket    string // Bucket storing the reports (created if missing)
+	Region    string // Bucket region (default: "us-east-1")
+	AccessKey string // Access key ID
+	SecretKey string // Secret access key
+	UseSSL    bool   // Connect over HTTPS
+	Prefix    string // Optional key prefix of all reports (e.g., "trivy/")
+}
+
+// S3ReportStore implements ReportStore on S3-compatible object storage (AWS S3, MinIO, ...).
+// Objects use the same keys as LocalReportStore below an optional prefix.
+type S3ReportStore struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3ReportStore connects to the object storage and makes sure the bucket exists.
+func NewS3ReportStore(opts S3Options) (*S3ReportStore, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return nil, fmt.Errorf("S3 endpoint and bucket are required")
+	}
+	region := opts.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure: opts.UseSSL,
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, opts.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket %s: %w", opts.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, opts.Bucket, minio.MakeBucketOptions{Region: region}); err != nil {
+			return nil, fmt.Errorf("failed to create bucket %s: %w", opts.Bucket, err)
+		}
+	}
+
+	prefix := strings.Trim(opts.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &S3ReportStore{
+		client: client,
+		bucket: opts.Bucket,
+		prefix: prefix,
+	}, nil
+}
+
+// objectName returns the object name of a key.
+func (s *S3ReportStore) objectName(key string) string {
+	return s.prefix + key
+}
+
+// isNotFound reports whether err is an S3 "no such key" error.
+func isNotFound(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == minio.NoSuchKey || code == "NotFound"
+}
+
+// Put uploads a report. S3 objects only become visible once fully uploaded.
+func (s *S3ReportStore) Put(key string, r io.Reader, size int64) error {
+	_, err := s.client.PutObject(context.Background(), s.bucket, s.objectName(key), r, size, minio.PutObjectOptions{
+		ContentType: reportContentType(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload report: %w", err)
+	}
+	return nil
+}
+
+// Open starts downloading a report.
+func (s *S3ReportStore) Open(key string) (io.ReadCloser, *ReportObject, error) {
+	obj, err := s.client.GetObject(context.Background(), s.bucket, s.objectName(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open report: %w", err)
+	}
+
+	// GetObject is lazy: Stat sends the request and reports missing objects
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		if isNotFound(err) {
+			return nil, nil, ErrReportNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open report: %w", err)
+	}
+
+	return obj, &ReportObject{Size: info.Size, ModTime: info.LastModified}, nil
+}
+
+// Stat returns the size and modification time of a report object.
+func (s *S3ReportStore) Stat(key string) (*ReportObject, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	info, err := s.client.StatObject(ctx, s.bucket, s.objectName(key), minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to stat report: %w", err)
+	}
+
+	return &ReportObject{Size: info.Size, ModTime: info.LastModified}, nil
+}
+
+// Delete removes a report object.
+func (s *S3ReportStore) Delete(key string) (int64, error) {
+	info, err := s.Stat(key)
+	if err == ErrReportNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	if err := s.client.RemoveObject(ctx, s.bucket, s.objectName(key), minio.RemoveObjectOptions{}); err != nil {
+		return 0, fmt.Errorf("failed to delete report: %w", err)
+	}
+
+	return info.Size, nil
+}
+
+// Usage returns the total size of the report objects below a key prefix.
+func (s *S3ReportStore) Usage(prefix string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	var total int64
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    s.objectName(prefix),
+		Recursive: true,
+	}) {
+		if obj.Err != nil {
+			return 0, fmt.Errorf("failed to list reports: %w", obj.Err)
+		}
+		total += obj.Size
+	}
+
+	return total, nil
+}
+
+// PresignGet returns a presigned GET URL of a report object.
+func (s *S3ReportStore) PresignGet(key, filename, contentType string, expiry time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	params := url.Values{}
+	params.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	if contentType != "" {
+		params.Set("response-content-type", contentType)
+	}
+
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, s.objectName(key), expiry, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign report download: %w", err)
+	}
+
+	return u.String(), nil
+}
+
+// reportContentType returns the content type stored with a report object.
+func reportContentType(key string) string {
+	switch {
+	case strings.HasSuffix(key, ".html"):
+		return "text/html"
+	case strings.HasSuffix(key, ".txt"):
+		return "text/plain"
+	default:
+		// JSON, SARIF, CycloneDX, SPDX and findings are JSON documents
+		return "application/json"
+	}
+}
@@ -21,6 +21,8 @@ import (
 )
 
 // ScanRepository defines the interface for scan task storage operations.
+// Implementations store and return copies (see models.ScanTask.Clone), so callers
+// may modify returned tasks freely; changes take effect on Update.
 type ScanRepository interface {
 	// Create adds a new scan task to the repository.
 	Create(task *models.ScanTask) error
@@ -30,7 +32,7 @@ type ScanRepository interface {
 	GetByID(id string) (*models.ScanTask, error)
 
 	// List retrieves scan tasks with pagination and filtering.
-	// Supports filtering by user ID, status, and sorting.
+	// Supports filtering by user ID (ignored when filter.AllUsers is set), status, and sorting.
 	List(userID string, filter *models.TaskListRequest) ([]*models.ScanTask, int, error)
 
 	// Update updates an existing scan task.
@@ -43,6 +45,10 @@ type ScanRepository interface {
 	// Returns tasks ordered by creation time (FIFO).
 	GetQueuedTasks(userID string) ([]*models.ScanTask, error)
 
+	// GetAllQueuedTasks retrieves all tasks in queued status across all users.
+	// Returns tasks ordered by creation time (FIFO).
+	GetAllQueuedTasks() ([]*models.ScanTask, error)
+
 	// GetRunningTask retrieves the currently running task for a user.
 	// Returns nil if no task is running.
 	GetRunningTask(userID string) (*models.ScanTask, error)
@@ -79,7 +85,7 @@ func (r *InMemoryScanRepository) Create(task *models.ScanTask) error {
 		return fmt.Errorf("task with ID %s already exists", task.ID)
 	}
 
-	r.tasks[task.ID] = task
+	r.tasks[task.ID] = task.Clone()
 	return nil
 }
 
@@ -93,7 +99,7 @@ func (r *InMemoryScanRepository) GetByID(id string) (*models.ScanTask, error) {
 		return nil, nil // Task not found
 	}
 
-	return task, nil
+	return task.Clone(), nil
 }
 
 // List retrieves scan tasks with pagination and filtering.
@@ -101,11 +107,20 @@ func (r *InMemoryScanRepository) List(userID string, filter *models.TaskListRequ
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Filter tasks by user ID and status
+	from, to, err := filter.DateRange()
+	if err != nil {
+		return nil, 0, err
+	}
+	kinds, err := filter.FindingKinds()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Filter tasks by user ID, status, date range and findings
 	var filtered []*models.ScanTask
 	for _, task := range r.tasks {
 		// Filter by user ID
-		if task.UserID != userID {
+		if !filter.AllUsers && task.UserID != userID {
 			continue
 		}
 
@@ -114,6 +129,16 @@ func (r *InMemoryScanRepository) List(userID string, filter *models.TaskListRequ
 			continue
 		}
 
+		// Filter by start date range (optional)
+		if !task.MatchesDateRange(from, to) {
+			continue
+		}
+
+		// Filter by finding kinds (optional)
+		if !task.HasFindings(kinds) {
+			continue
+		}
+
 		filtered = append(filtered, task)
 	}
 
@@ -134,7 +159,7 @@ func (r *InMemoryScanRepository) List(userID string, filter *models.TaskListRequ
 		end = total
 	}
 
-	return filtered[start:end], total, nil
+	return cloneTasks(filtered[start:end]), total, nil
 }
 
 // Update updates an existing scan task.
@@ -146,7 +171,7 @@ func (r *InMemoryScanRepository) Update(task *models.ScanTask) error {
 		return fmt.Errorf("task with ID %s does not exist", task.ID)
 	}
 
-	r.tasks[task.ID] = task
+	r.tasks[task.ID] = task.Clone()
 	return nil
 }
 
@@ -171,7 +196,7 @@ func (r *InMemoryScanRepository) GetQueuedTasks(userID string) ([]*models.ScanTa
 	var queued []*models.ScanTask
 	for _, task := range r.tasks {
 		if task.UserID == userID && task.Status == models.ScanStatusQueued {
-			queued = append(queued, task)
+			queued = append(queued, task.Clone())
 		}
 	}
 
@@ -183,6 +208,22 @@ func (r *InMemoryScanRepository) GetQueuedTasks(userID string) ([]*models.ScanTa
 	return queued, nil
 }
 
+// GetAllQueuedTasks retrieves all tasks in queued status across all users.
+func (r *InMemoryScanRepository) GetAllQueuedTasks() ([]*models.ScanTask, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var queued []*models.ScanTask
+	for _, task := range r.tasks {
+		if task.Status == models.ScanStatusQueued {
+			queued = append(queued, task.Clone())
+		}
+	}
+
+	sortQueuedTasks(queued)
+	return queued, nil
+}
+
 // GetRunningTask retrieves the currently running task for a user.
 func (r *InMemoryScanRepository) GetRunningTask(userID string) (*models.ScanTask, error) {
 	r.mu.RLock()
@@ -190,7 +231,7 @@ func (r *InMemoryScanRepository) GetRunningTask(userID string) (*models.ScanTask
 
 	for _, task := range r.tasks {
 		if task.UserID == userID && task.Status == models.ScanStatusRunning {
-			return task, nil
+			return task.Clone(), nil
 		}
 	}
 
@@ -205,7 +246,7 @@ func (r *InMemoryScanRepository) GetAllRunningTasks() ([]*models.ScanTask, error
 	var runningTasks []*models.ScanTask
 	for _, task := range r.tasks {
 		if task.Status == models.ScanStatusRunning {
-			runningTasks = append(runningTasks, task)
+			runningTasks = append(runningTasks, task.Clone())
 		}
 	}
 
@@ -219,8 +260,8 @@ func (r *InMemoryScanRepository) GetAllOldTasks(cutoffTime time.Time) ([]*models
 
 	var oldTasks []*models.ScanTask
 	for _, task := range r.tasks {
-		// Only consider completed or failed tasks
-		if task.Status != models.ScanStatusCompleted && task.Status != models.ScanStatusFailed {
+		// Only consider finished (completed, failed or cancelled) tasks
+		if !task.Status.IsFinished() {
 			continue
 		}
 
@@ -232,13 +273,33 @@ func (r *InMemoryScanRepository) GetAllOldTasks(cutoffTime time.Time) ([]*models
 		}
 
 		if taskTime.Before(cutoffTime) {
-			oldTasks = append(oldTasks, task)
+			oldTasks = append(oldTasks, task.Clone())
 		}
 	}
 
 	return oldTasks, nil
 }
 
+// cloneTasks returns copies of tasks, so callers never share instances with the repository.
+func cloneTasks(tasks []*models.ScanTask) []*models.ScanTask {
+	clones := make([]*models.ScanTask, len(tasks))
+	for i, task := range tasks {
+		clones[i] = task.Clone()
+	}
+	return clones
+}
+
+// sortQueuedTasks sorts queued tasks by creation time (FIFO).
+// Ties are broken by task ID so the order is stable across restarts.
+func sortQueuedTasks(tasks []*models.ScanTask) {
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].StartTime.Equal(tasks[j].StartTime) {
+			return tasks[i].ID < tasks[j].ID
+		}
+		return tasks[i].StartTime.Before(tasks[j].StartTime)
+	})
+}
+
 // sortTasks sorts tasks by the specified field and order.
 func sortTasks(tasks []*models.ScanTask, sortBy, sortOrder string) {
 	sort.Slice(tasks, func(i, j int) bool {
@@ -255,6 +316,14 @@ func sortTasks(tasks []*models.ScanTask, sortBy, sortOrder string) {
 			} else {
 				less = tasks[i].EndTime.Before(*tasks[j].EndTime)
 			}
+		case "vulnerabilities", "critical", "high", "misconfigurations", "secrets", "licenses":
+			// Finding counts; ties are ordered by start time
+			ci, cj := tasks[i].SummaryCount(sortBy), tasks[j].SummaryCount(sortBy)
+			if ci == cj {
+				less = tasks[i].StartTime.Before(tasks[j].StartTime)
+			} else {
+				less = ci < cj
+			}
 		default:
 			// Default to startTime
 			less = tasks[i].StartTime.Before(tasks[j].StartTime)
@@ -414,8 +483,7 @@ func (r *FileBasedScanRepository) loadTaskFromFile(metadataPath string) (*models
 	}
 
 	// Initialize transient fields (not persisted)
-	task.LogLines = []string{}
-	task.LogListeners = []chan string{}
+	task.TaskLog = models.NewTaskLog()
 
 	// Load result.json if exists
 	taskDir := filepath.Dir(metadataPath)
@@ -452,11 +520,17 @@ func (r *FileBasedScanRepository) saveTaskToFile(task *models.ScanTask) error {
 		StartTime:     task.StartTime,
 		EndTime:       task.EndTime,
 		ScanConfig:    task.ScanConfig,
+		ParentTaskID:  task.ParentTaskID,
+		ScheduleID:    task.ScheduleID,
+		RequestID:     task.RequestID,
 		QueuePosition: task.QueuePosition,
 		Result:        task.Result,
 		Output:        task.Output,
 		ErrorOutput:   task.ErrorOutput,
-		// Explicitly omit: LogLines, LogListeners, logMu
+		TrivyVersion:  task.TrivyVersion,
+		PolicyVerdict: task.PolicyVerdict,
+		Shares:        task.Shares,
+		// Explicitly omit: TaskLog
 	}
 
 	// Save metadata.json
@@ -501,7 +575,7 @@ func (r *FileBasedScanRepository) Create(task *models.ScanTask) error {
 	}
 
 	// Add to cache
-	r.cache[task.ID] = task
+	r.cache[task.ID] = task.Clone()
 	return nil
 }
 
@@ -515,7 +589,7 @@ func (r *FileBasedScanRepository) GetByID(id string) (*models.ScanTask, error) {
 		return nil, nil // Task not found
 	}
 
-	return task, nil
+	return task.Clone(), nil
 }
 
 // List retrieves scan tasks with pagination and filtering.
@@ -523,11 +597,20 @@ func (r *FileBasedScanRepository) List(userID string, filter *models.TaskListReq
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Filter tasks by user ID and status
+	from, to, err := filter.DateRange()
+	if err != nil {
+		return nil, 0, err
+	}
+	kinds, err := filter.FindingKinds()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Filter tasks by user ID, status, date range and findings
 	var filtered []*models.ScanTask
 	for _, task := range r.cache {
 		// Filter by user ID
-		if task.UserID != userID {
+		if !filter.AllUsers && task.UserID != userID {
 			continue
 		}
 
@@ -536,6 +619,16 @@ func (r *FileBasedScanRepository) List(userID string, filter *models.TaskListReq
 			continue
 		}
 
+		// Filter by start date range (optional)
+		if !task.MatchesDateRange(from, to) {
+			continue
+		}
+
+		// Filter by finding kinds (optional)
+		if !task.HasFindings(kinds) {
+			continue
+		}
+
 		filtered = append(filtered, task)
 	}
 
@@ -556,7 +649,7 @@ func (r *FileBasedScanRepository) List(userID string, filter *models.TaskListReq
 		end = total
 	}
 
-	return filtered[start:end], total, nil
+	return cloneTasks(filtered[start:end]), total, nil
 }
 
 // Update updates an existing scan task.
@@ -574,7 +667,7 @@ func (r *FileBasedScanRepository) Update(task *models.ScanTask) error {
 	}
 
 	// Update cache
-	r.cache[task.ID] = task
+	r.cache[task.ID] = task.Clone()
 	return nil
 }
 
@@ -607,7 +700,7 @@ func (r *FileBasedScanRepository) GetQueuedTasks(userID string) ([]*models.ScanT
 	var queued []*models.ScanTask
 	for _, task := range r.cache {
 		if task.UserID == userID && task.Status == models.ScanStatusQueued {
-			queued = append(queued, task)
+			queued = append(queued, task.Clone())
 		}
 	}
 
@@ -619,6 +712,22 @@ func (r *FileBasedScanRepository) GetQueuedTasks(userID string) ([]*models.ScanT
 	return queued, nil
 }
 
+// GetAllQueuedTasks retrieves all tasks in queued status across all users.
+func (r *FileBasedScanRepository) GetAllQueuedTasks() ([]*models.ScanTask, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var queued []*models.ScanTask
+	for _, task := range r.cache {
+		if task.Status == models.ScanStatusQueued {
+			queued = append(queued, task.Clone())
+		}
+	}
+
+	sortQueuedTasks(queued)
+	return queued, nil
+}
+
 // GetAllRunningTasks retrieves all tasks that are currently in running status.
 func (r *FileBasedScanRepository) GetAllRunningTasks() ([]*models.ScanTask, error) {
 	r.mu.RLock()
@@ -627,7 +736,7 @@ func (r *FileBasedScanRepository) GetAllRunningTasks() ([]*models.ScanTask, erro
 	var runningTasks []*models.ScanTask
 	for _, task := range r.cache {
 		if task.Status == models.ScanStatusRunning {
-			runningTasks = append(runningTasks, task)
+			runningTasks = append(runningTasks, task.Clone())
 		}
 	}
 
@@ -641,7 +750,7 @@ func (r *FileBasedScanRepository) GetRunningTask(userID string) (*models.ScanTas
 
 	for _, task := range r.cache {
 		if task.UserID == userID && task.Status == models.ScanStatusRunning {
-			return task, nil
+			return task.Clone(), nil
 		}
 	}
 
@@ -655,8 +764,8 @@ func (r *FileBasedScanRepository) GetAllOldTasks(cutoffTime time.Time) ([]*model
 
 	var oldTasks []*models.ScanTask
 	for _, task := range r.cache {
-		// Only consider completed or failed tasks
-		if task.Status != models.ScanStatusCompleted && task.Status != models.ScanStatusFailed {
+		// Only consider finished (completed, failed or cancelled) tasks
+		if !task.Status.IsFinished() {
 			continue
 		}
 
@@ -668,7 +777,7 @@ func (r *FileBasedScanRepository) GetAllOldTasks(cutoffTime time.Time) ([]*model
 		}
 
 		if taskTime.Before(cutoffTime) {
-			oldTasks = append(oldTasks, task)
+			oldTasks = append(oldTasks, task.Clone())
 		}
 	}
 
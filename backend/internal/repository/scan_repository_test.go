@@ -6,6 +6,7 @@ package repository
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -233,6 +234,20 @@ func TestList(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:   "List tasks of all users",
+			userID: "user-1",
+			req: &models.TaskListRequest{
+				Page:     1,
+				PageSize: 20,
+				AllUsers: true,
+			},
+			validate: func(t *testing.T, tasks []*models.ScanTask, total int) {
+				if total != 10 {
+					t.Errorf("Expected 10 total tasks of all users, got %d", total)
+				}
+			},
+		},
 		{
 			name:   "List with pagination",
 			userID: "user-1",
@@ -409,6 +424,81 @@ func TestList(t *testing.T) {
 	}
 }
 
+// TestListFindingSummaries tests filtering and sorting by finding counts
+func TestListFindingSummaries(t *testing.T) {
+	fileRepo, err := NewFileBasedScanRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	repos := map[string]ScanRepository{
+		"InMemory":  NewInMemoryScanRepository(),
+		"FileBased": fileRepo,
+	}
+
+	start := time.Now()
+	summaries := map[string]*models.VulnerabilitySummary{
+		"clean":   {},
+		"vulns":   {Total: 5, Critical: 2, High: 3},
+		"secrets": {Total: 1, Critical: 1, Secrets: &models.SecretSummary{Total: 2, Categories: map[string]int{"AWS": 2}}},
+		"misconf": {Total: 2, High: 2, Misconfigurations: &models.MisconfigurationSummary{Successes: 10, Failures: 3, High: 3},
+			Secrets: &models.SecretSummary{Total: 1, Categories: map[string]int{"GitHub": 1}}},
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			for i, id := range []string{"clean", "vulns", "secrets", "misconf"} {
+				task := models.NewScanTask(id, "user-1", "alpine:latest", &models.ScanConfig{Format: "json"})
+				task.Status = models.ScanStatusCompleted
+				task.StartTime = start.Add(time.Duration(i) * time.Second)
+				task.Result = &models.ScanResult{Format: "json", Summary: summaries[id]}
+				if err := repo.Create(task); err != nil {
+					t.Fatalf("Failed to create task: %v", err)
+				}
+			}
+			// Tasks without a result count as zero
+			repo.Create(models.NewScanTask("queued", "user-1", "alpine:latest", &models.ScanConfig{}))
+
+			tests := []struct {
+				name string
+				req  *models.TaskListRequest
+				want []string
+			}{
+				{"Has secrets", &models.TaskListRequest{HasFindings: "secret", SortBy: "startTime", SortOrder: "asc"}, []string{"secrets", "misconf"}},
+				{"Has secrets and misconfigurations", &models.TaskListRequest{HasFindings: "secret, misconfiguration"}, []string{"misconf"}},
+				{"Has vulnerabilities", &models.TaskListRequest{HasFindings: "vulnerability", SortBy: "vulnerabilities", SortOrder: "desc"}, []string{"vulns", "misconf", "secrets"}},
+				{"Sort by critical", &models.TaskListRequest{SortBy: "critical", SortOrder: "desc", PageSize: 2}, []string{"vulns", "secrets"}},
+				{"Sort by secrets", &models.TaskListRequest{SortBy: "secrets", SortOrder: "desc", PageSize: 2}, []string{"secrets", "misconf"}},
+				{"Sort by misconfigurations", &models.TaskListRequest{SortBy: "misconfigurations", SortOrder: "desc", PageSize: 1}, []string{"misconf"}},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					tt.req.Page = 1
+					if tt.req.PageSize == 0 {
+						tt.req.PageSize = 20
+					}
+					tasks, _, err := repo.List("user-1", tt.req)
+					if err != nil {
+						t.Fatalf("Failed to list tasks: %v", err)
+					}
+					var ids []string
+					for _, task := range tasks {
+						ids = append(ids, task.ID)
+					}
+					if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+						t.Errorf("Expected %v, got %v", tt.want, ids)
+					}
+				})
+			}
+
+			if _, _, err := repo.List("user-1", &models.TaskListRequest{Page: 1, PageSize: 20, HasFindings: "malware"}); err == nil {
+				t.Error("Expected error for an unsupported finding kind")
+			}
+		})
+	}
+}
+
 // TestGetQueuedTasks tests retrieving queued tasks
 func TestGetQueuedTasks(t *testing.T) {
 	repo := NewInMemoryScanRepository()
@@ -461,6 +551,46 @@ func TestGetQueuedTasks(t *testing.T) {
 	}
 }
 
+// TestGetAllQueuedTasks tests retrieving queued tasks across all users
+func TestGetAllQueuedTasks(t *testing.T) {
+	repo := NewInMemoryScanRepository()
+
+	tasks := []struct {
+		id     string
+		userID string
+		status models.ScanStatus
+	}{
+		{"task-1", "user-1", models.ScanStatusQueued},
+		{"task-2", "user-2", models.ScanStatusQueued},
+		{"task-3", "user-1", models.ScanStatusRunning},
+		{"task-4", "user-2", models.ScanStatusCompleted},
+		{"task-5", "user-1", models.ScanStatusQueued},
+	}
+
+	for _, tt := range tasks {
+		task := models.NewScanTask(tt.id, tt.userID, "alpine:latest", &models.ScanConfig{})
+		task.Status = tt.status
+		repo.Create(task)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	queued, err := repo.GetAllQueuedTasks()
+	if err != nil {
+		t.Fatalf("Failed to get queued tasks: %v", err)
+	}
+
+	expected := []string{"task-1", "task-2", "task-5"}
+	if len(queued) != len(expected) {
+		t.Fatalf("Expected %d queued tasks, got %d", len(expected), len(queued))
+	}
+
+	for i, id := range expected {
+		if queued[i].ID != id {
+			t.Errorf("Expected queued[%d] to be %s, got %s", i, id, queued[i].ID)
+		}
+	}
+}
+
 // TestConcurrentAccess tests concurrent access to repository
 func TestConcurrentAccess(t *testing.T) {
 	repo := NewInMemoryScanRepository()
@@ -492,8 +622,9 @@ func TestConcurrentAccess(t *testing.T) {
 	// Concurrent writes
 	for i := 0; i < 10; i++ {
 		go func(idx int) {
-			task.Message = "Update " + string(rune('0'+idx))
-			err := repo.Update(task)
+			update := task.Clone()
+			update.Message = "Update " + string(rune('0'+idx))
+			err := repo.Update(update)
 			if err != nil {
 				t.Errorf("Concurrent write failed: %v", err)
 			}
@@ -731,6 +862,8 @@ func TestFileBasedScanRepository_Persistence(t *testing.T) {
 
 	task1 := models.NewScanTask("persist-1", "user-1", "nginx:latest", &models.ScanConfig{})
 	task2 := models.NewScanTask("persist-2", "user-1", "redis:latest", &models.ScanConfig{})
+	task1.TrivyVersion = &models.TrivyVersion{Version: "0.58.0"}
+	task1.Shares = []models.TaskShare{{Group: "security", Permission: models.SharePermissionRead}}
 
 	repo1.Create(task1)
 	repo1.Create(task2)
@@ -757,6 +890,14 @@ func TestFileBasedScanRepository_Persistence(t *testing.T) {
 	if retrieved2 != nil && retrieved2.Image != "redis:latest" {
 		t.Errorf("Expected image redis:latest, got %s", retrieved2.Image)
 	}
+
+	if retrieved1 != nil && (retrieved1.TrivyVersion == nil || retrieved1.TrivyVersion.Version != "0.58.0") {
+		t.Errorf("Expected Trivy version 0.58.0 to be persisted, got %+v", retrieved1.TrivyVersion)
+	}
+
+	if retrieved1 != nil && (len(retrieved1.Shares) != 1 || retrieved1.Shares[0].Group != "security") {
+		t.Errorf("Expected task shares to be persisted, got %+v", retrieved1.Shares)
+	}
 }
 
 // TestFileBasedScanRepository_UserIsolation tests that users can only see their own tasks.
@@ -801,3 +942,34 @@ func TestFileBasedScanRepository_UserIsolation(t *testing.T) {
 		t.Errorf("User-2 should only see their own task")
 	}
 }
+
+// TestFileBasedScanRepository_QueuedTasksPersistence tests that queued tasks survive a restart.
+func TestFileBasedScanRepository_QueuedTasksPersistence(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	repo1, err := NewFileBasedScanRepository(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	queuedTask := models.NewScanTask("queued-1", "user-1", "nginx:latest", &models.ScanConfig{})
+	doneTask := models.NewScanTask("done-1", "user-2", "redis:latest", &models.ScanConfig{})
+	doneTask.Status = models.ScanStatusCompleted
+
+	repo1.Create(queuedTask)
+	repo1.Create(doneTask)
+
+	repo2, err := NewFileBasedScanRepository(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create second repository: %v", err)
+	}
+
+	queued, err := repo2.GetAllQueuedTasks()
+	if err != nil {
+		t.Fatalf("Failed to get queued tasks: %v", err)
+	}
+
+	if len(queued) != 1 || queued[0].ID != "queued-1" {
+		t.Errorf("Expected only queued-1 to be queued after reload, got %v", queued)
+	}
+}
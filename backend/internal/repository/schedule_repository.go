@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package repository provides data access layer for scan schedules.
+package repository
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// scheduleFileName is the per-user file schedules are persisted to.
+const scheduleFileName = "schedules.json"
+
+// ScheduleRepository defines the interface for scan schedule persistence.
+// Implementations return copies, so callers may modify returned schedules freely.
+type ScheduleRepository interface {
+	// Save creates or replaces a schedule.
+	Save(schedule *models.Schedule) error
+
+	// GetByID retrieves a schedule by its unique identifier.
+	// Returns nil if the schedule does not exist.
+	GetByID(id string) (*models.Schedule, error)
+
+	// List retrieves all schedules of a user, ordered by creation time.
+	List(userID string) ([]*models.Schedule, error)
+
+	// ListAll retrieves the schedules of all users, ordered by creation time.
+	ListAll() ([]*models.Schedule, error)
+
+	// Delete removes a schedule.
+	Delete(id string) error
+}
+
+// FileScheduleRepository implements ScheduleRepository using one JSON file per user,
+// stored next to the user's saved configurations:
+//
+//	{configDir}/schedules.json                  (shared, no user)
+//	{configDir}/users/{userID}/schedules.json
+type FileScheduleRepository struct {
+	store jsonUserStore[*models.Schedule]
+	mu    sync.RWMutex
+}
+
+// NewFileScheduleRepository creates a file-based schedule repository and loads
+// all persisted schedules from configDir.
+func NewFileScheduleRepository(configDir string) (*FileScheduleRepository, error) {
+	repo := &FileScheduleRepository{
+		store: jsonUserStore[*models.Schedule]{
+			baseDir:  configDir,
+			fileName: scheduleFileName,
+			name:     "schedule",
+			id:       func(s *models.Schedule) string { return s.ID },
+			owner:    func(s *models.Schedule) string { return s.UserID },
+			less: func(a, b *models.Schedule) bool {
+				if a.CreatedAt.Equal(b.CreatedAt) {
+					return a.ID < b.ID
+				}
+				return a.CreatedAt.Before(b.CreatedAt)
+			},
+			clone: (*models.Schedule).Clone,
+		},
+	}
+
+	if err := repo.store.load(); err != nil {
+		return nil, fmt.Errorf("failed to load schedules: %w", err)
+	}
+
+	return repo, nil
+}
+
+// Save creates or replaces a schedule.
+func (r *FileScheduleRepository) Save(schedule *models.Schedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if previous, exists := r.store.get(schedule.ID); exists && previous.UserID != schedule.UserID {
+		return fmt.Errorf("schedule %s belongs to another user", schedule.ID)
+	}
+
+	return r.store.put(schedule)
+}
+
+// GetByID retrieves a schedule by ID.
+func (r *FileScheduleRepository) GetByID(id string) (*models.Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedule, _ := r.store.get(id)
+	return schedule, nil
+}
+
+// List retrieves all schedules of a user.
+func (r *FileScheduleRepository) List(userID string) ([]*models.Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.store.list(func(s *models.Schedule) bool { return s.UserID == userID }), nil
+}
+
+// ListAll retrieves the schedules of all users.
+func (r *FileScheduleRepository) ListAll() ([]*models.Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.store.list(func(*models.Schedule) bool { return true }), nil
+}
+
+// Delete removes a schedule.
+func (r *FileScheduleRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.store.get(id); !exists {
+		return fmt.Errorf("schedule with ID %s not found", id)
+	}
+
+	_, err := r.store.remove(func(s *models.Schedule) bool { return s.ID == id })
+	return err
+}
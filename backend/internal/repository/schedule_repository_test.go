@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// newTestSchedule creates a schedule for testing.
+func newTestSchedule(id, userID string, createdAt time.Time) *models.Schedule {
+	nextRun := createdAt.Add(time.Hour)
+	return &models.Schedule{
+		ID:        id,
+		UserID:    userID,
+		Image:     "alpine:latest",
+		Cron:      "0 * * * *",
+		CreatedAt: createdAt,
+		NextRunAt: &nextRun,
+	}
+}
+
+// TestFileScheduleRepository_SaveAndGet tests saving and retrieving schedules
+func TestFileScheduleRepository_SaveAndGet(t *testing.T) {
+	repo, err := NewFileScheduleRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	schedule := newTestSchedule("schedule-1", "user@example.com", time.Now())
+	if err := repo.Save(schedule); err != nil {
+		t.Fatalf("Failed to save schedule: %v", err)
+	}
+
+	retrieved, err := repo.GetByID("schedule-1")
+	if err != nil || retrieved == nil {
+		t.Fatalf("Failed to get schedule: %v", err)
+	}
+	if retrieved.Image != schedule.Image || retrieved.Cron != schedule.Cron {
+		t.Errorf("Retrieved schedule does not match: %+v", retrieved)
+	}
+
+	// Returned schedules are copies
+	retrieved.Paused = true
+	*retrieved.NextRunAt = time.Time{}
+	again, _ := repo.GetByID("schedule-1")
+	if again.Paused || again.NextRunAt.IsZero() {
+		t.Error("Modifying a returned schedule should not change the repository")
+	}
+
+	// Missing schedule
+	missing, err := repo.GetByID("missing")
+	if err != nil || missing != nil {
+		t.Errorf("Expected nil schedule without error, got %v (%v)", missing, err)
+	}
+
+	// A schedule ID cannot move to another user
+	stolen := newTestSchedule("schedule-1", "other-user", time.Now())
+	if err := repo.Save(stolen); err == nil {
+		t.Error("Expected error when saving a schedule of another user")
+	}
+}
+
+// TestFileScheduleRepository_Persistence tests schedules survive a restart
+func TestFileScheduleRepository_Persistence(t *testing.T) {
+	configDir := t.TempDir()
+	now := time.Now()
+
+	repo1, err := NewFileScheduleRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	repo1.Save(newTestSchedule("schedule-1", "user-1", now))
+	repo1.Save(newTestSchedule("schedule-2", "user-1", now.Add(time.Second)))
+	repo1.Save(newTestSchedule("schedule-3", "user-2", now))
+	repo1.Save(newTestSchedule("schedule-4", "", now))
+
+	// Schedules are stored next to the user's saved configurations
+	for _, path := range []string{
+		filepath.Join(configDir, "users", "user-1", "schedules.json"),
+		filepath.Join(configDir, "users", "user-2", "schedules.json"),
+		filepath.Join(configDir, "schedules.json"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected schedule file %s: %v", path, err)
+		}
+	}
+
+	// Reload from disk
+	repo2, err := NewFileScheduleRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to reload repository: %v", err)
+	}
+
+	all, _ := repo2.ListAll()
+	if len(all) != 4 {
+		t.Fatalf("Expected 4 schedules after reload, got %d", len(all))
+	}
+
+	user1, _ := repo2.List("user-1")
+	if len(user1) != 2 || user1[0].ID != "schedule-1" || user1[1].ID != "schedule-2" {
+		t.Errorf("Expected user-1 schedules in creation order, got %v", user1)
+	}
+
+	user2, _ := repo2.List("user-2")
+	if len(user2) != 1 || user2[0].ID != "schedule-3" {
+		t.Errorf("Expected only user-2 schedules, got %v", user2)
+	}
+}
+
+// TestFileScheduleRepository_Delete tests deleting schedules
+func TestFileScheduleRepository_Delete(t *testing.T) {
+	configDir := t.TempDir()
+	repo, err := NewFileScheduleRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	repo.Save(newTestSchedule("schedule-1", "user-1", time.Now()))
+
+	if err := repo.Delete("schedule-1"); err != nil {
+		t.Fatalf("Failed to delete schedule: %v", err)
+	}
+	if schedule, _ := repo.GetByID("schedule-1"); schedule != nil {
+		t.Error("Schedule should be deleted")
+	}
+
+	// The user's schedule file is removed with the last schedule
+	if _, err := os.Stat(filepath.Join(configDir, "users", "user-1", "schedules.json")); !os.IsNotExist(err) {
+		t.Error("Expected schedule file to be removed")
+	}
+
+	if err := repo.Delete("schedule-1"); err == nil {
+		t.Error("Expected error when deleting a missing schedule")
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package repository provides data access layer for browser sessions.
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// sessionFileName is the file sessions are persisted to.
+const sessionFileName = "sessions.json"
+
+// SessionRepository defines the interface for session persistence.
+// Implementations return copies, so callers may modify returned sessions freely.
+type SessionRepository interface {
+	// Save creates or replaces a session.
+	Save(session *models.Session) error
+
+	// GetByID retrieves a session by its unique identifier.
+	// Returns nil if the session does not exist.
+	GetByID(id string) (*models.Session, error)
+
+	// GetByTokenHash retrieves a session by the hash of its cookie.
+	// Returns nil if no session has the hash.
+	GetByTokenHash(hash string) (*models.Session, error)
+
+	// List retrieves the sessions of a user, or of all users if userID is empty,
+	// ordered by login time.
+	List(userID string) ([]*models.Session, error)
+
+	// Delete removes a session.
+	Delete(id string) error
+
+	// DeleteByUser removes all sessions of a user and returns how many were removed.
+	DeleteByUser(userID string) (int, error)
+
+	// DeleteByOIDCSession removes the sessions of an OIDC subject, limited to one provider
+	// session if oidcSessionID is set, and returns how many were removed. Either may be
+	// empty, but not both.
+	DeleteByOIDCSession(subject, oidcSessionID string) (int, error)
+
+	// DeleteExpired removes sessions expired at the given time and returns how many were removed.
+	DeleteExpired(now time.Time) (int, error)
+}
+
+// FileSessionRepository implements SessionRepository using a single JSON file:
+//
+//	{configDir}/sessions.json
+//
+// All sessions are kept in memory and the file is rewritten on every change.
+type FileSessionRepository struct {
+	store  jsonUserStore[*models.Session] // all sessions are stored in the shared file
+	hashes map[string]string              // cookie hash -> session ID
+	mu     sync.RWMutex
+}
+
+// NewFileSessionRepository creates a file-based session repository and loads
+// the persisted sessions from configDir.
+func NewFileSessionRepository(configDir string) (*FileSessionRepository, error) {
+	repo := &FileSessionRepository{
+		store: jsonUserStore[*models.Session]{
+			baseDir:  configDir,
+			fileName: sessionFileName,
+			name:     "session",
+			id:       func(s *models.Session) string { return s.ID },
+			owner:    func(*models.Session) string { return "" },
+			less: func(a, b *models.Session) bool {
+				if a.CreatedAt.Equal(b.CreatedAt) {
+					return a.ID < b.ID
+				}
+				return a.CreatedAt.Before(b.CreatedAt)
+			},
+			clone: (*models.Session).Clone,
+		},
+		hashes: make(map[string]string),
+	}
+
+	if err := repo.store.load(); err != nil {
+		return nil, fmt.Errorf("failed to load sessions: %w", err)
+	}
+	for id, session := range repo.store.records {
+		repo.hashes[session.TokenHash] = id
+	}
+
+	return repo, nil
+}
+
+// deleteNoLock removes the sessions matching a predicate and returns how many were removed.
+// Must be called with the write lock held.
+func (r *FileSessionRepository) deleteNoLock(match func(*models.Session) bool) (int, error) {
+	removed, err := r.store.remove(match)
+	if err != nil {
+		return 0, err
+	}
+	for _, session := range removed {
+		delete(r.hashes, session.TokenHash)
+	}
+
+	return len(removed), nil
+}
+
+// Save creates or replaces a session.
+func (r *FileSessionRepository) Save(session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.store.get(session.ID)
+	if exists && (previous.UserID != session.UserID || previous.TokenHash != session.TokenHash) {
+		return fmt.Errorf("session %s cannot change its owner or cookie", session.ID)
+	}
+	if id, taken := r.hashes[session.TokenHash]; taken && id != session.ID {
+		return fmt.Errorf("session cookie hash is already in use")
+	}
+
+	if err := r.store.put(session); err != nil {
+		return err
+	}
+	r.hashes[session.TokenHash] = session.ID
+	return nil
+}
+
+// GetByID retrieves a session by ID.
+func (r *FileSessionRepository) GetByID(id string) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, _ := r.store.get(id)
+	return session, nil
+}
+
+// GetByTokenHash retrieves a session by the hash of its cookie.
+func (r *FileSessionRepository) GetByTokenHash(hash string) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, _ := r.store.get(r.hashes[hash])
+	return session, nil
+}
+
+// List retrieves the sessions of a user, or of all users if userID is empty.
+func (r *FileSessionRepository) List(userID string) ([]*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.store.list(func(session *models.Session) bool {
+		return userID == "" || session.UserID == userID
+	}), nil
+}
+
+// Delete removes a session.
+func (r *FileSessionRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.store.get(id); !exists {
+		return fmt.Errorf("session with ID %s not found", id)
+	}
+
+	_, err := r.deleteNoLock(func(session *models.Session) bool {
+		return session.ID == id
+	})
+	return err
+}
+
+// DeleteByUser removes all sessions of a user.
+func (r *FileSessionRepository) DeleteByUser(userID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deleteNoLock(func(session *models.Session) bool {
+		return session.UserID == userID
+	})
+}
+
+// DeleteByOIDCSession removes the sessions of an OIDC subject or provider session.
+func (r *FileSessionRepository) DeleteByOIDCSession(subject, oidcSessionID string) (int, error) {
+	if subject == "" && oidcSessionID == "" {
+		return 0, fmt.Errorf("subject or OIDC session ID is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deleteNoLock(func(session *models.Session) bool {
+		return (subject == "" || session.Subject == subject) &&
+			(oidcSessionID == "" || session.OIDCSessionID == oidcSessionID)
+	})
+}
+
+// DeleteExpired removes sessions expired at the given time.
+func (r *FileSessionRepository) DeleteExpired(now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deleteNoLock(func(session *models.Session) bool {
+		return session.IsExpired(now)
+	})
+}
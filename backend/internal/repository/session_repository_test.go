@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// TestFileSessionRepository tests persisting sessions and removing them by user and expiry
+func TestFileSessionRepository(t *testing.T) {
+	configDir := t.TempDir()
+	repo, err := NewFileSessionRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	now := time.Now()
+	sessions := []*models.Session{
+		{ID: "session-1", TokenHash: "hash-1", UserID: "alice", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "session-2", TokenHash: "hash-2", UserID: "alice", CreatedAt: now.Add(time.Second), ExpiresAt: now.Add(time.Hour)},
+		{ID: "session-3", TokenHash: "hash-3", UserID: "bob", CreatedAt: now, ExpiresAt: now.Add(-time.Minute)},
+	}
+	for _, session := range sessions {
+		if err := repo.Save(session); err != nil {
+			t.Fatalf("Failed to save session: %v", err)
+		}
+	}
+
+	found, _ := repo.GetByTokenHash("hash-2")
+	if found == nil || found.ID != "session-2" {
+		t.Fatalf("Expected to find the session by its hash, got %+v", found)
+	}
+	duplicate := found.Clone()
+	duplicate.ID = "session-4"
+	if err := repo.Save(duplicate); err == nil {
+		t.Error("Expected error when reusing a cookie hash")
+	}
+
+	// Sessions survive a restart
+	repo, err = NewFileSessionRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to reload repository: %v", err)
+	}
+	all, _ := repo.List("")
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 sessions after reload, got %d", len(all))
+	}
+	alice, _ := repo.List("alice")
+	if len(alice) != 2 || alice[0].ID != "session-1" {
+		t.Errorf("Expected alice's sessions ordered by login time, got %+v", alice)
+	}
+
+	if removed, err := repo.DeleteExpired(now); err != nil || removed != 1 {
+		t.Errorf("DeleteExpired() = %d, %v, want 1 removed", removed, err)
+	}
+	if removed, err := repo.DeleteByUser("alice"); err != nil || removed != 2 {
+		t.Errorf("DeleteByUser() = %d, %v, want 2 removed", removed, err)
+	}
+	if missing, _ := repo.GetByTokenHash("hash-1"); missing != nil {
+		t.Errorf("Expected deleted session not to be found, got %+v", missing)
+	}
+
+	repo, _ = NewFileSessionRepository(configDir)
+	if all, _ := repo.List(""); len(all) != 0 {
+		t.Errorf("Expected no sessions after deleting, got %d", len(all))
+	}
+}
@@ -0,0 +1,7 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+// Register the pure-Go SQLite driver (no cgo) as "sqlite".
+import _ "modernc.org/sqlite"
@@ -0,0 +1,611 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package repository provides data access layer for scan tasks stored in SQLite.
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// SQLiteDriverName is the database/sql driver used by SQLiteScanRepository.
+// It is registered by the pure-Go modernc.org/sqlite driver (see sqlite_driver.go).
+const SQLiteDriverName = "sqlite"
+
+// sqliteMigrations are the schema migrations of the scan database, applied in
+// order. PRAGMA user_version records how many have been applied.
+var sqliteMigrations = []string{
+	`CREATE TABLE scan_tasks (
+		id                TEXT PRIMARY KEY,
+		user_id           TEXT NOT NULL,
+		image             TEXT NOT NULL,
+		status            TEXT NOT NULL,
+		start_time        INTEGER NOT NULL,
+		end_time          INTEGER,
+		vulnerabilities   INTEGER NOT NULL DEFAULT 0,
+		critical          INTEGER NOT NULL DEFAULT 0,
+		high              INTEGER NOT NULL DEFAULT 0,
+		misconfigurations INTEGER NOT NULL DEFAULT 0,
+		secrets           INTEGER NOT NULL DEFAULT 0,
+		licenses          INTEGER NOT NULL DEFAULT 0,
+		metadata          TEXT NOT NULL,
+		output            TEXT NOT NULL DEFAULT '',
+		result_data       TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX idx_scan_tasks_user_start ON scan_tasks (user_id, start_time);
+	CREATE INDEX idx_scan_tasks_status_start ON scan_tasks (status, start_time);
+	CREATE INDEX idx_scan_tasks_image_start ON scan_tasks (image, start_time);
+	CREATE INDEX idx_scan_tasks_start ON scan_tasks (start_time);`,
+}
+
+// sqliteLightColumns are the columns needed to decode a task without its raw
+// Trivy output (used by list and bulk queries).
+const sqliteLightColumns = "id, metadata"
+
+// sqliteFullColumns additionally load the raw Trivy output of a task.
+const sqliteFullColumns = "id, metadata, output, result_data"
+
+// sqliteSortColumns maps TaskListRequest sort fields to count columns.
+var sqliteSortColumns = map[string]string{
+	"vulnerabilities":   "vulnerabilities",
+	"critical":          "critical",
+	"high":              "high",
+	"misconfigurations": "misconfigurations",
+	"secrets":           "secrets",
+	"licenses":          "licenses",
+}
+
+// sqliteFindingColumns maps finding kinds to count columns.
+var sqliteFindingColumns = map[string]string{
+	models.FindingKindVulnerability:    "vulnerabilities",
+	models.FindingKindMisconfiguration: "misconfigurations",
+	models.FindingKindSecret:           "secrets",
+	models.FindingKindLicense:          "licenses",
+}
+
+// SQLiteScanRepository implements ScanRepository with an embedded SQLite database.
+// Queries, filters, sorting and pagination run in SQL on indexed columns, so startup
+// and List do not depend on the size of the scan history.
+//
+// Unfinished (queued or running) tasks are kept in memory, and queries return copies
+// of those instances: the scan worker, cancellation and SSE log streams must share
+// one task log, and log lines are not persisted. Tasks leave memory when they finish.
+// List and bulk queries of other tasks omit the raw Trivy output
+// (Output and Result.Data); GetByID returns complete tasks.
+// Thread-safe for concurrent access.
+type SQLiteScanRepository struct {
+	db   *sql.DB
+	live map[string]*models.ScanTask // Tasks shared with the rest of the process
+	mu   sync.RWMutex                // Protects live
+}
+
+// NewSQLiteScanRepository opens (or creates) the scan database at path and
+// applies pending schema migrations.
+func NewSQLiteScanRepository(path string) (*SQLiteScanRepository, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := sql.Open(SQLiteDriverName, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// A single connection serializes writers (no SQLITE_BUSY) and keeps the
+	// per-connection pragmas below in effect
+	db.SetMaxOpenConns(1)
+
+	repo := &SQLiteScanRepository{
+		db:   db,
+		live: make(map[string]*models.ScanTask),
+	}
+	if err := repo.init(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// init configures the connection, migrates the schema and loads unfinished tasks.
+func (r *SQLiteScanRepository) init() error {
+	for _, pragma := range []string{
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = NORMAL",
+		"PRAGMA busy_timeout = 5000",
+	} {
+		if _, err := r.db.Exec(pragma); err != nil {
+			return fmt.Errorf("failed to configure database (%s): %w", pragma, err)
+		}
+	}
+
+	if err := r.migrate(); err != nil {
+		return err
+	}
+
+	// Unfinished tasks are always served from memory (see SQLiteScanRepository)
+	tasks, err := r.query("SELECT "+sqliteFullColumns+" FROM scan_tasks WHERE status IN (?, ?)",
+		true, models.ScanStatusQueued, models.ScanStatusRunning)
+	if err != nil {
+		return fmt.Errorf("failed to load unfinished tasks: %w", err)
+	}
+	for _, task := range tasks {
+		r.live[task.ID] = task
+	}
+
+	return nil
+}
+
+// migrate applies the schema migrations that have not been applied yet.
+func (r *SQLiteScanRepository) migrate() error {
+	var version int
+	if err := r.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version > len(sqliteMigrations) {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, len(sqliteMigrations))
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := r.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration: %w", err)
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not support bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the database.
+func (r *SQLiteScanRepository) Close() error {
+	return r.db.Close()
+}
+
+// sqliteRow holds the column values of a task row.
+type sqliteRow struct {
+	metadata   string
+	output     string
+	resultData string
+	args       []interface{} // Indexed columns, in sqliteRowColumns order
+}
+
+// sqliteRowColumns are the columns written for a task, in insert order.
+const sqliteRowColumns = "id, user_id, image, status, start_time, end_time, vulnerabilities, critical, high, " +
+	"misconfigurations, secrets, licenses, metadata, output, result_data"
+
+// encodeTask converts a task into its row values.
+// The raw Trivy output is stored in separate columns so that list queries do not load it.
+func encodeTask(task *models.ScanTask) (*sqliteRow, error) {
+	// Copy without transient fields (avoid copying mutex) and raw output
+	taskCopy := models.ScanTask{
+		ID:            task.ID,
+		UserID:        task.UserID,
+		Image:         task.Image,
+		Status:        task.Status,
+		Message:       task.Message,
+		StartTime:     task.StartTime,
+		EndTime:       task.EndTime,
+		ScanConfig:    task.ScanConfig,
+		ParentTaskID:  task.ParentTaskID,
+		ScheduleID:    task.ScheduleID,
+		RequestID:     task.RequestID,
+		QueuePosition: task.QueuePosition,
+		ErrorOutput:   task.ErrorOutput,
+		TrivyVersion:  task.TrivyVersion,
+		PolicyVerdict: task.PolicyVerdict,
+		Shares:        task.Shares,
+		// Explicitly omit: Output, Result.Data, TaskLog
+	}
+	var resultData string
+	if task.Result != nil {
+		result := *task.Result
+		resultData = result.Data
+		result.Data = ""
+		taskCopy.Result = &result
+	}
+
+	metadata, err := json.Marshal(&taskCopy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	var endTime interface{}
+	if task.EndTime != nil {
+		endTime = task.EndTime.UnixNano()
+	}
+
+	return &sqliteRow{
+		metadata:   string(metadata),
+		output:     task.Output,
+		resultData: resultData,
+		args: []interface{}{
+			task.ID, task.UserID, task.Image, string(task.Status), task.StartTime.UnixNano(), endTime,
+			task.SummaryCount("vulnerabilities"), task.SummaryCount("critical"), task.SummaryCount("high"),
+			task.SummaryCount("misconfigurations"), task.SummaryCount("secrets"), task.SummaryCount("licenses"),
+		},
+	}, nil
+}
+
+// values returns all column values in sqliteRowColumns order.
+func (row *sqliteRow) values() []interface{} {
+	return append(append([]interface{}(nil), row.args...), row.metadata, row.output, row.resultData)
+}
+
+// decodeTask builds a task from its metadata and (for full rows) raw output.
+func decodeTask(metadata string, output, resultData *string) (*models.ScanTask, error) {
+	var task models.ScanTask
+	if err := json.Unmarshal([]byte(metadata), &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task: %w", err)
+	}
+
+	// Initialize transient fields (not persisted)
+	task.TaskLog = models.NewTaskLog()
+
+	if output != nil {
+		task.Output = *output
+	}
+	if resultData != nil && task.Result != nil {
+		task.Result.Data = *resultData
+	}
+
+	return &task, nil
+}
+
+// query runs a task query and decodes the rows, preferring in-memory instances.
+// The query must select sqliteFullColumns when full is set, sqliteLightColumns otherwise.
+func (r *SQLiteScanRepository) query(query string, full bool, args ...interface{}) ([]*models.ScanTask, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []*models.ScanTask{}
+	for rows.Next() {
+		var id, metadata, output, resultData string
+		dest := []interface{}{&id, &metadata}
+		if full {
+			dest = append(dest, &output, &resultData)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to read task: %w", err)
+		}
+
+		if task, ok := r.live[id]; ok {
+			tasks = append(tasks, task.Clone())
+			continue
+		}
+
+		var task *models.ScanTask
+		if full {
+			task, err = decodeTask(metadata, &output, &resultData)
+		} else {
+			task, err = decodeTask(metadata, nil, nil)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", id, err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// Create adds a new scan task to the repository.
+func (r *SQLiteScanRepository) Create(task *models.ScanTask) error {
+	row, err := encodeTask(task)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(row.values())), ", ")
+	result, err := r.db.Exec("INSERT OR IGNORE INTO scan_tasks ("+sqliteRowColumns+") VALUES ("+placeholders+")", row.values()...)
+	if err != nil {
+		return fmt.Errorf("failed to insert task: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("task with ID %s already exists", task.ID)
+	}
+
+	r.trackNoLock(task)
+	return nil
+}
+
+// trackNoLock keeps a copy of an unfinished task in memory, and drops finished tasks.
+// Must be called with the write lock held.
+func (r *SQLiteScanRepository) trackNoLock(task *models.ScanTask) {
+	if task.Status.IsFinished() {
+		delete(r.live, task.ID)
+		return
+	}
+	r.live[task.ID] = task.Clone()
+}
+
+// GetByID retrieves a scan task by its unique identifier.
+func (r *SQLiteScanRepository) GetByID(id string) (*models.ScanTask, error) {
+	r.mu.RLock()
+	task, ok := r.live[id]
+	r.mu.RUnlock()
+	if ok {
+		return task.Clone(), nil
+	}
+
+	tasks, err := r.query("SELECT "+sqliteFullColumns+" FROM scan_tasks WHERE id = ?", true, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, nil // Task not found
+	}
+
+	return tasks[0], nil
+}
+
+// List retrieves scan tasks with pagination and filtering.
+func (r *SQLiteScanRepository) List(userID string, filter *models.TaskListRequest) ([]*models.ScanTask, int, error) {
+	from, to, err := filter.DateRange()
+	if err != nil {
+		return nil, 0, err
+	}
+	kinds, err := filter.FindingKinds()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Filter tasks by user ID, status, date range and findings
+	var where []string
+	var args []interface{}
+	if !filter.AllUsers {
+		where = append(where, "user_id = ?")
+		args = append(args, userID)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if !from.IsZero() {
+		where = append(where, "start_time >= ?")
+		args = append(args, from.UnixNano())
+	}
+	if !to.IsZero() {
+		where = append(where, "start_time <= ?")
+		args = append(args, to.UnixNano())
+	}
+	for _, kind := range kinds {
+		where = append(where, sqliteFindingColumns[kind]+" > 0")
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM scan_tasks"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	if offset >= total {
+		return []*models.ScanTask{}, total, nil
+	}
+
+	query := "SELECT " + sqliteLightColumns + " FROM scan_tasks" + whereClause +
+		" ORDER BY " + sqliteOrderBy(filter.SortBy, filter.SortOrder) + " LIMIT ? OFFSET ?"
+	tasks, err := r.query(query, false, append(args, filter.PageSize, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
+// sqliteOrderBy returns the ORDER BY clause of a list query (same order as sortTasks).
+func sqliteOrderBy(sortBy, sortOrder string) string {
+	dir := "ASC"
+	if sortOrder == "desc" {
+		dir = "DESC"
+	}
+
+	if sortBy == "endTime" {
+		// Unfinished tasks come after finished ones in ascending order
+		return fmt.Sprintf("end_time IS NULL %[1]s, end_time %[1]s, id %[1]s", dir)
+	}
+	if column, ok := sqliteSortColumns[sortBy]; ok {
+		// Finding counts; ties are ordered by start time
+		return fmt.Sprintf("%[1]s %[2]s, start_time %[2]s, id %[2]s", column, dir)
+	}
+	// Default to startTime
+	return fmt.Sprintf("start_time %[1]s, id %[1]s", dir)
+}
+
+// Update updates an existing scan task.
+func (r *SQLiteScanRepository) Update(task *models.ScanTask) error {
+	row, err := encodeTask(task)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	columns := strings.Split(sqliteRowColumns, ", ")[1:]
+	values := row.values()[1:]
+	result, err := r.db.Exec("UPDATE scan_tasks SET "+strings.Join(columns, " = ?, ")+" = ? WHERE id = ?",
+		append(values, task.ID)...)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("task with ID %s does not exist", task.ID)
+	}
+
+	r.trackNoLock(task)
+	return nil
+}
+
+// Delete removes a scan task from the repository.
+func (r *SQLiteScanRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, err := r.db.Exec("DELETE FROM scan_tasks WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("task with ID %s does not exist", id)
+	}
+
+	delete(r.live, id)
+	return nil
+}
+
+// GetQueuedTasks retrieves all tasks in queued status for a specific user.
+func (r *SQLiteScanRepository) GetQueuedTasks(userID string) ([]*models.ScanTask, error) {
+	return r.query("SELECT "+sqliteLightColumns+" FROM scan_tasks WHERE user_id = ? AND status = ? ORDER BY start_time, id",
+		false, userID, models.ScanStatusQueued)
+}
+
+// GetAllQueuedTasks retrieves all tasks in queued status across all users.
+func (r *SQLiteScanRepository) GetAllQueuedTasks() ([]*models.ScanTask, error) {
+	return r.query("SELECT "+sqliteLightColumns+" FROM scan_tasks WHERE status = ? ORDER BY start_time, id",
+		false, models.ScanStatusQueued)
+}
+
+// GetRunningTask retrieves the currently running task for a user.
+func (r *SQLiteScanRepository) GetRunningTask(userID string) (*models.ScanTask, error) {
+	tasks, err := r.query("SELECT "+sqliteLightColumns+" FROM scan_tasks WHERE user_id = ? AND status = ? ORDER BY start_time LIMIT 1",
+		false, userID, models.ScanStatusRunning)
+	if err != nil || len(tasks) == 0 {
+		return nil, err // No running task
+	}
+	return tasks[0], nil
+}
+
+// GetAllRunningTasks retrieves all tasks that are currently in running status.
+func (r *SQLiteScanRepository) GetAllRunningTasks() ([]*models.ScanTask, error) {
+	return r.query("SELECT "+sqliteLightColumns+" FROM scan_tasks WHERE status = ?", false, models.ScanStatusRunning)
+}
+
+// GetAllOldTasks retrieves all finished tasks that ended (or, without an end
+// time, started) before the cutoff time.
+func (r *SQLiteScanRepository) GetAllOldTasks(cutoffTime time.Time) ([]*models.ScanTask, error) {
+	return r.query("SELECT "+sqliteLightColumns+" FROM scan_tasks WHERE status IN (?, ?, ?) AND COALESCE(end_time, start_time) < ?",
+		false, models.ScanStatusCompleted, models.ScanStatusFailed, models.ScanStatusCancelled, cutoffTime.UnixNano())
+}
+
+// GetUserTaskCount returns the total number of tasks for a specific user.
+func (r *SQLiteScanRepository) GetUserTaskCount(userID string) (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM scan_tasks WHERE user_id = ?", userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	return count, nil
+}
+
+// GetUserStorageSize returns the total storage size (in bytes) used by a user's scans.
+func (r *SQLiteScanRepository) GetUserStorageSize(userID string) (int64, error) {
+	var size int64
+	err := r.db.QueryRow(`SELECT COALESCE(SUM(
+		LENGTH(CAST(metadata AS BLOB)) + LENGTH(CAST(output AS BLOB)) + LENGTH(CAST(result_data AS BLOB))
+	), 0) FROM scan_tasks WHERE user_id = ?`, userID).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute storage size: %w", err)
+	}
+	return size, nil
+}
+
+// ImportResult reports the outcome of importing tasks into the SQLite repository.
+type ImportResult struct {
+	Imported int // Tasks written to the database
+	Skipped  int // Tasks already present in the database
+}
+
+// ImportTasks inserts tasks in a single transaction, skipping tasks whose ID
+// already exists (so an interrupted import can be re-run).
+// Imported tasks are not kept in memory.
+func (r *SQLiteScanRepository) ImportTasks(tasks []*models.ScanTask) (*ImportResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(strings.Split(sqliteRowColumns, ", "))), ", ")
+	stmt, err := tx.Prepare("INSERT OR IGNORE INTO scan_tasks (" + sqliteRowColumns + ") VALUES (" + placeholders + ")")
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare import: %w", err)
+	}
+	defer stmt.Close()
+
+	result := &ImportResult{}
+	for _, task := range tasks {
+		row, err := encodeTask(task)
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", task.ID, err)
+		}
+		res, err := stmt.Exec(row.values()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import task %s: %w", task.ID, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			result.Skipped++
+		} else {
+			result.Imported++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return result, nil
+}
+
+// MigrateFileTasksToSQLite imports the scan history of the file-based repository
+// rooted at baseDir ({baseDir}/scans/...) into the SQLite repository.
+// The file layout is left untouched; re-running the migration skips tasks
+// that were already imported.
+func MigrateFileTasksToSQLite(baseDir string, dst *SQLiteScanRepository) (*ImportResult, error) {
+	src, err := NewFileBasedScanRepository(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file-based tasks: %w", err)
+	}
+
+	src.mu.RLock()
+	tasks := make([]*models.ScanTask, 0, len(src.cache))
+	for _, task := range src.cache {
+		tasks = append(tasks, task)
+	}
+	src.mu.RUnlock()
+
+	return dst.ImportTasks(tasks)
+}
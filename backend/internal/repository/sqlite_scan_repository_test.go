@@ -0,0 +1,297 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// newTestSQLiteRepository opens a repository in a temporary directory.
+func newTestSQLiteRepository(t *testing.T, path string) *SQLiteScanRepository {
+	t.Helper()
+	repo, err := NewSQLiteScanRepository(path)
+	if err != nil {
+		t.Fatalf("Failed to open SQLite repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+// completedTask returns a completed task with a JSON result.
+func completedTask(id, userID, image string, start time.Time, critical int) *models.ScanTask {
+	task := models.NewScanTask(id, userID, image, &models.ScanConfig{Format: "json"})
+	task.StartTime = start
+	end := start.Add(time.Minute)
+	task.EndTime = &end
+	task.Status = models.ScanStatusCompleted
+	task.Output = "output of " + id
+	task.Result = &models.ScanResult{
+		Format:  "json",
+		Data:    `{"Results":[]}`,
+		Summary: &models.VulnerabilitySummary{Total: critical, Critical: critical},
+	}
+	return task
+}
+
+func TestSQLiteScanRepository_CRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trivy.db")
+	repo := newTestSQLiteRepository(t, path)
+
+	task := completedTask("task-1", "user-1", "alpine:latest", time.Now(), 2)
+	if err := repo.Create(task); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if err := repo.Create(task); err == nil {
+		t.Error("Expected error when creating duplicate task")
+	}
+
+	// Tasks are copied: changes take effect on Update
+	retrieved, err := repo.GetByID("task-1")
+	if err != nil || retrieved == nil || retrieved == task {
+		t.Fatalf("Expected a copy of the created task, got %v (err: %v)", retrieved, err)
+	}
+	retrieved.Message = "not saved"
+	if current, _ := repo.GetByID("task-1"); current.Message == "not saved" {
+		t.Errorf("Expected an unchanged task, got %v", current)
+	}
+
+	task.Message = "updated"
+	task.Shares = []models.TaskShare{{Group: "security", Permission: models.SharePermissionManage}}
+	if err := repo.Update(task); err != nil {
+		t.Fatalf("Failed to update task: %v", err)
+	}
+	if err := repo.Update(models.NewScanTask("missing", "user-1", "alpine", nil)); err == nil {
+		t.Error("Expected error when updating missing task")
+	}
+
+	// Reopen: the task is loaded from the database with its raw output
+	repo.Close()
+	repo = newTestSQLiteRepository(t, path)
+	retrieved, err = repo.GetByID("task-1")
+	if err != nil || retrieved == nil {
+		t.Fatalf("Failed to retrieve task after reopen: %v", err)
+	}
+	if retrieved.Message != "updated" || retrieved.Output != "output of task-1" ||
+		retrieved.Result.Data != `{"Results":[]}` || retrieved.Result.Summary.Critical != 2 ||
+		len(retrieved.Shares) != 1 || retrieved.Shares[0].Group != "security" {
+		t.Errorf("Task not restored: %+v", retrieved)
+	}
+
+	if err := repo.Delete("task-1"); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+	if retrieved, _ := repo.GetByID("task-1"); retrieved != nil {
+		t.Error("Expected task to be deleted")
+	}
+	if err := repo.Delete("task-1"); err == nil {
+		t.Error("Expected error when deleting missing task")
+	}
+}
+
+func TestSQLiteScanRepository_LiveTasks(t *testing.T) {
+	repo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "trivy.db"))
+
+	task := models.NewScanTask("task-1", "user-1", "alpine:latest", &models.ScanConfig{})
+	if err := repo.Create(task); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	// Unfinished tasks share their log with the rest of the process
+	retrieved, _ := repo.GetByID("task-1")
+	retrieved.AddLog("shared line")
+	if current, _ := repo.GetByID("task-1"); len(current.GetLogLines()) != 1 {
+		t.Errorf("Expected the queued task to share its log, got %v", current.GetLogLines())
+	}
+
+	task.Status = models.ScanStatusRunning
+	if err := repo.Update(task); err != nil {
+		t.Fatalf("Failed to update task: %v", err)
+	}
+	if _, ok := repo.live["task-1"]; !ok {
+		t.Error("Expected the running task to be kept in memory")
+	}
+
+	// Finished tasks are served from the database only
+	for _, status := range []models.ScanStatus{models.ScanStatusCompleted, models.ScanStatusFailed, models.ScanStatusCancelled} {
+		task.Status = status
+		if err := repo.Update(task); err != nil {
+			t.Fatalf("Failed to update task: %v", err)
+		}
+		if _, ok := repo.live["task-1"]; ok {
+			t.Errorf("Expected the %s task to be dropped from memory", status)
+		}
+		if current, _ := repo.GetByID("task-1"); current == nil || current.Status != status {
+			t.Errorf("Expected the %s task to be loaded from the database, got %v", status, current)
+		}
+	}
+
+	if err := repo.Create(completedTask("task-2", "user-1", "alpine:latest", time.Now(), 0)); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if _, ok := repo.live["task-2"]; ok {
+		t.Error("Expected a task created finished not to be kept in memory")
+	}
+}
+
+func TestSQLiteScanRepository_List(t *testing.T) {
+	repo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "trivy.db"))
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, critical := range []int{3, 0, 5, 1} {
+		task := completedTask("task-"+string(rune('a'+i)), "user-1", "alpine:latest", base.Add(time.Duration(i)*time.Hour), critical)
+		if err := repo.Create(task); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+	running := models.NewScanTask("task-running", "user-1", "nginx:latest", nil)
+	running.StartTime = base.Add(10 * time.Hour)
+	running.Status = models.ScanStatusRunning
+	repo.Create(running)
+	repo.Create(completedTask("task-other", "user-2", "alpine:latest", base, 9))
+
+	ids := func(tasks []*models.ScanTask) []string {
+		var result []string
+		for _, task := range tasks {
+			result = append(result, task.ID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		req       *models.TaskListRequest
+		wantIDs   []string
+		wantTotal int
+	}{
+		{
+			name:      "Default sort by start time with pagination",
+			req:       &models.TaskListRequest{Page: 1, PageSize: 2},
+			wantIDs:   []string{"task-a", "task-b"},
+			wantTotal: 5,
+		},
+		{
+			name:      "Status filter, newest first",
+			req:       &models.TaskListRequest{Page: 1, PageSize: 10, Status: "completed", SortOrder: "desc"},
+			wantIDs:   []string{"task-d", "task-c", "task-b", "task-a"},
+			wantTotal: 4,
+		},
+		{
+			name:      "Sort by critical count",
+			req:       &models.TaskListRequest{Page: 1, PageSize: 3, SortBy: "critical", SortOrder: "desc"},
+			wantIDs:   []string{"task-c", "task-a", "task-d"},
+			wantTotal: 5,
+		},
+		{
+			name:      "Unfinished tasks sort last by end time",
+			req:       &models.TaskListRequest{Page: 2, PageSize: 4, SortBy: "endTime"},
+			wantIDs:   []string{"task-running"},
+			wantTotal: 5,
+		},
+		{
+			name:      "Only tasks with vulnerabilities",
+			req:       &models.TaskListRequest{Page: 1, PageSize: 10, HasFindings: "vulnerability"},
+			wantIDs:   []string{"task-a", "task-c", "task-d"},
+			wantTotal: 3,
+		},
+		{
+			name:      "Page beyond the last one",
+			req:       &models.TaskListRequest{Page: 3, PageSize: 10},
+			wantIDs:   nil,
+			wantTotal: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, total, err := repo.List("user-1", tt.req)
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("Expected total %d, got %d", tt.wantTotal, total)
+			}
+			got := ids(tasks)
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("Expected %v, got %v", tt.wantIDs, got)
+			}
+			for i := range got {
+				if got[i] != tt.wantIDs[i] {
+					t.Fatalf("Expected %v, got %v", tt.wantIDs, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteScanRepository_QueueQueries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trivy.db")
+	repo := newTestSQLiteRepository(t, path)
+
+	base := time.Now().Add(-time.Hour)
+	for i, id := range []string{"queued-2", "queued-1"} {
+		task := models.NewScanTask(id, "user-1", "alpine", nil)
+		task.StartTime = base.Add(time.Duration(1-i) * time.Minute)
+		repo.Create(task)
+	}
+	running := models.NewScanTask("running", "user-1", "alpine", nil)
+	running.Status = models.ScanStatusRunning
+	repo.Create(running)
+	repo.Create(completedTask("old", "user-1", "alpine", base.Add(-48*time.Hour), 0))
+
+	// Unfinished tasks are restored after a restart
+	repo.Close()
+	repo = newTestSQLiteRepository(t, path)
+
+	queued, err := repo.GetAllQueuedTasks()
+	if err != nil || len(queued) != 2 || queued[0].ID != "queued-1" {
+		t.Errorf("Expected queued tasks in FIFO order, got %v (err: %v)", queued, err)
+	}
+	if task, _ := repo.GetRunningTask("user-1"); task == nil || task.ID != "running" {
+		t.Errorf("Expected running task, got %v", task)
+	}
+	if task, _ := repo.GetByID("running"); task == nil || task.LogLines == nil {
+		t.Error("Expected restored task to have initialized log lines")
+	}
+	old, err := repo.GetAllOldTasks(time.Now().Add(-24 * time.Hour))
+	if err != nil || len(old) != 1 || old[0].ID != "old" {
+		t.Errorf("Expected one old task, got %v (err: %v)", old, err)
+	}
+	if count, _ := repo.GetUserTaskCount("user-1"); count != 4 {
+		t.Errorf("Expected 4 tasks for user-1, got %d", count)
+	}
+}
+
+func TestMigrateFileTasksToSQLite(t *testing.T) {
+	baseDir := t.TempDir()
+	fileRepo, err := NewFileBasedScanRepository(baseDir)
+	if err != nil {
+		t.Fatalf("Failed to create file repository: %v", err)
+	}
+	fileRepo.Create(completedTask("task-1", "user-1", "alpine", time.Now(), 1))
+	fileRepo.Create(completedTask("task-2", "", "nginx", time.Now(), 0))
+
+	repo := newTestSQLiteRepository(t, filepath.Join(t.TempDir(), "trivy.db"))
+	result, err := MigrateFileTasksToSQLite(baseDir, repo)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	if result.Imported != 2 || result.Skipped != 0 {
+		t.Errorf("Expected 2 imported tasks, got %+v", result)
+	}
+
+	// Re-running skips tasks that were already imported
+	result, err = MigrateFileTasksToSQLite(baseDir, repo)
+	if err != nil || result.Imported != 0 || result.Skipped != 2 {
+		t.Errorf("Expected 2 skipped tasks, got %+v (err: %v)", result, err)
+	}
+
+	task, err := repo.GetByID("task-1")
+	if err != nil || task == nil || task.Output != "output of task-1" || task.Result.Summary.Total != 1 {
+		t.Errorf("Task not migrated: %+v (err: %v)", task, err)
+	}
+}
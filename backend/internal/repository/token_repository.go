@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package repository provides data access layer for personal API tokens.
+package repository
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// tokenFileName is the per-user file API tokens are persisted to.
+const tokenFileName = "api_tokens.json"
+
+// TokenRepository defines the interface for API token persistence.
+// Implementations return copies, so callers may modify returned tokens freely.
+type TokenRepository interface {
+	// Save creates or replaces a token.
+	Save(token *models.APIToken) error
+
+	// GetByID retrieves a token by its unique identifier.
+	// Returns nil if the token does not exist.
+	GetByID(id string) (*models.APIToken, error)
+
+	// GetBySecretHash retrieves a token by the hash of its secret.
+	// Returns nil if no token has the hash.
+	GetBySecretHash(hash string) (*models.APIToken, error)
+
+	// List retrieves all tokens of a user, ordered by creation time.
+	List(userID string) ([]*models.APIToken, error)
+
+	// Delete removes a token.
+	Delete(id string) error
+}
+
+// FileTokenRepository implements TokenRepository using one JSON file per user,
+// stored next to the user's saved configurations:
+//
+//	{configDir}/api_tokens.json                  (shared, no user)
+//	{configDir}/users/{userID}/api_tokens.json
+type FileTokenRepository struct {
+	store  jsonUserStore[*models.APIToken]
+	hashes map[string]string // secret hash -> token ID
+	mu     sync.RWMutex
+}
+
+// NewFileTokenRepository creates a file-based API token repository and loads
+// all persisted tokens from configDir.
+func NewFileTokenRepository(configDir string) (*FileTokenRepository, error) {
+	repo := &FileTokenRepository{
+		store: jsonUserStore[*models.APIToken]{
+			baseDir:  configDir,
+			fileName: tokenFileName,
+			name:     "token",
+			id:       func(t *models.APIToken) string { return t.ID },
+			owner:    func(t *models.APIToken) string { return t.UserID },
+			less: func(a, b *models.APIToken) bool {
+				if a.CreatedAt.Equal(b.CreatedAt) {
+					return a.ID < b.ID
+				}
+				return a.CreatedAt.Before(b.CreatedAt)
+			},
+			clone: (*models.APIToken).Clone,
+		},
+		hashes: make(map[string]string),
+	}
+
+	if err := repo.store.load(); err != nil {
+		return nil, fmt.Errorf("failed to load API tokens: %w", err)
+	}
+	for id, token := range repo.store.records {
+		repo.hashes[token.SecretHash] = id
+	}
+
+	return repo, nil
+}
+
+// Save creates or replaces a token.
+func (r *FileTokenRepository) Save(token *models.APIToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.store.get(token.ID)
+	if exists && (previous.UserID != token.UserID || previous.SecretHash != token.SecretHash) {
+		return fmt.Errorf("token %s cannot change its owner or secret", token.ID)
+	}
+	if id, taken := r.hashes[token.SecretHash]; taken && id != token.ID {
+		return fmt.Errorf("token secret hash is already in use")
+	}
+
+	if err := r.store.put(token); err != nil {
+		return err
+	}
+	r.hashes[token.SecretHash] = token.ID
+	return nil
+}
+
+// GetByID retrieves a token by ID.
+func (r *FileTokenRepository) GetByID(id string) (*models.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, _ := r.store.get(id)
+	return token, nil
+}
+
+// GetBySecretHash retrieves a token by the hash of its secret.
+func (r *FileTokenRepository) GetBySecretHash(hash string) (*models.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, _ := r.store.get(r.hashes[hash])
+	return token, nil
+}
+
+// List retrieves all tokens of a user.
+func (r *FileTokenRepository) List(userID string) ([]*models.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.store.list(func(t *models.APIToken) bool { return t.UserID == userID }), nil
+}
+
+// Delete removes a token.
+func (r *FileTokenRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, exists := r.store.get(id)
+	if !exists {
+		return fmt.Errorf("token with ID %s not found", id)
+	}
+
+	if _, err := r.store.remove(func(t *models.APIToken) bool { return t.ID == id }); err != nil {
+		return err
+	}
+	delete(r.hashes, token.SecretHash)
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// TestFileTokenRepository tests persisting API tokens and looking them up by secret hash
+func TestFileTokenRepository(t *testing.T) {
+	configDir := t.TempDir()
+	repo, err := NewFileTokenRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	token := &models.APIToken{
+		ID:         "token-1",
+		UserID:     "user@example.com",
+		Name:       "jenkins",
+		Scope:      models.TokenScopeScanner,
+		SecretHash: "hash-1",
+		CreatedAt:  time.Now(),
+		ExpiresAt:  time.Now().Add(24 * time.Hour),
+	}
+	if err := repo.Save(token); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+
+	found, _ := repo.GetBySecretHash("hash-1")
+	if found == nil || found.ID != "token-1" {
+		t.Fatalf("Expected to find the token by its hash, got %+v", found)
+	}
+	if missing, _ := repo.GetBySecretHash("other"); missing != nil {
+		t.Errorf("Expected no token for an unknown hash, got %+v", missing)
+	}
+
+	// The owner and secret of a token cannot change
+	moved := token.Clone()
+	moved.UserID = "other-user"
+	if err := repo.Save(moved); err == nil {
+		t.Error("Expected error when moving a token to another user")
+	}
+	duplicate := token.Clone()
+	duplicate.ID = "token-2"
+	if err := repo.Save(duplicate); err == nil {
+		t.Error("Expected error when reusing a secret hash")
+	}
+
+	// Updates such as the last-used time survive a restart
+	lastUsed := time.Now()
+	found.LastUsedAt = &lastUsed
+	if err := repo.Save(found); err != nil {
+		t.Fatalf("Failed to update token: %v", err)
+	}
+	repo2, err := NewFileTokenRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to reload repository: %v", err)
+	}
+	reloaded, _ := repo2.GetBySecretHash("hash-1")
+	if reloaded == nil || reloaded.LastUsedAt == nil || !reloaded.LastUsedAt.Equal(lastUsed) {
+		t.Fatalf("Unexpected reloaded token: %+v", reloaded)
+	}
+	if tokens, _ := repo2.List("other-user"); len(tokens) != 0 {
+		t.Errorf("Expected no tokens for another user, got %d", len(tokens))
+	}
+
+	if err := repo2.Delete("token-1"); err != nil {
+		t.Fatalf("Failed to delete token: %v", err)
+	}
+	if deleted, _ := repo2.GetBySecretHash("hash-1"); deleted != nil {
+		t.Error("Expected deleted tokens to no longer be found by hash")
+	}
+	if tokens, _ := repo2.List("user@example.com"); len(tokens) != 0 {
+		t.Errorf("Expected no tokens after deletion, got %d", len(tokens))
+	}
+}
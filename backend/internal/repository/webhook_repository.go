@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package repository provides data access layer for webhooks.
+package repository
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+const (
+	// webhookFileName is the per-user file webhooks are persisted to.
+	webhookFileName = "webhooks.json"
+
+	// MaxWebhookDeliveries is the number of deliveries kept in the log of a webhook.
+	MaxWebhookDeliveries = 50
+)
+
+// WebhookRepository defines the interface for webhook persistence.
+// Implementations return copies, so callers may modify returned webhooks freely.
+type WebhookRepository interface {
+	// Save creates or replaces a webhook. The delivery log of an existing webhook
+	// is kept; it is only changed by AddDelivery.
+	Save(webhook *models.Webhook) error
+
+	// GetByID retrieves a webhook by its unique identifier.
+	// Returns nil if the webhook does not exist.
+	GetByID(id string) (*models.Webhook, error)
+
+	// List retrieves all webhooks of a user, ordered by creation time.
+	List(userID string) ([]*models.Webhook, error)
+
+	// Delete removes a webhook.
+	Delete(id string) error
+
+	// AddDelivery prepends a delivery to the log of a webhook, keeping the
+	// latest MaxWebhookDeliveries entries.
+	AddDelivery(id string, delivery *models.WebhookDelivery) error
+}
+
+// FileWebhookRepository implements WebhookRepository using one JSON file per user,
+// stored next to the user's saved configurations:
+//
+//	{configDir}/webhooks.json                  (shared, no user)
+//	{configDir}/users/{userID}/webhooks.json
+type FileWebhookRepository struct {
+	store jsonUserStore[*models.Webhook]
+	mu    sync.RWMutex
+}
+
+// NewFileWebhookRepository creates a file-based webhook repository and loads
+// all persisted webhooks from configDir.
+func NewFileWebhookRepository(configDir string) (*FileWebhookRepository, error) {
+	repo := &FileWebhookRepository{
+		store: jsonUserStore[*models.Webhook]{
+			baseDir:  configDir,
+			fileName: webhookFileName,
+			name:     "webhook",
+			id:       func(w *models.Webhook) string { return w.ID },
+			owner:    func(w *models.Webhook) string { return w.UserID },
+			less: func(a, b *models.Webhook) bool {
+				if a.CreatedAt.Equal(b.CreatedAt) {
+					return a.ID < b.ID
+				}
+				return a.CreatedAt.Before(b.CreatedAt)
+			},
+			clone: (*models.Webhook).Clone,
+		},
+	}
+
+	if err := repo.store.load(); err != nil {
+		return nil, fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	return repo, nil
+}
+
+// Save creates or replaces a webhook.
+func (r *FileWebhookRepository) Save(webhook *models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.store.get(webhook.ID)
+	if exists && previous.UserID != webhook.UserID {
+		return fmt.Errorf("webhook %s belongs to another user", webhook.ID)
+	}
+
+	saved := webhook.Clone()
+	saved.Deliveries = nil
+	if exists {
+		saved.Deliveries = previous.Deliveries
+	}
+
+	return r.store.put(saved)
+}
+
+// GetByID retrieves a webhook by ID.
+func (r *FileWebhookRepository) GetByID(id string) (*models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, _ := r.store.get(id)
+	return webhook, nil
+}
+
+// List retrieves all webhooks of a user.
+func (r *FileWebhookRepository) List(userID string) ([]*models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.store.list(func(w *models.Webhook) bool { return w.UserID == userID }), nil
+}
+
+// Delete removes a webhook.
+func (r *FileWebhookRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.store.get(id); !exists {
+		return fmt.Errorf("webhook with ID %s not found", id)
+	}
+
+	_, err := r.store.remove(func(w *models.Webhook) bool { return w.ID == id })
+	return err
+}
+
+// AddDelivery prepends a delivery to the log of a webhook.
+func (r *FileWebhookRepository) AddDelivery(id string, delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, exists := r.store.get(id)
+	if !exists {
+		// The webhook was deleted while the delivery was in flight
+		return nil
+	}
+
+	d := *delivery
+	webhook.Deliveries = append([]*models.WebhookDelivery{&d}, webhook.Deliveries...)
+	if len(webhook.Deliveries) > MaxWebhookDeliveries {
+		webhook.Deliveries = webhook.Deliveries[:MaxWebhookDeliveries]
+	}
+
+	return r.store.put(webhook)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// TestFileWebhookRepository tests persisting webhooks and their delivery log
+func TestFileWebhookRepository(t *testing.T) {
+	configDir := t.TempDir()
+	repo, err := NewFileWebhookRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	webhook := &models.Webhook{
+		ID:        "webhook-1",
+		UserID:    "user@example.com",
+		URL:       "https://hooks.example.com/trivy",
+		Format:    models.WebhookFormatGeneric,
+		Events:    []string{models.WebhookEventFailed},
+		CreatedAt: time.Now(),
+	}
+	if err := repo.Save(webhook); err != nil {
+		t.Fatalf("Failed to save webhook: %v", err)
+	}
+
+	// The delivery log keeps the latest deliveries, newest first
+	for i := 0; i < MaxWebhookDeliveries+5; i++ {
+		delivery := &models.WebhookDelivery{ID: fmt.Sprintf("delivery-%d", i), Event: models.WebhookEventFailed}
+		if err := repo.AddDelivery("webhook-1", delivery); err != nil {
+			t.Fatalf("Failed to add delivery: %v", err)
+		}
+	}
+	retrieved, _ := repo.GetByID("webhook-1")
+	if len(retrieved.Deliveries) != MaxWebhookDeliveries || retrieved.Deliveries[0].ID != fmt.Sprintf("delivery-%d", MaxWebhookDeliveries+4) {
+		t.Errorf("Unexpected delivery log: %d entries, newest %s", len(retrieved.Deliveries), retrieved.Deliveries[0].ID)
+	}
+
+	// Saving a webhook keeps its delivery log
+	retrieved.Paused = true
+	retrieved.Deliveries = nil
+	if err := repo.Save(retrieved); err != nil {
+		t.Fatalf("Failed to update webhook: %v", err)
+	}
+
+	// A webhook ID cannot move to another user
+	stolen := retrieved.Clone()
+	stolen.UserID = "other-user"
+	if err := repo.Save(stolen); err == nil {
+		t.Error("Expected error when saving a webhook of another user")
+	}
+
+	// Webhooks survive a restart
+	repo2, err := NewFileWebhookRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to reload repository: %v", err)
+	}
+	reloaded, _ := repo2.GetByID("webhook-1")
+	if reloaded == nil || !reloaded.Paused || len(reloaded.Deliveries) != MaxWebhookDeliveries {
+		t.Fatalf("Unexpected reloaded webhook: %+v", reloaded)
+	}
+	if webhooks, _ := repo2.List("other-user"); len(webhooks) != 0 {
+		t.Errorf("Expected no webhooks for another user, got %d", len(webhooks))
+	}
+
+	if err := repo2.Delete("webhook-1"); err != nil {
+		t.Fatalf("Failed to delete webhook: %v", err)
+	}
+	if err := repo2.AddDelivery("webhook-1", &models.WebhookDelivery{ID: "late"}); err != nil {
+		t.Errorf("Expected deliveries of deleted webhooks to be dropped, got %v", err)
+	}
+	if webhooks, _ := repo2.List("user@example.com"); len(webhooks) != 0 {
+		t.Errorf("Expected no webhooks after deletion, got %d", len(webhooks))
+	}
+}
@@ -7,19 +7,28 @@ package router
 import (
 	"github.com/lazycatapps/trivy/backend/internal/handler"
 	"github.com/lazycatapps/trivy/backend/internal/middleware"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/metrics"
 	"github.com/lazycatapps/trivy/backend/internal/types"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Router manages HTTP request routing and handler registration.
-// It holds references to all HTTP handlers (scan, report, config, auth, etc.).
+// It holds references to all HTTP handlers (scan, report, config, schedule, webhook, digest, token, auth, etc.).
 type Router struct {
 	scanHandler      *handler.ScanHandler
 	reportHandler    *handler.ReportHandler
 	configHandler    *handler.ConfigHandler
+	scheduleHandler  *handler.ScheduleHandler
+	webhookHandler   *handler.WebhookHandler
+	digestHandler    *handler.DigestHandler
+	tokenHandler     *handler.TokenHandler
 	authHandler      *handler.AuthHandler
 	sessionValidator middleware.SessionValidator
+	tokenValidator   middleware.SessionValidator
+	roleMapping      *middleware.RoleMapping
+	logger           logger.Logger
 }
 
 // New creates a new Router instance with the provided handlers.
@@ -27,32 +36,50 @@ func New(
 	scanHandler *handler.ScanHandler,
 	reportHandler *handler.ReportHandler,
 	configHandler *handler.ConfigHandler,
+	scheduleHandler *handler.ScheduleHandler,
+	webhookHandler *handler.WebhookHandler,
+	digestHandler *handler.DigestHandler,
+	tokenHandler *handler.TokenHandler,
 	authHandler *handler.AuthHandler,
 	sessionValidator middleware.SessionValidator,
+	tokenValidator middleware.SessionValidator,
+	roleMapping *middleware.RoleMapping,
+	logger logger.Logger,
 ) *Router {
 	return &Router{
 		scanHandler:      scanHandler,
 		reportHandler:    reportHandler,
 		configHandler:    configHandler,
+		scheduleHandler:  scheduleHandler,
+		webhookHandler:   webhookHandler,
+		digestHandler:    digestHandler,
+		tokenHandler:     tokenHandler,
 		authHandler:      authHandler,
 		sessionValidator: sessionValidator,
+		tokenValidator:   tokenValidator,
+		roleMapping:      roleMapping,
+		logger:           logger,
 	}
 }
 
 // Setup initializes the Gin engine with middleware and routes.
 // It configures the following middleware in order:
-//  1. gin.Logger() - HTTP request logging
-//  2. gin.Recovery() - Panic recovery
-//  3. CORS - Cross-Origin Resource Sharing
-//  4. Auth - OIDC authentication (if enabled)
+//  1. RequestID - Request correlation ID (X-Request-ID)
+//  2. Logger - Structured HTTP request logging
+//  3. gin.Recovery() - Panic recovery
+//  4. CORS - Cross-Origin Resource Sharing
+//  5. Auth - OIDC session or API token authentication (if enabled)
+//  6. Roles - Role of the user (viewer, scanner, admin) mapped from OIDC groups
 //
 // Returns a configured *gin.Engine ready to serve HTTP requests.
 func (r *Router) Setup(cfg *types.Config) *gin.Engine {
 	engine := gin.New()
-	engine.Use(gin.Logger())
+	engine.Use(middleware.RequestID())
+	engine.Use(middleware.Logger(r.logger))
 	engine.Use(gin.Recovery())
 	engine.Use(middleware.CORS(cfg.CORS.AllowedOrigins))
-	engine.Use(middleware.Auth(cfg.OIDC.Enabled, r.sessionValidator))
+	engine.Use(middleware.Auth(cfg.OIDC.Enabled, r.sessionValidator, r.tokenValidator))
+	engine.Use(middleware.Roles(cfg.OIDC.Enabled, r.roleMapping))
 
 	// Disable trusted proxy feature for security
 	engine.SetTrustedProxies(nil)
@@ -62,26 +89,79 @@ func (r *Router) Setup(cfg *types.Config) *gin.Engine {
 	return engine
 }
 
-// registerRoutes registers all API routes under /api/v1 prefix.
+// registerRoutes registers the Prometheus /metrics endpoint and all API routes under /api/v1 prefix.
 // Available endpoints:
 //   - GET    /health               - Health check
 //   - GET    /auth/login           - Redirect to OIDC provider for login
 //   - GET    /auth/callback        - OIDC callback handler
-//   - POST   /auth/logout          - Logout current user
+//   - POST   /auth/logout          - Logout current user (returns the provider logout URL if supported)
+//   - POST   /auth/backchannel-logout - OIDC back-channel logout (called by the provider)
 //   - GET    /auth/userinfo        - Get current user information
 //   - POST   /scan                 - Create a new scan task
+//   - POST   /scan/upload          - Scan an uploaded image archive, SBOM, filesystem tarball or VM image
 //   - GET    /scan                 - List scan tasks with pagination and filtering
+//   - GET    /scan/export          - Export scan history as CSV, JSON or XLSX
+//   - GET    /scan/diff            - Compare the vulnerabilities of two tasks (?base=&target=)
 //   - GET    /scan/:id             - Get scan task status and details
 //   - GET    /scan/:id/logs        - Stream scan task logs via SSE
+//   - DELETE /scan/:id/cancel      - Cancel a queued or running scan task
+//   - POST   /scan/:id/rescan      - Rescan with the parameters of an existing task
+//   - PUT    /scan/:id/shares      - Share a task with OIDC groups (read or manage permission)
+//   - GET    /scan/:id/findings    - List structured findings with filtering and pagination
 //   - GET    /scan/:id/report/:format - Download scan report in specified format
+//   - GET    /scan/:id/report/archive - Download all report formats as a zip archive
 //   - GET    /queue/status         - Get queue status
 //   - GET    /configs              - List all saved configuration names
 //   - GET    /config/last-used     - Get the name of the last used configuration
 //   - GET    /config/:name         - Get a saved user configuration by name
 //   - POST   /config/:name         - Save user configuration with name
 //   - DELETE /config/:name         - Delete a saved user configuration by name
+//   - GET    /policies             - List all saved policy names
+//   - GET    /policy/:name         - Get a saved policy by name
+//   - POST   /policy/:name         - Save a policy (rules evaluated after scans that reference it)
+//   - DELETE /policy/:name         - Delete a saved policy by name
+//   - GET    /checks               - List custom Rego check bundles with their versions
+//   - GET    /checks/:name         - Get the versions of a check bundle
+//   - POST   /checks/:name         - Upload a new version of a check bundle (multipart/form-data)
+//   - DELETE /checks/:name         - Delete a check bundle with all its versions
+//   - GET    /suppressions         - List global and personal suppression rules
+//   - POST   /suppressions         - Create a suppression rule (vulnerability, package, expiry, justification)
+//   - DELETE /suppressions/:id     - Delete a personal suppression rule
+//   - GET    /schedules            - List scheduled scans with their last and next run
+//   - POST   /schedules            - Create a scheduled scan (image or saved config + cron expression)
+//   - POST   /schedules/:id/pause  - Pause a scheduled scan
+//   - POST   /schedules/:id/resume - Resume a paused scheduled scan
+//   - DELETE /schedules/:id        - Delete a scheduled scan
+//   - GET    /webhooks             - List webhooks
+//   - POST   /webhooks             - Create a webhook (URL, format, secret, events, threshold)
+//   - POST   /webhooks/:id/pause   - Pause a webhook
+//   - POST   /webhooks/:id/resume  - Resume a paused webhook
+//   - DELETE /webhooks/:id         - Delete a webhook
+//   - GET    /webhooks/:id/deliveries - List the recent deliveries of a webhook
+//   - POST   /webhooks/:id/test    - Send a test event to a webhook
+//   - GET    /digest               - Get the email digest subscription
+//   - PUT    /digest               - Subscribe to or update the email digest (address, daily/weekly)
+//   - DELETE /digest               - Unsubscribe from the email digest
+//   - POST   /digest/send          - Send the digest of the last period now
+//   - GET    /tokens               - List personal API tokens
+//   - POST   /tokens               - Create a personal API token (name, scope, expiry; secret returned once)
+//   - DELETE /tokens/:id           - Revoke a personal API token
+//   - GET    /admin/scans          - List the scan tasks of all users
+//   - POST   /admin/purge          - Delete finished tasks older than a number of days
+//   - POST   /admin/suppressions   - Create a global suppression rule
+//   - DELETE /admin/suppressions/:id - Delete a global suppression rule
+//   - GET    /admin/sessions       - List active sessions
+//   - DELETE /admin/sessions       - End all sessions of a user (userId query parameter)
+//   - DELETE /admin/sessions/:id   - End a session
 //   - GET    /trivy/version        - Get Trivy Server version information
+//
+// Each protected route requires a role: viewer for reads, scanner for creating scans and
+// changing personal settings (and listing local Docker images), admin for the /admin routes.
+// GET /metrics (outside /api/v1, admin role) exposes Prometheus metrics; scrapers
+// authenticate with an API token of an admin.
 func (r *Router) registerRoutes(engine *gin.Engine) {
+	engine.GET("/metrics", middleware.RequireRole(middleware.RoleAdmin), gin.WrapH(metrics.Handler()))
+
 	api := engine.Group("/api/v1")
 	{
 		// Public endpoints (no auth required)
@@ -93,34 +173,103 @@ func (r *Router) registerRoutes(engine *gin.Engine) {
 			auth.GET("/login", r.authHandler.Login)
 			auth.GET("/callback", r.authHandler.Callback)
 			auth.POST("/logout", r.authHandler.Logout)
+			auth.POST("/backchannel-logout", r.authHandler.BackchannelLogout)
 			auth.GET("/userinfo", r.authHandler.UserInfo)
 		}
 
-		// Protected endpoints (require auth if OIDC enabled)
+		// Protected endpoints (require auth if OIDC enabled) with the minimum role of each route
+		viewer := middleware.RequireRole(middleware.RoleViewer)
+		scanner := middleware.RequireRole(middleware.RoleScanner)
+		admin := middleware.RequireRole(middleware.RoleAdmin)
+
 		// Scan endpoints
-		api.POST("/scan", r.scanHandler.CreateScan)
-		api.GET("/scan", r.scanHandler.ListScans)
-		api.DELETE("/scan", r.scanHandler.DeleteAllScans)
-		api.GET("/scan/:id", r.scanHandler.GetScan)
-		api.DELETE("/scan/:id", r.scanHandler.DeleteScan)
-		api.GET("/scan/:id/logs", r.scanHandler.StreamLogs)
+		api.POST("/scan", scanner, r.scanHandler.CreateScan)
+		api.POST("/scan/upload", scanner, r.scanHandler.UploadScan)
+		api.GET("/scan", viewer, r.scanHandler.ListScans)
+		api.GET("/scan/export", viewer, r.scanHandler.ExportScans)
+		api.GET("/scan/diff", viewer, r.scanHandler.DiffScans)
+		api.DELETE("/scan", scanner, r.scanHandler.DeleteAllScans)
+		api.GET("/scan/:id", viewer, r.scanHandler.GetScan)
+		api.DELETE("/scan/:id", scanner, r.scanHandler.DeleteScan)
+		api.GET("/scan/:id/logs", viewer, r.scanHandler.StreamLogs)
+		api.DELETE("/scan/:id/cancel", scanner, r.scanHandler.CancelScan)
+		api.POST("/scan/:id/rescan", scanner, r.scanHandler.RescanScan)
+		api.PUT("/scan/:id/shares", scanner, r.scanHandler.ShareScan)
+		api.GET("/scan/:id/findings", viewer, r.scanHandler.ListFindings)
 
 		// Report download endpoints
-		api.GET("/scan/:id/report/:format", r.reportHandler.DownloadReport)
+		api.GET("/scan/:id/report/archive", viewer, r.reportHandler.DownloadArchive)
+		api.GET("/scan/:id/report/:format", viewer, r.reportHandler.DownloadReport)
 
 		// Queue status endpoint
-		api.GET("/queue/status", r.scanHandler.GetQueueStatus)
+		api.GET("/queue/status", viewer, r.scanHandler.GetQueueStatus)
 
 		// Docker endpoints
-		api.GET("/docker/images", r.scanHandler.ListDockerImages)
-		api.GET("/docker/containers", r.scanHandler.ListDockerContainers)
+		api.GET("/docker/images", scanner, r.scanHandler.ListDockerImages)
+		api.GET("/docker/containers", scanner, r.scanHandler.ListDockerContainers)
 
 		// Config management endpoints
-		api.GET("/configs", r.configHandler.ListConfigs)
-		api.GET("/config/last-used", r.configHandler.GetLastUsedConfig)
-		api.GET("/config/:name", r.configHandler.GetConfig)
-		api.POST("/config/:name", r.configHandler.SaveConfig)
-		api.DELETE("/config/:name", r.configHandler.DeleteConfig)
+		api.GET("/configs", viewer, r.configHandler.ListConfigs)
+		api.GET("/config/last-used", viewer, r.configHandler.GetLastUsedConfig)
+		api.GET("/config/:name", viewer, r.configHandler.GetConfig)
+		api.POST("/config/:name", scanner, r.configHandler.SaveConfig)
+		api.DELETE("/config/:name", scanner, r.configHandler.DeleteConfig)
+
+		// Policy gate endpoints
+		api.GET("/policies", viewer, r.configHandler.ListPolicies)
+		api.GET("/policy/:name", viewer, r.configHandler.GetPolicy)
+		api.POST("/policy/:name", scanner, r.configHandler.SavePolicy)
+		api.DELETE("/policy/:name", scanner, r.configHandler.DeletePolicy)
+
+		// Custom check bundle endpoints
+		api.GET("/checks", viewer, r.configHandler.ListCheckBundles)
+		api.GET("/checks/:name", viewer, r.configHandler.GetCheckBundle)
+		api.POST("/checks/:name", scanner, r.configHandler.UploadCheckBundle)
+		api.DELETE("/checks/:name", scanner, r.configHandler.DeleteCheckBundle)
+
+		// Suppression rule endpoints
+		api.GET("/suppressions", viewer, r.configHandler.ListSuppressions)
+		api.POST("/suppressions", scanner, r.configHandler.AddSuppression)
+		api.DELETE("/suppressions/:id", scanner, r.configHandler.DeleteSuppression)
+
+		// Scheduled scan endpoints
+		api.GET("/schedules", viewer, r.scheduleHandler.ListSchedules)
+		api.POST("/schedules", scanner, r.scheduleHandler.CreateSchedule)
+		api.POST("/schedules/:id/pause", scanner, r.scheduleHandler.PauseSchedule)
+		api.POST("/schedules/:id/resume", scanner, r.scheduleHandler.ResumeSchedule)
+		api.DELETE("/schedules/:id", scanner, r.scheduleHandler.DeleteSchedule)
+
+		// Webhook endpoints
+		api.GET("/webhooks", viewer, r.webhookHandler.ListWebhooks)
+		api.POST("/webhooks", scanner, r.webhookHandler.CreateWebhook)
+		api.POST("/webhooks/:id/pause", scanner, r.webhookHandler.PauseWebhook)
+		api.POST("/webhooks/:id/resume", scanner, r.webhookHandler.ResumeWebhook)
+		api.DELETE("/webhooks/:id", scanner, r.webhookHandler.DeleteWebhook)
+		api.GET("/webhooks/:id/deliveries", viewer, r.webhookHandler.ListDeliveries)
+		api.POST("/webhooks/:id/test", scanner, r.webhookHandler.TestWebhook)
+
+		// Email digest endpoints
+		api.GET("/digest", viewer, r.digestHandler.GetSubscription)
+		api.PUT("/digest", scanner, r.digestHandler.UpdateSubscription)
+		api.DELETE("/digest", scanner, r.digestHandler.DeleteSubscription)
+		api.POST("/digest/send", scanner, r.digestHandler.SendDigest)
+
+		// Personal API token endpoints
+		api.GET("/tokens", viewer, r.tokenHandler.ListTokens)
+		api.POST("/tokens", viewer, r.tokenHandler.CreateToken)
+		api.DELETE("/tokens/:id", viewer, r.tokenHandler.RevokeToken)
+
+		// Admin endpoints
+		adminGroup := api.Group("/admin", admin)
+		{
+			adminGroup.GET("/scans", r.scanHandler.ListAllScans)
+			adminGroup.POST("/purge", r.scanHandler.PurgeScans)
+			adminGroup.POST("/suppressions", r.configHandler.AddGlobalSuppression)
+			adminGroup.DELETE("/suppressions/:id", r.configHandler.DeleteGlobalSuppression)
+			adminGroup.GET("/sessions", r.authHandler.ListSessions)
+			adminGroup.DELETE("/sessions", r.authHandler.RevokeUserSessions)
+			adminGroup.DELETE("/sessions/:id", r.authHandler.RevokeSession)
+		}
 
 		// System config endpoint (public)
 		api.GET("/system/config", r.configHandler.GetSystemConfig)
@@ -0,0 +1,433 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for custom Rego check bundles.
+package service
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/validator"
+	"github.com/open-policy-agent/opa/v1/ast"
+)
+
+const (
+	// checksDirName is the directory (inside the user's config directory) holding check bundles.
+	checksDirName = "checks"
+
+	// maxCheckBundleSize limits the uploaded and extracted size of a check bundle.
+	maxCheckBundleSize = 4 * 1024 * 1024
+
+	// maxCheckBundleFiles limits the number of Rego modules of a check bundle.
+	maxCheckBundleFiles = 200
+
+	// maxCheckBundleVersions is the number of versions kept per bundle; older versions are removed.
+	maxCheckBundleVersions = 10
+)
+
+// regoModule is a Rego file of an uploaded check bundle.
+type regoModule struct {
+	path string // Relative path inside the bundle
+	data []byte // Module source
+}
+
+// getCheckBundleDir returns the directory holding all versions of a check bundle:
+// {configDir}/users/{userIdentifier}/checks/{name}
+func (s *ConfigService) getCheckBundleDir(userIdentifier, name string) string {
+	return filepath.Join(s.getUserConfigDir(userIdentifier), checksDirName, filepath.Base(filepath.Clean(name)))
+}
+
+// checkVersionDir returns the directory holding the Rego modules of a bundle version.
+// This directory is passed to trivy with --config-check.
+func (s *ConfigService) checkVersionDir(userIdentifier, name string, version int) string {
+	return filepath.Join(s.getCheckBundleDir(userIdentifier, name), fmt.Sprintf("v%d", version))
+}
+
+// ListCheckBundles returns all check bundles of a user with their versions.
+func (s *ConfigService) ListCheckBundles(userIdentifier string) (*models.CheckBundleListResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	response := &models.CheckBundleListResponse{Bundles: []*models.CheckBundle{}}
+
+	entries, err := os.ReadDir(filepath.Join(s.getUserConfigDir(userIdentifier), checksDirName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return response, nil
+		}
+		s.logger.Error("Failed to read checks directory: %v", err)
+		return nil, errors.WrapInternal(err, "Failed to read checks directory")
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || validator.ValidateConfigName(entry.Name()) != nil {
+			continue
+		}
+		versions, err := s.listCheckVersionsNoLock(userIdentifier, entry.Name())
+		if err != nil {
+			s.logger.Error("Failed to read check bundle %s: %v", entry.Name(), err)
+			return nil, errors.WrapInternal(err, "Failed to read check bundle")
+		}
+		if len(versions) > 0 {
+			response.Bundles = append(response.Bundles, &models.CheckBundle{Name: entry.Name(), Versions: versions})
+		}
+	}
+
+	return response, nil
+}
+
+// GetCheckBundle returns a check bundle of a user with its versions.
+func (s *ConfigService) GetCheckBundle(userIdentifier, name string) (*models.CheckBundle, error) {
+	if err := validator.ValidateConfigName(name); err != nil {
+		return nil, errors.NewInvalidInput(err.Error())
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions, err := s.listCheckVersionsNoLock(userIdentifier, name)
+	if err != nil {
+		s.logger.Error("Failed to read check bundle %s: %v", name, err)
+		return nil, errors.WrapInternal(err, "Failed to read check bundle")
+	}
+	if len(versions) == 0 {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Check bundle %q not found", name))
+	}
+
+	return &models.CheckBundle{Name: name, Versions: versions}, nil
+}
+
+// listCheckVersionsNoLock reads the version metadata of a bundle without locking, oldest first.
+func (s *ConfigService) listCheckVersionsNoLock(userIdentifier, name string) ([]models.CheckBundleVersion, error) {
+	entries, err := os.ReadDir(s.getCheckBundleDir(userIdentifier, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var versions []models.CheckBundleVersion
+	for _, entry := range entries {
+		// Version metadata is stored as v{N}.json next to the v{N} module directory
+		filename := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(filename, "v") || !strings.HasSuffix(filename, configFileSuffix) {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(filename, "v"), configFileSuffix)); err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.getCheckBundleDir(userIdentifier, name), filename))
+		if err != nil {
+			return nil, err
+		}
+		var version models.CheckBundleVersion
+		if err := json.Unmarshal(data, &version); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, nil
+}
+
+// ResolveCheckBundle returns the requested version of a bundle, or its latest version when version is 0.
+func (s *ConfigService) ResolveCheckBundle(userIdentifier, name string, version int) (*models.CheckBundleVersion, error) {
+	bundle, err := s.GetCheckBundle(userIdentifier, name)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return &bundle.Versions[len(bundle.Versions)-1], nil
+	}
+	for i := range bundle.Versions {
+		if bundle.Versions[i].Version == version {
+			return &bundle.Versions[i], nil
+		}
+	}
+	return nil, errors.NewInvalidInput(fmt.Sprintf("Check bundle %q has no version %d", name, version))
+}
+
+// UploadCheckBundle validates an uploaded Rego module (.rego) or tarball of modules
+// (.tar, .tar.gz, .tgz) and stores it as the next version of the named bundle.
+func (s *ConfigService) UploadCheckBundle(userIdentifier, name, filename string, content io.Reader) (*models.CheckBundleVersion, error) {
+	if err := validator.ValidateConfigName(name); err != nil {
+		return nil, errors.NewInvalidInput(err.Error())
+	}
+	filename = filepath.Base(filename)
+	if err := validator.ValidateUploadFilename(filename); err != nil {
+		return nil, errors.WrapInvalidInput(err, err.Error())
+	}
+
+	modules, err := readRegoModules(filename, content)
+	if err != nil {
+		if err == errUploadTooLarge {
+			return nil, errors.NewPayloadTooLarge(fmt.Sprintf("Check bundle exceeds maximum size of %d bytes", maxCheckBundleSize))
+		}
+		return nil, errors.WrapInvalidInput(err, err.Error())
+	}
+
+	version := &models.CheckBundleVersion{UploadedAt: time.Now()}
+	namespaces := make(map[string]bool)
+	hasDeny := false
+	for _, module := range modules {
+		parsed, err := validator.ValidateRegoModule(module.path, module.data)
+		if err != nil {
+			return nil, errors.WrapInvalidInput(err, err.Error())
+		}
+		// --check-namespaces matches package prefixes, so the top-level namespace covers the bundle
+		namespaces[strings.SplitN(validator.RegoPackage(parsed), ".", 2)[0]] = true
+		hasDeny = hasDeny || hasDenyRule(parsed)
+		version.Files = append(version.Files, module.path)
+		version.Size += int64(len(module.data))
+	}
+	if !hasDeny {
+		return nil, errors.NewInvalidInput("Check bundle has no deny rule")
+	}
+	for namespace := range namespaces {
+		version.Namespaces = append(version.Namespaces, namespace)
+	}
+	sort.Strings(version.Namespaces)
+	sort.Strings(version.Files)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.listCheckVersionsNoLock(userIdentifier, name)
+	if err != nil {
+		s.logger.Error("Failed to read check bundle %s: %v", name, err)
+		return nil, errors.WrapInternal(err, "Failed to read check bundle")
+	}
+	version.Version = 1
+	if len(versions) > 0 {
+		version.Version = versions[len(versions)-1].Version + 1
+	}
+
+	if err := s.writeCheckVersionNoLock(userIdentifier, name, version, modules); err != nil {
+		s.logger.Error("Failed to store check bundle %s: %v", name, err)
+		return nil, errors.WrapInternal(err, "Failed to store check bundle")
+	}
+
+	// Remove the oldest versions beyond the limit
+	for len(versions) >= maxCheckBundleVersions {
+		s.removeCheckVersionNoLock(userIdentifier, name, versions[0].Version)
+		versions = versions[1:]
+	}
+
+	s.logger.Info("Check bundle '%s' version %d saved for user %s (%d modules)", name, version.Version, userIdentifier, len(modules))
+	return version, nil
+}
+
+// writeCheckVersionNoLock writes the modules of a new version to a temporary directory,
+// moves it into place and then writes the version metadata.
+func (s *ConfigService) writeCheckVersionNoLock(userIdentifier, name string, version *models.CheckBundleVersion, modules []regoModule) error {
+	dir := s.checkVersionDir(userIdentifier, name, version.Version)
+	tmpDir := dir + ".tmp"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+
+	for _, module := range modules {
+		target := filepath.Join(tmpDir, filepath.FromSlash(module.path))
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			os.RemoveAll(tmpDir)
+			return err
+		}
+		if err := os.WriteFile(target, module.data, 0600); err != nil {
+			os.RemoveAll(tmpDir)
+			return err
+		}
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+
+	data, err := json.MarshalIndent(version, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dir+configFileSuffix, data, 0600)
+}
+
+// removeCheckVersionNoLock removes the metadata and modules of a bundle version.
+func (s *ConfigService) removeCheckVersionNoLock(userIdentifier, name string, version int) {
+	dir := s.checkVersionDir(userIdentifier, name, version)
+	if err := os.Remove(dir + configFileSuffix); err != nil && !os.IsNotExist(err) {
+		s.logger.Error("Failed to remove check bundle %s version %d: %v", name, version, err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		s.logger.Error("Failed to remove check bundle %s version %d: %v", name, version, err)
+	}
+}
+
+// DeleteCheckBundle removes all versions of a check bundle.
+// Queued tasks referencing the bundle fail when their scan starts.
+func (s *ConfigService) DeleteCheckBundle(userIdentifier, name string) error {
+	if err := validator.ValidateConfigName(name); err != nil {
+		return errors.NewInvalidInput(err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.RemoveAll(s.getCheckBundleDir(userIdentifier, name)); err != nil {
+		s.logger.Error("Failed to delete check bundle %s: %v", name, err)
+		return errors.WrapInternal(err, "Failed to delete check bundle")
+	}
+
+	s.logger.Info("Check bundle '%s' deleted for user %s", name, userIdentifier)
+	return nil
+}
+
+// readRegoModules reads the Rego modules of an upload: a single .rego file, or a
+// (optionally gzip-compressed) tarball whose regular files must all be .rego modules.
+func readRegoModules(filename string, content io.Reader) ([]regoModule, error) {
+	limited := &io.LimitedReader{R: content, N: maxCheckBundleSize + 1}
+
+	if strings.HasSuffix(filename, ".rego") {
+		data, err := io.ReadAll(limited)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upload: %w", err)
+		}
+		if limited.N <= 0 {
+			return nil, errUploadTooLarge
+		}
+		return []regoModule{{path: filename, data: data}}, nil
+	}
+
+	if !strings.HasSuffix(filename, ".tar") && !strings.HasSuffix(filename, ".tar.gz") && !strings.HasSuffix(filename, ".tgz") {
+		return nil, fmt.Errorf("unsupported check bundle file %q (expected .rego, .tar, .tar.gz or .tgz)", filename)
+	}
+
+	// Detect gzip compression from the magic bytes
+	var r io.Reader
+	buffered := bufio.NewReader(limited)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip archive: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	var modules []regoModule
+	var extracted int64
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if limited.N <= 0 {
+				return nil, errUploadTooLarge
+			}
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		if err := validator.ValidateArchiveEntryPath(header.Name); err != nil {
+			return nil, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+		default:
+			return nil, fmt.Errorf("archive entry %q is not a regular file", header.Name)
+		}
+
+		entryPath := path.Clean(strings.TrimPrefix(header.Name, "/"))
+		if !strings.HasSuffix(entryPath, ".rego") {
+			return nil, fmt.Errorf("archive entry %q is not a .rego module", header.Name)
+		}
+		if len(modules) >= maxCheckBundleFiles {
+			return nil, fmt.Errorf("check bundle has more than %d modules", maxCheckBundleFiles)
+		}
+		extracted += header.Size
+		if extracted > maxCheckBundleSize {
+			return nil, errUploadTooLarge
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, header.Size))
+		if err != nil {
+			if limited.N <= 0 {
+				return nil, errUploadTooLarge
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		modules = append(modules, regoModule{path: entryPath, data: data})
+	}
+
+	if limited.N <= 0 {
+		return nil, errUploadTooLarge
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("check bundle contains no .rego modules")
+	}
+	return modules, nil
+}
+
+// resolveCheckBundle pins the requested check bundle version in a new task's scan
+// configuration. Custom checks run in the misconfiguration scanner, which must be enabled.
+func (s *scanServiceImpl) resolveCheckBundle(userID string, req *models.ScanRequest, scanConfig *models.ScanConfig) error {
+	name := strings.TrimSpace(req.CheckBundle)
+	if name == "" {
+		return nil
+	}
+	if s.configService == nil {
+		return errors.NewInvalidInput("Check bundles are not available")
+	}
+	if !containsString(scanConfig.Scanners, "misconfig") || scanConfig.Target() == models.TargetTypeSBOM {
+		return errors.NewInvalidInput("Check bundles require the misconfig scanner")
+	}
+
+	version, err := s.configService.ResolveCheckBundle(userID, name, req.CheckVersion)
+	if err != nil {
+		return err
+	}
+	scanConfig.CheckBundle = name
+	scanConfig.CheckVersion = version.Version
+	scanConfig.CheckNamespaces = version.Namespaces
+	return nil
+}
+
+// hasDenyRule reports whether a module defines a deny rule, which Trivy evaluates for custom checks.
+func hasDenyRule(module *ast.Module) bool {
+	deny := ast.VarTerm("deny")
+	for _, rule := range module.Rules {
+		if ref := rule.Head.Ref(); len(ref) > 0 && ref[0].Equal(deny) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether values contains value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+const testRegoCheck = `# METADATA
+# title: No root user
+package user.dockerfile.no_root
+
+import rego.v1
+
+deny contains msg if {
+	input.Stages[_].Commands[_].Cmd == "user"
+	msg := "Containers must not run as root"
+}
+`
+
+const testRegoLibrary = `package user.lib.utils
+
+is_root(user) if user == "root"
+`
+
+// TestUploadCheckBundle tests validating and versioning uploaded Rego check bundles
+func TestUploadCheckBundle(t *testing.T) {
+	configService := NewConfigService(t.TempDir(), false, 4096, 10, &mockLogger{})
+
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		wantErr  bool
+	}{
+		{
+			name:     "Single module",
+			filename: "no_root.rego",
+			content:  []byte(testRegoCheck),
+		},
+		{
+			name:     "Tarball with library module",
+			filename: "checks.tar.gz",
+			content: buildTarball(t, true, []*tar.Header{
+				{Name: "checks/", Typeflag: tar.TypeDir},
+				{Name: "checks/no_root.rego", Typeflag: tar.TypeReg},
+				{Name: "checks/lib/utils.rego", Typeflag: tar.TypeReg},
+			}, []string{"", testRegoCheck, testRegoLibrary}),
+		},
+		{
+			name:     "Syntax error",
+			filename: "broken.rego",
+			content:  []byte("package user.broken\n\ndeny[msg] {\n  msg := \"unterminated\n}\n"),
+			wantErr:  true,
+		},
+		{
+			name:     "Reserved namespace",
+			filename: "builtin.rego",
+			content:  []byte(strings.Replace(testRegoCheck, "package user.", "package builtin.", 1)),
+			wantErr:  true,
+		},
+		{
+			name:     "No deny rule",
+			filename: "utils.rego",
+			content:  []byte(testRegoLibrary),
+			wantErr:  true,
+		},
+		{
+			name:     "Non-Rego file in tarball",
+			filename: "checks.tar",
+			content: buildTarball(t, false, []*tar.Header{
+				{Name: "no_root.rego", Typeflag: tar.TypeReg},
+				{Name: "run.sh", Typeflag: tar.TypeReg},
+			}, []string{testRegoCheck, "#!/bin/sh"}),
+			wantErr: true,
+		},
+		{
+			name:     "Unsupported file type",
+			filename: "checks.zip",
+			content:  []byte("PK"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := configService.UploadCheckBundle("user1", "dockerfile", tt.filename, bytes.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UploadCheckBundle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Only the two valid uploads were stored, as versions 1 and 2
+	bundle, err := configService.GetCheckBundle("user1", "dockerfile")
+	if err != nil {
+		t.Fatalf("Failed to get bundle: %v", err)
+	}
+	if len(bundle.Versions) != 2 || bundle.Versions[1].Version != 2 {
+		t.Fatalf("Expected versions 1 and 2, got %+v", bundle.Versions)
+	}
+	latest := bundle.Versions[1]
+	if len(latest.Files) != 2 || len(latest.Namespaces) != 1 || latest.Namespaces[0] != "user" {
+		t.Errorf("Unexpected version metadata: %+v", latest)
+	}
+	if _, err := os.Stat(filepath.Join(configService.checkVersionDir("user1", "dockerfile", 2), "checks", "lib", "utils.rego")); err != nil {
+		t.Errorf("Expected extracted module: %v", err)
+	}
+
+	// Bundles are per user
+	if _, err := configService.GetCheckBundle("user2", "dockerfile"); err == nil {
+		t.Error("Expected error when loading another user's bundle")
+	}
+
+	// Old versions are removed beyond the limit
+	for i := 0; i < maxCheckBundleVersions; i++ {
+		if _, err := configService.UploadCheckBundle("user1", "dockerfile", "no_root.rego", strings.NewReader(testRegoCheck)); err != nil {
+			t.Fatalf("Failed to upload version: %v", err)
+		}
+	}
+	bundle, _ = configService.GetCheckBundle("user1", "dockerfile")
+	if len(bundle.Versions) != maxCheckBundleVersions || bundle.Versions[0].Version != 3 {
+		t.Errorf("Expected the latest %d versions starting at 3, got %d starting at %d",
+			maxCheckBundleVersions, len(bundle.Versions), bundle.Versions[0].Version)
+	}
+}
+
+// TestCheckBundleScanArgs tests passing a check bundle to trivy
+func TestCheckBundleScanArgs(t *testing.T) {
+	configService := NewConfigService(t.TempDir(), false, 4096, 10, &mockLogger{})
+	executor := &blockingCommandExecutor{release: make(chan struct{})}
+	service := NewScanServiceWithExecutor(repository.NewInMemoryScanRepository(), &types.TrivyConfig{Timeout: 600, MaxWorkers: 1},
+		t.TempDir(), &mockLogger{}, executor, WithConfigService(configService)).(*scanServiceImpl)
+	defer service.Stop()
+	defer close(executor.release)
+
+	for i := 0; i < 2; i++ {
+		if _, err := configService.UploadCheckBundle("user1", "dockerfile", "no_root.rego", strings.NewReader(testRegoCheck)); err != nil {
+			t.Fatalf("Failed to upload bundle: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		req         *models.ScanRequest
+		wantErr     bool
+		wantVersion int
+	}{
+		{
+			name:        "Latest version",
+			req:         &models.ScanRequest{Image: "alpine:3.19", Scanners: []string{"vuln", "misconfig"}, CheckBundle: "dockerfile"},
+			wantVersion: 2,
+		},
+		{
+			name:        "Pinned version",
+			req:         &models.ScanRequest{Image: "alpine:3.19", Scanners: []string{"misconfig"}, CheckBundle: "dockerfile", CheckVersion: 1},
+			wantVersion: 1,
+		},
+		{
+			name:    "Missing version",
+			req:     &models.ScanRequest{Image: "alpine:3.19", Scanners: []string{"misconfig"}, CheckBundle: "dockerfile", CheckVersion: 7},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown bundle",
+			req:     &models.ScanRequest{Image: "alpine:3.19", Scanners: []string{"misconfig"}, CheckBundle: "k8s"},
+			wantErr: true,
+		},
+		{
+			name:    "Misconfig scanner disabled",
+			req:     &models.ScanRequest{Image: "alpine:3.19", Scanners: []string{"vuln"}, CheckBundle: "dockerfile"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := service.CreateScanTask("user1", tt.req)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if task.ScanConfig.CheckVersion != tt.wantVersion {
+				t.Errorf("Expected version %d, got %d", tt.wantVersion, task.ScanConfig.CheckVersion)
+			}
+
+			args := strings.Join(service.buildTrivyArgs(task), " ")
+			wantDir := configService.checkVersionDir("user1", "dockerfile", tt.wantVersion)
+			if !strings.Contains(args, "--config-check "+wantDir) || !strings.Contains(args, "--check-namespaces user") {
+				t.Errorf("Expected check bundle arguments, got %s", args)
+			}
+		})
+	}
+}
@@ -176,6 +176,25 @@ func (s *ConfigService) GetConfig(userIdentifier, name string) (*models.SavedSca
 	return &config, nil
 }
 
+// ConfigExists reports whether a saved configuration with the given name exists for a user
+func (s *ConfigService) ConfigExists(userIdentifier, name string) (bool, error) {
+	// Validate config name
+	if err := validator.ValidateConfigName(name); err != nil {
+		return false, errors.NewInvalidInput(err.Error())
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := os.Stat(s.getConfigPath(userIdentifier, name)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, errors.WrapInternal(err, "Failed to check config file")
+	}
+	return true, nil
+}
+
 // SaveConfig saves a configuration with the given name for a user
 func (s *ConfigService) SaveConfig(userIdentifier, name string, config *models.SavedScanConfig) error {
 	// Validate config name
@@ -0,0 +1,500 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for email digests.
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+const (
+	// digestCheckInterval is how often the digest worker looks for due digests.
+	digestCheckInterval = time.Minute
+
+	// maxDigestFindings limits the new vulnerabilities listed per image.
+	maxDigestFindings = 10
+
+	// maxDigestErrorLength limits the error output shown per failed scan.
+	maxDigestErrorLength = 300
+
+	// digestPageSize is the page size used to read the scan history.
+	digestPageSize = 100
+)
+
+// DigestService defines the interface for email digest subscriptions and delivery.
+type DigestService interface {
+	// GetSubscription returns the digest subscription of a user.
+	GetSubscription(userID string) (*models.DigestSubscription, error)
+
+	// UpdateSubscription creates or updates the digest subscription of a user.
+	UpdateSubscription(userID string, req *models.DigestSubscriptionRequest) (*models.DigestSubscription, error)
+
+	// DeleteSubscription removes the digest subscription of a user.
+	DeleteSubscription(userID string) error
+
+	// SendDigest immediately sends the digest of the last period to a subscribed
+	// user, without changing the schedule of regular digests.
+	SendDigest(userID string) (*models.Digest, error)
+
+	// Start starts sending due digests in the background.
+	Start()
+
+	// Stop stops the digest worker.
+	Stop()
+}
+
+// digestServiceImpl implements DigestService.
+type digestServiceImpl struct {
+	repo        repository.DigestRepository
+	scanService ScanService
+	mailer      Mailer // nil when SMTP is not configured
+	config      *types.NotificationConfig
+	logger      logger.Logger
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.Mutex // Serializes subscription updates between the API and the worker
+}
+
+// NewDigestService creates a new digest service. The scan history is read through
+// scanService; digests are sent with mailer, which may be nil to disable sending.
+func NewDigestService(
+	repo repository.DigestRepository,
+	scanService ScanService,
+	mailer Mailer,
+	config *types.NotificationConfig,
+	log logger.Logger,
+) DigestService {
+	return &digestServiceImpl{
+		repo:        repo,
+		scanService: scanService,
+		mailer:      mailer,
+		config:      config,
+		logger:      log,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// digestPeriod returns the length of the period covered by a digest.
+func digestPeriod(frequency string) time.Duration {
+	if frequency == models.DigestFrequencyWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// nextDigestTime returns the next send time of a digest after the given time:
+// the configured hour of every day, or of every Monday for weekly digests.
+func nextDigestTime(frequency string, hour int, after time.Time) time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), hour, 0, 0, 0, after.Location())
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	if frequency == models.DigestFrequencyWeekly {
+		for next.Weekday() != time.Monday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
+// digestHour returns the configured send hour, falling back to 8 AM.
+func (s *digestServiceImpl) digestHour() int {
+	if s.config.DigestHour < 0 || s.config.DigestHour > 23 {
+		return 8
+	}
+	return s.config.DigestHour
+}
+
+// GetSubscription returns the digest subscription of a user.
+func (s *digestServiceImpl) GetSubscription(userID string) (*models.DigestSubscription, error) {
+	subscription, err := s.repo.Get(userID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to get digest subscription")
+	}
+	if subscription == nil {
+		return nil, errors.ErrDigestNotFound
+	}
+	return subscription, nil
+}
+
+// UpdateSubscription creates or updates the digest subscription of a user.
+// Changing the frequency or re-enabling the digest reschedules the next send.
+func (s *digestServiceImpl) UpdateSubscription(userID string, req *models.DigestSubscriptionRequest) (*models.DigestSubscription, error) {
+	if s.mailer == nil {
+		return nil, errors.NewInvalidInput("Email digests are not available: SMTP is not configured")
+	}
+
+	address, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+	if err != nil {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Invalid email address: %s", req.Email))
+	}
+
+	frequency := req.Frequency
+	if frequency == "" {
+		frequency = models.DigestFrequencyDaily
+	}
+	if frequency != models.DigestFrequencyDaily && frequency != models.DigestFrequencyWeekly {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Unsupported digest frequency: %s", frequency))
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, err := s.repo.Get(userID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to get digest subscription")
+	}
+	if subscription == nil {
+		subscription = &models.DigestSubscription{UserID: userID}
+	}
+
+	now := time.Now()
+	reschedule := !subscription.Enabled || subscription.Frequency != frequency
+	subscription.Email = address.Address
+	subscription.Frequency = frequency
+	subscription.Enabled = enabled
+	subscription.UpdatedAt = now
+	if !enabled {
+		subscription.NextSendAt = nil
+	} else if reschedule || subscription.NextSendAt == nil {
+		next := nextDigestTime(frequency, s.digestHour(), now)
+		subscription.NextSendAt = &next
+	}
+
+	if err := s.repo.Save(subscription); err != nil {
+		return nil, errors.WrapInternal(err, "Failed to save digest subscription")
+	}
+
+	s.logger.Info("Digest subscription of user %s updated (%s, enabled: %v)", userID, frequency, enabled)
+	return subscription, nil
+}
+
+// DeleteSubscription removes the digest subscription of a user.
+func (s *digestServiceImpl) DeleteSubscription(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.GetSubscription(userID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(userID); err != nil {
+		return errors.WrapInternal(err, "Failed to delete digest subscription")
+	}
+
+	s.logger.Info("Digest subscription of user %s deleted", userID)
+	return nil
+}
+
+// SendDigest sends the digest of the last period to a subscribed user now.
+func (s *digestServiceImpl) SendDigest(userID string) (*models.Digest, error) {
+	if s.mailer == nil {
+		return nil, errors.NewInvalidInput("Email digests are not available: SMTP is not configured")
+	}
+
+	subscription, err := s.GetSubscription(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	digest, err := s.buildDigest(userID, subscription.Frequency, end.Add(-digestPeriod(subscription.Frequency)), end)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to build digest")
+	}
+	if err := s.sendDigest(subscription.Email, digest); err != nil {
+		return nil, errors.WrapInternal(err, fmt.Sprintf("Failed to send digest: %v", err))
+	}
+
+	return digest, nil
+}
+
+// buildDigest summarizes the scans a user started between start and end. Findings are
+// new when they are missing from the latest scan of the same image before the period.
+func (s *digestServiceImpl) buildDigest(userID, frequency string, start, end time.Time) (*models.Digest, error) {
+	digest := &models.Digest{
+		Frequency:   frequency,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Images:      []*models.DigestImage{},
+		FailedScans: []*models.DigestFailedScan{},
+	}
+
+	// Walk the completed scans from newest to oldest: the first scan of an image in the
+	// period is its latest, the first one before the period is the comparison base
+	images := make(map[string]*models.DigestImage)
+	waitingForBase := 0
+	err := s.eachTask(userID, &models.TaskListRequest{
+		Status:  string(models.ScanStatusCompleted),
+		EndDate: end.Format(time.RFC3339Nano),
+	}, func(task *models.TaskSummary) bool {
+		image, seen := images[task.Image]
+		if !task.StartTime.Before(start) {
+			if !seen {
+				image = &models.DigestImage{Image: task.Image, TaskID: task.ID, Summary: task.Summary}
+				images[task.Image] = image
+				digest.Images = append(digest.Images, image)
+				waitingForBase++
+			}
+			image.Scans++
+			return true
+		}
+		if seen && image.BaseTaskID == "" {
+			image.BaseTaskID = task.ID
+			waitingForBase--
+		}
+		return waitingForBase > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, image := range digest.Images {
+		s.addNewFindings(userID, image)
+		digest.NewCritical += image.NewCritical
+		digest.NewHigh += image.NewHigh
+	}
+	sort.SliceStable(digest.Images, func(i, j int) bool {
+		a, b := digest.Images[i], digest.Images[j]
+		if a.NewCritical != b.NewCritical {
+			return a.NewCritical > b.NewCritical
+		}
+		if a.NewHigh != b.NewHigh {
+			return a.NewHigh > b.NewHigh
+		}
+		return a.Image < b.Image
+	})
+
+	err = s.eachTask(userID, &models.TaskListRequest{
+		Status:    string(models.ScanStatusFailed),
+		StartDate: start.Format(time.RFC3339Nano),
+		EndDate:   end.Format(time.RFC3339Nano),
+	}, func(task *models.TaskSummary) bool {
+		failed := &models.DigestFailedScan{TaskID: task.ID, Image: task.Image, StartTime: task.StartTime}
+		if full, err := s.scanService.GetTask(task.ID); err == nil && full != nil {
+			failed.Error = full.ErrorOutput
+			if len(failed.Error) > maxDigestErrorLength {
+				failed.Error = failed.Error[:maxDigestErrorLength] + "..."
+			}
+		}
+		digest.FailedScans = append(digest.FailedScans, failed)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return digest, nil
+}
+
+// eachTask calls fn for the tasks of a user matching the filter, newest first,
+// until fn returns false or all tasks were visited.
+func (s *digestServiceImpl) eachTask(userID string, filter *models.TaskListRequest, fn func(*models.TaskSummary) bool) error {
+	filter.PageSize = digestPageSize
+	filter.SortBy = "startTime"
+	filter.SortOrder = "desc"
+
+	for page := 1; ; page++ {
+		filter.Page = page
+		resp, err := s.scanService.ListTasks(userID, filter)
+		if err != nil {
+			return err
+		}
+		for _, task := range resp.Tasks {
+			if !fn(task) {
+				return nil
+			}
+		}
+		if page*digestPageSize >= resp.Total {
+			return nil
+		}
+	}
+}
+
+// addNewFindings fills in the new CRITICAL and HIGH vulnerabilities and the report link
+// of an image. Without an earlier scan, all of them are new. Reports without structured
+// findings (non-JSON formats) only carry the summary counts.
+func (s *digestServiceImpl) addNewFindings(userID string, image *models.DigestImage) {
+	// Digests only cover the subscriber's own tasks
+	owner := models.Principal{UserID: userID}
+	if task, err := s.scanService.GetTask(image.TaskID); err == nil && task != nil {
+		image.ReportURL = taskReportURL(s.config.PublicURL, task)
+	}
+
+	var newFindings []models.DiffVulnerability
+	if image.BaseTaskID != "" {
+		diff, err := s.scanService.DiffTasks(owner, image.BaseTaskID, image.TaskID)
+		if err != nil {
+			s.logger.Error("Digest: failed to compare tasks %s and %s: %v", image.BaseTaskID, image.TaskID, err)
+			return
+		}
+		image.NewCritical = diff.Added.Summary.Critical
+		image.NewHigh = diff.Added.Summary.High
+		for _, vuln := range diff.Added.Vulnerabilities {
+			if vuln.Severity == "CRITICAL" || vuln.Severity == "HIGH" {
+				newFindings = append(newFindings, vuln)
+			}
+		}
+	} else {
+		if image.Summary != nil {
+			image.NewCritical = image.Summary.Critical
+			image.NewHigh = image.Summary.High
+		}
+		findings, err := s.scanService.ListFindings(owner, image.TaskID, &models.FindingListRequest{
+			Kind:           models.FindingKindVulnerability,
+			Severity:       "CRITICAL,HIGH",
+			HideSuppressed: true,
+			PageSize:       maxDigestFindings,
+		})
+		if err != nil {
+			return
+		}
+		for _, finding := range findings.Findings {
+			vuln := finding.Vulnerability
+			newFindings = append(newFindings, models.DiffVulnerability{
+				VulnerabilityID:  vuln.VulnerabilityID,
+				PkgName:          vuln.PkgName,
+				InstalledVersion: vuln.InstalledVersion,
+				FixedVersion:     vuln.FixedVersion,
+				Severity:         vuln.Severity,
+				Title:            vuln.Title,
+				Target:           finding.Target,
+			})
+		}
+	}
+
+	if len(newFindings) > maxDigestFindings {
+		newFindings = newFindings[:maxDigestFindings]
+	}
+	image.NewFindings = newFindings
+	image.NewFindingsMore = image.NewCritical + image.NewHigh - len(newFindings)
+	if image.NewFindingsMore < 0 {
+		image.NewFindingsMore = 0
+	}
+}
+
+// sendDigest renders a digest and mails it to the recipient.
+func (s *digestServiceImpl) sendDigest(to string, digest *models.Digest) error {
+	msg, err := renderDigest(digest, s.config.PublicURL)
+	if err != nil {
+		return err
+	}
+	msg.To = to
+	return s.mailer.Send(msg)
+}
+
+// Start starts the digest worker. Digests missed while the server was down
+// are sent on the first check.
+func (s *digestServiceImpl) Start() {
+	if s.mailer == nil {
+		s.logger.Info("Email digests disabled (SMTP not configured)")
+		return
+	}
+
+	s.logger.Info("Starting digest worker (send hour: %02d:00)", s.digestHour())
+	s.wg.Add(1)
+	go s.digestWorker()
+}
+
+// Stop stops the digest worker.
+func (s *digestServiceImpl) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+// digestWorker sends due digests.
+func (s *digestServiceImpl) digestWorker() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			s.sendDueDigests(now)
+		}
+	}
+}
+
+// sendDueDigests sends the digest of every enabled subscription whose next send is due.
+// A digest covers the period since the last digest, at most one period. Digests without
+// any scans are skipped. A failed send is not retried before the next send time.
+func (s *digestServiceImpl) sendDueDigests(now time.Time) {
+	subscriptions, err := s.repo.ListAll()
+	if err != nil {
+		s.logger.Error("Failed to list digest subscriptions: %v", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.Enabled || subscription.NextSendAt == nil || subscription.NextSendAt.After(now) {
+			continue
+		}
+
+		start := now.Add(-digestPeriod(subscription.Frequency))
+		if subscription.LastSentAt != nil && subscription.LastSentAt.After(start) {
+			start = *subscription.LastSentAt
+		}
+
+		sendErr := ""
+		digest, err := s.buildDigest(subscription.UserID, subscription.Frequency, start, now)
+		if err != nil {
+			sendErr = fmt.Sprintf("failed to build digest: %v", err)
+		} else if len(digest.Images) == 0 && len(digest.FailedScans) == 0 {
+			s.logger.Info("Digest of user %s skipped: no scans since %s", subscription.UserID, start.Format(time.RFC3339))
+		} else if err := s.sendDigest(subscription.Email, digest); err != nil {
+			sendErr = err.Error()
+		} else {
+			s.logger.Info("Digest sent to user %s (%d images, %d failed scans)", subscription.UserID, len(digest.Images), len(digest.FailedScans))
+		}
+		if sendErr != "" {
+			s.logger.Error("Digest of user %s failed: %s", subscription.UserID, sendErr)
+		}
+
+		s.recordSend(subscription.UserID, now, sendErr)
+	}
+}
+
+// recordSend stores the outcome of a scheduled digest and schedules the next one.
+// Subscriptions changed or deleted while the digest was sent are left alone.
+func (s *digestServiceImpl) recordSend(userID string, now time.Time, sendErr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, err := s.repo.Get(userID)
+	if err != nil || subscription == nil || !subscription.Enabled || subscription.NextSendAt == nil || subscription.NextSendAt.After(now) {
+		return
+	}
+
+	if sendErr == "" {
+		sentAt := now
+		subscription.LastSentAt = &sentAt
+	}
+	subscription.LastError = sendErr
+	next := nextDigestTime(subscription.Frequency, s.digestHour(), now)
+	subscription.NextSendAt = &next
+
+	if err := s.repo.Save(subscription); err != nil {
+		s.logger.Error("Failed to update digest subscription of user %s: %v", userID, err)
+	}
+}
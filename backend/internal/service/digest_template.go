@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides the email templates of digests.
+package service
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// digestTemplateData is passed to the digest templates.
+type digestTemplateData struct {
+	*models.Digest
+	Title     string // Digest title (also the subject prefix)
+	PublicURL string // Link to the web UI (optional)
+}
+
+// digestTemplateFuncs are the helper functions available in digest templates.
+var digestTemplateFuncs = map[string]interface{}{
+	"datetime": func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+}
+
+// digestTextTemplate is the plaintext body of a digest.
+var digestTextTemplate = texttemplate.Must(texttemplate.New("digest.txt").Funcs(digestTemplateFuncs).Parse(
+	`{{.Title}}
+Period: {{datetime .PeriodStart}} - {{datetime .PeriodEnd}}
+
+New vulnerabilities: {{.NewCritical}} CRITICAL, {{.NewHigh}} HIGH
+Images scanned: {{len .Images}}
+Failed scans: {{len .FailedScans}}
+{{range .Images}}
+== {{.Image}} ==
+Scans: {{.Scans}}{{with .Summary}}, latest: {{.Total}} vulnerabilities ({{.Critical}} CRITICAL, {{.High}} HIGH, {{.Medium}} MEDIUM, {{.Low}} LOW){{end}}
+New: {{.NewCritical}} CRITICAL, {{.NewHigh}} HIGH{{if not .BaseTaskID}} (first scan){{end}}
+{{range .NewFindings}}  - [{{.Severity}}] {{.VulnerabilityID}} {{.PkgName}} {{.InstalledVersion}}{{if .FixedVersion}} (fixed in {{.FixedVersion}}){{end}}
+{{end}}{{if .NewFindingsMore}}  ... and {{.NewFindingsMore}} more
+{{end}}{{if .ReportURL}}Report: {{.ReportURL}}
+{{end}}{{end}}{{if .FailedScans}}
+== Failed scans ==
+{{range .FailedScans}}- {{.Image}} at {{datetime .StartTime}} (task {{.TaskID}}){{if .Error}}
+  {{.Error}}{{end}}
+{{end}}{{end}}{{if .PublicURL}}
+Open Trivy Web UI: {{.PublicURL}}
+{{end}}`))
+
+// digestHTMLTemplate is the HTML body of a digest.
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(digestTemplateFuncs).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif; color: #262626; font-size: 14px;">
+<h2 style="margin-bottom: 4px;">{{.Title}}</h2>
+<p style="color: #8c8c8c; margin-top: 0;">{{datetime .PeriodStart}} - {{datetime .PeriodEnd}}</p>
+<table cellpadding="8" style="border-collapse: collapse; margin-bottom: 16px;">
+<tr>
+<td style="background: #fff1f0; color: #cf1322;"><strong>{{.NewCritical}}</strong> new CRITICAL</td>
+<td style="background: #fff7e6; color: #d46b08;"><strong>{{.NewHigh}}</strong> new HIGH</td>
+<td style="background: #f5f5f5;"><strong>{{len .Images}}</strong> images scanned</td>
+<td style="background: #f5f5f5;"><strong>{{len .FailedScans}}</strong> failed scans</td>
+</tr>
+</table>
+{{range .Images}}
+<h3 style="margin-bottom: 4px;"><code>{{.Image}}</code></h3>
+<p style="margin-top: 0;">
+{{.Scans}} scan(s){{with .Summary}}, latest: {{.Total}} vulnerabilities ({{.Critical}} CRITICAL, {{.High}} HIGH, {{.Medium}} MEDIUM, {{.Low}} LOW){{end}}<br>
+New: <strong>{{.NewCritical}}</strong> CRITICAL, <strong>{{.NewHigh}}</strong> HIGH{{if not .BaseTaskID}} (first scan){{end}}
+{{if .ReportURL}} &middot; <a href="{{.ReportURL}}">View report</a>{{end}}
+</p>
+{{if .NewFindings}}
+<table cellpadding="4" style="border-collapse: collapse; border: 1px solid #f0f0f0;">
+<tr style="background: #fafafa; text-align: left;"><th>Severity</th><th>Vulnerability</th><th>Package</th><th>Installed</th><th>Fixed</th></tr>
+{{range .NewFindings}}<tr style="border-top: 1px solid #f0f0f0;">
+<td style="color: {{if eq .Severity "CRITICAL"}}#cf1322{{else}}#d46b08{{end}};">{{.Severity}}</td>
+<td>{{.VulnerabilityID}}</td><td>{{.PkgName}}</td><td>{{.InstalledVersion}}</td><td>{{.FixedVersion}}</td>
+</tr>
+{{end}}</table>
+{{if .NewFindingsMore}}<p style="color: #8c8c8c;">... and {{.NewFindingsMore}} more</p>{{end}}
+{{end}}
+{{end}}
+{{if .FailedScans}}
+<h3>Failed scans</h3>
+<ul>
+{{range .FailedScans}}<li><code>{{.Image}}</code> at {{datetime .StartTime}} (task {{.TaskID}}){{if .Error}}<br><span style="color: #cf1322;">{{.Error}}</span>{{end}}</li>
+{{end}}</ul>
+{{end}}
+{{if .PublicURL}}<p><a href="{{.PublicURL}}">Open Trivy Web UI</a></p>{{end}}
+</body>
+</html>
+`))
+
+// renderDigest renders the subject and the plaintext and HTML bodies of a digest.
+func renderDigest(digest *models.Digest, publicURL string) (*EmailMessage, error) {
+	title := "Trivy daily digest"
+	if digest.Frequency == models.DigestFrequencyWeekly {
+		title = "Trivy weekly digest"
+	}
+	data := &digestTemplateData{
+		Digest:    digest,
+		Title:     title,
+		PublicURL: strings.TrimRight(publicURL, "/"),
+	}
+
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render digest: %w", err)
+	}
+	if err := digestHTMLTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	subject := fmt.Sprintf("%s: %d images scanned, %d new critical, %d new high, %d failed scans",
+		title, len(digest.Images), digest.NewCritical, digest.NewHigh, len(digest.FailedScans))
+
+	return &EmailMessage{
+		Subject: subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,341 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+// smtpStandIn is a minimal SMTP server recording the messages it receives.
+type smtpStandIn struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []string
+}
+
+// newSMTPStandIn starts an SMTP stand-in on a random local port.
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := &smtpStandIn{listener: listener}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+// config returns an SMTP configuration pointing at the stand-in.
+func (s *smtpStandIn) config() *types.SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return &types.SMTPConfig{Host: host, Port: portNumber, From: "Trivy <trivy@example.com>", TLS: "none", Enabled: true}
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.Fields(line + " x")[0])
+		switch command {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// received returns the messages received so far.
+func (s *smtpStandIn) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+// parseEmail decodes a received message into its subject and plaintext and HTML bodies.
+func parseEmail(t *testing.T, raw string) (subject, text, html string) {
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	subject, _ = new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("Failed to parse content type: %v", err)
+	}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+		// The multipart reader decodes quoted-printable parts
+		body, _ := io.ReadAll(part)
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/html") {
+			html = string(body)
+		} else {
+			text = string(body)
+		}
+	}
+	return subject, text, html
+}
+
+// TestSMTPMailer tests sending a multipart message to an SMTP server
+func TestSMTPMailer(t *testing.T) {
+	server := newSMTPStandIn(t)
+
+	mailer := NewSMTPMailer(server.config())
+	err := mailer.Send(&EmailMessage{
+		To:      "lead@example.com",
+		Subject: "Trivy digest – 漏洞",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+	})
+	if err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	messages := server.received()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(messages))
+	}
+	subject, text, html := parseEmail(t, messages[0])
+	if subject != "Trivy digest – 漏洞" || text != "plain body" || html != "<p>html body</p>" {
+		t.Errorf("Unexpected message: subject=%q text=%q html=%q", subject, text, html)
+	}
+
+	// STARTTLS is required by default
+	config := server.config()
+	config.TLS = "starttls"
+	if err := NewSMTPMailer(config).Send(&EmailMessage{To: "lead@example.com"}); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("Expected STARTTLS error, got %v", err)
+	}
+}
+
+// sequenceExecutor returns the given outputs in order; an empty output fails the scan.
+type sequenceExecutor struct {
+	mu      sync.Mutex
+	outputs []string
+}
+
+func (m *sequenceExecutor) ExecuteCommand(ctx context.Context, name string, args []string, logCallback func(string)) (string, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	output := m.outputs[0]
+	m.outputs = m.outputs[1:]
+	if output == "" {
+		return "", "registry unreachable", fmt.Errorf("exit status 1")
+	}
+	return output, "", nil
+}
+
+// trivyOutput builds a trivy JSON report with the given vulnerabilities (ID:SEVERITY).
+func trivyOutput(vulns ...string) string {
+	var list []map[string]interface{}
+	for _, vuln := range vulns {
+		parts := strings.Split(vuln, ":")
+		list = append(list, map[string]interface{}{"VulnerabilityID": parts[0], "PkgName": "openssl", "InstalledVersion": "3.1.0", "Severity": parts[1]})
+	}
+	data, _ := json.Marshal(map[string]interface{}{"Results": []map[string]interface{}{{"Target": "alpine", "Vulnerabilities": list}}})
+	return string(data)
+}
+
+// TestDigest tests building and sending digests
+func TestDigest(t *testing.T) {
+	executor := &sequenceExecutor{outputs: []string{
+		trivyOutput("CVE-2023-0001:HIGH"),
+		trivyOutput("CVE-2023-0001:HIGH", "CVE-2023-0002:CRITICAL", "CVE-2023-0003:LOW"),
+		trivyOutput("CVE-2023-0004:HIGH"),
+		"",
+	}}
+	scanService := NewScanServiceWithExecutor(repository.NewInMemoryScanRepository(), &types.TrivyConfig{Timeout: 600, MaxWorkers: 1},
+		t.TempDir(), &mockLogger{}, executor)
+	defer scanService.Stop()
+
+	runScan := func(image string) *models.ScanTask {
+		task, err := scanService.CreateScanTask("user1", &models.ScanRequest{Image: image})
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		return waitForTask(t, scanService, task.ID)
+	}
+
+	base := runScan("alpine:3.19")
+	time.Sleep(10 * time.Millisecond)
+	periodStart := time.Now()
+	runScan("alpine:3.19")
+	runScan("nginx:1.25")
+	failed := runScan("private/app:1.0")
+	if failed.Status != models.ScanStatusFailed {
+		t.Fatalf("Expected the last scan to fail, got %s", failed.Status)
+	}
+
+	server := newSMTPStandIn(t)
+	digestRepo, _ := repository.NewFileDigestRepository(t.TempDir())
+	config := &types.NotificationConfig{PublicURL: "https://trivy.example.com", DigestHour: 8}
+	service := NewDigestService(digestRepo, scanService, NewSMTPMailer(server.config()), config, &mockLogger{}).(*digestServiceImpl)
+
+	digest, err := service.buildDigest("user1", models.DigestFrequencyDaily, periodStart, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to build digest: %v", err)
+	}
+	if len(digest.Images) != 2 || len(digest.FailedScans) != 1 || digest.NewCritical != 1 || digest.NewHigh != 1 {
+		t.Fatalf("Unexpected digest: %d images, %d failed, %d critical, %d high",
+			len(digest.Images), len(digest.FailedScans), digest.NewCritical, digest.NewHigh)
+	}
+	// Findings are compared with the scan before the period, images with new CRITICAL findings come first
+	alpine := digest.Images[0]
+	if alpine.Image != "alpine:3.19" || alpine.BaseTaskID != base.ID || alpine.NewCritical != 1 || alpine.NewHigh != 0 ||
+		len(alpine.NewFindings) != 1 || alpine.NewFindings[0].VulnerabilityID != "CVE-2023-0002" {
+		t.Errorf("Unexpected alpine entry: %+v", alpine)
+	}
+	if nginx := digest.Images[1]; nginx.BaseTaskID != "" || nginx.NewHigh != 1 || len(nginx.NewFindings) != 1 {
+		t.Errorf("Expected all findings of a first scan to be new, got %+v", nginx)
+	}
+	if digest.FailedScans[0].Error == "" {
+		t.Error("Expected the error of the failed scan")
+	}
+
+	// Subscribing schedules the next digest at the configured hour
+	subscription, err := service.UpdateSubscription("user1", &models.DigestSubscriptionRequest{Email: "Lead <lead@example.com>"})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	if subscription.Email != "lead@example.com" || subscription.NextSendAt == nil || subscription.NextSendAt.Hour() != 8 {
+		t.Errorf("Unexpected subscription: %+v", subscription)
+	}
+	if _, err := service.UpdateSubscription("user1", &models.DigestSubscriptionRequest{Email: "lead@example.com", Frequency: "hourly"}); err == nil {
+		t.Error("Expected error for unsupported frequency")
+	}
+
+	// Due digests cover the period since the last digest and schedule the next one
+	now := time.Now()
+	service.sendDueDigests(subscription.NextSendAt.Add(-time.Minute))
+	if len(server.received()) != 0 {
+		t.Fatal("Expected no digest before the send time")
+	}
+	past := now.Add(-time.Minute)
+	subscription.NextSendAt = &past
+	subscription.LastSentAt = &periodStart
+	digestRepo.Save(subscription)
+	service.sendDueDigests(now)
+
+	messages := server.received()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 digest, got %d", len(messages))
+	}
+	subject, text, html := parseEmail(t, messages[0])
+	if !strings.Contains(subject, "2 images scanned, 1 new critical, 1 new high, 1 failed scans") {
+		t.Errorf("Unexpected subject: %s", subject)
+	}
+	reportURL := "https://trivy.example.com/api/v1/scan/" + alpine.TaskID + "/report/json"
+	for _, body := range []string{text, html} {
+		for _, want := range []string{"CVE-2023-0002", "private/app:1.0", reportURL} {
+			if !strings.Contains(body, want) {
+				t.Errorf("Expected %q in body:\n%s", want, body)
+			}
+		}
+		if strings.Contains(body, "CVE-2023-0001") || strings.Contains(body, "CVE-2023-0003") {
+			t.Errorf("Expected only new CRITICAL/HIGH findings in body:\n%s", body)
+		}
+	}
+
+	updated, _ := service.GetSubscription("user1")
+	if updated.LastSentAt == nil || !updated.LastSentAt.Equal(now) || !updated.NextSendAt.After(now) || updated.LastError != "" {
+		t.Errorf("Unexpected subscription after sending: %+v", updated)
+	}
+
+	// A manual send does not move the schedule
+	if _, err := service.SendDigest("user1"); err != nil {
+		t.Fatalf("Failed to send digest: %v", err)
+	}
+	if len(server.received()) != 2 {
+		t.Errorf("Expected 2 digests, got %d", len(server.received()))
+	}
+	if again, _ := service.GetSubscription("user1"); !again.NextSendAt.Equal(*updated.NextSendAt) {
+		t.Error("Expected the next send time to stay unchanged")
+	}
+}
+
+// TestNextDigestTime tests scheduling daily and weekly digests
+func TestNextDigestTime(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 10, 15, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		frequency string
+		hour      int
+		want      time.Time
+	}{
+		{models.DigestFrequencyDaily, 8, time.Date(2025, 10, 16, 8, 0, 0, 0, time.UTC)},
+		{models.DigestFrequencyDaily, 18, time.Date(2025, 10, 15, 18, 0, 0, 0, time.UTC)},
+		{models.DigestFrequencyWeekly, 8, time.Date(2025, 10, 20, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := nextDigestTime(tt.frequency, tt.hour, now); !got.Equal(tt.want) {
+			t.Errorf("nextDigestTime(%s, %d) = %s, want %s", tt.frequency, tt.hour, got, tt.want)
+		}
+	}
+}
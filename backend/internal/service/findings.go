@@ -0,0 +1,299 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for structured scan findings.
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+)
+
+const (
+	// findingsExtension is the report file extension of a task's parsed findings.
+	findingsExtension = "findings.json"
+
+	// maxFindingsPageSize limits the page size of the findings endpoint.
+	maxFindingsPageSize = 500
+)
+
+// severityRank orders severities from most to least severe.
+var severityRank = map[string]int{
+	"CRITICAL": 0,
+	"HIGH":     1,
+	"MEDIUM":   2,
+	"LOW":      3,
+}
+
+// severityOrder returns the sort rank of a severity; unknown severities sort last.
+func severityOrder(severity string) int {
+	if rank, ok := severityRank[severity]; ok {
+		return rank
+	}
+	return len(severityRank)
+}
+
+// parseTrivyReport decodes Trivy JSON output into the typed report model.
+func parseTrivyReport(jsonOutput string) (*models.TrivyReport, error) {
+	var report models.TrivyReport
+	if err := json.Unmarshal([]byte(jsonOutput), &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// findingsKey returns the report store key of a task's parsed findings, stored next to its reports.
+func (s *scanServiceImpl) findingsKey(task *models.ScanTask) string {
+	return repository.ReportKey(task.UserID, task.ID, findingsExtension)
+}
+
+// saveFindings stores the parsed report of a task.
+func (s *scanServiceImpl) saveFindings(task *models.ScanTask, report *models.TrivyReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal findings: %w", err)
+	}
+
+	// Stores never expose partially written reports to concurrent readers
+	if err := s.reportStore.Put(s.findingsKey(task), bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("failed to save findings: %w", err)
+	}
+
+	return nil
+}
+
+// readReport reads a whole report from the report store.
+func (s *scanServiceImpl) readReport(key string) ([]byte, error) {
+	rc, _, err := s.reportStore.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+// loadFindings returns the parsed report of a completed JSON-format task.
+// Tasks completed before findings were stored are parsed from their JSON report
+// (or the output kept with the task) and the findings are stored for next time.
+func (s *scanServiceImpl) loadFindings(task *models.ScanTask) (*models.TrivyReport, error) {
+	if task.Status != models.ScanStatusCompleted {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Task %s has not completed", task.ID))
+	}
+	if task.ScanConfig == nil || task.ScanConfig.Format != "json" {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Task %s has no JSON report", task.ID))
+	}
+
+	if data, err := s.readReport(s.findingsKey(task)); err == nil {
+		var report models.TrivyReport
+		if err := json.Unmarshal(data, &report); err == nil {
+			return &report, nil
+		}
+		s.logger.Error("Failed to parse stored findings of task %s, parsing report again", task.ID)
+	}
+
+	data, err := s.readReport(repository.ReportKey(task.UserID, task.ID, "json"))
+	if err != nil {
+		// Fall back to the output stored with the task
+		if task.Result == nil || task.Result.Data == "" {
+			return nil, errors.NewInvalidInput(fmt.Sprintf("Report of task %s not found", task.ID))
+		}
+		data = []byte(task.Result.Data)
+	}
+
+	report, err := parseTrivyReport(string(data))
+	if err != nil {
+		return nil, errors.WrapInternal(err, fmt.Sprintf("Failed to parse report of task %s", task.ID))
+	}
+	if err := s.saveFindings(task, report); err != nil {
+		s.logger.Error("Failed to store findings of task %s: %v", task.ID, err)
+	}
+
+	return report, nil
+}
+
+// ListFindings returns a filtered page of the findings of a task the principal can read.
+func (s *scanServiceImpl) ListFindings(principal models.Principal, taskID string, req *models.FindingListRequest) (*models.FindingListResponse, error) {
+	switch req.Kind {
+	case models.FindingKindVulnerability, models.FindingKindMisconfiguration, models.FindingKindSecret, models.FindingKindLicense:
+	default:
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Unsupported finding kind: %s", req.Kind))
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = 50
+	}
+	if req.PageSize > maxFindingsPageSize {
+		req.PageSize = maxFindingsPageSize
+	}
+
+	task, err := authorizeTask(s.repo, principal, taskID, models.SharePermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.loadFindings(task)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := filterFindings(report, req)
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityOrder(findingSeverity(findings[i])) < severityOrder(findingSeverity(findings[j]))
+	})
+
+	response := &models.FindingListResponse{
+		TaskID:       task.ID,
+		ArtifactName: report.ArtifactName,
+		Kind:         req.Kind,
+		Targets:      make([]string, 0, len(report.Results)),
+		Total:        len(findings),
+		Page:         req.Page,
+		PageSize:     req.PageSize,
+		Findings:     []models.Finding{},
+	}
+	for _, result := range report.Results {
+		response.Targets = append(response.Targets, result.Target)
+	}
+
+	start := (req.Page - 1) * req.PageSize
+	if start < len(findings) {
+		end := start + req.PageSize
+		if end > len(findings) {
+			end = len(findings)
+		}
+		response.Findings = findings[start:end]
+	}
+
+	return response, nil
+}
+
+// filterFindings flattens the findings of the requested kind and applies the request filters.
+func filterFindings(report *models.TrivyReport, req *models.FindingListRequest) []models.Finding {
+	severities := make(map[string]bool)
+	for _, severity := range strings.Split(req.Severity, ",") {
+		if severity = strings.ToUpper(strings.TrimSpace(severity)); severity != "" {
+			severities[severity] = true
+		}
+	}
+	search := strings.ToLower(strings.TrimSpace(req.Search))
+
+	// matches applies the filters shared by all kinds
+	matches := func(severity, status string, fields ...string) bool {
+		if len(severities) > 0 && !severities[severity] {
+			return false
+		}
+		if req.Status != "" && !strings.EqualFold(req.Status, status) {
+			return false
+		}
+		if search == "" {
+			return true
+		}
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), search) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var findings []models.Finding
+	for i := range report.Results {
+		result := &report.Results[i]
+		if req.Target != "" && result.Target != req.Target {
+			continue
+		}
+		base := models.Finding{Target: result.Target, Class: result.Class, Type: result.Type}
+
+		switch req.Kind {
+		case models.FindingKindVulnerability:
+			for j := range result.Vulnerabilities {
+				vuln := &result.Vulnerabilities[j]
+				if req.FixAvailable && vuln.FixedVersion == "" {
+					continue
+				}
+				if matches(vuln.Severity, vuln.Status, vuln.VulnerabilityID, vuln.PkgName, vuln.Title) {
+					finding := base
+					finding.Vulnerability = vuln
+					findings = append(findings, finding)
+				}
+			}
+			if req.HideSuppressed {
+				continue
+			}
+			for _, modified := range result.ModifiedFindings {
+				if modified.Type != models.FindingKindVulnerability {
+					continue
+				}
+				var vuln models.TrivyVulnerability
+				if err := json.Unmarshal(modified.Finding, &vuln); err != nil {
+					continue
+				}
+				if req.FixAvailable && vuln.FixedVersion == "" {
+					continue
+				}
+				if matches(vuln.Severity, vuln.Status, vuln.VulnerabilityID, vuln.PkgName, vuln.Title) {
+					finding := base
+					finding.Vulnerability = &vuln
+					finding.Suppressed = true
+					finding.Justification = modified.Statement
+					findings = append(findings, finding)
+				}
+			}
+		case models.FindingKindMisconfiguration:
+			for j := range result.Misconfigurations {
+				misconf := &result.Misconfigurations[j]
+				if matches(misconf.Severity, misconf.Status, misconf.ID, misconf.AVDID, misconf.Title, misconf.Message) {
+					finding := base
+					finding.Misconfiguration = misconf
+					findings = append(findings, finding)
+				}
+			}
+		case models.FindingKindSecret:
+			for j := range result.Secrets {
+				secret := &result.Secrets[j]
+				if matches(secret.Severity, "", secret.RuleID, secret.Category, secret.Title, result.Target) {
+					finding := base
+					finding.Secret = secret
+					findings = append(findings, finding)
+				}
+			}
+		case models.FindingKindLicense:
+			for j := range result.Licenses {
+				license := &result.Licenses[j]
+				if matches(license.Severity, "", license.Name, license.Category, license.PkgName, license.FilePath) {
+					finding := base
+					finding.License = license
+					findings = append(findings, finding)
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+// findingSeverity returns the severity of a finding of any kind.
+func findingSeverity(finding models.Finding) string {
+	switch {
+	case finding.Vulnerability != nil:
+		return finding.Vulnerability.Severity
+	case finding.Misconfiguration != nil:
+		return finding.Misconfiguration.Severity
+	case finding.Secret != nil:
+		return finding.Secret.Severity
+	case finding.License != nil:
+		return finding.License.Severity
+	}
+	return ""
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for scan history export.
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// Supported scan history export formats and their MIME types.
+var historyExportMimeTypes = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"json": "application/json",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// historyColumns defines the exported columns, in order.
+// Numeric columns are written as numbers in XLSX output.
+var historyColumns = []struct {
+	header  string
+	numeric bool
+}{
+	{"Task ID", false},
+	{"Image", false},
+	{"Status", false},
+	{"Start Time", false},
+	{"End Time", false},
+	{"Duration (s)", true},
+	{"Total", true},
+	{"Critical", true},
+	{"High", true},
+	{"Medium", true},
+	{"Low", true},
+	{"Unknown", true},
+	{"Misconfigurations", true},
+	{"Secrets", true},
+	{"Licenses", true},
+	{"Trivy Version", false},
+	{"Vulnerability DB Version", false},
+	{"Vulnerability DB Updated At", false},
+}
+
+// historyRecord represents one exported scan history row.
+type historyRecord struct {
+	ID              string     `json:"id"`
+	Image           string     `json:"image"`
+	Status          string     `json:"status"`
+	StartTime       time.Time  `json:"startTime"`
+	EndTime         *time.Time `json:"endTime,omitempty"`
+	DurationSeconds *float64   `json:"durationSeconds,omitempty"`
+	Total           int        `json:"total"`
+	Critical        int        `json:"critical"`
+	High            int        `json:"high"`
+	Medium          int        `json:"medium"`
+	Low             int        `json:"low"`
+	Unknown         int        `json:"unknown"`
+	Misconfigs      int        `json:"misconfigurations"`
+	Secrets         int        `json:"secrets"`
+	Licenses        int        `json:"licenses"`
+	TrivyVersion    string     `json:"trivyVersion,omitempty"`
+	DBVersion       string     `json:"vulnerabilityDbVersion,omitempty"`
+	DBUpdatedAt     *time.Time `json:"vulnerabilityDbUpdatedAt,omitempty"`
+}
+
+// newHistoryRecord builds an export row from a scan task.
+func newHistoryRecord(task *models.ScanTask) *historyRecord {
+	record := &historyRecord{
+		ID:        task.ID,
+		Image:     task.Image,
+		Status:    string(task.Status),
+		StartTime: task.StartTime,
+		EndTime:   task.EndTime,
+	}
+
+	if task.EndTime != nil {
+		duration := task.EndTime.Sub(task.StartTime).Seconds()
+		record.DurationSeconds = &duration
+	}
+
+	if task.Result != nil && task.Result.Summary != nil {
+		summary := task.Result.Summary
+		record.Total = summary.Total
+		record.Critical = summary.Critical
+		record.High = summary.High
+		record.Medium = summary.Medium
+		record.Low = summary.Low
+		record.Unknown = summary.Unknown
+		record.Misconfigs = summary.Count(models.FindingKindMisconfiguration)
+		record.Secrets = summary.Count(models.FindingKindSecret)
+		record.Licenses = summary.Count(models.FindingKindLicense)
+	}
+
+	if task.TrivyVersion != nil {
+		record.TrivyVersion = task.TrivyVersion.Version
+		if db := task.TrivyVersion.VulnerabilityDB; db != nil {
+			record.DBVersion = strconv.Itoa(db.Version)
+			updatedAt := db.UpdatedAt
+			record.DBUpdatedAt = &updatedAt
+		}
+	}
+
+	return record
+}
+
+// values returns the row values in historyColumns order.
+func (r *historyRecord) values() []string {
+	formatTime := func(t *time.Time) string {
+		if t == nil || t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	duration := ""
+	if r.DurationSeconds != nil {
+		duration = strconv.FormatFloat(*r.DurationSeconds, 'f', 0, 64)
+	}
+
+	return []string{
+		r.ID,
+		r.Image,
+		r.Status,
+		formatTime(&r.StartTime),
+		formatTime(r.EndTime),
+		duration,
+		strconv.Itoa(r.Total),
+		strconv.Itoa(r.Critical),
+		strconv.Itoa(r.High),
+		strconv.Itoa(r.Medium),
+		strconv.Itoa(r.Low),
+		strconv.Itoa(r.Unknown),
+		strconv.Itoa(r.Misconfigs),
+		strconv.Itoa(r.Secrets),
+		strconv.Itoa(r.Licenses),
+		r.TrivyVersion,
+		r.DBVersion,
+		formatTime(r.DBUpdatedAt),
+	}
+}
+
+// encodeTaskHistory encodes scan tasks in the given export format.
+// Returns the encoded data and its MIME type.
+func encodeTaskHistory(format string, tasks []*models.ScanTask) ([]byte, string, error) {
+	mimeType, ok := historyExportMimeTypes[format]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	records := make([]*historyRecord, len(tasks))
+	for i, task := range tasks {
+		records[i] = newHistoryRecord(task)
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case "csv":
+		data, err = encodeHistoryCSV(records)
+	case "json":
+		data, err = json.MarshalIndent(records, "", "  ")
+	case "xlsx":
+		data, err = encodeHistoryXLSX(records)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, mimeType, nil
+}
+
+// encodeHistoryCSV writes the records as CSV with a header row.
+func encodeHistoryCSV(records []*historyRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := make([]string, len(historyColumns))
+	for i, col := range historyColumns {
+		header[i] = col.header
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, record := range records {
+		if err := writer.Write(record.values()); err != nil {
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Static parts of a minimal single-sheet XLSX (Office Open XML) workbook.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Scan History" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+)
+
+// encodeHistoryXLSX writes the records as a single-sheet XLSX workbook.
+// Strings are stored inline so no shared string table is needed.
+func encodeHistoryXLSX(records []*historyRecord) ([]byte, error) {
+	var sheet bytes.Buffer
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]string, len(historyColumns))
+	for i, col := range historyColumns {
+		header[i] = col.header
+	}
+	writeXLSXRow(&sheet, 1, header, false)
+	for i, record := range records {
+		writeXLSXRow(&sheet, i+2, record.values(), true)
+	}
+
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	parts := []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", []byte(xlsxContentTypes)},
+		{"_rels/.rels", []byte(xlsxRootRels)},
+		{"xl/workbook.xml", []byte(xlsxWorkbook)},
+		{"xl/_rels/workbook.xml.rels", []byte(xlsxWorkbookRels)},
+		{"xl/worksheets/sheet1.xml", sheet.Bytes()},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", part.name, err)
+		}
+		if _, err := w.Write(part.content); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize workbook: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeXLSXRow appends a worksheet row. When typed is true, numeric columns
+// are written as number cells; everything else is an inline string.
+func writeXLSXRow(buf *bytes.Buffer, rowNum int, values []string, typed bool) {
+	fmt.Fprintf(buf, `<row r="%d">`, rowNum)
+	for i, value := range values {
+		ref := xlsxColumnName(i) + strconv.Itoa(rowNum)
+		if value == "" {
+			continue
+		}
+		if typed && historyColumns[i].numeric {
+			fmt.Fprintf(buf, `<c r="%s"><v>%s</v></c>`, ref, value)
+			continue
+		}
+		fmt.Fprintf(buf, `<c r="%s" t="inlineStr"><is><t>`, ref)
+		xml.EscapeText(buf, []byte(value))
+		buf.WriteString(`</t></is></c>`)
+	}
+	buf.WriteString(`</row>`)
+}
+
+// xlsxColumnName converts a zero-based column index to a spreadsheet column name (A, B, ..., AA).
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
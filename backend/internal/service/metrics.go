@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides the Prometheus metrics of the scan and report services.
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/metrics"
+)
+
+// maxMetricImages limits the images exposed by trivy_image_vulnerabilities.
+// Beyond it, the image whose latest scan is the oldest is dropped.
+const maxMetricImages = 200
+
+var (
+	// scanDurationSeconds observes the run time of completed scans (queue wait excluded).
+	scanDurationSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "trivy_scan_duration_seconds",
+		Help:    "Run time of completed scans in seconds.",
+		Buckets: []float64{5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	})
+
+	// scansTotal counts finished scans by status (completed, failed, cancelled).
+	scansTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "trivy_scans_total",
+		Help: "Finished scans by status.",
+	}, []string{"status"})
+
+	// trivyFailuresTotal counts failed Trivy executions by reason (timeout, error).
+	trivyFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "trivy_execution_failures_total",
+		Help: "Failed Trivy executions by reason (timeout or error).",
+	}, []string{"reason"})
+
+	// reportConversionSeconds observes trivy convert runs by target format.
+	reportConversionSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "trivy_report_conversion_duration_seconds",
+		Help:    "Latency of report conversions (trivy convert) in seconds.",
+		Buckets: []float64{.1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"format"})
+
+	// imageVulnerabilities holds the vulnerability counts of the latest completed scan of each image.
+	imageVulnerabilities = newImageVulnerabilityGauge()
+)
+
+func init() {
+	metrics.Register(scanDurationSeconds, scansTotal, trivyFailuresTotal, reportConversionSeconds, imageVulnerabilities.gauge)
+}
+
+// registerMetrics registers the gauges computed from the state of the scan service
+// when the metrics are scraped, and records the stored scan results in the image
+// vulnerability gauge, which is updated as scans complete afterwards.
+func (s *scanServiceImpl) registerMetrics() {
+	workers := func(state string, count func() int) prometheus.GaugeFunc {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "trivy_scan_workers",
+			Help:        "Number of busy and idle scan workers.",
+			ConstLabels: prometheus.Labels{"state": state},
+		}, func() float64 { return float64(count()) })
+	}
+	busy := func() int {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.runningScans)
+	}
+
+	metrics.Register(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "trivy_scan_queue_length",
+			Help: "Number of queued scans.",
+		}, func() float64 {
+			queued, err := s.repo.GetAllQueuedTasks()
+			if err != nil {
+				s.logger.Error("Failed to load queued tasks for metrics: %v", err)
+			}
+			return float64(len(queued))
+		}),
+		workers("busy", busy),
+		workers("idle", func() int { return cap(s.workerPool) - busy() }),
+	)
+
+	// Every finished task ended before now
+	tasks, err := s.repo.GetAllOldTasks(time.Now())
+	if err != nil {
+		s.logger.Error("Failed to load tasks for metrics: %v", err)
+		return
+	}
+	for _, task := range tasks {
+		imageVulnerabilities.record(task)
+	}
+}
+
+// imageVulnerabilityGauge is the trivy_image_vulnerabilities gauge, limited to the
+// maxMetricImages most recently scanned images.
+type imageVulnerabilityGauge struct {
+	gauge   *prometheus.GaugeVec
+	scanned map[string]time.Time // Image -> end time of its recorded scan
+	mu      sync.Mutex
+}
+
+// newImageVulnerabilityGauge creates an empty image vulnerability gauge.
+func newImageVulnerabilityGauge() *imageVulnerabilityGauge {
+	return &imageVulnerabilityGauge{
+		gauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "trivy_image_vulnerabilities",
+			Help: "Vulnerabilities found by the latest completed scan of each image, by severity.",
+		}, []string{"image", "severity"}),
+		scanned: make(map[string]time.Time),
+	}
+}
+
+// record sets the counts of a completed scan, unless a later scan of the image is recorded.
+func (g *imageVulnerabilityGauge) record(task *models.ScanTask) {
+	if task.Status != models.ScanStatusCompleted || task.Result == nil || task.Result.Summary == nil || task.EndTime == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if scanned, ok := g.scanned[task.Image]; ok && scanned.After(*task.EndTime) {
+		return
+	}
+	g.scanned[task.Image] = *task.EndTime
+
+	summary := task.Result.Summary
+	for severity, count := range map[string]int{
+		"CRITICAL": summary.Critical,
+		"HIGH":     summary.High,
+		"MEDIUM":   summary.Medium,
+		"LOW":      summary.Low,
+		"UNKNOWN":  summary.Unknown,
+	} {
+		g.gauge.WithLabelValues(task.Image, severity).Set(float64(count))
+	}
+
+	if len(g.scanned) > maxMetricImages {
+		oldest := task.Image
+		for image, scanned := range g.scanned {
+			if scanned.Before(g.scanned[oldest]) {
+				oldest = image
+			}
+		}
+		delete(g.scanned, oldest)
+		g.gauge.DeletePartialMatch(prometheus.Labels{"image": oldest})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/metrics"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+// scrapeMetrics returns the metrics registry in the text exposition format.
+func scrapeMetrics(t *testing.T) string {
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	return recorder.Body.String()
+}
+
+// TestScanMetrics tests the worker pool, scan outcome and vulnerability metrics
+func TestScanMetrics(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	config := &types.TrivyConfig{
+		Timeout:    600,
+		MaxWorkers: 1,
+	}
+	executor := &blockingCommandExecutor{release: make(chan struct{})}
+	service := NewScanServiceWithExecutor(repo, config, t.TempDir(), &mockLogger{}, executor).(*scanServiceImpl)
+	service.registerMetrics()
+
+	var tasks []*models.ScanTask
+	for _, image := range []string{"nginx:1.25", "redis:7"} {
+		task, err := service.CreateScanTask("alice", &models.ScanRequest{Image: image})
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	// One scan occupies the only worker, the other one waits in the queue
+	output := scrapeMetrics(t)
+	for _, line := range []string{
+		"trivy_scan_queue_length 1\n",
+		`trivy_scan_workers{state="busy"} 1` + "\n",
+		`trivy_scan_workers{state="idle"} 0` + "\n",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, output)
+		}
+	}
+
+	close(executor.release)
+	last := waitForTask(t, service, tasks[1].ID)
+	service.Stop()
+	if last.Status != models.ScanStatusCompleted {
+		t.Fatalf("Expected both scans to complete, got %s", last.Status)
+	}
+
+	output = scrapeMetrics(t)
+	for _, line := range []string{
+		"trivy_scan_queue_length 0\n",
+		`trivy_scan_workers{state="idle"} 1` + "\n",
+		`trivy_image_vulnerabilities{image="nginx:1.25",severity="CRITICAL"} 1` + "\n",
+		`trivy_image_vulnerabilities{image="redis:7",severity="LOW"} 0` + "\n",
+		`trivy_scans_total{status="completed"}`,
+		`trivy_scan_duration_seconds_count`,
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+// TestImageVulnerabilityGauge tests that the gauge keeps the latest scan of the most recently scanned images
+func TestImageVulnerabilityGauge(t *testing.T) {
+	gauge := newImageVulnerabilityGauge()
+	completed := func(image string, endTime time.Time, critical int) *models.ScanTask {
+		return &models.ScanTask{
+			Image:   image,
+			Status:  models.ScanStatusCompleted,
+			EndTime: &endTime,
+			Result:  &models.ScanResult{Summary: &models.VulnerabilitySummary{Critical: critical}},
+		}
+	}
+
+	start := time.Now()
+	gauge.record(completed("nginx:1.25", start, 2))
+	// Older scans and unfinished scans are ignored
+	gauge.record(completed("nginx:1.25", start.Add(-time.Hour), 5))
+	gauge.record(&models.ScanTask{Image: "redis:7", Status: models.ScanStatusFailed})
+	if got := testutil.ToFloat64(gauge.gauge.WithLabelValues("nginx:1.25", "CRITICAL")); got != 2 {
+		t.Errorf("Expected 2 critical vulnerabilities, got %v", got)
+	}
+
+	for i := 1; i <= maxMetricImages; i++ {
+		gauge.record(completed(fmt.Sprintf("app-%d:latest", i), start.Add(time.Duration(i)*time.Second), i))
+	}
+	if len(gauge.scanned) != maxMetricImages {
+		t.Errorf("Expected %d images, got %d", maxMetricImages, len(gauge.scanned))
+	}
+	if got := testutil.CollectAndCount(gauge.gauge); got != maxMetricImages*5 {
+		t.Errorf("Expected %d series, got %d", maxMetricImages*5, got)
+	}
+	if _, ok := gauge.scanned["nginx:1.25"]; ok {
+		t.Error("Expected the least recently scanned image to be dropped")
+	}
+}
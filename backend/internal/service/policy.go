@@ -0,0 +1,193 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for policy gates.
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/validator"
+)
+
+const policyFilePrefix = "policy_"
+
+// getPolicyPath returns the file path for a given policy name and user.
+// Policies are stored in the user's config directory as policy_NAME.json.
+func (s *ConfigService) getPolicyPath(userIdentifier, name string) string {
+	cleanName := filepath.Base(filepath.Clean(name))
+	return filepath.Join(s.getUserConfigDir(userIdentifier), policyFilePrefix+cleanName+configFileSuffix)
+}
+
+// ListPolicies returns the names of all saved policies of a user.
+func (s *ConfigService) ListPolicies(userIdentifier string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names, err := s.listPoliciesNoLock(userIdentifier)
+	if err != nil {
+		s.logger.Error("Failed to read config directory: %v", err)
+		return nil, errors.WrapInternal(err, "Failed to read config directory")
+	}
+	return names, nil
+}
+
+// listPoliciesNoLock lists policy names without locking, sorted alphabetically.
+func (s *ConfigService) listPoliciesNoLock(userIdentifier string) ([]string, error) {
+	entries, err := os.ReadDir(s.getUserConfigDir(userIdentifier))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		filename := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(filename, policyFilePrefix) || !strings.HasSuffix(filename, configFileSuffix) {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(filename, policyFilePrefix), configFileSuffix))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetPolicy retrieves a saved policy by name. Missing policies are reported as invalid input.
+func (s *ConfigService) GetPolicy(userIdentifier, name string) (*models.Policy, error) {
+	if err := validator.ValidateConfigName(name); err != nil {
+		return nil, errors.NewInvalidInput(err.Error())
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(s.getPolicyPath(userIdentifier, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.NewInvalidInput(fmt.Sprintf("Policy %q not found", name))
+		}
+		s.logger.Error("Failed to read policy file %s: %v", name, err)
+		return nil, errors.WrapInternal(err, "Failed to read policy file")
+	}
+
+	var policy models.Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		s.logger.Error("Failed to parse policy file %s: %v", name, err)
+		return nil, errors.WrapInternal(err, "Failed to parse policy file")
+	}
+
+	return &policy, nil
+}
+
+// SavePolicy validates and saves a policy with the given name for a user.
+func (s *ConfigService) SavePolicy(userIdentifier, name string, policy *models.Policy) error {
+	if err := validator.ValidateConfigName(name); err != nil {
+		return errors.NewInvalidInput(err.Error())
+	}
+	if err := policy.Validate(); err != nil {
+		return errors.WrapInvalidInput(err, fmt.Sprintf("Invalid policy: %v", err))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Check max policies limit (only for new policies)
+	policyPath := s.getPolicyPath(userIdentifier, name)
+	if _, err := os.Stat(policyPath); os.IsNotExist(err) {
+		policies, _ := s.listPoliciesNoLock(userIdentifier)
+		if len(policies) >= s.maxConfigFiles {
+			return errors.NewInvalidInput(fmt.Sprintf("Maximum number of policies (%d) reached", s.maxConfigFiles))
+		}
+	}
+
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		s.logger.Error("Failed to marshal policy: %v", err)
+		return errors.WrapInternal(err, "Failed to marshal policy")
+	}
+	if len(data) > s.maxConfigSize {
+		return errors.NewInvalidInput(fmt.Sprintf("Policy size (%d bytes) exceeds maximum allowed size (%d bytes)", len(data), s.maxConfigSize))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(policyPath), 0700); err != nil {
+		s.logger.Error("Failed to create config directory: %v", err)
+		return errors.WrapInternal(err, "Failed to create config directory")
+	}
+	if err := os.WriteFile(policyPath, data, 0600); err != nil {
+		s.logger.Error("Failed to write policy file %s: %v", name, err)
+		return errors.WrapInternal(err, "Failed to write policy file")
+	}
+
+	s.logger.Info("Policy '%s' saved successfully for user %s", name, userIdentifier)
+	return nil
+}
+
+// DeletePolicy removes a saved policy by name. Tasks keep the snapshot taken when they were created.
+func (s *ConfigService) DeletePolicy(userIdentifier, name string) error {
+	if err := validator.ValidateConfigName(name); err != nil {
+		return errors.NewInvalidInput(err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.getPolicyPath(userIdentifier, name)); err != nil && !os.IsNotExist(err) {
+		s.logger.Error("Failed to delete policy file %s: %v", name, err)
+		return errors.WrapInternal(err, "Failed to delete policy file")
+	}
+
+	s.logger.Info("Policy '%s' deleted successfully for user %s", name, userIdentifier)
+	return nil
+}
+
+// resolvePolicy attaches a snapshot of the named saved policy to a new task's scan
+// configuration. Policies are evaluated against the JSON report, so other formats are rejected.
+func (s *scanServiceImpl) resolvePolicy(userID, name string, scanConfig *models.ScanConfig) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	if s.configService == nil {
+		return errors.NewInvalidInput("Policies are not available")
+	}
+	if scanConfig.Format != "json" {
+		return errors.NewInvalidInput("Policies require the JSON output format")
+	}
+
+	policy, err := s.configService.GetPolicy(userID, name)
+	if err != nil {
+		return err
+	}
+	scanConfig.PolicyName = name
+	scanConfig.Policy = policy
+	return nil
+}
+
+// evaluatePolicy checks every rule of a policy against the findings of a report.
+func evaluatePolicy(name string, policy *models.Policy, report *models.TrivyReport) *models.PolicyVerdict {
+	verdict := &models.PolicyVerdict{
+		Policy:      name,
+		Passed:      true,
+		EvaluatedAt: time.Now(),
+	}
+
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+		if count := rule.Count(report); count > rule.Max {
+			verdict.Passed = false
+			verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("%s: found %d, allowed %d", rule, count, rule.Max))
+		}
+	}
+
+	return verdict
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+// TestSavePolicy tests storing policies next to saved configurations
+func TestSavePolicy(t *testing.T) {
+	configService := NewConfigService(t.TempDir(), false, 4096, 2, &mockLogger{})
+	policy := &models.Policy{Rules: []models.PolicyRule{{Kind: models.FindingKindSecret}}}
+
+	if err := configService.SavePolicy("user1", "no-secrets", policy); err != nil {
+		t.Fatalf("Failed to save policy: %v", err)
+	}
+	if err := configService.SaveConfig("user1", "prod", &models.SavedScanConfig{}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	// Policies and configurations are listed separately
+	policies, err := configService.ListPolicies("user1")
+	if err != nil || len(policies) != 1 || policies[0] != "no-secrets" {
+		t.Errorf("Expected [no-secrets], got %v (err: %v)", policies, err)
+	}
+	configs, err := configService.ListConfigs("user1")
+	if err != nil || len(configs) != 1 || configs[0] != "prod" {
+		t.Errorf("Expected [prod], got %v (err: %v)", configs, err)
+	}
+
+	// Policies are per user
+	if _, err := configService.GetPolicy("user2", "no-secrets"); err == nil {
+		t.Error("Expected error when loading another user's policy")
+	}
+
+	// Invalid policies are rejected
+	if err := configService.SavePolicy("user1", "empty", &models.Policy{}); err == nil {
+		t.Error("Expected error when saving a policy without rules")
+	}
+
+	if err := configService.DeletePolicy("user1", "no-secrets"); err != nil {
+		t.Fatalf("Failed to delete policy: %v", err)
+	}
+	if policies, _ := configService.ListPolicies("user1"); len(policies) != 0 {
+		t.Errorf("Expected no policies after delete, got %v", policies)
+	}
+}
+
+// TestPolicyVerdict tests evaluating the policy of a scan after it completes
+func TestPolicyVerdict(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	configService := NewConfigService(t.TempDir(), false, 4096, 10, &mockLogger{})
+	// The mock report has one CRITICAL, one HIGH and one MEDIUM vulnerability
+	service := NewScanServiceWithExecutor(repo, &types.TrivyConfig{Timeout: 600, MaxWorkers: 1}, t.TempDir(), &mockLogger{},
+		&mockCommandExecutor{mockStdout: createMockJSONOutput()},
+		WithConfigService(configService),
+	)
+	defer service.Stop()
+
+	policies := map[string]*models.Policy{
+		"no-critical": {Rules: []models.PolicyRule{
+			{Kind: models.FindingKindVulnerability, Severities: []string{"CRITICAL"}},
+			{Kind: models.FindingKindVulnerability, Severities: []string{"HIGH"}, Max: 5},
+		}},
+		"no-fixable-critical": {Rules: []models.PolicyRule{
+			{Kind: models.FindingKindVulnerability, Severities: []string{"CRITICAL"}, FixAvailable: true},
+		}},
+	}
+	for name, policy := range policies {
+		if err := configService.SavePolicy("user1", name, policy); err != nil {
+			t.Fatalf("Failed to save policy %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		req        *models.ScanRequest
+		wantErr    bool
+		wantPassed bool
+		wantReason string
+	}{
+		{
+			name:       "Failed verdict",
+			req:        &models.ScanRequest{Image: "alpine:3.19", Policy: "no-critical"},
+			wantReason: "CRITICAL vulnerabilities: found 1, allowed 0",
+		},
+		{
+			name:       "Passed verdict",
+			req:        &models.ScanRequest{Image: "alpine:3.19", Policy: "no-fixable-critical"},
+			wantPassed: true,
+		},
+		{
+			name:    "Unknown policy",
+			req:     &models.ScanRequest{Image: "alpine:3.19", Policy: "missing"},
+			wantErr: true,
+		},
+		{
+			name:    "Non-JSON format",
+			req:     &models.ScanRequest{Image: "alpine:3.19", Policy: "no-critical", Format: "table"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := service.CreateScanTask("user1", tt.req)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			task = waitForTask(t, service, task.ID)
+			if task.Status != models.ScanStatusCompleted {
+				t.Fatalf("Expected completed task, got %s", task.Status)
+			}
+
+			verdict := task.PolicyVerdict
+			if verdict == nil {
+				t.Fatal("Expected a policy verdict")
+			}
+			if verdict.Policy != tt.req.Policy || verdict.Passed != tt.wantPassed {
+				t.Errorf("Expected policy %s passed=%v, got %+v", tt.req.Policy, tt.wantPassed, verdict)
+			}
+			if tt.wantReason != "" && (len(verdict.Reasons) != 1 || !strings.Contains(verdict.Reasons[0], tt.wantReason)) {
+				t.Errorf("Expected reason %q, got %v", tt.wantReason, verdict.Reasons)
+			}
+			if summary := task.ToSummary(); summary.PolicyVerdict != verdict {
+				t.Error("Expected the verdict in the task summary")
+			}
+		})
+	}
+}
@@ -5,11 +5,16 @@
 package service
 
 import (
+	"archive/zip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/lazycatapps/trivy/backend/internal/models"
@@ -19,143 +24,280 @@ import (
 
 // ReportService defines the interface for report operations.
 type ReportService interface {
-	// GetReport retrieves a scan report in the specified format.
-	// If the format differs from the original, it converts the report.
-	GetReport(taskID, format string) ([]byte, string, error)
+	// OpenReport opens a scan report of a task the principal can read in the specified
+	// format for download. If the format differs from the original, it converts the report.
+	// filename is the download file name used by presigned download URLs.
+	OpenReport(principal models.Principal, taskID, format, filename string) (*Report, error)
 
-	// GetReportPath returns the file path for a specific report format.
-	GetReportPath(taskID, format string) (string, error)
+	// PrepareArchive converts the report of a task the principal can read into the requested
+	// formats (all archive formats if empty) and returns an archive ready to be streamed.
+	PrepareArchive(principal models.Principal, taskID string, formats []string) (ReportArchive, error)
 }
 
+// Report is a report opened for download. Either Body or RedirectURL is set.
+type Report struct {
+	Body        io.ReadCloser // Report content, streamed from the report store (closed by the caller)
+	Size        int64         // Report size in bytes
+	ContentType string        // MIME type of the report format
+	RedirectURL string        // Presigned URL the client downloads the report from directly
+}
+
+// ReportArchive is a zip archive of a task's reports that is streamed on demand.
+type ReportArchive interface {
+	// Filename returns the suggested download filename of the archive.
+	Filename() string
+
+	// Stream writes the zip archive to w, copying report files one at a time.
+	Stream(w io.Writer) error
+}
+
+// ArchiveFormats lists the report formats bundled in a report archive, in order.
+var ArchiveFormats = []string{"json", "table", "sarif", "cyclonedx", "spdx", "html"}
+
 // reportServiceImpl implements ReportService.
 type reportServiceImpl struct {
-	scanRepo   repository.ScanRepository
-	reportsDir string
-	logger     logger.Logger
+	scanRepo    repository.ScanRepository
+	reportStore repository.ReportStore
+	logger      logger.Logger
+
+	// Presigned download redirects (disabled when presignMinSize is 0)
+	presignMinSize int64
+	presignExpiry  time.Duration
+}
+
+// ReportServiceOption configures optional behavior of the report service.
+type ReportServiceOption func(*reportServiceImpl)
+
+// WithPresignedDownloads redirects downloads of reports of at least minSize bytes
+// to presigned URLs valid for expiry, when the report store supports them.
+func WithPresignedDownloads(minSize int64, expiry time.Duration) ReportServiceOption {
+	return func(s *reportServiceImpl) {
+		s.presignMinSize = minSize
+		s.presignExpiry = expiry
+	}
 }
 
 // NewReportService creates a new report service instance.
 func NewReportService(
 	scanRepo repository.ScanRepository,
-	reportsDir string,
+	reportStore repository.ReportStore,
 	logger logger.Logger,
+	opts ...ReportServiceOption,
 ) ReportService {
-	return &reportServiceImpl{
-		scanRepo:   scanRepo,
-		reportsDir: reportsDir,
-		logger:     logger,
+	s := &reportServiceImpl{
+		scanRepo:    scanRepo,
+		reportStore: reportStore,
+		logger:      logger,
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
-// GetReport retrieves a scan report in the specified format.
-func (s *reportServiceImpl) GetReport(taskID, format string) ([]byte, string, error) {
-	// Get task to verify it exists and is completed
-	task, err := s.scanRepo.GetByID(taskID)
+// OpenReport opens a scan report in the specified format for download.
+func (s *reportServiceImpl) OpenReport(principal models.Principal, taskID, format, filename string) (*Report, error) {
+	// Get task to verify it can be read and is completed
+	task, err := authorizeTask(s.scanRepo, principal, taskID, models.SharePermissionRead)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to get task: %w", err)
-	}
-	if task == nil {
-		return nil, "", fmt.Errorf("task not found")
+		return nil, err
 	}
 	if task.Status != models.ScanStatusCompleted {
-		return nil, "", fmt.Errorf("task not completed")
+		return nil, fmt.Errorf("task not completed")
 	}
 
 	// Determine file extension and MIME type
 	ext, mimeType := s.getFileExtension(format)
 
-	// User-specific reports directory: reports/users/{userID}/
-	userReportsDir := filepath.Join(s.reportsDir, "reports", "users", task.UserID)
-
-	// Check if report exists in the requested format
-	reportPath := filepath.Join(userReportsDir, fmt.Sprintf("%s.%s", taskID, ext))
+	// Report key in the requested format: users/{userID}/{taskID}.{ext}
+	reportKey := repository.ReportKey(task.UserID, taskID, ext)
 
 	// If report exists, return it
-	if _, err := os.Stat(reportPath); err == nil {
-		data, err := os.ReadFile(reportPath)
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to read report: %w", err)
-		}
-		return data, mimeType, nil
+	report, err := s.openStoredReport(reportKey, filename, mimeType)
+	if err == nil {
+		return report, nil
+	}
+	if !errors.Is(err, repository.ErrReportNotFound) {
+		return nil, err
+	}
+
+	// Get original report
+	originalKey, err := s.ensureOriginalReport(task)
+	if err != nil {
+		return nil, err
+	}
+	if originalKey == reportKey {
+		// Original format restored from the task result
+		return s.openStoredReport(reportKey, filename, mimeType)
 	}
 
 	// Report doesn't exist in requested format, need to convert
 	s.logger.Info("Converting report for task %s to format %s", taskID, format)
-
-	// Get original report path
-	originalExt, _ := s.getFileExtension(task.ScanConfig.Format)
-	originalPath := filepath.Join(userReportsDir, fmt.Sprintf("%s.%s", taskID, originalExt))
-
-	// Check if original report exists
-	if _, err := os.Stat(originalPath); err != nil {
-		// Original report not found, try to use task result data
-		if task.Result != nil && task.Result.Data != "" {
-			// Save original data first
-			if err := os.WriteFile(originalPath, []byte(task.Result.Data), 0644); err != nil {
-				return nil, "", fmt.Errorf("failed to save original report: %w", err)
-			}
-		} else {
-			return nil, "", fmt.Errorf("original report not found")
-		}
+	originalPath, err := s.downloadReport(originalKey)
+	if err != nil {
+		return nil, err
 	}
+	defer os.Remove(originalPath)
 
 	// Convert report using trivy convert command
-	convertedData, err := s.convertReport(originalPath, format)
+	converted, size, err := s.convertReport(originalPath, format)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to convert report: %w", err)
+		return nil, fmt.Errorf("failed to convert report: %w", err)
 	}
 
 	// Save converted report
-	if err := os.WriteFile(reportPath, convertedData, 0644); err != nil {
+	if err := s.reportStore.Put(reportKey, converted, size); err != nil {
 		s.logger.Error("Failed to save converted report: %v", err)
 		// Don't fail, just return the data without saving
 	}
+	if _, err := converted.Seek(0, io.SeekStart); err != nil {
+		converted.Close()
+		return nil, fmt.Errorf("failed to read converted report: %w", err)
+	}
 
-	return convertedData, mimeType, nil
+	return &Report{Body: converted, Size: size, ContentType: mimeType}, nil
 }
 
-// GetReportPath returns the file path for a specific report format.
-func (s *reportServiceImpl) GetReportPath(taskID, format string) (string, error) {
-	// Get task to retrieve userID
-	task, err := s.scanRepo.GetByID(taskID)
+// openStoredReport opens an existing report, or presigns a download URL for it when it
+// is large enough and the store supports presigned downloads.
+// Returns repository.ErrReportNotFound if the report does not exist.
+func (s *reportServiceImpl) openStoredReport(key, filename, mimeType string) (*Report, error) {
+	if s.presignMinSize > 0 {
+		obj, err := s.reportStore.Stat(key)
+		if err != nil {
+			return nil, err
+		}
+		if obj.Size >= s.presignMinSize {
+			url, err := s.reportStore.PresignGet(key, filename, mimeType, s.presignExpiry)
+			if err == nil {
+				return &Report{Size: obj.Size, ContentType: mimeType, RedirectURL: url}, nil
+			}
+			if !errors.Is(err, repository.ErrPresignNotSupported) {
+				s.logger.Error("Failed to presign download of report %s, streaming it instead: %v", key, err)
+			}
+		}
+	}
+
+	body, obj, err := s.reportStore.Open(key)
 	if err != nil {
-		return "", fmt.Errorf("failed to get task: %w", err)
+		if errors.Is(err, repository.ErrReportNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	return &Report{Body: body, Size: obj.Size, ContentType: mimeType}, nil
+}
+
+// ensureOriginalReport returns the key of the task's original report,
+// restoring it from the stored task result if it is missing.
+func (s *reportServiceImpl) ensureOriginalReport(task *models.ScanTask) (string, error) {
+	originalExt, _ := s.getFileExtension(task.ScanConfig.Format)
+	originalKey := repository.ReportKey(task.UserID, task.ID, originalExt)
+
+	// Check if original report exists
+	_, err := s.reportStore.Stat(originalKey)
+	if err == nil {
+		return originalKey, nil
 	}
-	if task == nil {
-		return "", fmt.Errorf("task not found")
+	if !errors.Is(err, repository.ErrReportNotFound) {
+		return "", err
 	}
 
-	ext, _ := s.getFileExtension(format)
-	reportPath := filepath.Join(s.reportsDir, "reports", "users", task.UserID, fmt.Sprintf("%s.%s", taskID, ext))
+	// Original report not found, try to use task result data
+	if task.Result == nil || task.Result.Data == "" {
+		return "", fmt.Errorf("original report not found")
+	}
+	data := task.Result.Data
+	if err := s.reportStore.Put(originalKey, strings.NewReader(data), int64(len(data))); err != nil {
+		return "", fmt.Errorf("failed to save original report: %w", err)
+	}
+
+	return originalKey, nil
+}
 
-	// Check if report exists
-	if _, err := os.Stat(reportPath); err != nil {
-		return "", fmt.Errorf("report not found")
+// downloadReport copies a report from the report store into a temporary file
+// (trivy convert reads local files) and returns its path. The caller removes the file.
+func (s *reportServiceImpl) downloadReport(key string) (string, error) {
+	body, _, err := s.reportStore.Open(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read report: %w", err)
 	}
+	defer body.Close()
 
-	return reportPath, nil
+	tmpFile, err := os.CreateTemp("", "trivy-report-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer tmpFile.Close()
+
+	if _, err := io.Copy(tmpFile, body); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("failed to download report: %w", err)
+	}
+
+	return tmpFile.Name(), nil
+}
+
+// tempFile is a temporary file that is removed when closed.
+type tempFile struct {
+	*os.File
+}
+
+// Close closes and removes the file.
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
 }
 
 // convertReport converts a trivy report from one format to another using trivy convert.
-func (s *reportServiceImpl) convertReport(inputPath, targetFormat string) ([]byte, error) {
+// The result is returned as an open temporary file that is removed when closed.
+func (s *reportServiceImpl) convertReport(inputPath, targetFormat string) (*tempFile, int64, error) {
 	// Create temporary output file
 	tmpFile, err := os.CreateTemp("", "trivy-report-*.tmp")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+		return nil, 0, fmt.Errorf("failed to create temp file: %w", err)
 	}
 	tmpPath := tmpFile.Name()
 	tmpFile.Close()
-	defer os.Remove(tmpPath)
 
+	if err := s.convertReportToFile(inputPath, targetFormat, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, 0, err
+	}
+
+	// Open converted data
+	f, err := os.Open(tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, 0, fmt.Errorf("failed to read converted report: %w", err)
+	}
+	converted := &tempFile{File: f}
+	info, err := f.Stat()
+	if err != nil {
+		converted.Close()
+		return nil, 0, fmt.Errorf("failed to read converted report: %w", err)
+	}
+
+	return converted, info.Size(), nil
+}
+
+// convertReportToFile converts a trivy report using trivy convert and writes the result to outputPath.
+func (s *reportServiceImpl) convertReportToFile(inputPath, targetFormat, outputPath string) error {
 	// Build trivy convert command
 	args := []string{
 		"convert",
 		"--format", targetFormat,
-		"--output", tmpPath,
+		"--output", outputPath,
 		inputPath,
 	}
 
 	s.logger.Info("Executing: trivy %v", args)
+	start := time.Now()
+	defer func() { reportConversionSeconds.WithLabelValues(targetFormat).Observe(time.Since(start).Seconds()) }()
 
 	// Execute conversion with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -164,16 +306,250 @@ func (s *reportServiceImpl) convertReport(inputPath, targetFormat string) ([]byt
 	cmd := exec.CommandContext(ctx, "trivy", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("trivy convert failed: %w (output: %s)", err, string(output))
+		return fmt.Errorf("trivy convert failed: %w (output: %s)", err, string(output))
 	}
 
-	// Read converted data
-	data, err := os.ReadFile(tmpPath)
+	return nil
+}
+
+// convertAndStoreReport converts a report and saves the result in the report store.
+func (s *reportServiceImpl) convertAndStoreReport(inputPath, targetFormat, key string) error {
+	converted, size, err := s.convertReport(inputPath, targetFormat)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read converted report: %w", err)
+		return err
+	}
+	defer converted.Close()
+
+	return s.reportStore.Put(key, converted, size)
+}
+
+// archiveEntry is a single file in a report archive.
+// Entries are backed either by a report in the report store (key) or by small in-memory data.
+type archiveEntry struct {
+	name string
+	key  string
+	data []byte
+}
+
+// reportArchive implements ReportArchive.
+type reportArchive struct {
+	filename string
+	modified time.Time
+	entries  []archiveEntry
+	store    repository.ReportStore
+}
+
+// archiveMetadata is written to metadata.json inside a report archive.
+type archiveMetadata struct {
+	Task    *models.ScanTask  `json:"task"`
+	Reports map[string]string `json:"reports"`          // format -> file name in archive
+	Errors  map[string]string `json:"errors,omitempty"` // format -> reason it is missing
+}
+
+// imageNameSanitizer replaces characters that are unsafe in file names.
+var imageNameSanitizer = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// PrepareArchive converts the task's report into the requested formats and returns a streamable archive.
+// Formats that cannot be produced are listed in metadata.json instead of failing the archive.
+func (s *reportServiceImpl) PrepareArchive(principal models.Principal, taskID string, formats []string) (ReportArchive, error) {
+	if len(formats) == 0 {
+		formats = ArchiveFormats
+	}
+	for _, format := range formats {
+		if !isArchiveFormat(format) {
+			return nil, fmt.Errorf("unsupported format: %s", format)
+		}
+	}
+
+	task, err := authorizeTask(s.scanRepo, principal, taskID, models.SharePermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != models.ScanStatusCompleted {
+		return nil, fmt.Errorf("task not completed")
+	}
+
+	originalKey, err := s.ensureOriginalReport(task)
+	if err != nil {
+		return nil, err
+	}
+
+	baseName := fmt.Sprintf("trivy-report-%s-%s", archiveImageName(task.Image), task.StartTime.Format("20060102-150405"))
+	metadata := &archiveMetadata{
+		Task:    archiveTaskMetadata(task, task.UserID == principal.UserID),
+		Reports: make(map[string]string),
+		Errors:  make(map[string]string),
+	}
+
+	// Trivy writes table output when no format is given
+	originalFormat := task.ScanConfig.Format
+	if originalFormat == "" {
+		originalFormat = "table"
+	}
+
+	// The original report is downloaded once, when the first conversion is needed
+	var originalPath string
+	defer func() {
+		if originalPath != "" {
+			os.Remove(originalPath)
+		}
+	}()
+
+	var reports []archiveEntry
+	for _, format := range formats {
+		ext, _ := s.getFileExtension(format)
+		reportKey := repository.ReportKey(task.UserID, task.ID, ext)
+
+		switch {
+		case format == originalFormat:
+			reportKey = originalKey
+		case originalFormat != "json":
+			// trivy convert only accepts JSON reports as input
+			metadata.Errors[format] = "conversion requires a JSON report"
+			continue
+		default:
+			if _, err := s.reportStore.Stat(reportKey); err != nil {
+				s.logger.Info("Converting report for task %s to format %s", taskID, format)
+				if originalPath == "" {
+					if originalPath, err = s.downloadReport(originalKey); err != nil {
+						return nil, err
+					}
+				}
+				if err := s.convertAndStoreReport(originalPath, format, reportKey); err != nil {
+					s.logger.Error("Failed to convert report for task %s to %s: %v", taskID, format, err)
+					metadata.Errors[format] = err.Error()
+					continue
+				}
+			}
+		}
+
+		name := fmt.Sprintf("%s.%s", baseName, ext)
+		metadata.Reports[format] = name
+		reports = append(reports, archiveEntry{name: name, key: reportKey})
+	}
+
+	metadataData, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	return &reportArchive{
+		filename: fmt.Sprintf("trivy-reports-%s-%s.zip", archiveImageName(task.Image), task.StartTime.Format("20060102-150405")),
+		modified: task.StartTime,
+		entries:  append([]archiveEntry{{name: "metadata.json", data: metadataData}}, reports...),
+		store:    s.reportStore,
+	}, nil
+}
+
+// Filename returns the suggested download filename of the archive.
+func (a *reportArchive) Filename() string {
+	return a.filename
+}
+
+// Stream writes the zip archive to w, copying reports one at a time.
+func (a *reportArchive) Stream(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	for _, entry := range a.entries {
+		entryWriter, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     entry.name,
+			Method:   zip.Deflate,
+			Modified: a.modified,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create archive entry %s: %w", entry.name, err)
+		}
+
+		if entry.key == "" {
+			if _, err := entryWriter.Write(entry.data); err != nil {
+				return fmt.Errorf("failed to write archive entry %s: %w", entry.name, err)
+			}
+			continue
+		}
+
+		if err := copyReportTo(entryWriter, a.store, entry.key); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", entry.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// copyReportTo streams a report from the report store to w.
+func copyReportTo(w io.Writer, store repository.ReportStore, key string) error {
+	body, _, err := store.Open(key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(w, body)
+	return err
+}
+
+// isArchiveFormat reports whether format can be included in a report archive.
+func isArchiveFormat(format string) bool {
+	for _, f := range ArchiveFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveImageName returns a short, file-name-safe name for an image reference
+// (e.g. "docker.io/library/nginx:latest" -> "nginx").
+func archiveImageName(image string) string {
+	name := image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.Trim(imageNameSanitizer.ReplaceAllString(name, "-"), "-.")
+	if name == "" {
+		return "image"
+	}
+	return name
+}
+
+// archiveTaskMetadata returns a copy of the task suitable for metadata.json
+// (no registry password, logs or raw result data). Like GET /api/v1/scan/:id,
+// the registry username and the owner are hidden from readers other than the owner.
+func archiveTaskMetadata(task *models.ScanTask, owner bool) *models.ScanTask {
+	meta := &models.ScanTask{
+		ID:           task.ID,
+		UserID:       task.UserID,
+		Image:        task.Image,
+		Status:       task.Status,
+		Message:      task.Message,
+		StartTime:    task.StartTime,
+		EndTime:      task.EndTime,
+		ParentTaskID: task.ParentTaskID,
+		TrivyVersion: task.TrivyVersion,
+	}
+
+	if task.ScanConfig != nil {
+		config := *task.ScanConfig
+		config.Password = ""
+		if !owner {
+			config.Username = ""
+		}
+		meta.ScanConfig = &config
+	}
+	if !owner {
+		meta.UserID = ""
+	}
+
+	if task.Result != nil {
+		meta.Result = &models.ScanResult{
+			Format:  task.Result.Format,
+			Summary: task.Result.Summary,
+		}
 	}
 
-	return data, nil
+	return meta
 }
 
 // getFileExtension returns the file extension and MIME type for a format.
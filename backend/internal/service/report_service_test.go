@@ -0,0 +1,313 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+)
+
+// readArchive streams a report archive into memory and returns its files by name.
+func readArchive(t *testing.T, archive ReportArchive) map[string]string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := archive.Stream(&buf); err != nil {
+		t.Fatalf("Failed to stream archive: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Invalid zip archive: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+// TestPrepareArchive tests building report archives
+func TestPrepareArchive(t *testing.T) {
+	reportsDir := t.TempDir()
+	repo := repository.NewInMemoryScanRepository()
+	service := NewReportService(repo, repository.NewLocalReportStore(filepath.Join(reportsDir, "reports")), &mockLogger{})
+	owner := models.Principal{UserID: "user1"}
+
+	startTime := time.Date(2025, 10, 2, 12, 0, 0, 0, time.UTC)
+	jsonTask := models.NewScanTask("task-json", "user1", "docker.io/library/nginx:latest", &models.ScanConfig{
+		Username: "deployer",
+		Password: "secret",
+		Format:   "json",
+	})
+	jsonTask.Status = models.ScanStatusCompleted
+	jsonTask.StartTime = startTime
+	jsonTask.Result = &models.ScanResult{Format: "json", Data: `{"Results":[]}`}
+	repo.Create(jsonTask)
+
+	// A previously converted SARIF report is reused from the cache
+	userDir := filepath.Join(reportsDir, "reports", "users", "user1")
+	if err := os.MkdirAll(userDir, 0755); err != nil {
+		t.Fatalf("Failed to create reports dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(userDir, "task-json.sarif"), []byte(`{"runs":[]}`), 0644); err != nil {
+		t.Fatalf("Failed to write cached report: %v", err)
+	}
+
+	tableTask := models.NewScanTask("task-table", "user1", "alpine:3.19", &models.ScanConfig{Format: "table"})
+	tableTask.Status = models.ScanStatusCompleted
+	tableTask.StartTime = startTime
+	tableTask.Result = &models.ScanResult{Format: "table", Data: "alpine:3.19 (alpine 3.19)\n"}
+	repo.Create(tableTask)
+
+	runningTask := models.NewScanTask("task-running", "user1", "alpine:3.19", &models.ScanConfig{Format: "json"})
+	runningTask.Status = models.ScanStatusRunning
+	repo.Create(runningTask)
+
+	t.Run("JSON report with cached conversion", func(t *testing.T) {
+		archive, err := service.PrepareArchive(owner, "task-json", []string{"json", "sarif"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if archive.Filename() != "trivy-reports-nginx-20251002-120000.zip" {
+			t.Errorf("Unexpected filename: %s", archive.Filename())
+		}
+
+		files := readArchive(t, archive)
+		if files["trivy-report-nginx-20251002-120000.json"] != `{"Results":[]}` {
+			t.Errorf("Raw JSON report missing or wrong: %v", files)
+		}
+		if files["trivy-report-nginx-20251002-120000.sarif"] != `{"runs":[]}` {
+			t.Errorf("Cached SARIF report missing or wrong: %v", files)
+		}
+
+		metadata, ok := files["metadata.json"]
+		if !ok {
+			t.Fatal("metadata.json missing from archive")
+		}
+		if strings.Contains(metadata, "secret") {
+			t.Error("metadata.json must not contain the registry password")
+		}
+		var parsed archiveMetadata
+		if err := json.Unmarshal([]byte(metadata), &parsed); err != nil {
+			t.Fatalf("Invalid metadata.json: %v", err)
+		}
+		if parsed.Task.ID != "task-json" || len(parsed.Reports) != 2 {
+			t.Errorf("Unexpected metadata: %+v", parsed)
+		}
+	})
+
+	t.Run("Shared archive hides the owner and registry username", func(t *testing.T) {
+		sharedTask := models.NewScanTask("task-shared", "user1", "nginx:latest", &models.ScanConfig{
+			Username: "deployer",
+			Password: "secret",
+			Format:   "json",
+		})
+		sharedTask.Status = models.ScanStatusCompleted
+		sharedTask.StartTime = startTime
+		sharedTask.Result = &models.ScanResult{Format: "json", Data: `{"Results":[]}`}
+		sharedTask.Shares = []models.TaskShare{{Group: "security", Permission: models.SharePermissionRead}}
+		repo.Create(sharedTask)
+
+		archive, err := service.PrepareArchive(models.Principal{UserID: "user2", Groups: []string{"security"}}, "task-shared", []string{"json"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		metadata := readArchive(t, archive)["metadata.json"]
+		for _, hidden := range []string{"secret", "deployer", "user1"} {
+			if strings.Contains(metadata, hidden) {
+				t.Errorf("metadata.json of a shared task must not contain %q: %s", hidden, metadata)
+			}
+		}
+
+		// The owner sees the registry username
+		archive, err = service.PrepareArchive(owner, "task-shared", []string{"json"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var parsed archiveMetadata
+		json.Unmarshal([]byte(readArchive(t, archive)["metadata.json"]), &parsed)
+		if parsed.Task.UserID != "user1" || parsed.Task.ScanConfig.Username != "deployer" {
+			t.Errorf("Expected the owner to see the full metadata, got %+v", parsed.Task)
+		}
+	})
+
+	t.Run("Non-JSON report cannot be converted", func(t *testing.T) {
+		archive, err := service.PrepareArchive(owner, "task-table", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		files := readArchive(t, archive)
+		if len(files) != 2 {
+			t.Errorf("Expected metadata.json and the table report, got %d files", len(files))
+		}
+		if _, ok := files["trivy-report-alpine-20251002-120000.txt"]; !ok {
+			t.Error("Table report missing from archive")
+		}
+
+		var parsed archiveMetadata
+		json.Unmarshal([]byte(files["metadata.json"]), &parsed)
+		if len(parsed.Errors) != len(ArchiveFormats)-1 {
+			t.Errorf("Expected %d missing formats, got %v", len(ArchiveFormats)-1, parsed.Errors)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		if _, err := service.PrepareArchive(owner, "task-json", []string{"pdf"}); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+			t.Errorf("Expected unsupported format error, got %v", err)
+		}
+		if _, err := service.PrepareArchive(owner, "task-running", nil); err == nil || !strings.Contains(err.Error(), "not completed") {
+			t.Errorf("Expected not completed error, got %v", err)
+		}
+		if _, err := service.PrepareArchive(owner, "missing", nil); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+		if _, err := service.PrepareArchive(models.Principal{UserID: "user2"}, "task-json", nil); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected not found error for another user, got %v", err)
+		}
+	})
+}
+
+// presigningReportStore is a local report store that issues fake presigned URLs.
+type presigningReportStore struct {
+	*repository.LocalReportStore
+}
+
+func (s *presigningReportStore) PresignGet(key, filename, contentType string, expiry time.Duration) (string, error) {
+	return "https://s3.example.com/" + key + "?filename=" + filename, nil
+}
+
+// TestOpenReport tests opening stored reports for download
+func TestOpenReport(t *testing.T) {
+	store := &presigningReportStore{repository.NewLocalReportStore(t.TempDir())}
+	repo := repository.NewInMemoryScanRepository()
+	owner := models.Principal{UserID: "user1"}
+
+	task := models.NewScanTask("task-json", "user1", "nginx", &models.ScanConfig{Format: "json"})
+	task.Status = models.ScanStatusCompleted
+	task.Result = &models.ScanResult{Format: "json", Data: `{"Results":[]}`}
+	repo.Create(task)
+	largeReport := strings.Repeat("x", 2048)
+	store.Put(repository.ReportKey("user1", "task-json", "sarif"), strings.NewReader(largeReport), int64(len(largeReport)))
+
+	readReport := func(t *testing.T, report *Report) string {
+		t.Helper()
+		if report.Body == nil {
+			t.Fatalf("Expected a streamed report, got %+v", report)
+		}
+		defer report.Body.Close()
+		data, _ := io.ReadAll(report.Body)
+		if int64(len(data)) != report.Size {
+			t.Errorf("Expected size %d, got %d", len(data), report.Size)
+		}
+		return string(data)
+	}
+
+	t.Run("Original report is restored from the task result", func(t *testing.T) {
+		service := NewReportService(repo, store, &mockLogger{})
+		report, err := service.OpenReport(owner, "task-json", "json", "report.json")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if data := readReport(t, report); data != `{"Results":[]}` || report.ContentType != "application/json" {
+			t.Errorf("Unexpected report %q (%s)", data, report.ContentType)
+		}
+		if _, err := store.Stat(repository.ReportKey("user1", "task-json", "json")); err != nil {
+			t.Errorf("Expected original report to be stored: %v", err)
+		}
+	})
+
+	t.Run("Small reports are streamed", func(t *testing.T) {
+		service := NewReportService(repo, store, &mockLogger{}, WithPresignedDownloads(4096, time.Minute))
+		report, err := service.OpenReport(owner, "task-json", "sarif", "report.sarif")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if data := readReport(t, report); data != largeReport {
+			t.Error("Unexpected SARIF report content")
+		}
+	})
+
+	t.Run("Large reports are redirected", func(t *testing.T) {
+		service := NewReportService(repo, store, &mockLogger{}, WithPresignedDownloads(1024, time.Minute))
+		report, err := service.OpenReport(owner, "task-json", "sarif", "report.sarif")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if report.Body != nil || report.RedirectURL != "https://s3.example.com/users/user1/task-json.sarif?filename=report.sarif" {
+			t.Errorf("Expected a presigned redirect, got %+v", report)
+		}
+	})
+
+	t.Run("Access of other users", func(t *testing.T) {
+		service := NewReportService(repo, store, &mockLogger{})
+		member := models.Principal{UserID: "user2", Groups: []string{"security"}}
+		if _, err := service.OpenReport(member, "task-json", "json", "report.json"); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected not found error before sharing, got %v", err)
+		}
+
+		task.Shares = []models.TaskShare{{Group: "security", Permission: models.SharePermissionRead}}
+		repo.Update(task)
+		defer func() {
+			task.Shares = nil
+			repo.Update(task)
+		}()
+		report, err := service.OpenReport(member, "task-json", "json", "report.json")
+		if err != nil {
+			t.Fatalf("Expected shared report to be readable: %v", err)
+		}
+		readReport(t, report)
+	})
+
+	t.Run("Missing report", func(t *testing.T) {
+		missing := models.NewScanTask("task-empty", "user1", "nginx", &models.ScanConfig{Format: "json"})
+		missing.Status = models.ScanStatusCompleted
+		repo.Create(missing)
+
+		service := NewReportService(repo, store, &mockLogger{})
+		if _, err := service.OpenReport(owner, "task-empty", "json", "report.json"); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+}
+
+// TestArchiveImageName tests deriving file names from image references
+func TestArchiveImageName(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{"nginx", "nginx"},
+		{"docker.io/library/nginx:latest", "nginx"},
+		{"registry.example.com:5000/team/app@sha256:abcd", "app"},
+		{"/tmp/upload/rootfs.tar", "rootfs.tar"},
+		{":::", "image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := archiveImageName(tt.image); got != tt.expected {
+				t.Errorf("archiveImageName(%q) = %q, want %q", tt.image, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for comparing scan results.
+package service
+
+import (
+	"sort"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+)
+
+// vulnerabilityKey identifies the same vulnerability across two scans.
+type vulnerabilityKey struct {
+	vulnerabilityID  string
+	pkgName          string
+	installedVersion string
+}
+
+// DiffTasks compares the JSON reports of two completed tasks the principal can read.
+func (s *scanServiceImpl) DiffTasks(principal models.Principal, baseTaskID, targetTaskID string) (*models.ScanDiff, error) {
+	base, err := authorizeTask(s.repo, principal, baseTaskID, models.SharePermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	target, err := authorizeTask(s.repo, principal, targetTaskID, models.SharePermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	baseVulns, err := s.loadReportVulnerabilities(base)
+	if err != nil {
+		return nil, err
+	}
+	targetVulns, err := s.loadReportVulnerabilities(target)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.ScanDiff{
+		BaseTaskID:   base.ID,
+		BaseImage:    base.Image,
+		TargetTaskID: target.ID,
+		TargetImage:  target.Image,
+	}
+
+	for key, vuln := range targetVulns {
+		previous, ok := baseVulns[key]
+		if !ok {
+			addDiffVulnerability(&diff.Added, vuln)
+			continue
+		}
+		if previous.Severity != vuln.Severity {
+			vuln.PreviousSeverity = previous.Severity
+		}
+		addDiffVulnerability(&diff.Unchanged, vuln)
+	}
+	for key, vuln := range baseVulns {
+		if _, ok := targetVulns[key]; !ok {
+			addDiffVulnerability(&diff.Removed, vuln)
+		}
+	}
+
+	for _, bucket := range []*models.DiffBucket{&diff.Added, &diff.Removed, &diff.Unchanged} {
+		sortDiffVulnerabilities(bucket.Vulnerabilities)
+		if bucket.Vulnerabilities == nil {
+			bucket.Vulnerabilities = []models.DiffVulnerability{}
+		}
+	}
+
+	return diff, nil
+}
+
+// loadReportVulnerabilities returns the vulnerabilities of a task's report keyed by
+// VulnerabilityID + PkgName + InstalledVersion. A vulnerability reported for several
+// targets (e.g. OS packages and a lock file) is kept once.
+func (s *scanServiceImpl) loadReportVulnerabilities(task *models.ScanTask) (map[vulnerabilityKey]models.DiffVulnerability, error) {
+	report, err := s.loadFindings(task)
+	if err != nil {
+		return nil, err
+	}
+
+	vulns := make(map[vulnerabilityKey]models.DiffVulnerability)
+	for _, result := range report.Results {
+		for _, vuln := range result.Vulnerabilities {
+			key := vulnerabilityKey{vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion}
+			if _, exists := vulns[key]; exists {
+				continue
+			}
+			vulns[key] = models.DiffVulnerability{
+				VulnerabilityID:  vuln.VulnerabilityID,
+				PkgName:          vuln.PkgName,
+				InstalledVersion: vuln.InstalledVersion,
+				FixedVersion:     vuln.FixedVersion,
+				Severity:         vuln.Severity,
+				Title:            vuln.Title,
+				Target:           result.Target,
+			}
+		}
+	}
+
+	return vulns, nil
+}
+
+// addDiffVulnerability adds a vulnerability to a diff bucket and counts its severity.
+func addDiffVulnerability(bucket *models.DiffBucket, vuln models.DiffVulnerability) {
+	bucket.Vulnerabilities = append(bucket.Vulnerabilities, vuln)
+	bucket.Summary.Add(vuln.Severity)
+}
+
+// sortDiffVulnerabilities sorts vulnerabilities by severity (most severe first),
+// then by vulnerability ID and package.
+func sortDiffVulnerabilities(vulns []models.DiffVulnerability) {
+	sort.Slice(vulns, func(i, j int) bool {
+		a, b := vulns[i], vulns[j]
+		if severityOrder(a.Severity) != severityOrder(b.Severity) {
+			return severityOrder(a.Severity) < severityOrder(b.Severity)
+		}
+		if a.VulnerabilityID != b.VulnerabilityID {
+			return a.VulnerabilityID < b.VulnerabilityID
+		}
+		if a.PkgName != b.PkgName {
+			return a.PkgName < b.PkgName
+		}
+		return a.InstalledVersion < b.InstalledVersion
+	})
+}
@@ -10,21 +10,34 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
-	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
 	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/validator"
 	"github.com/lazycatapps/trivy/backend/internal/repository"
 	"github.com/lazycatapps/trivy/backend/internal/types"
 )
 
+const (
+	// defaultScanDuration is the assumed scan duration used for wait time
+	// estimates until enough scans have completed.
+	defaultScanDuration = 30 * time.Second
+
+	// scanDurationWindow is the number of recent scan durations kept for
+	// averaging.
+	scanDurationWindow = 20
+)
+
 // CommandExecutor defines the interface for executing external commands.
 type CommandExecutor interface {
 	// ExecuteCommand executes a command with streaming output callback.
@@ -108,12 +121,45 @@ type ScanService interface {
 	// CreateScanTask creates a new scan task and adds it to the queue.
 	CreateScanTask(userID string, req *models.ScanRequest) (*models.ScanTask, error)
 
-	// GetTask retrieves a scan task by ID.
+	// CreateUploadScanTask stores an uploaded scan target (image archive, SBOM,
+	// filesystem tarball or VM image) and adds a scan task for it to the queue.
+	// The upload is deleted once the scan finishes.
+	CreateUploadScanTask(userID string, req *models.ScanRequest, filename string, content io.Reader) (*models.ScanTask, error)
+
+	// UploadLimit returns the maximum size of an upload by a user in bytes, or a
+	// quota exceeded error if the user's storage quota is already used up.
+	UploadLimit(userID string) (int64, error)
+
+	// CreateSchedule creates a recurring scan of an image or saved configuration.
+	CreateSchedule(userID string, req *models.ScheduleRequest) (*models.Schedule, error)
+
+	// ListSchedules returns the schedules of a user with their last and next run.
+	ListSchedules(userID string) ([]*models.Schedule, error)
+
+	// SetSchedulePaused pauses or resumes a schedule of a user.
+	SetSchedulePaused(userID, scheduleID string, paused bool) (*models.Schedule, error)
+
+	// DeleteSchedule deletes a schedule of a user. Tasks it started are kept.
+	DeleteSchedule(userID, scheduleID string) error
+
+	// GetTask retrieves a scan task by ID without checking who may access it.
 	GetTask(taskID string) (*models.ScanTask, error)
 
+	// AuthorizeTask retrieves a scan task the principal owns or that is shared with one of the
+	// principal's groups with at least the required permission. Tasks the principal cannot read
+	// are reported as not found; read-only access to a task that must be managed is forbidden.
+	AuthorizeTask(principal models.Principal, taskID string, required models.SharePermission) (*models.ScanTask, error)
+
+	// ShareTask replaces the OIDC groups a task is shared with. Only the owner can change sharing.
+	ShareTask(principal models.Principal, taskID string, req *models.ShareTaskRequest) (*models.ScanTask, error)
+
 	// ListTasks retrieves scan tasks with pagination and filtering.
 	ListTasks(userID string, req *models.TaskListRequest) (*models.TaskListResponse, error)
 
+	// ExportTasks encodes all of the user's tasks matching the filter in the
+	// requested format (csv, json, xlsx). Returns the data and its MIME type.
+	ExportTasks(userID string, req *models.ExportRequest) ([]byte, string, error)
+
 	// GetQueueStatus returns the current queue status for a user.
 	GetQueueStatus(userID string) (*models.QueueStatusResponse, error)
 
@@ -132,6 +178,24 @@ type ScanService interface {
 	// DeleteAllTasks deletes all scan tasks and their report files for a user.
 	DeleteAllTasks(userID string) error
 
+	// PurgeTasks deletes the finished tasks of all users (or of req.UserID) that ended more
+	// than req.OlderThanDays days ago, together with their report files.
+	PurgeTasks(req *models.PurgeRequest) (*models.PurgeResponse, error)
+
+	// CancelTask cancels a queued or running scan task.
+	// Running scans are stopped by killing the trivy process.
+	CancelTask(taskID string) error
+
+	// Rescan creates a new scan task, owned by the principal, that replays the image and
+	// scan configuration of an existing task the principal can manage.
+	Rescan(principal models.Principal, taskID string, req *models.RescanRequest) (*models.ScanTask, error)
+
+	// ListFindings returns a filtered page of the structured findings of a task the principal can read.
+	ListFindings(principal models.Principal, taskID string, req *models.FindingListRequest) (*models.FindingListResponse, error)
+
+	// DiffTasks compares the vulnerabilities of two completed tasks the principal can read.
+	DiffTasks(principal models.Principal, baseTaskID, targetTaskID string) (*models.ScanDiff, error)
+
 	// Start starts the scan worker pool.
 	Start()
 
@@ -152,6 +216,57 @@ type scanServiceImpl struct {
 	stopCh     chan struct{}  // Signal to stop worker
 	wg         sync.WaitGroup // Wait group for graceful shutdown
 	mu         sync.Mutex     // Mutex for thread-safe operations
+
+	// Recent scan durations (ring buffer) for queue wait time estimates
+	scanDurations []time.Duration
+
+	// Last time a task was dispatched per user (round-robin fairness)
+	lastDispatched map[string]time.Time
+
+	// Dispatched scans, keyed by task ID
+	runningScans map[string]*runningScan
+
+	// Optional collaborators (set via ScanServiceOption)
+	configService *ConfigService                // Resolves saved configurations (credentials)
+	scheduleRepo  repository.ScheduleRepository // Persists scheduled scans
+	notifier      TaskNotifier                  // Sends notifications of finished tasks
+	reportStore   repository.ReportStore        // Stores report files (default: local disk)
+
+	// Serializes schedule updates between the API and the scheduler
+	scheduleMu sync.Mutex
+}
+
+// runningScan is a dispatched scan.
+type runningScan struct {
+	cancel context.CancelFunc // Stops the trivy process
+	done   chan struct{}      // Closed once executeScan has returned
+}
+
+// ScanServiceOption configures optional collaborators of the scan service.
+type ScanServiceOption func(*scanServiceImpl)
+
+// WithConfigService lets the scan service resolve registry credentials from
+// saved configurations (used by Rescan).
+func WithConfigService(configService *ConfigService) ScanServiceOption {
+	return func(s *scanServiceImpl) {
+		s.configService = configService
+	}
+}
+
+// WithNotifier lets the scan service notify completed and failed tasks
+// (e.g. to webhooks).
+func WithNotifier(notifier TaskNotifier) ScanServiceOption {
+	return func(s *scanServiceImpl) {
+		s.notifier = notifier
+	}
+}
+
+// WithReportStore stores report files in the given store instead of
+// {storageDir}/reports on local disk.
+func WithReportStore(store repository.ReportStore) ScanServiceOption {
+	return func(s *scanServiceImpl) {
+		s.reportStore = store
+	}
 }
 
 // NewScanService creates a new scan service instance.
@@ -160,8 +275,9 @@ func NewScanService(
 	config *types.TrivyConfig,
 	storageDir string,
 	logger logger.Logger,
+	opts ...ScanServiceOption,
 ) ScanService {
-	return NewScanServiceWithExecutor(repo, config, storageDir, logger, &realCommandExecutor{})
+	return NewScanServiceWithExecutor(repo, config, storageDir, logger, &realCommandExecutor{}, opts...)
 }
 
 // NewScanServiceWithExecutor creates a new scan service instance with custom executor.
@@ -172,13 +288,14 @@ func NewScanServiceWithExecutor(
 	storageDir string,
 	logger logger.Logger,
 	executor CommandExecutor,
+	opts ...ScanServiceOption,
 ) ScanService {
 	maxWorkers := config.MaxWorkers
 	if maxWorkers <= 0 {
 		maxWorkers = 5 // Default to 5 concurrent scans
 	}
 
-	return &scanServiceImpl{
+	s := &scanServiceImpl{
 		repo:       repo,
 		config:     config,
 		storageDir: storageDir,
@@ -186,7 +303,18 @@ func NewScanServiceWithExecutor(
 		executor:   executor,
 		workerPool: make(chan struct{}, maxWorkers),
 		stopCh:     make(chan struct{}),
+
+		lastDispatched: make(map[string]time.Time),
+		runningScans:   make(map[string]*runningScan),
+
+		reportStore: repository.NewLocalReportStore(filepath.Join(storageDir, "reports")),
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 // Start starts the scan worker pool.
@@ -196,6 +324,8 @@ func (s *scanServiceImpl) Start() {
 	// Mark all running tasks as failed (server restart recovery)
 	s.markInterruptedTasksAsFailed()
 
+	s.registerMetrics()
+
 	s.wg.Add(1)
 	go s.queueWorker()
 
@@ -207,6 +337,13 @@ func (s *scanServiceImpl) Start() {
 	} else {
 		s.logger.Info("Cleanup disabled (retention days = 0, keeping all scans)")
 	}
+
+	// Start scheduler if scheduled scans are enabled
+	if s.scheduleRepo != nil {
+		s.initSchedules(time.Now())
+		s.wg.Add(1)
+		go s.scheduleWorker()
+	}
 }
 
 // Stop stops the scan worker pool gracefully.
@@ -259,13 +396,26 @@ func (s *scanServiceImpl) cleanupWorker() {
 	}
 }
 
-// processQueue checks for queued tasks and starts scans if workers are available.
+// processQueue starts queued tasks until all workers are busy or the queue is empty.
 func (s *scanServiceImpl) processQueue() {
-	// Try to acquire a worker slot (non-blocking)
-	select {
-	case s.workerPool <- struct{}{}:
+	for {
+		// Do not start new scans once the service is stopping
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+
+		// Try to acquire a worker slot (non-blocking)
+		select {
+		case s.workerPool <- struct{}{}:
+		default:
+			// All workers busy, wait for the next tick or a finished scan
+			return
+		}
+
 		// Worker acquired, find next queued task
-		task := s.getNextQueuedTask()
+		task, ctx := s.getNextQueuedTask()
 		if task == nil {
 			// No queued tasks, release worker
 			<-s.workerPool
@@ -276,30 +426,285 @@ func (s *scanServiceImpl) processQueue() {
 		s.wg.Add(1)
 		go func() {
 			defer s.wg.Done()
-			defer func() { <-s.workerPool }() // Release worker when done
 
-			s.executeScan(task)
+			s.executeScan(ctx, task)
+
+			// Release worker and immediately hand it to the next queued task
+			<-s.workerPool
+			s.processQueue()
 		}()
-	default:
-		// All workers busy, skip this tick
 	}
 }
 
-// getNextQueuedTask retrieves the next queued task across all users (FIFO).
-func (s *scanServiceImpl) getNextQueuedTask() *models.ScanTask {
-	// Note: This is a simplified implementation.
-	// In production, you might want to use a proper queue with priorities.
+// getNextQueuedTask claims the next queued task according to the queue order.
+// The returned task is already stored as running so it cannot be dispatched twice;
+// it belongs to the caller, which persists further changes with saveTask.
+// The returned context carries the scan timeout and is cancelled by CancelTask.
+func (s *scanServiceImpl) getNextQueuedTask() (*models.ScanTask, context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// For now, we'll just scan through the repository
-	// This is not efficient but works for the MVP
-	return nil // Placeholder - will be improved
+	queue, err := s.orderedQueue()
+	if err != nil {
+		s.logger.Error("Failed to load scan queue: %v", err)
+		return nil, nil
+	}
+	if len(queue) == 0 {
+		return nil, nil
+	}
+
+	task := queue[0]
+	task.Status = models.ScanStatusRunning
+	task.Message = "Scan starting"
+	task.QueuePosition = 0
+	if err := s.repo.Update(task); err != nil {
+		s.logger.Error("Failed to claim task %s: %v", task.ID, err)
+		return nil, nil
+	}
+	s.lastDispatched[task.UserID] = time.Now()
+
+	// Register the scan context so the scan can be cancelled from outside
+	timeout := time.Duration(s.config.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	s.runningScans[task.ID] = &runningScan{cancel: cancel, done: make(chan struct{})}
+
+	return task, ctx
+}
+
+// releaseScan releases the context of a finished scan and signals that it exited.
+func (s *scanServiceImpl) releaseScan(taskID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if scan, ok := s.runningScans[taskID]; ok {
+		scan.cancel()
+		close(scan.done)
+		delete(s.runningScans, taskID)
+	}
+}
+
+// waitForScan blocks until the scan of a task, if one is running, has exited.
+func (s *scanServiceImpl) waitForScan(taskID string) {
+	s.mu.Lock()
+	scan, ok := s.runningScans[taskID]
+	s.mu.Unlock()
+
+	if ok {
+		<-scan.done
+	}
+}
+
+// orderedQueue returns all queued tasks in dispatch order.
+// Must be called with s.mu held.
+//
+// Tasks are served round-robin across users: every user gets one task started
+// per round. Tasks a user already has running count towards their rounds, so a
+// user who submits many images cannot starve others. Within a round, users who
+// were served least recently go first, then the oldest task wins (FIFO).
+//
+// Queued tasks are persisted by the repository, so the queue survives restarts;
+// only the dispatch history is in-memory and resets to plain FIFO.
+func (s *scanServiceImpl) orderedQueue() ([]*models.ScanTask, error) {
+	queued, err := s.repo.GetAllQueuedTasks()
+	if err != nil {
+		return nil, err
+	}
+	running, err := s.repo.GetAllRunningTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	return orderQueue(queued, running, s.lastDispatched), nil
+}
+
+// orderQueue orders FIFO-sorted queued tasks into round-robin dispatch order.
+// lastDispatched holds the last dispatch time per user (zero if never served).
+func orderQueue(queued, running []*models.ScanTask, lastDispatched map[string]time.Time) []*models.ScanTask {
+	// Round number each user's next queued task belongs to
+	nextRound := make(map[string]int)
+	for _, task := range running {
+		nextRound[task.UserID]++
+	}
+
+	rounds := make(map[string]int, len(queued))
+	ordered := make([]*models.ScanTask, len(queued))
+	for i, task := range queued {
+		rounds[task.ID] = nextRound[task.UserID]
+		nextRound[task.UserID]++
+		ordered[i] = task
+	}
+
+	// Stable sort keeps FIFO order for users with equal dispatch history
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if rounds[a.ID] != rounds[b.ID] {
+			return rounds[a.ID] < rounds[b.ID]
+		}
+		return lastDispatched[a.UserID].Before(lastDispatched[b.UserID])
+	})
+
+	return ordered
+}
+
+// queuePositions returns the 1-based queue position of every queued task.
+func (s *scanServiceImpl) queuePositions() (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, err := s.orderedQueue()
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]int, len(queue))
+	for i, task := range queue {
+		positions[task.ID] = i + 1
+	}
+
+	return positions, nil
+}
+
+// recordScanDuration records a completed scan duration for wait time estimates.
+func (s *scanServiceImpl) recordScanDuration(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scanDurations = append(s.scanDurations, d)
+	if len(s.scanDurations) > scanDurationWindow {
+		s.scanDurations = s.scanDurations[len(s.scanDurations)-scanDurationWindow:]
+	}
+}
+
+// averageScanDuration returns the average duration of recent scans.
+func (s *scanServiceImpl) averageScanDuration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.scanDurations) == 0 {
+		return defaultScanDuration
+	}
+
+	var total time.Duration
+	for _, d := range s.scanDurations {
+		total += d
+	}
+	return total / time.Duration(len(s.scanDurations))
 }
 
 // CreateScanTask creates a new scan task and adds it to the queue.
 func (s *scanServiceImpl) CreateScanTask(userID string, req *models.ScanRequest) (*models.ScanTask, error) {
-	// Generate unique task ID
-	taskID := uuid.New().String()
+	// Validate scan target
+	if req.TargetType == "" {
+		req.TargetType = models.TargetTypeImage
+	}
+	if !req.TargetType.IsValid() {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Unsupported target type: %s", req.TargetType))
+	}
+	if req.TargetType.IsUpload() {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Target type %s requires an uploaded file", req.TargetType))
+	}
+	if err := validateRemoteTarget(req.TargetType, req.Image); err != nil {
+		return nil, errors.WrapInvalidInput(err, err.Error())
+	}
+
+	task, err := s.newRemoteScanTask(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.enqueueTask(task)
+}
+
+// newRemoteScanTask creates (but does not enqueue) a scan task for a validated
+// remote target, enforcing the user's storage quota.
+func (s *scanServiceImpl) newRemoteScanTask(userID string, req *models.ScanRequest) (*models.ScanTask, error) {
+	if err := s.checkStorageQuota(userID); err != nil {
+		return nil, err
+	}
+
+	scanConfig := newScanConfig(req)
+	if err := s.resolvePolicy(userID, req.Policy, scanConfig); err != nil {
+		return nil, err
+	}
+	if err := s.resolveCheckBundle(userID, req, scanConfig); err != nil {
+		return nil, err
+	}
 
+	task := models.NewScanTask(uuid.New().String(), userID, req.Image, scanConfig)
+	task.RequestID = req.RequestID
+	return task, nil
+}
+
+// CreateUploadScanTask stores an uploaded scan target and adds a scan task for it to the queue.
+func (s *scanServiceImpl) CreateUploadScanTask(userID string, req *models.ScanRequest, filename string, content io.Reader) (*models.ScanTask, error) {
+	if !req.TargetType.IsUpload() && req.TargetType != models.TargetTypeImage {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Target type %q does not accept uploads", req.TargetType))
+	}
+	if err := s.checkStorageQuota(userID); err != nil {
+		return nil, err
+	}
+
+	filename = filepath.Base(filename)
+	validateName := validator.ValidateUploadFilename
+	if req.TargetType == models.TargetTypeVM {
+		validateName = validator.ValidateVMImageName
+	}
+	if err := validateName(filename); err != nil {
+		return nil, errors.WrapInvalidInput(err, err.Error())
+	}
+
+	// Registry credentials do not apply to uploaded targets
+	req.Username = ""
+	req.Password = ""
+
+	// The uploaded file name is shown as the scan target
+	scanConfig := newScanConfig(req)
+	scanConfig.ImageArchive = req.TargetType == models.TargetTypeImage
+	if err := s.resolvePolicy(userID, req.Policy, scanConfig); err != nil {
+		return nil, err
+	}
+	if err := s.resolveCheckBundle(userID, req, scanConfig); err != nil {
+		return nil, err
+	}
+	task := models.NewScanTask(uuid.New().String(), userID, filename, scanConfig)
+	task.RequestID = req.RequestID
+
+	err := s.storeUpload(task, content)
+	if err == nil {
+		// The stored upload counts towards the quota as well
+		err = s.checkStorageQuota(userID)
+	}
+	if err != nil {
+		if _, cleanupErr := s.deleteUpload(task); cleanupErr != nil {
+			s.logger.Error("Failed to clean up rejected upload for task %s: %v", task.ID, cleanupErr)
+		}
+		if err == errUploadTooLarge {
+			return nil, errors.NewPayloadTooLarge(fmt.Sprintf("Upload exceeds maximum size of %d bytes", s.maxUploadSize()))
+		}
+		if _, ok := err.(*errors.AppError); ok {
+			return nil, err
+		}
+		if _, ok := err.(*validator.ValidationError); ok {
+			return nil, errors.WrapInvalidInput(err, err.Error())
+		}
+		return nil, errors.WrapInternal(err, "Failed to store upload")
+	}
+
+	task.AddLog(fmt.Sprintf("Uploaded %s target: %s", req.TargetType, filename))
+
+	return s.enqueueTask(task)
+}
+
+// validateRemoteTarget validates the target reference of a non-upload scan.
+func validateRemoteTarget(targetType models.TargetType, target string) error {
+	if targetType == models.TargetTypeRepository {
+		return validator.ValidateRepositoryURL(target)
+	}
+	return validator.ValidateImageName(target)
+}
+
+// newScanConfig builds a scan configuration from a request, applying defaults.
+func newScanConfig(req *models.ScanRequest) *models.ScanConfig {
 	// Set default values
 	if req.TLSVerify == nil {
 		defaultTLS := true
@@ -317,8 +722,8 @@ func (s *scanServiceImpl) CreateScanTask(userID string, req *models.ScanRequest)
 	// Note: In client-server mode, database configuration is managed by Trivy Server
 	// Client does not need --skip-db-update, --db-repository, or --java-db-repository flags
 
-	// Create scan config
-	scanConfig := &models.ScanConfig{
+	return &models.ScanConfig{
+		TargetType:        req.TargetType,
 		Username:          req.Username,
 		Password:          req.Password,
 		TLSVerify:         *req.TLSVerify,
@@ -329,55 +734,132 @@ func (s *scanServiceImpl) CreateScanTask(userID string, req *models.ScanRequest)
 		PkgTypes:          req.PkgTypes,
 		Format:            req.Format,
 	}
+}
+
+// Rescan creates a new scan task, owned by the principal, that replays the image and
+// scan configuration of an existing task the principal can manage.
+func (s *scanServiceImpl) Rescan(principal models.Principal, taskID string, req *models.RescanRequest) (*models.ScanTask, error) {
+	// Managing the original task is required since its registry credentials are reused
+	parent, err := authorizeTask(s.repo, principal, taskID, models.SharePermissionManage)
+	if err != nil {
+		return nil, err
+	}
+	userID := principal.UserID
+	if parent.ScanConfig == nil {
+		return nil, errors.NewInvalidInput("Original task has no scan configuration")
+	}
+	if parent.ScanConfig.HasUpload() {
+		return nil, errors.NewInvalidInput("Uploaded targets cannot be rescanned: upload the file again")
+	}
+	if req == nil {
+		req = &models.RescanRequest{}
+	}
+
+	// Clone configuration so the new task never shares slices with the original
+	scanConfig := cloneScanConfig(parent.ScanConfig)
+
+	if err := s.resolveRescanCredentials(userID, scanConfig, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkStorageQuota(userID); err != nil {
+		return nil, err
+	}
+
+	task := models.NewScanTask(uuid.New().String(), userID, parent.Image, scanConfig)
+	task.ParentTaskID = parent.ID
+	task.RequestID = req.RequestID
+	task.AddLog(fmt.Sprintf("Rescan of task %s", parent.ID))
+
+	return s.enqueueTask(task)
+}
+
+// resolveRescanCredentials fills registry credentials of a rescan configuration.
+// Priority: explicit credentials, named saved configuration, original task credentials.
+func (s *scanServiceImpl) resolveRescanCredentials(userID string, scanConfig *models.ScanConfig, req *models.RescanRequest) error {
+	switch {
+	case req.Username != "" || req.Password != "":
+		scanConfig.Username = req.Username
+		scanConfig.Password = req.Password
+	case req.ConfigName != "":
+		if s.configService == nil {
+			return errors.NewInvalidInput("Saved configurations are not available")
+		}
+		saved, err := s.configService.GetConfig(userID, req.ConfigName)
+		if err != nil {
+			return err
+		}
+		// Saved configurations store credentials base64 encoded
+		scanConfig.Username = decodeFromBase64(saved.Username)
+		scanConfig.Password = decodeFromBase64(saved.Password)
+	}
+
+	// A username without password means the password was never persisted
+	if scanConfig.Username != "" && scanConfig.Password == "" {
+		return errors.NewInvalidInput("Registry password is required: re-supply credentials or choose a saved configuration")
+	}
+
+	return nil
+}
+
+// cloneScanConfig returns a deep copy of a scan configuration for a new scan.
+func cloneScanConfig(cfg *models.ScanConfig) *models.ScanConfig {
+	clone := cfg.Clone()
+	clone.Suppressions = nil // Resolved again when the new scan starts
+	return clone
+}
 
-	// Create scan task
-	task := models.NewScanTask(taskID, userID, req.Image, scanConfig)
+// enqueueTask persists a new task and dispatches it if a worker is free.
+func (s *scanServiceImpl) enqueueTask(task *models.ScanTask) (*models.ScanTask, error) {
+	taskID := task.ID
 
 	// Save task to repository
 	if err := s.repo.Create(task); err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
-	s.logger.Info("Created scan task %s for user %s (image: %s)", taskID, userID, req.Image)
+	log := s.taskLogger(task)
+	log.Info("Created scan task %s for user %s (image: %s)", taskID, task.UserID, task.Image)
 
-	// Try to start scan immediately if worker is available
-	select {
-	case s.workerPool <- struct{}{}:
-		// Worker acquired, start scan
-		s.wg.Add(1)
-		go func() {
-			defer s.wg.Done()
-			defer func() { <-s.workerPool }()
-			s.executeScan(task)
-		}()
-	default:
-		// All workers busy, task will wait in queue
-		s.logger.Info("All workers busy, task %s queued", taskID)
+	// Start scan immediately if a worker is available and the task is next in line
+	s.processQueue()
+
+	// Return the stored state: a worker may already own and modify the dispatched task
+	task, err := s.GetTask(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if task.Status == models.ScanStatusQueued {
+		log.Info("All workers busy, task %s queued (position %d)", taskID, task.QueuePosition)
 	}
 
 	return task, nil
 }
 
 // executeScan executes a Trivy scan for the given task.
-func (s *scanServiceImpl) executeScan(task *models.ScanTask) {
-	s.logger.Info("Starting scan for task %s (image: %s)", task.ID, task.Image)
+// The context carries the scan timeout and is cancelled when the user cancels the task.
+func (s *scanServiceImpl) executeScan(ctx context.Context, task *models.ScanTask) {
+	defer s.releaseScan(task.ID)
+	defer s.releaseUpload(task)
+
+	log := s.taskLogger(task)
+	log.Info("Starting scan for task %s (image: %s)", task.ID, task.Image)
+	runStart := time.Now()
 
 	// Update task status to running
 	task.Status = models.ScanStatusRunning
+	task.QueuePosition = 0
 	task.Message = "Scan in progress"
 	task.AddLog(fmt.Sprintf("Scan started at %s", time.Now().Format(time.RFC3339)))
-	s.repo.Update(task)
-
-	// Create context with timeout
-	timeout := time.Duration(s.config.Timeout) * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	if task.RequestID != "" {
+		task.AddLog(fmt.Sprintf("Request ID: %s", task.RequestID))
+	}
+	s.saveTask(task)
 
 	// Fetch and record Trivy Server version
 	versionCtx, versionCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer versionCancel()
 	if version, err := s.GetTrivyVersion(versionCtx); err != nil {
-		s.logger.Error("Failed to fetch Trivy Server version: %v", err)
+		log.Error("Failed to fetch Trivy Server version: %v", err)
 		task.AddLog(fmt.Sprintf("Warning: Could not fetch Trivy Server version: %v", err))
 		// Don't fail the scan, just continue without version info
 	} else {
@@ -393,42 +875,74 @@ func (s *scanServiceImpl) executeScan(task *models.ScanTask) {
 				version.JavaDB.Version,
 				version.JavaDB.UpdatedAt.Format(time.RFC3339)))
 		}
-		s.repo.Update(task)
+		s.saveTask(task)
+	}
+
+	// Resolve the suppression rules applying to this scan
+	if err := s.prepareSuppressions(task); err != nil {
+		s.failTask(task, fmt.Sprintf("Failed to prepare suppressions: %v", err))
+		return
 	}
 
 	// Build trivy command
 	args := s.buildTrivyArgs(task)
-	task.AddLog(fmt.Sprintf("Executing: trivy %s", strings.Join(s.maskCredentials(args), " ")))
+	command := strings.Join(s.maskCredentials(args), " ")
+	task.AddLog(fmt.Sprintf("Executing: trivy %s", command))
+	log.Info("Executing: trivy %s", command)
 
 	// Execute command with streaming logs
 	stdout, stderr, cmdErr := s.executor.ExecuteCommand(ctx, "trivy", args, func(line string) {
 		task.AddLog(line)
 	})
+	s.removeIgnoreFile(task)
 
 	// Handle command result
+	if ctx.Err() == context.Canceled {
+		s.mu.Lock()
+		s.finishCancelledTask(task, "Scan cancelled by user")
+		s.mu.Unlock()
+		return
+	}
 	if cmdErr != nil {
 		if ctx.Err() == context.DeadlineExceeded {
+			trivyFailuresTotal.WithLabelValues("timeout").Inc()
 			s.failTask(task, "Scan timeout exceeded")
 		} else {
+			trivyFailuresTotal.WithLabelValues("error").Inc()
 			s.failTask(task, fmt.Sprintf("Scan failed: %v\n%s", cmdErr, stderr))
 		}
 		return
 	}
 
 	// Parse scan results
-	result, err := s.parseScanResult(stdout, task.ScanConfig.Format)
+	result, report, err := s.parseScanResult(stdout, task.ScanConfig)
 	if err != nil {
 		s.failTask(task, fmt.Sprintf("Failed to parse scan result: %v", err))
 		return
 	}
 
-	// Save report to file
-	reportPath, err := s.saveReport(task.ID, task.ScanConfig.Format, stdout)
+	// Save report to the report store
+	reportKey, err := s.saveReport(task.ID, task.ScanConfig.Format, stdout)
 	if err != nil {
-		s.logger.Error("Failed to save report for task %s: %v", task.ID, err)
+		log.Error("Failed to save report for task %s: %v", task.ID, err)
 		// Don't fail the task, just log the error
 	} else {
-		task.AddLog(fmt.Sprintf("Report saved to: %s", reportPath))
+		task.AddLog(fmt.Sprintf("Report saved to: %s", reportKey))
+	}
+	if report != nil {
+		if err := s.saveFindings(task, report); err != nil {
+			log.Error("Failed to save findings for task %s: %v", task.ID, err)
+		}
+	}
+
+	// Evaluate the policy gate against the parsed findings
+	if task.ScanConfig.Policy != nil && report != nil {
+		task.PolicyVerdict = evaluatePolicy(task.ScanConfig.PolicyName, task.ScanConfig.Policy, report)
+		if task.PolicyVerdict.Passed {
+			task.AddLog(fmt.Sprintf("Policy %s passed", task.ScanConfig.PolicyName))
+		} else {
+			task.AddLog(fmt.Sprintf("Policy %s failed: %s", task.ScanConfig.PolicyName, strings.Join(task.PolicyVerdict.Reasons, "; ")))
+		}
 	}
 
 	// Update task with success
@@ -440,18 +954,28 @@ func (s *scanServiceImpl) executeScan(task *models.ScanTask) {
 	task.Output = stdout
 	task.AddLog(fmt.Sprintf("Scan completed at %s", endTime.Format(time.RFC3339)))
 	task.CloseAllLogListeners()
-	s.repo.Update(task)
-
-	s.logger.Info("Scan completed for task %s", task.ID)
+	s.saveTask(task)
+	s.recordScanDuration(endTime.Sub(runStart))
+	scanDurationSeconds.Observe(endTime.Sub(runStart).Seconds())
+	imageVulnerabilities.record(task)
+	scansTotal.WithLabelValues(string(models.ScanStatusCompleted)).Inc()
+	s.notify(task)
+
+	log.Info("Scan completed for task %s", task.ID)
 }
 
 // buildTrivyArgs builds the trivy command arguments from task configuration.
 func (s *scanServiceImpl) buildTrivyArgs(task *models.ScanTask) []string {
-	args := []string{"image"}
+	// The target type is the trivy subcommand (image, fs, repo, sbom, rootfs, vm)
+	targetType := task.ScanConfig.Target()
+	args := []string{string(targetType)}
 
-	// Trivy server connection
+	// Trivy server connection (the request ID lets server logs be correlated with the task)
 	if s.config.ServerURL != "" {
 		args = append(args, "--server", s.config.ServerURL)
+		if task.RequestID != "" {
+			args = append(args, "--custom-headers", "X-Request-ID:"+task.RequestID)
+		}
 	}
 
 	// Skip database updates (managed by Trivy Server in client-server mode)
@@ -460,18 +984,26 @@ func (s *scanServiceImpl) buildTrivyArgs(task *models.ScanTask) []string {
 	args = append(args, "--skip-db-update")
 	// args = append(args, "--skip-java-db-update")
 
-	// Force remote image source (pull from registry instead of local Docker/Containerd/Podman)
-	args = append(args, "--image-src", "remote")
+	// Uploaded image archives are read with --input; registry images use the
+	// remote image source (pull from registry instead of local Docker/Containerd/Podman)
+	imageArchive := targetType == models.TargetTypeImage && task.ScanConfig.ImageArchive
+	if imageArchive {
+		args = append(args, "--input", s.uploadTarget(task))
+	} else if targetType == models.TargetTypeImage {
+		args = append(args, "--image-src", "remote")
+	}
 
 	// Timeout for Trivy internal operations (registry access, scanning, etc.)
 	args = append(args, "--timeout", "10m")
 
-	// Authentication
-	if task.ScanConfig.Username != "" {
-		args = append(args, "--username", task.ScanConfig.Username)
-	}
-	if task.ScanConfig.Password != "" {
-		args = append(args, "--password", task.ScanConfig.Password)
+	// Registry authentication (registry images only)
+	if targetType == models.TargetTypeImage && !imageArchive {
+		if task.ScanConfig.Username != "" {
+			args = append(args, "--username", task.ScanConfig.Username)
+		}
+		if task.ScanConfig.Password != "" {
+			args = append(args, "--password", task.ScanConfig.Password)
+		}
 	}
 
 	// TLS verification
@@ -489,9 +1021,26 @@ func (s *scanServiceImpl) buildTrivyArgs(task *models.ScanTask) []string {
 		args = append(args, "--ignore-unfixed")
 	}
 
-	// Scanners
-	if len(task.ScanConfig.Scanners) > 0 {
-		args = append(args, "--scanners", strings.Join(task.ScanConfig.Scanners, ","))
+	// Scanners (an SBOM only lists packages, so only vuln and license apply)
+	scanners := task.ScanConfig.Scanners
+	if targetType == models.TargetTypeSBOM {
+		scanners = nil
+		for _, scanner := range task.ScanConfig.Scanners {
+			if scanner == "vuln" || scanner == "license" {
+				scanners = append(scanners, scanner)
+			}
+		}
+	}
+	if len(scanners) > 0 {
+		args = append(args, "--scanners", strings.Join(scanners, ","))
+	}
+
+	// Custom Rego checks of the pinned bundle version (misconfig scanner only)
+	if task.ScanConfig.CheckBundle != "" && s.configService != nil && containsString(scanners, "misconfig") {
+		args = append(args, "--config-check", s.configService.checkVersionDir(task.UserID, task.ScanConfig.CheckBundle, task.ScanConfig.CheckVersion))
+		if len(task.ScanConfig.CheckNamespaces) > 0 {
+			args = append(args, "--check-namespaces", strings.Join(task.ScanConfig.CheckNamespaces, ","))
+		}
 	}
 
 	// Detection priority
@@ -504,13 +1053,25 @@ func (s *scanServiceImpl) buildTrivyArgs(task *models.ScanTask) []string {
 		args = append(args, "--pkg-types", strings.Join(task.ScanConfig.PkgTypes, ","))
 	}
 
+	// Suppression rules; suppressed findings stay in JSON and table reports, marked with their justification
+	if len(task.ScanConfig.Suppressions) > 0 {
+		args = append(args, "--ignorefile", s.ignoreFilePath(task))
+		if task.ScanConfig.Format == "json" || task.ScanConfig.Format == "table" {
+			args = append(args, "--show-suppressed")
+		}
+	}
+
 	// Output format
 	if task.ScanConfig.Format != "" {
 		args = append(args, "--format", task.ScanConfig.Format)
 	}
 
-	// Image to scan
-	args = append(args, task.Image)
+	// Target to scan: remote reference, or the stored upload (image archives are passed via --input)
+	if targetType.IsUpload() {
+		args = append(args, s.uploadTarget(task))
+	} else if !imageArchive {
+		args = append(args, task.Image)
+	}
 
 	return args
 }
@@ -529,65 +1090,41 @@ func (s *scanServiceImpl) maskCredentials(args []string) []string {
 	return masked
 }
 
-// parseScanResult parses trivy output and extracts vulnerability summary.
-func (s *scanServiceImpl) parseScanResult(output, format string) (*models.ScanResult, error) {
+// parseScanResult parses trivy output and extracts the findings summary.
+// For JSON output, the typed report is returned as well (nil otherwise).
+func (s *scanServiceImpl) parseScanResult(output string, config *models.ScanConfig) (*models.ScanResult, *models.TrivyReport, error) {
 	result := &models.ScanResult{
-		Format: format,
+		Format: config.Format,
 		Data:   output,
 	}
 
-	// Parse vulnerability summary only for JSON format
-	if format == "json" {
-		summary, err := s.parseVulnerabilitySummary(output)
-		if err != nil {
-			s.logger.Error("Failed to parse vulnerability summary: %v", err)
-			// Don't fail, just skip summary
-		} else {
-			result.Summary = summary
-		}
+	// Parse the report only for JSON format
+	if config.Format != "json" {
+		return result, nil, nil
+	}
+
+	report, err := parseTrivyReport(output)
+	if err != nil {
+		s.logger.Error("Failed to parse vulnerability summary: %v", err)
+		// Don't fail, just skip summary
+		return result, nil, nil
 	}
+	result.Summary = report.Summary(config.Scanners)
 
-	return result, nil
+	return result, report, nil
 }
 
 // parseVulnerabilitySummary parses trivy JSON output to extract vulnerability statistics.
 func (s *scanServiceImpl) parseVulnerabilitySummary(jsonOutput string) (*models.VulnerabilitySummary, error) {
-	var trivyOutput struct {
-		Results []struct {
-			Vulnerabilities []struct {
-				Severity string `json:"Severity"`
-			} `json:"Vulnerabilities"`
-		} `json:"Results"`
-	}
-
-	if err := json.Unmarshal([]byte(jsonOutput), &trivyOutput); err != nil {
+	report, err := parseTrivyReport(jsonOutput)
+	if err != nil {
 		return nil, err
 	}
-
-	summary := &models.VulnerabilitySummary{}
-
-	for _, result := range trivyOutput.Results {
-		for _, vuln := range result.Vulnerabilities {
-			summary.Total++
-			switch vuln.Severity {
-			case "CRITICAL":
-				summary.Critical++
-			case "HIGH":
-				summary.High++
-			case "MEDIUM":
-				summary.Medium++
-			case "LOW":
-				summary.Low++
-			default:
-				summary.Unknown++
-			}
-		}
-	}
-
-	return summary, nil
+	return report.Summary(nil), nil
 }
 
-// saveReport saves the scan report to disk with user isolation.
+// saveReport saves the scan report to the report store with user isolation.
+// Returns the key of the stored report.
 func (s *scanServiceImpl) saveReport(taskID, format, data string) (string, error) {
 	// Get task to retrieve userID
 	task, err := s.repo.GetByID(taskID)
@@ -598,12 +1135,6 @@ func (s *scanServiceImpl) saveReport(taskID, format, data string) (string, error
 		return "", fmt.Errorf("task not found")
 	}
 
-	// Create user-specific reports directory: reports/users/{userID}/
-	reportsDir := filepath.Join(s.storageDir, "reports", "users", task.UserID)
-	if err := os.MkdirAll(reportsDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create reports directory: %w", err)
-	}
-
 	// Determine file extension
 	ext := format
 	if ext == "json" {
@@ -612,19 +1143,18 @@ func (s *scanServiceImpl) saveReport(taskID, format, data string) (string, error
 		ext = "txt"
 	}
 
-	// Save report
-	filename := fmt.Sprintf("%s.%s", taskID, ext)
-	filepath := filepath.Join(reportsDir, filename)
-
-	if err := os.WriteFile(filepath, []byte(data), 0644); err != nil {
-		return "", fmt.Errorf("failed to write report: %w", err)
+	// Save report: users/{userID}/{taskID}.{ext}
+	key := repository.ReportKey(task.UserID, taskID, ext)
+	if err := s.reportStore.Put(key, strings.NewReader(data), int64(len(data))); err != nil {
+		return "", err
 	}
 
-	return filepath, nil
+	return key, nil
 }
 
 // failTask marks a task as failed with error message.
 func (s *scanServiceImpl) failTask(task *models.ScanTask, errorMsg string) {
+	log := s.taskLogger(task)
 	endTime := time.Now()
 	task.Status = models.ScanStatusFailed
 	task.Message = "Scan failed"
@@ -633,9 +1163,105 @@ func (s *scanServiceImpl) failTask(task *models.ScanTask, errorMsg string) {
 	task.AddLog(fmt.Sprintf("ERROR: %s", errorMsg))
 	task.AddLog(fmt.Sprintf("Scan failed at %s", endTime.Format(time.RFC3339)))
 	task.CloseAllLogListeners()
-	s.repo.Update(task)
+	s.saveTask(task)
+	scansTotal.WithLabelValues(string(models.ScanStatusFailed)).Inc()
+	s.notify(task)
 
-	s.logger.Error("Scan failed for task %s: %s", task.ID, errorMsg)
+	log.Error("Scan failed for task %s: %s", task.ID, errorMsg)
+}
+
+// saveTask persists a task owned by a running scan (see saveTaskNoLock).
+func (s *scanServiceImpl) saveTask(task *models.ScanTask) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveTaskNoLock(task)
+}
+
+// saveTaskNoLock persists the scan state of a task. Sharing may change while the
+// scan runs, so the stored shares are kept; deleted tasks are not recreated.
+// Must be called with s.mu held (ShareTask updates tasks under s.mu as well).
+func (s *scanServiceImpl) saveTaskNoLock(task *models.ScanTask) {
+	stored, err := s.repo.GetByID(task.ID)
+	if err != nil {
+		s.logger.Error("Failed to get task %s: %v", task.ID, err)
+		return
+	}
+	if stored == nil {
+		return
+	}
+
+	task.Shares = stored.Shares
+	if err := s.repo.Update(task); err != nil {
+		s.logger.Error("Failed to update task %s: %v", task.ID, err)
+	}
+}
+
+// taskLogger returns a logger tagging messages with the task ID and, for tasks
+// created through the API, the ID of the HTTP request that created the task.
+func (s *scanServiceImpl) taskLogger(task *models.ScanTask) logger.Logger {
+	if task.RequestID == "" {
+		return logger.With(s.logger, "task_id", task.ID)
+	}
+	return logger.With(s.logger, "task_id", task.ID, "request_id", task.RequestID)
+}
+
+// notify passes a finished task to the notifier, if any.
+func (s *scanServiceImpl) notify(task *models.ScanTask) {
+	if s.notifier != nil {
+		s.notifier.Notify(task)
+	}
+}
+
+// CancelTask cancels a queued or running scan task.
+func (s *scanServiceImpl) CancelTask(taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.repo.GetByID(taskID)
+	if err != nil {
+		return errors.WrapInternal(err, "Failed to get task")
+	}
+	if task == nil {
+		return errors.ErrTaskNotFound
+	}
+
+	switch task.Status {
+	case models.ScanStatusQueued:
+		// Holding s.mu prevents the dispatcher from claiming the task meanwhile
+		s.finishCancelledTask(task, "Task cancelled by user before it started")
+		return nil
+	case models.ScanStatusRunning:
+		scan, ok := s.runningScans[taskID]
+		if !ok {
+			return errors.NewInvalidInput("Cannot cancel task: task is not managed by this server")
+		}
+		// Kill the trivy process; executeScan marks the task as cancelled
+		task.AddLog("Cancellation requested by user")
+		scan.cancel()
+		s.logger.Info("Cancellation requested for running task %s", taskID)
+		return nil
+	default:
+		return errors.NewInvalidInput("Cannot cancel task: task is already finished")
+	}
+}
+
+// finishCancelledTask marks a task as cancelled and closes its log streams.
+// Must be called with s.mu held.
+func (s *scanServiceImpl) finishCancelledTask(task *models.ScanTask, reason string) {
+	log := s.taskLogger(task)
+	endTime := time.Now()
+	task.Status = models.ScanStatusCancelled
+	task.Message = reason
+	task.EndTime = &endTime
+	task.QueuePosition = 0
+	task.AddLog(fmt.Sprintf("Scan cancelled at %s", endTime.Format(time.RFC3339)))
+	task.CloseAllLogListeners()
+	s.saveTaskNoLock(task)
+	s.releaseUpload(task)
+	scansTotal.WithLabelValues(string(models.ScanStatusCancelled)).Inc()
+
+	log.Info("Cancelled task %s: %s", task.ID, reason)
 }
 
 // GetTask retrieves a scan task by ID.
@@ -647,6 +1273,16 @@ func (s *scanServiceImpl) GetTask(taskID string) (*models.ScanTask, error) {
 	if task == nil {
 		return nil, fmt.Errorf("task not found")
 	}
+
+	// Refresh queue position so it reflects the current dispatch order
+	if task.Status == models.ScanStatusQueued {
+		positions, err := s.queuePositions()
+		if err != nil {
+			s.logger.Error("Failed to compute queue positions: %v", err)
+		}
+		task.QueuePosition = positions[task.ID]
+	}
+
 	return task, nil
 }
 
@@ -665,6 +1301,12 @@ func (s *scanServiceImpl) ListTasks(userID string, req *models.TaskListRequest)
 	if req.SortOrder == "" {
 		req.SortOrder = "desc"
 	}
+	if _, _, err := req.DateRange(); err != nil {
+		return nil, errors.NewInvalidInput(err.Error())
+	}
+	if _, err := req.FindingKinds(); err != nil {
+		return nil, errors.NewInvalidInput(err.Error())
+	}
 
 	// Get tasks from repository
 	tasks, total, err := s.repo.List(userID, req)
@@ -678,14 +1320,16 @@ func (s *scanServiceImpl) ListTasks(userID string, req *models.TaskListRequest)
 		summaries[i] = task.ToSummary()
 	}
 
-	// Update queue positions for queued tasks
-	queuedTasks, _ := s.repo.GetQueuedTasks(userID)
-	for i, qt := range queuedTasks {
-		for _, summary := range summaries {
-			if summary.ID == qt.ID {
-				summary.QueuePosition = i + 1
-				break
-			}
+	// Update queue positions for queued tasks (global dispatch order)
+	positions, err := s.queuePositions()
+	if err != nil {
+		s.logger.Error("Failed to compute queue positions: %v", err)
+	}
+	for _, summary := range summaries {
+		if summary.Status == string(models.ScanStatusQueued) {
+			summary.QueuePosition = positions[summary.ID]
+		} else {
+			summary.QueuePosition = 0
 		}
 	}
 
@@ -697,20 +1341,86 @@ func (s *scanServiceImpl) ListTasks(userID string, req *models.TaskListRequest)
 	}, nil
 }
 
+// ExportTasks encodes all of the user's tasks matching the filter in the requested format.
+func (s *scanServiceImpl) ExportTasks(userID string, req *models.ExportRequest) ([]byte, string, error) {
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = "csv"
+	}
+	if format == "excel" {
+		format = "xlsx"
+	}
+	if _, ok := historyExportMimeTypes[format]; !ok {
+		return nil, "", errors.NewInvalidInput(fmt.Sprintf("Unsupported export format: %s", req.Format))
+	}
+	if _, _, err := req.DateRange(); err != nil {
+		return nil, "", errors.NewInvalidInput(err.Error())
+	}
+	if _, err := req.FindingKinds(); err != nil {
+		return nil, "", errors.NewInvalidInput(err.Error())
+	}
+
+	// Export the whole filtered history, ignoring pagination
+	filter := req.TaskListRequest
+	filter.Page = 1
+	filter.PageSize = math.MaxInt32
+	if filter.SortBy == "" {
+		filter.SortBy = "startTime"
+	}
+	if filter.SortOrder == "" {
+		filter.SortOrder = "desc"
+	}
+
+	tasks, _, err := s.repo.List(userID, &filter)
+	if err != nil {
+		return nil, "", errors.WrapInternal(err, "Failed to list tasks")
+	}
+
+	data, mimeType, err := encodeTaskHistory(format, tasks)
+	if err != nil {
+		return nil, "", errors.WrapInternal(err, "Failed to export scan history")
+	}
+
+	return data, mimeType, nil
+}
+
 // GetQueueStatus returns the current queue status for a user.
 func (s *scanServiceImpl) GetQueueStatus(userID string) (*models.QueueStatusResponse, error) {
-	queuedTasks, err := s.repo.GetQueuedTasks(userID)
+	s.mu.Lock()
+	queue, err := s.orderedQueue()
+	s.mu.Unlock()
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate average wait time (simplified)
-	// In production, this should be based on historical data
-	avgWaitTime := float64(len(queuedTasks)) * 30.0 // Assume 30 seconds per task
+	running, err := s.repo.GetAllRunningTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the user's queued tasks and find the position of the last one
+	userQueued := 0
+	lastPosition := 0
+	for i, task := range queue {
+		if task.UserID == userID {
+			userQueued++
+			lastPosition = i + 1
+		}
+	}
+
+	// Each round of maxWorkers tasks takes roughly one average scan duration
+	maxWorkers := cap(s.workerPool)
+	avgScan := s.averageScanDuration()
+	rounds := (lastPosition + maxWorkers - 1) / maxWorkers
+	avgWaitTime := float64(rounds) * avgScan.Seconds()
 
 	return &models.QueueStatusResponse{
-		QueueLength:     len(queuedTasks),
+		QueueLength:     userQueued,
+		TotalQueued:     len(queue),
+		RunningTasks:    len(running),
+		MaxWorkers:      maxWorkers,
 		AverageWaitTime: avgWaitTime,
+		AverageScanTime: avgScan.Seconds(),
 	}, nil
 }
 
@@ -798,10 +1508,47 @@ func (s *scanServiceImpl) cleanupOldReports() {
 	s.logger.Info("Found %d old reports to delete", len(oldTasks))
 
 	// Delete tasks and their reports
+	deletedCount, deletedSize := s.deleteFinishedTasks(oldTasks)
+
+	// Log cleanup statistics
+	s.logger.Info("Cleanup completed: deleted %d reports, freed %.2f MB",
+		deletedCount, float64(deletedSize)/(1024*1024))
+}
+
+// PurgeTasks deletes the finished tasks of all users, or of a single user, that ended
+// before the given number of days ago, together with their report files.
+func (s *scanServiceImpl) PurgeTasks(req *models.PurgeRequest) (*models.PurgeResponse, error) {
+	if req.OlderThanDays < 0 {
+		return nil, errors.NewInvalidInput("olderThanDays must not be negative")
+	}
+
+	cutoffTime := time.Now().AddDate(0, 0, -req.OlderThanDays)
+	oldTasks, err := s.repo.GetAllOldTasks(cutoffTime)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to get tasks")
+	}
+
+	tasks := oldTasks[:0]
+	for _, task := range oldTasks {
+		if req.UserID == "" || task.UserID == req.UserID {
+			tasks = append(tasks, task)
+		}
+	}
+
+	deletedCount, deletedSize := s.deleteFinishedTasks(tasks)
+	s.logger.Info("Purge completed (older than %d days, user %q): deleted %d tasks, freed %.2f MB",
+		req.OlderThanDays, req.UserID, deletedCount, float64(deletedSize)/(1024*1024))
+
+	return &models.PurgeResponse{Deleted: deletedCount, FreedBytes: deletedSize}, nil
+}
+
+// deleteFinishedTasks deletes finished tasks and their report files.
+// Returns the number of deleted tasks and the size of the deleted files in bytes.
+func (s *scanServiceImpl) deleteFinishedTasks(tasks []*models.ScanTask) (int, int64) {
 	deletedCount := 0
 	deletedSize := int64(0)
 
-	for _, task := range oldTasks {
+	for _, task := range tasks {
 		// Delete report file
 		reportSize, err := s.deleteReport(task.ID)
 		if err != nil {
@@ -820,13 +1567,11 @@ func (s *scanServiceImpl) cleanupOldReports() {
 		deletedCount++
 	}
 
-	// Log cleanup statistics
-	s.logger.Info("Cleanup completed: deleted %d reports, freed %.2f MB",
-		deletedCount, float64(deletedSize)/(1024*1024))
+	return deletedCount, deletedSize
 }
 
-// deleteReport deletes the report file for a task with user isolation.
-// Returns the size of deleted file in bytes.
+// deleteReport deletes the report files and uploaded target of a task with user isolation.
+// Returns the size of deleted files in bytes.
 func (s *scanServiceImpl) deleteReport(taskID string) (int64, error) {
 	// Get task to retrieve userID
 	task, err := s.repo.GetByID(taskID)
@@ -837,32 +1582,24 @@ func (s *scanServiceImpl) deleteReport(taskID string) (int64, error) {
 		return 0, fmt.Errorf("task not found")
 	}
 
-	// User-specific reports directory: reports/users/{userID}/
-	reportsDir := filepath.Join(s.storageDir, "reports", "users", task.UserID)
-
-	// Try all possible extensions
-	extensions := []string{"json", "txt"}
+	// Try all possible extensions (original reports and cached conversions)
+	extensions := []string{"json", "txt", "sarif", "cyclonedx", "cyclonedx.json", "spdx", "spdx.json", "html", findingsExtension}
 	var totalSize int64
 
 	for _, ext := range extensions {
-		filename := fmt.Sprintf("%s.%s", taskID, ext)
-		filepath := filepath.Join(reportsDir, filename)
-
-		info, err := os.Stat(filepath)
+		size, err := s.reportStore.Delete(repository.ReportKey(task.UserID, taskID, ext))
 		if err != nil {
-			if os.IsNotExist(err) {
-				continue // File doesn't exist, try next extension
-			}
-			return 0, err
-		}
-
-		// Delete file
-		if err := os.Remove(filepath); err != nil {
 			return 0, err
 		}
+		totalSize += size
+	}
 
-		totalSize += info.Size()
+	// Uploaded scan target (SBOM, tarball, VM image)
+	uploadSize, err := s.deleteUpload(task)
+	if err != nil {
+		return 0, err
 	}
+	totalSize += uploadSize
 
 	return totalSize, nil
 }
@@ -918,6 +1655,15 @@ func (s *scanServiceImpl) ListDockerContainers() ([]models.DockerContainer, erro
 
 // DeleteTask deletes a scan task and its report files.
 func (s *scanServiceImpl) DeleteTask(taskID string) error {
+	// Stop the scan first if it is still queued or running, and wait until it has
+	// exited so that it cannot write reports after they were deleted
+	if task, _ := s.repo.GetByID(taskID); task != nil && !task.Status.IsFinished() {
+		if err := s.CancelTask(taskID); err != nil {
+			s.logger.Error("Failed to cancel task %s before deletion: %v", taskID, err)
+		}
+		s.waitForScan(taskID)
+	}
+
 	// Delete report files
 	if _, err := s.deleteReport(taskID); err != nil {
 		s.logger.Error("Failed to delete report for task %s: %v", taskID, err)
@@ -964,6 +1710,7 @@ func (s *scanServiceImpl) markInterruptedTasksAsFailed() {
 		} else {
 			s.logger.Info("Marked interrupted task %s as failed", task.ID)
 		}
+		s.releaseUpload(task)
 	}
 }
 
@@ -991,6 +1738,14 @@ func (s *scanServiceImpl) DeleteAllTasks(userID string) error {
 	var deletedSize int64
 
 	for _, task := range tasks {
+		// Stop scans that are still queued or running (see DeleteTask)
+		if !task.Status.IsFinished() {
+			if err := s.CancelTask(task.ID); err != nil {
+				s.logger.Error("Failed to cancel task %s before deletion: %v", task.ID, err)
+			}
+			s.waitForScan(task.ID)
+		}
+
 		// Delete report files
 		size, err := s.deleteReport(task.ID)
 		if err != nil {
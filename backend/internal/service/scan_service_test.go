@@ -4,12 +4,25 @@
 package service
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
 	"github.com/lazycatapps/trivy/backend/internal/repository"
 	"github.com/lazycatapps/trivy/backend/internal/types"
 )
@@ -45,6 +58,50 @@ func (m *mockCommandExecutor) ExecuteCommand(ctx context.Context, name string, a
 	return m.mockStdout, m.mockStderr, nil
 }
 
+// blockingCommandExecutor blocks every command until release is closed.
+// It records the last argument (scan target) of each executed command.
+type blockingCommandExecutor struct {
+	release chan struct{}
+	mu      sync.Mutex
+	targets []string
+}
+
+func (m *blockingCommandExecutor) ExecuteCommand(ctx context.Context, name string, args []string, logCallback func(string)) (string, string, error) {
+	m.mu.Lock()
+	m.targets = append(m.targets, args[len(args)-1])
+	m.mu.Unlock()
+
+	select {
+	case <-m.release:
+	case <-ctx.Done():
+		return "", "", ctx.Err()
+	}
+	return createMockJSONOutput(), "", nil
+}
+
+func (m *blockingCommandExecutor) executed() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.targets...)
+}
+
+// waitForTask polls a task until it is finished or five seconds have passed
+// and returns its latest state.
+func waitForTask(t *testing.T, service ScanService, taskID string) *models.ScanTask {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		task, err := service.GetTask(taskID)
+		if err != nil {
+			t.Fatalf("Failed to get task %s: %v", taskID, err)
+		}
+		if task.Status.IsFinished() || time.Now().After(deadline) {
+			return task
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 // createMockJSONOutput creates a valid Trivy JSON output for testing
 func createMockJSONOutput() string {
 	output := map[string]interface{}{
@@ -246,6 +303,135 @@ func TestGetTask(t *testing.T) {
 	}
 }
 
+// TestShareTask tests task ownership checks and sharing with OIDC groups
+func TestShareTask(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	service := NewScanServiceWithExecutor(repo, &types.TrivyConfig{MaxWorkers: 1}, t.TempDir(), &mockLogger{}, &mockCommandExecutor{})
+	defer service.Stop()
+
+	task := models.NewScanTask("task-1", "owner", "nginx", &models.ScanConfig{Format: "json"})
+	task.Status = models.ScanStatusCompleted
+	repo.Create(task)
+
+	owner := models.Principal{UserID: "owner"}
+	reader := models.Principal{UserID: "reader", Groups: []string{"dev"}}
+	manager := models.Principal{UserID: "manager", Groups: []string{"dev", "ops"}}
+	stranger := models.Principal{UserID: "stranger", Groups: []string{"sales"}}
+
+	expectStatus := func(t *testing.T, err error, status int) {
+		t.Helper()
+		appErr, ok := err.(*errors.AppError)
+		if !ok || appErr.StatusCode != status {
+			t.Errorf("Expected %d AppError, got %v", status, err)
+		}
+	}
+
+	t.Run("Unshared tasks are only visible to the owner", func(t *testing.T) {
+		if _, err := service.AuthorizeTask(owner, task.ID, models.SharePermissionManage); err != nil {
+			t.Errorf("Owner should manage the task: %v", err)
+		}
+		_, err := service.AuthorizeTask(reader, task.ID, models.SharePermissionRead)
+		expectStatus(t, err, http.StatusNotFound)
+	})
+
+	t.Run("Only the owner can share", func(t *testing.T) {
+		_, err := service.ShareTask(reader, task.ID, &models.ShareTaskRequest{})
+		expectStatus(t, err, http.StatusNotFound)
+
+		_, err = service.ShareTask(owner, task.ID, &models.ShareTaskRequest{Shares: []models.TaskShare{{Group: "dev", Permission: "admin"}}})
+		expectStatus(t, err, http.StatusBadRequest)
+
+		shared, err := service.ShareTask(owner, task.ID, &models.ShareTaskRequest{Shares: []models.TaskShare{
+			{Group: "dev", Permission: models.SharePermissionManage},
+			{Group: "ops", Permission: models.SharePermissionManage},
+			{Group: " dev ", Permission: models.SharePermissionRead},
+		}})
+		if err != nil {
+			t.Fatalf("ShareTask() error = %v", err)
+		}
+		if len(shared.Shares) != 2 || shared.Shares[0] != (models.TaskShare{Group: "dev", Permission: models.SharePermissionRead}) {
+			t.Errorf("Expected duplicate groups to be merged, got %+v", shared.Shares)
+		}
+
+		_, err = service.ShareTask(manager, task.ID, &models.ShareTaskRequest{})
+		expectStatus(t, err, http.StatusForbidden)
+	})
+
+	t.Run("Group permissions", func(t *testing.T) {
+		if _, err := service.AuthorizeTask(reader, task.ID, models.SharePermissionRead); err != nil {
+			t.Errorf("Read-only member should read the task: %v", err)
+		}
+		_, err := service.AuthorizeTask(reader, task.ID, models.SharePermissionManage)
+		expectStatus(t, err, http.StatusForbidden)
+
+		// The highest permission of the member's groups applies
+		if _, err := service.AuthorizeTask(manager, task.ID, models.SharePermissionManage); err != nil {
+			t.Errorf("Member of a managing group should manage the task: %v", err)
+		}
+
+		_, err = service.AuthorizeTask(stranger, task.ID, models.SharePermissionRead)
+		expectStatus(t, err, http.StatusNotFound)
+
+		_, err = service.Rescan(reader, task.ID, nil)
+		expectStatus(t, err, http.StatusForbidden)
+	})
+
+	t.Run("Admins can read all tasks", func(t *testing.T) {
+		admin := models.Principal{UserID: "admin", Admin: true}
+		if _, err := service.AuthorizeTask(admin, task.ID, models.SharePermissionRead); err != nil {
+			t.Errorf("Admin should read the task: %v", err)
+		}
+		_, err := service.AuthorizeTask(admin, task.ID, models.SharePermissionManage)
+		expectStatus(t, err, http.StatusForbidden)
+	})
+}
+
+// TestPurgeTasks tests purging old finished tasks of all users or of one user
+func TestPurgeTasks(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	service := NewScanServiceWithExecutor(repo, &types.TrivyConfig{MaxWorkers: 1}, t.TempDir(), &mockLogger{}, &mockCommandExecutor{})
+	defer service.Stop()
+
+	addTask := func(id, userID string, status models.ScanStatus, age time.Duration) {
+		task := models.NewScanTask(id, userID, "nginx", &models.ScanConfig{Format: "json"})
+		task.Status = status
+		endTime := time.Now().Add(-age)
+		task.EndTime = &endTime
+		repo.Create(task)
+	}
+	addTask("old-1", "user1", models.ScanStatusCompleted, 10*24*time.Hour)
+	addTask("old-2", "user2", models.ScanStatusFailed, 10*24*time.Hour)
+	addTask("recent", "user1", models.ScanStatusCompleted, time.Hour)
+	addTask("running", "user1", models.ScanStatusRunning, 10*24*time.Hour)
+
+	if _, err := service.PurgeTasks(&models.PurgeRequest{OlderThanDays: -1}); err == nil {
+		t.Error("Expected an error for a negative age")
+	}
+
+	result, err := service.PurgeTasks(&models.PurgeRequest{OlderThanDays: 7, UserID: "user2"})
+	if err != nil {
+		t.Fatalf("PurgeTasks() error = %v", err)
+	}
+	if result.Deleted != 1 {
+		t.Errorf("Expected 1 task of user2 to be purged, got %d", result.Deleted)
+	}
+
+	result, err = service.PurgeTasks(&models.PurgeRequest{OlderThanDays: 7})
+	if err != nil {
+		t.Fatalf("PurgeTasks() error = %v", err)
+	}
+	if result.Deleted != 1 {
+		t.Errorf("Expected 1 more old task to be purged, got %d", result.Deleted)
+	}
+
+	for id, kept := range map[string]bool{"old-1": false, "old-2": false, "recent": true, "running": true} {
+		task, _ := repo.GetByID(id)
+		if (task != nil) != kept {
+			t.Errorf("Task %s: expected kept=%v", id, kept)
+		}
+	}
+}
+
 // TestListTasks tests listing scan tasks
 func TestListTasks(t *testing.T) {
 	repo := repository.NewInMemoryScanRepository()
@@ -380,6 +566,973 @@ func TestGetQueueStatus(t *testing.T) {
 	}
 }
 
+// TestOrderQueue tests round-robin ordering of queued tasks across users
+func TestOrderQueue(t *testing.T) {
+	base := time.Now()
+	newTask := func(id, userID string, offset int, status models.ScanStatus) *models.ScanTask {
+		task := models.NewScanTask(id, userID, "alpine:latest", &models.ScanConfig{})
+		task.StartTime = base.Add(time.Duration(offset) * time.Second)
+		task.Status = status
+		return task
+	}
+
+	tests := []struct {
+		name           string
+		queued         []*models.ScanTask
+		running        []*models.ScanTask
+		lastDispatched map[string]time.Time
+		want           []string
+	}{
+		{
+			name: "Single user keeps FIFO order",
+			queued: []*models.ScanTask{
+				newTask("a1", "alice", 1, models.ScanStatusQueued),
+				newTask("a2", "alice", 2, models.ScanStatusQueued),
+				newTask("a3", "alice", 3, models.ScanStatusQueued),
+			},
+			want: []string{"a1", "a2", "a3"},
+		},
+		{
+			name: "Users are interleaved round-robin",
+			queued: []*models.ScanTask{
+				newTask("a1", "alice", 1, models.ScanStatusQueued),
+				newTask("a2", "alice", 2, models.ScanStatusQueued),
+				newTask("a3", "alice", 3, models.ScanStatusQueued),
+				newTask("b1", "bob", 4, models.ScanStatusQueued),
+				newTask("c1", "carol", 5, models.ScanStatusQueued),
+				newTask("b2", "bob", 6, models.ScanStatusQueued),
+			},
+			want: []string{"a1", "b1", "c1", "a2", "b2", "a3"},
+		},
+		{
+			name: "Running tasks count towards the user's rounds",
+			queued: []*models.ScanTask{
+				newTask("a2", "alice", 2, models.ScanStatusQueued),
+				newTask("a3", "alice", 3, models.ScanStatusQueued),
+				newTask("b1", "bob", 4, models.ScanStatusQueued),
+			},
+			running: []*models.ScanTask{
+				newTask("a1", "alice", 1, models.ScanStatusRunning),
+			},
+			want: []string{"b1", "a2", "a3"},
+		},
+		{
+			name: "Least recently served user goes first within a round",
+			queued: []*models.ScanTask{
+				newTask("a2", "alice", 2, models.ScanStatusQueued),
+				newTask("b1", "bob", 4, models.ScanStatusQueued),
+			},
+			lastDispatched: map[string]time.Time{
+				"alice": base,
+			},
+			want: []string{"b1", "a2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered := orderQueue(tt.queued, tt.running, tt.lastDispatched)
+
+			if len(ordered) != len(tt.want) {
+				t.Fatalf("Expected %d tasks, got %d", len(tt.want), len(ordered))
+			}
+			for i, id := range tt.want {
+				if ordered[i].ID != id {
+					t.Errorf("Expected position %d to be %s, got %s", i+1, id, ordered[i].ID)
+				}
+			}
+		})
+	}
+}
+
+// TestQueueDispatch tests that queued tasks are dispatched fairly when workers free up
+func TestQueueDispatch(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	config := &types.TrivyConfig{
+		Timeout:    600,
+		MaxWorkers: 1,
+	}
+	executor := &blockingCommandExecutor{release: make(chan struct{})}
+	service := NewScanServiceWithExecutor(repo, config, t.TempDir(), &mockLogger{}, executor).(*scanServiceImpl)
+
+	// alice fills the only worker and queues two more scans, then bob queues one
+	var tasks []*models.ScanTask
+	for _, item := range []struct{ user, image string }{
+		{"alice", "alice-1"},
+		{"alice", "alice-2"},
+		{"alice", "alice-3"},
+		{"bob", "bob-1"},
+	} {
+		task, err := service.CreateScanTask(item.user, &models.ScanRequest{Image: item.image})
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		tasks = append(tasks, task)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if tasks[0].Status != models.ScanStatusRunning {
+		t.Fatalf("Expected first task to be running, got %s", tasks[0].Status)
+	}
+
+	// bob's task jumps ahead of alice's backlog
+	resp, err := service.ListTasks("bob", &models.TaskListRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %v", err)
+	}
+	if len(resp.Tasks) != 1 || resp.Tasks[0].QueuePosition != 1 {
+		t.Errorf("Expected bob's task at queue position 1, got %+v", resp.Tasks)
+	}
+
+	status, err := service.GetQueueStatus("alice")
+	if err != nil {
+		t.Fatalf("Failed to get queue status: %v", err)
+	}
+	if status.QueueLength != 2 || status.TotalQueued != 3 || status.RunningTasks != 1 {
+		t.Errorf("Unexpected queue status: %+v", status)
+	}
+
+	// Release the worker and let the queue drain
+	close(executor.release)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && len(executor.executed()) < 4 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	service.Stop()
+
+	want := []string{"alice-1", "bob-1", "alice-2", "alice-3"}
+	got := executor.executed()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d executed scans, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected scan %d to be %s, got %s", i+1, want[i], got[i])
+		}
+	}
+}
+
+// TestRequestIDPropagation tests that the request ID reaches the task and its logs
+func TestRequestIDPropagation(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	config := &types.TrivyConfig{Timeout: 600, MaxWorkers: 1}
+	executor := &mockCommandExecutor{mockStdout: createMockJSONOutput()}
+	service := NewScanServiceWithExecutor(repo, config, t.TempDir(), &mockLogger{}, executor)
+	defer service.Stop()
+
+	task, err := service.CreateScanTask("alice", &models.ScanRequest{Image: "nginx:latest", RequestID: "req-123"})
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	task = waitForTask(t, service, task.ID)
+
+	if task.RequestID != "req-123" {
+		t.Errorf("Expected task request ID req-123, got %q", task.RequestID)
+	}
+	found := false
+	for _, line := range task.GetLogLines() {
+		if line == "Request ID: req-123" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected the request ID in the task logs, got %v", task.GetLogLines())
+	}
+}
+
+// TestDeleteRunningTask tests that deleting a running task waits for its scan to exit
+func TestDeleteRunningTask(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	config := &types.TrivyConfig{Timeout: 600, MaxWorkers: 1}
+	executor := &blockingCommandExecutor{release: make(chan struct{})}
+	service := NewScanServiceWithExecutor(repo, config, t.TempDir(), &mockLogger{}, executor).(*scanServiceImpl)
+	defer service.Stop()
+
+	task, err := service.CreateScanTask("user1", &models.ScanRequest{Image: "alpine:latest"})
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && len(executor.executed()) < 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := service.DeleteTask(task.ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+
+	service.mu.Lock()
+	running := len(service.runningScans)
+	service.mu.Unlock()
+	if running != 0 {
+		t.Errorf("Expected the scan to have exited, %d still running", running)
+	}
+	if stored, _ := repo.GetByID(task.ID); stored != nil {
+		t.Errorf("Expected the task to be deleted, got status %s", stored.Status)
+	}
+}
+
+// TestCancelTask tests cancelling queued and running scan tasks
+func TestCancelTask(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	config := &types.TrivyConfig{
+		Timeout:    600,
+		MaxWorkers: 1,
+	}
+	executor := &blockingCommandExecutor{release: make(chan struct{})}
+	service := NewScanServiceWithExecutor(repo, config, t.TempDir(), &mockLogger{}, executor).(*scanServiceImpl)
+	defer service.Stop()
+
+	running, err := service.CreateScanTask("user1", &models.ScanRequest{Image: "alpine:latest"})
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	queued, err := service.CreateScanTask("user1", &models.ScanRequest{Image: "nginx:latest"})
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	// Wait until the running scan has reached the executor
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && len(executor.executed()) < 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Cancel the queued task first so it is not dispatched when the worker frees up
+	logCh := queued.AddLogListener()
+	if err := service.CancelTask(queued.ID); err != nil {
+		t.Fatalf("Failed to cancel queued task: %v", err)
+	}
+	if queued, _ = service.GetTask(queued.ID); queued.Status != models.ScanStatusCancelled {
+		t.Errorf("Expected queued task to be cancelled, got %s", queued.Status)
+	}
+	for range logCh {
+		// Drain until the listener is closed
+	}
+
+	// Cancel the running task; the executor returns once its context is cancelled
+	if err := service.CancelTask(running.ID); err != nil {
+		t.Fatalf("Failed to cancel running task: %v", err)
+	}
+	running = waitForTask(t, service, running.ID)
+	if running.Status != models.ScanStatusCancelled {
+		t.Errorf("Expected running task to be cancelled, got %s", running.Status)
+	}
+	if running.EndTime == nil {
+		t.Error("Expected cancelled task to have an end time")
+	}
+
+	// Finished tasks cannot be cancelled again
+	if err := service.CancelTask(running.ID); err == nil {
+		t.Error("Expected error when cancelling a finished task")
+	}
+	if err := service.CancelTask("non-existent-id"); err == nil {
+		t.Error("Expected error when cancelling a non-existent task")
+	}
+
+	// The cancelled queued task must never have been executed
+	for _, target := range executor.executed() {
+		if target == "nginx:latest" {
+			t.Error("Cancelled queued task should not be executed")
+		}
+	}
+}
+
+// TestRescan tests cloning a previous task into a new scan task
+func TestRescan(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	config := &types.TrivyConfig{
+		Timeout:    600,
+		MaxWorkers: 1,
+	}
+	configService := NewConfigService(t.TempDir(), true, 4096, 10, &mockLogger{})
+	executor := &blockingCommandExecutor{release: make(chan struct{})}
+	service := NewScanServiceWithExecutor(repo, config, t.TempDir(), &mockLogger{}, executor,
+		WithConfigService(configService),
+	)
+	defer service.Stop()
+	defer close(executor.release)
+
+	// Original task with a username whose password was never persisted
+	parent := models.NewScanTask("parent-1", "user1", "private.registry/app:1.0", &models.ScanConfig{
+		Username:  "deployer",
+		TLSVerify: true,
+		Severity:  []string{"HIGH", "CRITICAL"},
+		Scanners:  []string{"vuln"},
+		Format:    "json",
+	})
+	parent.Status = models.ScanStatusCompleted
+	repo.Create(parent)
+
+	if err := configService.SaveConfig("user1", "prod", &models.SavedScanConfig{
+		Username: encodeToBase64("saved-user"),
+		Password: encodeToBase64("saved-pass"),
+	}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	t.Run("Missing password is rejected", func(t *testing.T) {
+		if _, err := service.Rescan(models.Principal{UserID: "user1"}, parent.ID, nil); err == nil {
+			t.Error("Expected error when password cannot be resolved")
+		}
+	})
+
+	t.Run("Other users cannot rescan", func(t *testing.T) {
+		if _, err := service.Rescan(models.Principal{UserID: "user2"}, parent.ID, &models.RescanRequest{Password: "x"}); err == nil {
+			t.Error("Expected error when rescanning another user's task")
+		}
+	})
+
+	t.Run("Explicit credentials", func(t *testing.T) {
+		task, err := service.Rescan(models.Principal{UserID: "user1"}, parent.ID, &models.RescanRequest{Username: "deployer", Password: "secret"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if task.ParentTaskID != parent.ID {
+			t.Errorf("Expected parentTaskId %s, got %s", parent.ID, task.ParentTaskID)
+		}
+		if task.Image != parent.Image {
+			t.Errorf("Expected image %s, got %s", parent.Image, task.Image)
+		}
+		if task.ScanConfig.Password != "secret" {
+			t.Errorf("Expected re-supplied password, got %q", task.ScanConfig.Password)
+		}
+
+		// The clone must not share slices with the original configuration
+		task.ScanConfig.Severity[0] = "LOW"
+		if parent.ScanConfig.Severity[0] != "HIGH" {
+			t.Error("Rescan config shares severity slice with original task")
+		}
+	})
+
+	t.Run("Credentials from saved config", func(t *testing.T) {
+		task, err := service.Rescan(models.Principal{UserID: "user1"}, parent.ID, &models.RescanRequest{ConfigName: "prod"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if task.ScanConfig.Username != "saved-user" || task.ScanConfig.Password != "saved-pass" {
+			t.Errorf("Expected decoded saved credentials, got %q/%q", task.ScanConfig.Username, task.ScanConfig.Password)
+		}
+	})
+}
+
+// TestDiffTasks tests comparing the vulnerabilities of two tasks
+func TestDiffTasks(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	storageDir := t.TempDir()
+	service := NewScanServiceWithExecutor(repo, &types.TrivyConfig{Timeout: 600, MaxWorkers: 1}, storageDir, &mockLogger{},
+		&mockCommandExecutor{mockStdout: createMockJSONOutput()}).(*scanServiceImpl)
+	defer service.Stop()
+
+	newCompletedTask := func(id, userID, format string) *models.ScanTask {
+		task := models.NewScanTask(id, userID, "registry.example.com/app:"+id, &models.ScanConfig{Format: format})
+		task.Status = models.ScanStatusCompleted
+		repo.Create(task)
+		return task
+	}
+
+	// Base report, stored on disk
+	base := newCompletedTask("base", "user1", "json")
+	baseReport := `{"Results": [
+		{"Target": "debian", "Vulnerabilities": [
+			{"VulnerabilityID": "CVE-1", "PkgName": "openssl", "InstalledVersion": "1.0", "FixedVersion": "1.1", "Severity": "CRITICAL"},
+			{"VulnerabilityID": "CVE-2", "PkgName": "zlib", "InstalledVersion": "1.2", "Severity": "MEDIUM"},
+			{"VulnerabilityID": "CVE-3", "PkgName": "curl", "InstalledVersion": "7.0", "Severity": "LOW"}
+		]},
+		{"Target": "app/package-lock.json", "Vulnerabilities": [
+			{"VulnerabilityID": "CVE-2", "PkgName": "zlib", "InstalledVersion": "1.2", "Severity": "MEDIUM"}
+		]}
+	]}`
+	if _, err := service.saveReport(base.ID, "json", baseReport); err != nil {
+		t.Fatalf("Failed to save base report: %v", err)
+	}
+
+	// Target report, only available from the stored task result
+	target := newCompletedTask("target", "user1", "json")
+	target.Result = &models.ScanResult{Format: "json", Data: `{"Results": [
+		{"Target": "debian", "Vulnerabilities": [
+			{"VulnerabilityID": "CVE-2", "PkgName": "zlib", "InstalledVersion": "1.2", "Severity": "HIGH"},
+			{"VulnerabilityID": "CVE-3", "PkgName": "curl", "InstalledVersion": "7.1", "Severity": "LOW"},
+			{"VulnerabilityID": "CVE-4", "PkgName": "bash", "InstalledVersion": "5.0", "Severity": "CRITICAL"}
+		]}
+	]}`}
+	repo.Update(target)
+
+	newCompletedTask("table", "user1", "table")
+	newCompletedTask("other", "user2", "json")
+	running := models.NewScanTask("running", "user1", "alpine:latest", &models.ScanConfig{Format: "json"})
+	repo.Create(running)
+
+	diff, err := service.DiffTasks(models.Principal{UserID: "user1"}, base.ID, target.ID)
+	if err != nil {
+		t.Fatalf("DiffTasks() error = %v", err)
+	}
+
+	ids := func(bucket models.DiffBucket) []string {
+		var result []string
+		for _, vuln := range bucket.Vulnerabilities {
+			result = append(result, vuln.VulnerabilityID+"/"+vuln.InstalledVersion)
+		}
+		return result
+	}
+
+	// A changed installed version is a different vulnerability: CVE-3 is fixed in 7.0 and new in 7.1
+	if got := ids(diff.Added); strings.Join(got, ",") != "CVE-4/5.0,CVE-3/7.1" {
+		t.Errorf("Unexpected added vulnerabilities: %v", got)
+	}
+	if got := ids(diff.Removed); strings.Join(got, ",") != "CVE-1/1.0,CVE-3/7.0" {
+		t.Errorf("Unexpected removed vulnerabilities: %v", got)
+	}
+	if got := ids(diff.Unchanged); strings.Join(got, ",") != "CVE-2/1.2" {
+		t.Errorf("Unexpected unchanged vulnerabilities: %v", got)
+	}
+
+	if diff.Added.Summary.Total != 2 || diff.Added.Summary.Critical != 1 || diff.Added.Summary.Low != 1 {
+		t.Errorf("Unexpected added summary: %+v", diff.Added.Summary)
+	}
+	if diff.Removed.Summary.Total != 2 || diff.Removed.Summary.Critical != 1 || diff.Removed.Summary.Low != 1 {
+		t.Errorf("Unexpected removed summary: %+v", diff.Removed.Summary)
+	}
+	unchanged := diff.Unchanged.Vulnerabilities[0]
+	if diff.Unchanged.Summary.High != 1 || unchanged.Severity != "HIGH" || unchanged.PreviousSeverity != "MEDIUM" {
+		t.Errorf("Expected re-rated vulnerability with previous severity, got %+v", unchanged)
+	}
+
+	errorTests := []struct {
+		name       string
+		base       string
+		target     string
+		wantStatus int
+	}{
+		{"Missing task", base.ID, "missing", http.StatusNotFound},
+		{"Task of another user", "other", target.ID, http.StatusNotFound},
+		{"Task not completed", base.ID, running.ID, http.StatusBadRequest},
+		{"Non-JSON report", "table", target.ID, http.StatusBadRequest},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.DiffTasks(models.Principal{UserID: "user1"}, tt.base, tt.target)
+			appErr, ok := err.(*errors.AppError)
+			if !ok || appErr.StatusCode != tt.wantStatus {
+				t.Errorf("Expected %d AppError, got %v", tt.wantStatus, err)
+			}
+		})
+	}
+}
+
+// TestListFindings tests parsing, storing, filtering and paginating task findings
+func TestListFindings(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	service := NewScanServiceWithExecutor(repo, &types.TrivyConfig{Timeout: 600, MaxWorkers: 1}, t.TempDir(), &mockLogger{},
+		&mockCommandExecutor{mockStdout: createMockJSONOutput()}).(*scanServiceImpl)
+	defer service.Stop()
+
+	// Trivy output uses PascalCase field names
+	output := `{"SchemaVersion": 2, "ArtifactName": "app:1.0", "ArtifactType": "container_image", "Results": [
+		{"Target": "app:1.0 (debian 12.5)", "Class": "os-pkgs", "Type": "debian", "Vulnerabilities": [
+			{"VulnerabilityID": "CVE-1", "PkgName": "openssl", "InstalledVersion": "3.0.1", "FixedVersion": "3.0.2", "Status": "fixed", "Severity": "LOW",
+			 "Title": "openssl: timing attack", "CweIDs": ["CWE-203"], "References": ["https://example.com/CVE-1"],
+			 "CVSS": {"nvd": {"V3Vector": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:N", "V3Score": 5.9}}},
+			{"VulnerabilityID": "CVE-2", "PkgName": "zlib1g", "InstalledVersion": "1.2.13", "Status": "affected", "Severity": "CRITICAL"},
+			{"VulnerabilityID": "CVE-3", "PkgName": "curl", "InstalledVersion": "7.88", "FixedVersion": "7.89", "Status": "fixed", "Severity": "HIGH"}
+		]},
+		{"Target": "Dockerfile", "Class": "config", "Type": "dockerfile",
+		 "MisconfSummary": {"Successes": 20, "Failures": 1, "Exceptions": 0},
+		 "Misconfigurations": [
+			{"Type": "Dockerfile Security Check", "ID": "DS002", "AVDID": "AVD-DS-0002", "Title": "Image user should not be root", "Severity": "HIGH", "Status": "FAIL",
+			 "CauseMetadata": {"Provider": "Dockerfile", "Service": "general", "StartLine": 1, "EndLine": 1}}
+		]},
+		{"Target": "/app/.env", "Class": "secret", "Secrets": [
+			{"RuleID": "aws-access-key-id", "Category": "AWS", "Severity": "CRITICAL", "Title": "AWS Access Key ID", "StartLine": 3, "EndLine": 3, "Match": "AWS_ACCESS_KEY_ID=****"}
+		]},
+		{"Target": "OS Packages", "Class": "license", "Licenses": [
+			{"Severity": "HIGH", "Category": "restricted", "PkgName": "bash", "Name": "GPL-3.0", "Confidence": 1}
+		]}
+	]}`
+
+	task := models.NewScanTask("task-1", "user1", "app:1.0", &models.ScanConfig{Format: "json"})
+	task.Status = models.ScanStatusCompleted
+	task.Result = &models.ScanResult{Format: "json", Data: output}
+	repo.Create(task)
+
+	tableTask := models.NewScanTask("task-table", "user1", "app:1.0", &models.ScanConfig{Format: "table"})
+	tableTask.Status = models.ScanStatusCompleted
+	repo.Create(tableTask)
+
+	t.Run("Typed model", func(t *testing.T) {
+		response, err := service.ListFindings(models.Principal{UserID: "user1"}, task.ID, &models.FindingListRequest{Kind: models.FindingKindVulnerability, Search: "CVE-1"})
+		if err != nil {
+			t.Fatalf("ListFindings() error = %v", err)
+		}
+		if response.ArtifactName != "app:1.0" || len(response.Targets) != 4 || len(response.Findings) != 1 {
+			t.Fatalf("Unexpected response: %+v", response)
+		}
+		finding := response.Findings[0]
+		vuln := finding.Vulnerability
+		if finding.Class != "os-pkgs" || vuln.FixedVersion != "3.0.2" || vuln.CweIDs[0] != "CWE-203" || len(vuln.References) != 1 {
+			t.Errorf("Vulnerability not fully decoded: %+v", vuln)
+		}
+		if vuln.CVSS["nvd"].V3Score != 5.9 {
+			t.Errorf("Expected CVSS score 5.9, got %+v", vuln.CVSS)
+		}
+
+		// Findings are stored with the task's reports
+		if _, err := service.reportStore.Stat(service.findingsKey(task)); err != nil {
+			t.Errorf("Expected stored findings: %v", err)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		req     models.FindingListRequest
+		wantIDs []string
+		total   int
+	}{
+		{"All vulnerabilities, most severe first", models.FindingListRequest{Kind: "vulnerability"}, []string{"CVE-2", "CVE-3", "CVE-1"}, 3},
+		{"Severity filter", models.FindingListRequest{Kind: "vulnerability", Severity: "high, critical"}, []string{"CVE-2", "CVE-3"}, 2},
+		{"Fix available", models.FindingListRequest{Kind: "vulnerability", FixAvailable: true}, []string{"CVE-3", "CVE-1"}, 2},
+		{"Search package", models.FindingListRequest{Kind: "vulnerability", Search: "ZLIB"}, []string{"CVE-2"}, 1},
+		{"Status filter", models.FindingListRequest{Kind: "vulnerability", Status: "affected"}, []string{"CVE-2"}, 1},
+		{"Pagination", models.FindingListRequest{Kind: "vulnerability", Page: 2, PageSize: 2}, []string{"CVE-1"}, 3},
+		{"Page out of range", models.FindingListRequest{Kind: "vulnerability", Page: 3, PageSize: 2}, nil, 3},
+		{"Target filter", models.FindingListRequest{Kind: "vulnerability", Target: "Dockerfile"}, nil, 0},
+		{"Misconfigurations", models.FindingListRequest{Kind: "misconfiguration", Status: "FAIL"}, []string{"DS002"}, 1},
+		{"Secrets", models.FindingListRequest{Kind: "secret", Search: "aws"}, []string{"aws-access-key-id"}, 1},
+		{"Licenses", models.FindingListRequest{Kind: "license", Severity: "HIGH"}, []string{"GPL-3.0"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			response, err := service.ListFindings(models.Principal{UserID: "user1"}, task.ID, &req)
+			if err != nil {
+				t.Fatalf("ListFindings() error = %v", err)
+			}
+
+			var ids []string
+			for _, finding := range response.Findings {
+				switch {
+				case finding.Vulnerability != nil:
+					ids = append(ids, finding.Vulnerability.VulnerabilityID)
+				case finding.Misconfiguration != nil:
+					ids = append(ids, finding.Misconfiguration.ID)
+				case finding.Secret != nil:
+					ids = append(ids, finding.Secret.RuleID)
+				case finding.License != nil:
+					ids = append(ids, finding.License.Name)
+				}
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") || response.Total != tt.total {
+				t.Errorf("Expected %v (total %d), got %v (total %d)", tt.wantIDs, tt.total, ids, response.Total)
+			}
+		})
+	}
+
+	errorTests := []struct {
+		name       string
+		userID     string
+		taskID     string
+		kind       string
+		wantStatus int
+	}{
+		{"Unknown kind", "user1", task.ID, "malware", http.StatusBadRequest},
+		{"Task of another user", "user2", task.ID, "vulnerability", http.StatusNotFound},
+		{"Non-JSON report", "user1", tableTask.ID, "vulnerability", http.StatusBadRequest},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ListFindings(models.Principal{UserID: tt.userID}, tt.taskID, &models.FindingListRequest{Kind: tt.kind})
+			appErr, ok := err.(*errors.AppError)
+			if !ok || appErr.StatusCode != tt.wantStatus {
+				t.Errorf("Expected %d AppError, got %v", tt.wantStatus, err)
+			}
+		})
+	}
+}
+
+// TestExportTasks tests exporting the scan history in each supported format
+func TestExportTasks(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	service := NewScanServiceWithExecutor(repo, &types.TrivyConfig{MaxWorkers: 1}, t.TempDir(), &mockLogger{}, &mockCommandExecutor{})
+
+	start := time.Date(2025, 10, 1, 8, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Second)
+	completed := models.NewScanTask("task-1", "user1", "alpine:3.19", &models.ScanConfig{})
+	completed.Status = models.ScanStatusCompleted
+	completed.StartTime = start
+	completed.EndTime = &end
+	completed.Result = &models.ScanResult{
+		Format: "json",
+		Summary: &models.VulnerabilitySummary{
+			Total:    3,
+			Critical: 1,
+			High:     2,
+			Secrets:  &models.SecretSummary{Total: 1, Categories: map[string]int{"AWS": 1}},
+		},
+	}
+	completed.TrivyVersion = &models.TrivyVersion{
+		Version:         "0.58.0",
+		VulnerabilityDB: &models.DatabaseInfo{Version: 2, UpdatedAt: start.Add(-time.Hour)},
+	}
+	repo.Create(completed)
+
+	failed := models.NewScanTask("task-2", "user1", "nginx:<latest>", &models.ScanConfig{})
+	failed.Status = models.ScanStatusFailed
+	failed.StartTime = start.Add(48 * time.Hour)
+	repo.Create(failed)
+
+	other := models.NewScanTask("task-3", "user2", "redis:7", &models.ScanConfig{})
+	other.StartTime = start
+	repo.Create(other)
+
+	t.Run("CSV", func(t *testing.T) {
+		data, mimeType, err := service.ExportTasks("user1", &models.ExportRequest{Format: "csv"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(mimeType, "text/csv") {
+			t.Errorf("Expected text/csv, got %s", mimeType)
+		}
+
+		rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			t.Fatalf("Invalid CSV: %v", err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("Expected header + 2 rows, got %d", len(rows))
+		}
+		// Newest first: the failed task comes before the completed one
+		want := []string{"task-1", "alpine:3.19", "completed", "2025-10-01T08:00:00Z", "2025-10-01T08:01:30Z",
+			"90", "3", "1", "2", "0", "0", "0", "0", "1", "0", "0.58.0", "2", "2025-10-01T07:00:00Z"}
+		if strings.Join(rows[2], ",") != strings.Join(want, ",") {
+			t.Errorf("Unexpected row:\n got %v\nwant %v", rows[2], want)
+		}
+	})
+
+	t.Run("JSON with filters", func(t *testing.T) {
+		req := &models.ExportRequest{Format: "json"}
+		req.Status = "completed"
+		req.EndDate = "2025-10-01"
+		data, _, err := service.ExportTasks("user1", req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var records []map[string]interface{}
+		if err := json.Unmarshal(data, &records); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		if len(records) != 1 || records[0]["id"] != "task-1" {
+			t.Fatalf("Expected only task-1, got %v", records)
+		}
+		if records[0]["vulnerabilityDbVersion"] != "2" {
+			t.Errorf("Expected DB version 2, got %v", records[0]["vulnerabilityDbVersion"])
+		}
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		data, _, err := service.ExportTasks("user1", &models.ExportRequest{Format: "excel"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("Invalid XLSX archive: %v", err)
+		}
+		var sheet []byte
+		for _, f := range zr.File {
+			if f.Name == "xl/worksheets/sheet1.xml" {
+				rc, _ := f.Open()
+				sheet, _ = io.ReadAll(rc)
+				rc.Close()
+			}
+		}
+		if sheet == nil {
+			t.Fatal("Worksheet not found in XLSX archive")
+		}
+		if err := xml.Unmarshal(sheet, new(interface{})); err != nil {
+			t.Errorf("Worksheet is not well-formed XML: %v", err)
+		}
+		if !bytes.Contains(sheet, []byte("nginx:&lt;latest&gt;")) {
+			t.Error("Expected escaped image name in worksheet")
+		}
+		if !bytes.Contains(sheet, []byte(`<c r="G3"><v>3</v></c>`)) {
+			t.Error("Expected numeric total cell for task-1")
+		}
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		if _, _, err := service.ExportTasks("user1", &models.ExportRequest{Format: "pdf"}); err == nil {
+			t.Error("Expected error for unsupported format")
+		}
+		req := &models.ExportRequest{Format: "csv"}
+		req.StartDate = "yesterday"
+		if _, _, err := service.ExportTasks("user1", req); err == nil {
+			t.Error("Expected error for invalid startDate")
+		}
+	})
+}
+
+// buildTarball creates an in-memory tarball (gzip-compressed if compress is set).
+func buildTarball(t *testing.T, compress bool, entries []*tar.Header, contents []string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		w = gz
+	}
+
+	tw := tar.NewWriter(w)
+	for i, header := range entries {
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(contents[i]))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			tw.Write([]byte(contents[i]))
+		}
+	}
+	tw.Close()
+	if gz != nil {
+		gz.Close()
+	}
+
+	return buf.Bytes()
+}
+
+// TestCreateUploadScanTask tests storing uploaded scan targets
+func TestCreateUploadScanTask(t *testing.T) {
+	repo := repository.NewInMemoryScanRepository()
+	storageDir := t.TempDir()
+	config := &types.TrivyConfig{
+		Timeout:       600,
+		MaxWorkers:    1,
+		MaxUploadSize: 4096,
+	}
+	executor := &blockingCommandExecutor{release: make(chan struct{})}
+	service := NewScanServiceWithExecutor(repo, config, storageDir, &mockLogger{}, executor).(*scanServiceImpl)
+	defer service.Stop()
+	defer close(executor.release)
+
+	t.Run("SBOM upload", func(t *testing.T) {
+		sbom := `{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": []}`
+		task, err := service.CreateUploadScanTask("user1", &models.ScanRequest{
+			TargetType: models.TargetTypeSBOM,
+			Username:   "ignored",
+			Password:   "ignored",
+		}, "bom.cdx.json", strings.NewReader(sbom))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if task.Image != "bom.cdx.json" || task.ScanConfig.Target() != models.TargetTypeSBOM {
+			t.Errorf("Unexpected task target: %s (%s)", task.Image, task.ScanConfig.Target())
+		}
+		if task.ScanConfig.Username != "" || task.ScanConfig.Password != "" {
+			t.Error("Registry credentials should be dropped for uploads")
+		}
+
+		data, err := os.ReadFile(service.uploadTarget(task))
+		if err != nil || string(data) != sbom {
+			t.Fatalf("Uploaded SBOM not stored: %v", err)
+		}
+
+		// Deleting the task removes the upload
+		if err := service.DeleteTask(task.ID); err != nil {
+			t.Fatalf("Failed to delete task: %v", err)
+		}
+		if _, err := os.Stat(service.uploadDir(task.UserID, task.ID)); !os.IsNotExist(err) {
+			t.Error("Upload directory should be removed with the task")
+		}
+	})
+
+	t.Run("Rootfs tarball is extracted safely", func(t *testing.T) {
+		tarball := buildTarball(t, true, []*tar.Header{
+			{Name: "etc/", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "etc/os-release", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "etc/passwd-link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		}, []string{"", "ID=alpine\n", ""})
+
+		task, err := service.CreateUploadScanTask("user1", &models.ScanRequest{TargetType: models.TargetTypeRootfs}, "rootfs.tar.gz", bytes.NewReader(tarball))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		root := service.uploadTarget(task)
+		if data, err := os.ReadFile(filepath.Join(root, "etc", "os-release")); err != nil || string(data) != "ID=alpine\n" {
+			t.Errorf("Expected extracted os-release, got %q (%v)", data, err)
+		}
+		if _, err := os.Lstat(filepath.Join(root, "etc", "passwd-link")); !os.IsNotExist(err) {
+			t.Error("Symlinks must not be extracted")
+		}
+	})
+
+	t.Run("Rejected uploads", func(t *testing.T) {
+		traversal := buildTarball(t, false, []*tar.Header{
+			{Name: "../../escape", Typeflag: tar.TypeReg, Mode: 0644},
+		}, []string{"x"})
+
+		tests := []struct {
+			name       string
+			targetType models.TargetType
+			filename   string
+			content    []byte
+			wantStatus int
+		}{
+			{"Invalid image archive", models.TargetTypeImage, "image.tar", []byte("not a tarball"), http.StatusBadRequest},
+			{"Repository target", models.TargetTypeRepository, "repo.tar", []byte("x"), http.StatusBadRequest},
+			{"Invalid SBOM", models.TargetTypeSBOM, "bom.json", []byte(`{"SchemaVersion": 2}`), http.StatusBadRequest},
+			{"Invalid file name", models.TargetTypeSBOM, "-bom.json", []byte(`{"spdxVersion": "SPDX-2.3"}`), http.StatusBadRequest},
+			{"Unsupported VM image", models.TargetTypeVM, "disk.iso", []byte("x"), http.StatusBadRequest},
+			{"Path traversal", models.TargetTypeFilesystem, "src.tar", traversal, http.StatusBadRequest},
+			{"Too large", models.TargetTypeVM, "disk.vmdk", bytes.Repeat([]byte("x"), 4097), http.StatusRequestEntityTooLarge},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := service.CreateUploadScanTask("user2", &models.ScanRequest{TargetType: tt.targetType}, tt.filename, bytes.NewReader(tt.content))
+				appErr, ok := err.(*errors.AppError)
+				if !ok {
+					t.Fatalf("Expected AppError, got %v", err)
+				}
+				if appErr.StatusCode != tt.wantStatus {
+					t.Errorf("Expected status %d, got %d (%v)", tt.wantStatus, appErr.StatusCode, err)
+				}
+			})
+		}
+
+		// Rejected uploads leave nothing behind
+		entries, _ := os.ReadDir(filepath.Join(storageDir, "uploads", "users", "user2"))
+		if len(entries) != 0 {
+			t.Errorf("Expected rejected uploads to be removed, found %d", len(entries))
+		}
+		if _, err := os.Stat(filepath.Join(storageDir, "uploads", "escape")); !os.IsNotExist(err) {
+			t.Error("Tarball entry escaped the upload directory")
+		}
+	})
+
+	t.Run("Remote targets are validated", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			req     *models.ScanRequest
+			wantErr bool
+		}{
+			{"Repository", &models.ScanRequest{TargetType: models.TargetTypeRepository, Image: "https://github.com/org/app"}, false},
+			{"Repository with local path", &models.ScanRequest{TargetType: models.TargetTypeRepository, Image: "/etc"}, true},
+			{"Image with shell characters", &models.ScanRequest{Image: "alpine; rm -rf /"}, true},
+			{"Upload type without file", &models.ScanRequest{TargetType: models.TargetTypeSBOM, Image: "bom.json"}, true},
+			{"Unknown type", &models.ScanRequest{TargetType: "k8s", Image: "cluster"}, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := service.CreateScanTask("user3", tt.req)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("CreateScanTask() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			})
+		}
+	})
+}
+
+// TestImageArchiveUpload tests scanning uploaded image tarballs and storage quotas
+func TestImageArchiveUpload(t *testing.T) {
+	storageDir := t.TempDir()
+	repo, err := repository.NewFileBasedScanRepository(storageDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	config := &types.TrivyConfig{
+		Timeout:       600,
+		MaxWorkers:    1,
+		MaxUploadSize: 64 * 1024,
+	}
+	executor := &mockCommandExecutor{mockStdout: createMockJSONOutput()}
+	service := NewScanServiceWithExecutor(repo, config, storageDir, &mockLogger{}, executor).(*scanServiceImpl)
+	defer service.Stop()
+
+	archive := buildTarball(t, false, []*tar.Header{
+		{Name: "manifest.json", Typeflag: tar.TypeReg, Mode: 0644},
+	}, []string{`[{"Config": "config.json", "Layers": []}]`})
+
+	waitFinished := func(taskID string) *models.ScanTask {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if task, _ := repo.GetByID(taskID); task != nil && task.Status.IsFinished() {
+				return task
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("Task %s did not finish", taskID)
+		return nil
+	}
+
+	t.Run("Upload is scanned and deleted on completion", func(t *testing.T) {
+		task, err := service.CreateUploadScanTask("user1", &models.ScanRequest{TargetType: models.TargetTypeImage}, "app.tar", bytes.NewReader(archive))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !task.ScanConfig.ImageArchive || !task.ScanConfig.HasUpload() {
+			t.Error("Expected task to be marked as an image archive upload")
+		}
+
+		finished := waitFinished(task.ID)
+		if finished.Status != models.ScanStatusCompleted {
+			t.Errorf("Expected completed task, got %s", finished.Status)
+		}
+		if _, err := os.Stat(service.uploadDir(task.UserID, task.ID)); !os.IsNotExist(err) {
+			t.Error("Upload should be deleted once the scan completes")
+		}
+
+		if _, err := service.Rescan(models.Principal{UserID: "user1"}, task.ID, nil); err == nil {
+			t.Error("Expected rescan of an uploaded image archive to fail")
+		}
+	})
+
+	t.Run("Storage quota", func(t *testing.T) {
+		usage, err := service.userStorageUsage("user1")
+		if err != nil || usage == 0 {
+			t.Fatalf("Expected storage usage from the previous scan, got %d (%v)", usage, err)
+		}
+
+		// An upload pushing the user over the quota is rejected and removed
+		config.UserStorageQuota = usage + int64(len(archive))/2
+		_, err = service.CreateUploadScanTask("user1", &models.ScanRequest{TargetType: models.TargetTypeImage}, "app.tar", bytes.NewReader(archive))
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.StatusCode != http.StatusInsufficientStorage {
+			t.Fatalf("Expected quota exceeded error, got %v", err)
+		}
+		entries, _ := os.ReadDir(filepath.Join(storageDir, "uploads", "users", "user1"))
+		if len(entries) != 0 {
+			t.Errorf("Expected rejected upload to be removed, found %d", len(entries))
+		}
+
+		// Once over the quota, no new scans or uploads are accepted
+		config.UserStorageQuota = usage - 1
+		if _, err := service.UploadLimit("user1"); err == nil {
+			t.Error("Expected upload limit check to fail over the quota")
+		}
+		if limit, err := service.UploadLimit("user2"); err != nil || limit != service.maxUploadSize() {
+			t.Errorf("Expected upload limit %d for another user, got %d (%v)", service.maxUploadSize(), limit, err)
+		}
+		_, err = service.CreateScanTask("user1", &models.ScanRequest{Image: "alpine:latest"})
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.StatusCode != http.StatusInsufficientStorage {
+			t.Errorf("Expected quota exceeded error, got %v", err)
+		}
+
+		// Other users are not affected
+		if _, err := service.CreateScanTask("user2", &models.ScanRequest{Image: "alpine:latest"}); err != nil {
+			t.Errorf("Unexpected error for another user: %v", err)
+		}
+	})
+}
+
 // TestBuildTrivyArgs tests building trivy command arguments
 func TestBuildTrivyArgs(t *testing.T) {
 	config := &types.TrivyConfig{
@@ -412,7 +1565,7 @@ func TestBuildTrivyArgs(t *testing.T) {
 				},
 			},
 			contains:    []string{"image", "--server", "http://localhost:4954", "--format", "json", "--scanners", "vuln", "--detection-priority", "precise", "alpine:latest"},
-			notContains: []string{"--insecure"},
+			notContains: []string{"--insecure", "--custom-headers"},
 		},
 		{
 			name: "Scan with insecure",
@@ -425,6 +1578,18 @@ func TestBuildTrivyArgs(t *testing.T) {
 			},
 			contains: []string{"image", "--insecure", "nginx:latest"},
 		},
+		{
+			name: "Scan created by an API request",
+			task: &models.ScanTask{
+				Image:     "nginx:latest",
+				RequestID: "req-123",
+				ScanConfig: &models.ScanConfig{
+					TLSVerify: true,
+					Format:    "json",
+				},
+			},
+			contains: []string{"--custom-headers", "X-Request-ID:req-123"},
+		},
 		{
 			name: "Scan with credentials",
 			task: &models.ScanTask{
@@ -486,6 +1651,69 @@ func TestBuildTrivyArgs(t *testing.T) {
 			},
 			contains: []string{"--pkg-types", "os,library"},
 		},
+		{
+			name: "Repository target",
+			task: &models.ScanTask{
+				Image: "https://github.com/org/app",
+				ScanConfig: &models.ScanConfig{
+					TargetType: models.TargetTypeRepository,
+					Username:   "ignored",
+					Password:   "ignored",
+					TLSVerify:  true,
+					Format:     "json",
+				},
+			},
+			contains:    []string{"repo", "--server", "https://github.com/org/app"},
+			notContains: []string{"image", "--image-src", "--username", "--password"},
+		},
+		{
+			name: "SBOM upload target",
+			task: &models.ScanTask{
+				ID:     "task-sbom",
+				UserID: "user1",
+				Image:  "bom.cdx.json",
+				ScanConfig: &models.ScanConfig{
+					TargetType: models.TargetTypeSBOM,
+					Scanners:   []string{"vuln", "secret", "license"},
+					TLSVerify:  true,
+					Format:     "json",
+				},
+			},
+			contains:    []string{"sbom", "vuln,license", "/tmp/trivy-test/uploads/users/user1/task-sbom/bom.cdx.json"},
+			notContains: []string{"--image-src", "vuln,secret,license"},
+		},
+		{
+			name: "Rootfs upload target",
+			task: &models.ScanTask{
+				ID:     "task-rootfs",
+				UserID: "user1",
+				Image:  "rootfs.tar.gz",
+				ScanConfig: &models.ScanConfig{
+					TargetType: models.TargetTypeRootfs,
+					TLSVerify:  true,
+					Format:     "json",
+				},
+			},
+			contains:    []string{"rootfs", "/tmp/trivy-test/uploads/users/user1/task-rootfs/root"},
+			notContains: []string{"--image-src", "rootfs.tar.gz"},
+		},
+		{
+			name: "Image archive upload target",
+			task: &models.ScanTask{
+				ID:     "task-archive",
+				UserID: "user1",
+				Image:  "app.tar",
+				ScanConfig: &models.ScanConfig{
+					ImageArchive: true,
+					Username:     "user",
+					Password:     "pass",
+					TLSVerify:    true,
+					Format:       "json",
+				},
+			},
+			contains:    []string{"image", "--input", "/tmp/trivy-test/uploads/users/user1/task-archive/app.tar"},
+			notContains: []string{"--image-src", "--username", "--password", "app.tar"},
+		},
 	}
 
 	for _, tt := range tests {
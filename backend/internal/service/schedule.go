@@ -0,0 +1,347 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for scheduled scans.
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/validator"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+)
+
+const (
+	// maxSchedulesPerUser limits the number of schedules a user can create.
+	maxSchedulesPerUser = 100
+
+	// maxScheduleNameLength limits the length of a schedule display name.
+	maxScheduleNameLength = 100
+
+	// minScheduleInterval is the shortest allowed interval between two runs of a schedule.
+	minScheduleInterval = time.Minute
+
+	// scheduleCheckInterval is how often the scheduler looks for due schedules.
+	scheduleCheckInterval = time.Second
+)
+
+// WithScheduleRepository enables scheduled scans, persisted in the given repository.
+func WithScheduleRepository(scheduleRepo repository.ScheduleRepository) ScanServiceOption {
+	return func(s *scanServiceImpl) {
+		s.scheduleRepo = scheduleRepo
+	}
+}
+
+// parseCron parses a standard 5-field cron expression or descriptor (@daily, @every 1h).
+// An optional CRON_TZ=<zone> prefix selects the time zone (default: server local time).
+func parseCron(expr string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(strings.TrimSpace(expr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression: %w", err)
+	}
+
+	// Reject expressions firing more often than the minimum interval
+	first := schedule.Next(time.Now())
+	if schedule.Next(first).Sub(first) < minScheduleInterval {
+		return nil, fmt.Errorf("cron expression fires more often than every %s", minScheduleInterval)
+	}
+
+	return schedule, nil
+}
+
+// nextRun computes the next run time of a schedule after the given time.
+// Returns nil for paused or unparsable schedules.
+func nextRun(schedule *models.Schedule, after time.Time) *time.Time {
+	if schedule.Paused {
+		return nil
+	}
+	parsed, err := parseCron(schedule.Cron)
+	if err != nil {
+		return nil
+	}
+	next := parsed.Next(after)
+	return &next
+}
+
+// CreateSchedule creates a recurring scan of an image or saved configuration.
+func (s *scanServiceImpl) CreateSchedule(userID string, req *models.ScheduleRequest) (*models.Schedule, error) {
+	if s.scheduleRepo == nil {
+		return nil, errors.NewInvalidInput("Scheduled scans are not available")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if len(name) > maxScheduleNameLength {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Schedule name exceeds maximum length of %d characters", maxScheduleNameLength))
+	}
+	if _, err := parseCron(req.Cron); err != nil {
+		return nil, errors.WrapInvalidInput(err, err.Error())
+	}
+
+	schedule := &models.Schedule{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Name:       name,
+		Image:      strings.TrimSpace(req.Image),
+		ConfigName: strings.TrimSpace(req.ConfigName),
+		Cron:       strings.TrimSpace(req.Cron),
+		CreatedAt:  time.Now(),
+	}
+
+	// Resolve the scan target now so that mistakes are reported immediately
+	if _, err := s.scheduledScanRequest(schedule); err != nil {
+		return nil, err
+	}
+
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	existing, err := s.scheduleRepo.List(userID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to list schedules")
+	}
+	if len(existing) >= maxSchedulesPerUser {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Maximum number of schedules (%d) reached", maxSchedulesPerUser))
+	}
+
+	schedule.NextRunAt = nextRun(schedule, schedule.CreatedAt)
+	if err := s.scheduleRepo.Save(schedule); err != nil {
+		return nil, errors.WrapInternal(err, "Failed to save schedule")
+	}
+
+	s.logger.Info("Created schedule %s for user %s (cron: %s, next run: %s)",
+		schedule.ID, userID, schedule.Cron, schedule.NextRunAt.Format(time.RFC3339))
+	return schedule, nil
+}
+
+// ListSchedules returns the schedules of a user.
+func (s *scanServiceImpl) ListSchedules(userID string) ([]*models.Schedule, error) {
+	if s.scheduleRepo == nil {
+		return []*models.Schedule{}, nil
+	}
+
+	schedules, err := s.scheduleRepo.List(userID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to list schedules")
+	}
+	return schedules, nil
+}
+
+// SetSchedulePaused pauses or resumes a schedule. Resuming computes the next
+// run from now; runs missed while paused are not caught up.
+func (s *scanServiceImpl) SetSchedulePaused(userID, scheduleID string, paused bool) (*models.Schedule, error) {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	schedule, err := s.getUserSchedule(userID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.Paused = paused
+	schedule.NextRunAt = nextRun(schedule, time.Now())
+	if err := s.scheduleRepo.Save(schedule); err != nil {
+		return nil, errors.WrapInternal(err, "Failed to save schedule")
+	}
+
+	s.logger.Info("Schedule %s of user %s paused=%v", scheduleID, userID, paused)
+	return schedule, nil
+}
+
+// DeleteSchedule deletes a schedule. Tasks it already started are kept.
+func (s *scanServiceImpl) DeleteSchedule(userID, scheduleID string) error {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	if _, err := s.getUserSchedule(userID, scheduleID); err != nil {
+		return err
+	}
+	if err := s.scheduleRepo.Delete(scheduleID); err != nil {
+		return errors.WrapInternal(err, "Failed to delete schedule")
+	}
+
+	s.logger.Info("Deleted schedule %s of user %s", scheduleID, userID)
+	return nil
+}
+
+// getUserSchedule loads a schedule owned by the user.
+// Schedules of other users are reported as missing to avoid leaking their existence.
+func (s *scanServiceImpl) getUserSchedule(userID, scheduleID string) (*models.Schedule, error) {
+	if s.scheduleRepo == nil {
+		return nil, errors.ErrScheduleNotFound
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to get schedule")
+	}
+	if schedule == nil || schedule.UserID != userID {
+		return nil, errors.ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+// scheduledScanRequest builds the scan request of a schedule. Options and registry
+// credentials come from the saved configuration, if any; the schedule's image
+// overrides the configuration's image prefix.
+func (s *scanServiceImpl) scheduledScanRequest(schedule *models.Schedule) (*models.ScanRequest, error) {
+	req := &models.ScanRequest{
+		TargetType: models.TargetTypeImage,
+		Image:      schedule.Image,
+	}
+
+	if schedule.ConfigName != "" {
+		if s.configService == nil {
+			return nil, errors.NewInvalidInput("Saved configurations are not available")
+		}
+		exists, err := s.configService.ConfigExists(schedule.UserID, schedule.ConfigName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.NewInvalidInput(fmt.Sprintf("Saved configuration %q not found", schedule.ConfigName))
+		}
+
+		saved, err := s.configService.GetConfig(schedule.UserID, schedule.ConfigName)
+		if err != nil {
+			return nil, err
+		}
+		if req.Image == "" {
+			req.Image = saved.ImagePrefix
+		}
+		tlsVerify := saved.TLSVerify
+		req.TLSVerify = &tlsVerify
+		req.Severity = saved.Severity
+		req.IgnoreUnfixed = saved.IgnoreUnfixed
+		req.Scanners = saved.Scanners
+		req.DetectionPriority = saved.DetectionPriority
+		req.PkgTypes = saved.PkgTypes
+		req.Format = saved.Format
+		req.Policy = saved.Policy
+		req.CheckBundle = saved.CheckBundle
+
+		// Saved configurations store credentials base64 encoded
+		req.Username = decodeFromBase64(saved.Username)
+		req.Password = decodeFromBase64(saved.Password)
+		if req.Username != "" && req.Password == "" {
+			return nil, errors.NewInvalidInput(fmt.Sprintf("Saved configuration %q has no registry password", schedule.ConfigName))
+		}
+	}
+
+	if req.Image == "" {
+		return nil, errors.NewInvalidInput("image or configName is required")
+	}
+	if err := validator.ValidateImageName(req.Image); err != nil {
+		return nil, errors.WrapInvalidInput(err, err.Error())
+	}
+
+	return req, nil
+}
+
+// initSchedules recomputes the next run of all schedules on startup.
+// Runs missed while the server was down are skipped, not caught up.
+func (s *scanServiceImpl) initSchedules(now time.Time) {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	schedules, err := s.scheduleRepo.ListAll()
+	if err != nil {
+		s.logger.Error("Failed to load schedules: %v", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		schedule.NextRunAt = nextRun(schedule, now)
+		if err := s.scheduleRepo.Save(schedule); err != nil {
+			s.logger.Error("Failed to update schedule %s: %v", schedule.ID, err)
+		}
+	}
+
+	s.logger.Info("Loaded %d scan schedules", len(schedules))
+}
+
+// scheduleWorker starts the scans of due schedules.
+func (s *scanServiceImpl) scheduleWorker() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			s.runDueSchedules(now)
+		}
+	}
+}
+
+// runDueSchedules starts a scan for every active schedule whose next run is due.
+func (s *scanServiceImpl) runDueSchedules(now time.Time) {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	schedules, err := s.scheduleRepo.ListAll()
+	if err != nil {
+		s.logger.Error("Failed to list schedules: %v", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		if schedule.Paused || schedule.NextRunAt == nil || schedule.NextRunAt.After(now) {
+			continue
+		}
+		s.runSchedule(schedule, now)
+	}
+}
+
+// runSchedule starts the scan of a due schedule and records the outcome.
+// A run is skipped while the previous scan of the schedule is still queued or running.
+func (s *scanServiceImpl) runSchedule(schedule *models.Schedule, now time.Time) {
+	runAt := now
+	schedule.LastRunAt = &runAt
+	schedule.LastError = ""
+	schedule.NextRunAt = nextRun(schedule, now)
+
+	if previous, _ := s.repo.GetByID(schedule.LastTaskID); previous != nil && !previous.Status.IsFinished() {
+		schedule.LastError = fmt.Sprintf("Skipped: previous scan %s has not finished", previous.ID)
+	} else if task, err := s.startScheduledScan(schedule); err != nil {
+		schedule.LastError = err.Error()
+	} else {
+		schedule.LastTaskID = task.ID
+	}
+
+	if schedule.LastError != "" {
+		s.logger.Error("Schedule %s run failed: %s", schedule.ID, schedule.LastError)
+	} else {
+		s.logger.Info("Schedule %s started task %s", schedule.ID, schedule.LastTaskID)
+	}
+
+	if err := s.scheduleRepo.Save(schedule); err != nil {
+		s.logger.Error("Failed to update schedule %s: %v", schedule.ID, err)
+	}
+}
+
+// startScheduledScan creates and enqueues the scan task of a schedule.
+func (s *scanServiceImpl) startScheduledScan(schedule *models.Schedule) (*models.ScanTask, error) {
+	req, err := s.scheduledScanRequest(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.newRemoteScanTask(schedule.UserID, req)
+	if err != nil {
+		return nil, err
+	}
+	task.ScheduleID = schedule.ID
+	task.AddLog(fmt.Sprintf("Started by schedule %s (%s)", schedule.ID, schedule.Cron))
+
+	return s.enqueueTask(task)
+}
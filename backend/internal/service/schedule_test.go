@@ -0,0 +1,240 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+// newScheduleTestService creates a scan service with schedules and saved configurations enabled.
+func newScheduleTestService(t *testing.T, configDir string, executor CommandExecutor) (*scanServiceImpl, *ConfigService) {
+	t.Helper()
+
+	scheduleRepo, err := repository.NewFileScheduleRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to create schedule repository: %v", err)
+	}
+	configService := NewConfigService(configDir, true, 4096, 10, &mockLogger{})
+	config := &types.TrivyConfig{
+		Timeout:    600,
+		MaxWorkers: 1,
+	}
+
+	service := NewScanServiceWithExecutor(repository.NewInMemoryScanRepository(), config, t.TempDir(), &mockLogger{}, executor,
+		WithConfigService(configService),
+		WithScheduleRepository(scheduleRepo),
+	).(*scanServiceImpl)
+	t.Cleanup(service.Stop)
+
+	return service, configService
+}
+
+// TestParseCron tests cron expression validation
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{"Five fields", "0 2 * * *", false},
+		{"Step values", "*/15 * * * *", false},
+		{"Descriptor", "@daily", false},
+		{"Interval descriptor", "@every 1h", false},
+		{"Time zone prefix", "CRON_TZ=Asia/Shanghai 0 9 * * 1-5", false},
+		{"Empty", "", true},
+		{"Free text", "every day", true},
+		{"Six fields with seconds", "0 0 2 * * *", true},
+		{"Too frequent", "@every 10s", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCron(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCron(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestCreateSchedule tests creating schedules
+func TestCreateSchedule(t *testing.T) {
+	service, configService := newScheduleTestService(t, t.TempDir(), &mockCommandExecutor{mockStdout: createMockJSONOutput()})
+
+	configService.SaveConfig("user1", "prod", &models.SavedScanConfig{
+		ImagePrefix: "registry.example.com/app:latest",
+		Username:    encodeToBase64("robot"),
+		Password:    encodeToBase64("secret"),
+		Severity:    []string{"CRITICAL"},
+		Format:      "json",
+	})
+	configService.SaveConfig("user1", "nopass", &models.SavedScanConfig{
+		ImagePrefix: "registry.example.com/app:latest",
+		Username:    encodeToBase64("robot"),
+	})
+
+	tests := []struct {
+		name    string
+		req     *models.ScheduleRequest
+		wantErr bool
+	}{
+		{"Image schedule", &models.ScheduleRequest{Image: "alpine:latest", Cron: "0 2 * * *"}, false},
+		{"Saved config schedule", &models.ScheduleRequest{ConfigName: "prod", Cron: "@daily"}, false},
+		{"Image overrides config", &models.ScheduleRequest{Image: "nginx:latest", ConfigName: "prod", Cron: "@weekly"}, false},
+		{"Missing target", &models.ScheduleRequest{Cron: "@daily"}, true},
+		{"Unknown config", &models.ScheduleRequest{ConfigName: "missing", Cron: "@daily"}, true},
+		{"Config without password", &models.ScheduleRequest{ConfigName: "nopass", Cron: "@daily"}, true},
+		{"Invalid image", &models.ScheduleRequest{Image: "alpine; rm -rf /", Cron: "@daily"}, true},
+		{"Invalid cron", &models.ScheduleRequest{Image: "alpine:latest", Cron: "sometimes"}, true},
+		{"Name too long", &models.ScheduleRequest{Name: strings.Repeat("n", 101), Image: "alpine:latest", Cron: "@daily"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := service.CreateSchedule("user1", tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if appErr, ok := err.(*errors.AppError); !ok || appErr.StatusCode != http.StatusBadRequest {
+					t.Errorf("Expected 400 AppError, got %v", err)
+				}
+				return
+			}
+			if schedule.NextRunAt == nil || !schedule.NextRunAt.After(schedule.CreatedAt) {
+				t.Errorf("Expected next run after creation, got %v", schedule.NextRunAt)
+			}
+		})
+	}
+
+	schedules, _ := service.ListSchedules("user1")
+	if len(schedules) != 3 {
+		t.Errorf("Expected 3 schedules, got %d", len(schedules))
+	}
+	if others, _ := service.ListSchedules("user2"); len(others) != 0 {
+		t.Errorf("Expected no schedules for another user, got %d", len(others))
+	}
+}
+
+// TestRunDueSchedules tests the scheduler starting scans on time
+func TestRunDueSchedules(t *testing.T) {
+	executor := &blockingCommandExecutor{release: make(chan struct{})}
+	service, configService := newScheduleTestService(t, t.TempDir(), executor)
+	defer close(executor.release)
+
+	configService.SaveConfig("user1", "prod", &models.SavedScanConfig{
+		ImagePrefix: "registry.example.com/app:latest",
+		Username:    encodeToBase64("robot"),
+		Password:    encodeToBase64("secret"),
+		Severity:    []string{"CRITICAL"},
+	})
+
+	schedule, err := service.CreateSchedule("user1", &models.ScheduleRequest{ConfigName: "prod", Cron: "0 * * * *"})
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+	due := *schedule.NextRunAt
+
+	// Not due yet
+	service.runDueSchedules(due.Add(-time.Second))
+	if current, _ := service.scheduleRepo.GetByID(schedule.ID); current.LastRunAt != nil {
+		t.Fatal("Schedule should not run before it is due")
+	}
+
+	// Due: a task is enqueued with the saved configuration
+	service.runDueSchedules(due)
+	current, _ := service.scheduleRepo.GetByID(schedule.ID)
+	if current.LastRunAt == nil || current.LastTaskID == "" || current.LastError != "" {
+		t.Fatalf("Expected a successful run, got %+v", current)
+	}
+	if !current.NextRunAt.After(due) {
+		t.Errorf("Expected next run after %s, got %s", due, current.NextRunAt)
+	}
+
+	task, err := service.GetTask(current.LastTaskID)
+	if err != nil {
+		t.Fatalf("Scheduled task not found: %v", err)
+	}
+	if task.ScheduleID != schedule.ID || task.Image != "registry.example.com/app:latest" {
+		t.Errorf("Unexpected scheduled task: schedule %q, image %q", task.ScheduleID, task.Image)
+	}
+	if task.ScanConfig.Username != "robot" || task.ScanConfig.Password != "secret" {
+		t.Error("Expected credentials from the saved configuration")
+	}
+	if len(task.ScanConfig.Severity) != 1 || task.ScanConfig.Severity[0] != "CRITICAL" {
+		t.Errorf("Expected options from the saved configuration, got %v", task.ScanConfig.Severity)
+	}
+
+	// The previous scan is still running, so the next run is skipped
+	service.runDueSchedules(*current.NextRunAt)
+	skipped, _ := service.scheduleRepo.GetByID(schedule.ID)
+	if skipped.LastTaskID != current.LastTaskID || !strings.HasPrefix(skipped.LastError, "Skipped") {
+		t.Errorf("Expected run to be skipped, got %+v", skipped)
+	}
+
+	// Paused schedules never run
+	paused, err := service.SetSchedulePaused("user1", schedule.ID, true)
+	if err != nil {
+		t.Fatalf("Failed to pause schedule: %v", err)
+	}
+	if paused.NextRunAt != nil {
+		t.Error("Paused schedule should have no next run")
+	}
+	service.runDueSchedules(due.Add(24 * time.Hour))
+	if current, _ := service.scheduleRepo.GetByID(schedule.ID); !current.LastRunAt.Equal(*skipped.LastRunAt) {
+		t.Error("Paused schedule should not run")
+	}
+
+	resumed, err := service.SetSchedulePaused("user1", schedule.ID, false)
+	if err != nil || resumed.NextRunAt == nil {
+		t.Fatalf("Expected resumed schedule with next run, got %+v (%v)", resumed, err)
+	}
+}
+
+// TestScheduleOwnershipAndPersistence tests user isolation and restart recovery
+func TestScheduleOwnershipAndPersistence(t *testing.T) {
+	configDir := t.TempDir()
+	service, _ := newScheduleTestService(t, configDir, &mockCommandExecutor{mockStdout: createMockJSONOutput()})
+
+	schedule, err := service.CreateSchedule("user1", &models.ScheduleRequest{Name: "nightly", Image: "alpine:latest", Cron: "0 2 * * *"})
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+
+	// Other users cannot see, pause or delete the schedule
+	if _, err := service.SetSchedulePaused("user2", schedule.ID, true); err != errors.ErrScheduleNotFound {
+		t.Errorf("Expected schedule not found for another user, got %v", err)
+	}
+	if err := service.DeleteSchedule("user2", schedule.ID); err != errors.ErrScheduleNotFound {
+		t.Errorf("Expected schedule not found for another user, got %v", err)
+	}
+
+	// Schedules survive a restart; the next run is recomputed from the start time
+	restarted, _ := newScheduleTestService(t, configDir, &mockCommandExecutor{mockStdout: createMockJSONOutput()})
+	later := time.Now().Add(72 * time.Hour)
+	restarted.initSchedules(later)
+
+	schedules, _ := restarted.ListSchedules("user1")
+	if len(schedules) != 1 || schedules[0].Name != "nightly" {
+		t.Fatalf("Expected schedule after restart, got %v", schedules)
+	}
+	if !schedules[0].NextRunAt.After(later) {
+		t.Errorf("Expected next run after restart time, got %s", schedules[0].NextRunAt)
+	}
+
+	if err := restarted.DeleteSchedule("user1", schedule.ID); err != nil {
+		t.Fatalf("Failed to delete schedule: %v", err)
+	}
+	if schedules, _ := restarted.ListSchedules("user1"); len(schedules) != 0 {
+		t.Errorf("Expected no schedules after deletion, got %d", len(schedules))
+	}
+}
@@ -5,18 +5,52 @@
 package service
 
 import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+)
+
+const (
+	// sessionActivityPrecision limits how often the sliding expiration of a session is written to disk.
+	sessionActivityPrecision = time.Minute
+
+	// sessionRenewTimeout limits the refresh token request to the identity provider.
+	sessionRenewTimeout = 10 * time.Second
+
+	// sealedTokenPrefix marks provider tokens stored encrypted with the session secret.
+	sealedTokenPrefix = "enc:v1:"
+
+	// sessionCleanupInterval is the interval expired sessions are removed at.
+	sessionCleanupInterval = 10 * time.Minute
 )
 
 // SessionInfo stores information about a user session.
+// Requests authenticated with an API token get a session built from the token.
 type SessionInfo struct {
-	UserID   string
-	Groups   []string
-	Email    string
-	ExpireAt time.Time
+	UserID    string
+	Groups    []string
+	Email     string
+	ExpireAt  time.Time
+	SessionID string // Persisted session the request was authenticated with (empty for API tokens)
+	TokenID   string // API token the request was authenticated with (empty for browser sessions)
+	Scope     string // Highest role of the API token (empty for browser sessions)
 }
 
 // GetUserID returns the user ID.
@@ -34,18 +68,63 @@ func (s *SessionInfo) GetGroups() []string {
 	return s.Groups
 }
 
-// SessionService manages user sessions.
+// GetScope returns the highest role the session can act with (empty if unlimited).
+func (s *SessionInfo) GetScope() string {
+	return s.Scope
+}
+
+// SessionIdentity is the identity of a user as reported by the identity provider.
+type SessionIdentity struct {
+	Subject      string   // OIDC user ID
+	Email        string   // Email of the user
+	Groups       []string // OIDC groups
+	RefreshToken string   // OIDC refresh token (empty if not issued or not rotated)
+	IDToken      string   // Raw ID token (empty if not issued on renewal)
+	SessionID    string   // Session ID at the provider (sid claim, empty if not supported)
+}
+
+// SessionRefresher renews the identity of a session with the identity provider.
+type SessionRefresher interface {
+	// RenewIdentity redeems a refresh token and returns the current identity of the user.
+	RenewIdentity(ctx context.Context, refreshToken string) (*SessionIdentity, error)
+}
+
+// IdentityListener keeps credentials derived from the identity of a user, such as
+// API tokens, in line with the identity provider.
+type IdentityListener interface {
+	// IdentityUpdated is called with the groups reported by the identity provider
+	// when a user logs in or a session of the user is renewed.
+	IdentityUpdated(userID string, groups []string)
+
+	// UserRevoked is called when an admin or the identity provider ends the sessions of a user.
+	UserRevoked(userID string)
+}
+
+// SessionService manages persistent user sessions.
+//
+// A session ends after idleTimeout without requests (sliding expiration). Independently
+// of activity, the user must authenticate with the identity provider again after maxAge:
+// sessions with an OIDC refresh token are renewed transparently (updating the groups),
+// other sessions end.
 type SessionService struct {
-	sessions map[string]*SessionInfo
-	mu       sync.RWMutex
-	ttl      time.Duration
+	repo        repository.SessionRepository
+	refresher   SessionRefresher
+	listener    IdentityListener
+	idleTimeout time.Duration
+	maxAge      time.Duration // 0 = unlimited
+	logger      logger.Logger
+	tokenCipher cipher.AEAD        // Encrypts stored provider tokens (nil = stored in plaintext)
+	mu          sync.Mutex         // Serializes session updates, so a revocation is not undone by a concurrent save
+	renewals    singleflight.Group // Coalesces concurrent renewals of a session, so a rotated refresh token is used once
 }
 
-// NewSessionService creates a new session service.
-func NewSessionService(ttl time.Duration) *SessionService {
+// NewSessionService creates a new session service and starts removing expired sessions.
+func NewSessionService(repo repository.SessionRepository, idleTimeout, maxAge time.Duration, log logger.Logger) *SessionService {
 	s := &SessionService{
-		sessions: make(map[string]*SessionInfo),
-		ttl:      ttl,
+		repo:        repo,
+		idleTimeout: idleTimeout,
+		maxAge:      maxAge,
+		logger:      log,
 	}
 
 	// Start cleanup goroutine
@@ -54,43 +133,152 @@ func NewSessionService(ttl time.Duration) *SessionService {
 	return s
 }
 
-// CreateSession creates a new session and returns the session ID.
-func (s *SessionService) CreateSession(userID, email string, groups []string) (string, error) {
+// SetRefresher sets the identity provider client used to renew sessions.
+// Without a refresher, sessions end when they reach the maximum age.
+func (s *SessionService) SetRefresher(refresher SessionRefresher) {
+	s.refresher = refresher
+}
+
+// SetSecret sets the server secret encrypting the OIDC refresh and ID tokens stored
+// with sessions (AES-256-GCM with a key derived from the secret). Without a secret the
+// tokens are stored in plaintext, protected only by the permissions of the sessions file.
+// Tokens that cannot be decrypted, e.g. after the secret changed, are ignored: such
+// sessions end when they reach the maximum age.
+func (s *SessionService) SetSecret(secret string) error {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return err
+	}
+	tokenCipher, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	s.tokenCipher = tokenCipher
+	return nil
+}
+
+// sealToken returns the stored form of a provider token: encrypted with the session
+// secret if one is set, otherwise the token itself.
+func (s *SessionService) sealToken(token string) string {
+	if s.tokenCipher == nil || token == "" {
+		return token
+	}
+	nonce := make([]byte, s.tokenCipher.NonceSize())
+	rand.Read(nonce)
+	return sealedTokenPrefix + base64.RawURLEncoding.EncodeToString(s.tokenCipher.Seal(nonce, nonce, []byte(token), nil))
+}
+
+// openToken returns the provider token of its stored form. Tokens stored before the
+// secret was set are returned as is, tokens that cannot be decrypted as "".
+func (s *SessionService) openToken(stored string) string {
+	encoded, sealed := strings.CutPrefix(stored, sealedTokenPrefix)
+	if !sealed {
+		return stored
+	}
+	if s.tokenCipher == nil {
+		return ""
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	nonceSize := s.tokenCipher.NonceSize()
+	if err != nil || len(data) < nonceSize {
+		return ""
+	}
+	token, err := s.tokenCipher.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return ""
+	}
+	return string(token)
+}
+
+// SetIdentityListener sets the listener notified of identity updates and revoked users.
+func (s *SessionService) SetIdentityListener(listener IdentityListener) {
+	s.listener = listener
+}
+
+// identityUpdated notifies the listener of the current groups of a user.
+func (s *SessionService) identityUpdated(userID string, groups []string) {
+	if s.listener != nil {
+		s.listener.IdentityUpdated(userID, groups)
+	}
+}
+
+// usersRevoked notifies the listener of users whose sessions were ended.
+func (s *SessionService) usersRevoked(userIDs ...string) {
+	if s.listener == nil {
+		return
+	}
+	for _, userID := range userIDs {
+		s.listener.UserRevoked(userID)
+	}
+}
+
+// CreateSession creates a new session and returns the session ID (the cookie value).
+func (s *SessionService) CreateSession(identity *SessionIdentity, ipAddress, userAgent string) (string, error) {
 	sessionID, err := generateSessionID()
 	if err != nil {
 		return "", err
 	}
 
+	now := time.Now()
+	session := &models.Session{
+		ID:              uuid.New().String(),
+		TokenHash:       hashSessionID(sessionID),
+		UserID:          identity.Email + "_" + identity.Subject,
+		Subject:         identity.Subject,
+		Email:           identity.Email,
+		Groups:          identity.Groups,
+		RefreshToken:    s.sealToken(identity.RefreshToken),
+		IDToken:         s.sealToken(identity.IDToken),
+		OIDCSessionID:   identity.SessionID,
+		IPAddress:       ipAddress,
+		UserAgent:       userAgent,
+		CreatedAt:       now,
+		AuthenticatedAt: now,
+		LastSeenAt:      now,
+	}
+	session.ExpiresAt = s.expiresAt(session, now)
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.sessions[sessionID] = &SessionInfo{
-		UserID:   userID,
-		Groups:   groups,
-		Email:    email,
-		ExpireAt: time.Now().Add(s.ttl),
+	if err := s.repo.Save(session); err != nil {
+		return "", err
 	}
+	s.identityUpdated(session.UserID, session.Groups)
 
 	return sessionID, nil
 }
 
-// GetSession retrieves session information by session ID.
+// GetSession retrieves session information by session ID, sliding its expiration
+// and renewing it with the identity provider when it reaches the maximum age.
 // Returns interface{} to satisfy middleware.SessionValidator interface.
 func (s *SessionService) GetSession(sessionID string) (interface{}, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	session, exists := s.sessions[sessionID]
-	if !exists {
+	session, err := s.repo.GetByTokenHash(hashSessionID(sessionID))
+	if err != nil {
+		s.logger.Error("Failed to look up session: %v", err)
 		return nil, false
 	}
 
 	// Check if session is expired
-	if time.Now().After(session.ExpireAt) {
+	now := time.Now()
+	if session == nil || session.IsExpired(now) {
 		return nil, false
 	}
 
-	return session, true
+	if s.maxAge > 0 && now.Sub(session.AuthenticatedAt) >= s.maxAge {
+		if session = s.renew(session.ID); session == nil {
+			return nil, false
+		}
+	}
+
+	if now.Sub(session.LastSeenAt) >= sessionActivityPrecision {
+		if session = s.touch(sessionID); session == nil {
+			return nil, false
+		}
+	}
+
+	return newSessionInfo(session), true
 }
 
 // GetSessionInfo retrieves typed session information by session ID.
@@ -102,45 +290,277 @@ func (s *SessionService) GetSessionInfo(sessionID string) (*SessionInfo, bool) {
 	return val.(*SessionInfo), true
 }
 
-// DeleteSession removes a session.
-func (s *SessionService) DeleteSession(sessionID string) {
+// DeleteSession removes a session by session ID (logout) and returns the removed
+// session, so the logout can be forwarded to the identity provider.
+// Returns nil if the session does not exist.
+func (s *SessionService) DeleteSession(sessionID string) *models.Session {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	delete(s.sessions, sessionID)
+	session, err := s.repo.GetByTokenHash(hashSessionID(sessionID))
+	if err != nil || session == nil {
+		return nil
+	}
+	if err := s.repo.Delete(session.ID); err != nil {
+		s.logger.Error("Failed to delete session %s: %v", session.ID, err)
+	}
+	session.IDToken = s.openToken(session.IDToken)
+	return session
 }
 
-// RefreshSession extends the session expiration time.
+// RefreshSession extends the session expiration time by the idle timeout.
 func (s *SessionService) RefreshSession(sessionID string) bool {
+	return s.touch(sessionID) != nil
+}
+
+// touch records activity of a session, extending its expiration time, and returns
+// the updated session. Returns nil if the session does not exist or has expired.
+func (s *SessionService) touch(sessionID string) *models.Session {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	session, exists := s.sessions[sessionID]
-	if !exists {
-		return false
+	session, err := s.repo.GetByTokenHash(hashSessionID(sessionID))
+	if err != nil || session == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if session.IsExpired(now) {
+		return nil
+	}
+
+	session.LastSeenAt = now
+	session.ExpiresAt = s.expiresAt(session, now)
+	if err := s.repo.Save(session); err != nil {
+		// The session is still valid, only its expiration could not be extended
+		s.logger.Error("Failed to refresh session %s: %v", session.ID, err)
+	}
+	return session
+}
+
+// ListSessions returns the active sessions of a user, or of all users if userID is empty.
+// Cookie hashes and refresh tokens are removed.
+func (s *SessionService) ListSessions(userID string) ([]*models.Session, error) {
+	sessions, err := s.repo.List(userID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to list sessions")
+	}
+
+	now := time.Now()
+	active := make([]*models.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if !session.IsExpired(now) {
+			session.TokenHash = ""
+			session.RefreshToken = ""
+			session.IDToken = ""
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// RevokeSession ends a session by its ID.
+func (s *SessionService) RevokeSession(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.WrapInternal(err, "Failed to get session")
+	}
+	if session == nil {
+		return errors.ErrSessionNotFound
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return errors.WrapInternal(err, "Failed to delete session")
+	}
+
+	s.logger.Info("Session %s of user %s revoked", session.ID, session.UserID)
+	return nil
+}
+
+// RevokeUserSessions ends all sessions of a user and returns how many were ended.
+// The identity listener is notified, so that the API tokens of the user are revoked too.
+func (s *SessionService) RevokeUserSessions(userID string) (int, error) {
+	if userID == "" {
+		return 0, errors.NewInvalidInput("userId is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked, err := s.repo.DeleteByUser(userID)
+	if err != nil {
+		return 0, errors.WrapInternal(err, "Failed to delete sessions")
+	}
+	s.usersRevoked(userID)
+
+	s.logger.Info("%d sessions of user %s revoked", revoked, userID)
+	return revoked, nil
+}
+
+// RevokeOIDCSessions ends the sessions the identity provider reports as logged out
+// (back-channel logout): the sessions of the provider session oidcSessionID if set,
+// otherwise all sessions of the subject. Returns how many sessions were ended.
+// The identity listener is notified of the users of the ended sessions.
+func (s *SessionService) RevokeOIDCSessions(subject, oidcSessionID string) (int, error) {
+	if subject == "" && oidcSessionID == "" {
+		return 0, errors.NewInvalidInput("subject or session ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Collect the users of the ended sessions, whose API tokens are revoked as well
+	sessions, err := s.repo.List("")
+	if err != nil {
+		return 0, errors.WrapInternal(err, "Failed to list sessions")
+	}
+	var userIDs []string
+	for _, session := range sessions {
+		if (subject == "" || session.Subject == subject) && (oidcSessionID == "" || session.OIDCSessionID == oidcSessionID) &&
+			!slices.Contains(userIDs, session.UserID) {
+			userIDs = append(userIDs, session.UserID)
+		}
+	}
+
+	revoked, err := s.repo.DeleteByOIDCSession(subject, oidcSessionID)
+	if err != nil {
+		return 0, errors.WrapInternal(err, "Failed to delete sessions")
+	}
+	s.usersRevoked(userIDs...)
+
+	s.logger.Info("%d sessions of OIDC subject %q (provider session %q) ended by the identity provider", revoked, subject, oidcSessionID)
+	return revoked, nil
+}
+
+// renew renews a session that reached the maximum age with its refresh token.
+// The session is deleted and nil returned if it cannot be renewed. Concurrent
+// requests of the session wait for a single renewal; other sessions are not blocked.
+func (s *SessionService) renew(id string) *models.Session {
+	session, _, _ := s.renewals.Do(id, func() (interface{}, error) {
+		return s.renewOnce(id), nil
+	})
+	return session.(*models.Session)
+}
+
+// renewOnce performs the renewal of renew.
+func (s *SessionService) renewOnce(id string) *models.Session {
+	session, err := s.repo.GetByID(id)
+	if err != nil || session == nil {
+		return nil
+	}
+	now := time.Now()
+	if now.Sub(session.AuthenticatedAt) < s.maxAge {
+		// Renewed by a concurrent request
+		return session
+	}
+
+	identity, err := s.renewIdentity(session)
+	if err != nil {
+		s.logger.Info("Session %s of user %s ended: %v", session.ID, session.UserID, err)
+		s.mu.Lock()
+		s.repo.Delete(session.ID)
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Reload the session so that a concurrent revocation is not undone by the save
+	session, err = s.repo.GetByID(id)
+	if err != nil || session == nil {
+		return nil
+	}
+	session.Groups = identity.Groups
+	if identity.RefreshToken != "" {
+		session.RefreshToken = s.sealToken(identity.RefreshToken)
+	}
+	if identity.IDToken != "" {
+		session.IDToken = s.sealToken(identity.IDToken)
+	}
+	session.AuthenticatedAt = now
+	session.LastSeenAt = now
+	session.ExpiresAt = s.expiresAt(session, now)
+	if err := s.repo.Save(session); err != nil {
+		s.logger.Error("Failed to save renewed session %s: %v", session.ID, err)
+		return nil
+	}
+	s.identityUpdated(session.UserID, session.Groups)
+
+	s.logger.Info("Session %s of user %s renewed with the identity provider", session.ID, session.UserID)
+	return session
+}
+
+// renewIdentity redeems the refresh token of a session. The user must log in again
+// if the identity provider reports another user or email, as the email is part of
+// the user identifier that owns scans and settings.
+func (s *SessionService) renewIdentity(session *models.Session) (*SessionIdentity, error) {
+	refreshToken := s.openToken(session.RefreshToken)
+	if s.refresher == nil || refreshToken == "" {
+		return nil, fmt.Errorf("maximum session age reached")
 	}
 
-	session.ExpireAt = time.Now().Add(s.ttl)
-	return true
+	ctx, cancel := context.WithTimeout(context.Background(), sessionRenewTimeout)
+	defer cancel()
+
+	identity, err := s.refresher.RenewIdentity(ctx, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("refresh token rejected: %w", err)
+	}
+	if identity.Subject != session.Subject || identity.Email != session.Email {
+		return nil, fmt.Errorf("identity provider reported a different user")
+	}
+	return identity, nil
+}
+
+// expiresAt returns the expiration of a session active at the given time: the idle
+// timeout, capped by the maximum age unless the session can be renewed.
+func (s *SessionService) expiresAt(session *models.Session, now time.Time) time.Time {
+	expiresAt := now.Add(s.idleTimeout)
+	if s.maxAge > 0 && (s.refresher == nil || session.RefreshToken == "") {
+		if limit := session.AuthenticatedAt.Add(s.maxAge); limit.Before(expiresAt) {
+			expiresAt = limit
+		}
+	}
+	return expiresAt
 }
 
 // cleanup removes expired sessions periodically.
 func (s *SessionService) cleanup() {
-	ticker := time.NewTicker(10 * time.Minute)
+	ticker := time.NewTicker(sessionCleanupInterval)
 	defer ticker.Stop()
 
 	for range ticker.C {
 		s.mu.Lock()
-		now := time.Now()
-		for id, session := range s.sessions {
-			if now.After(session.ExpireAt) {
-				delete(s.sessions, id)
-			}
-		}
+		removed, err := s.repo.DeleteExpired(time.Now())
 		s.mu.Unlock()
+		if err != nil {
+			s.logger.Error("Failed to remove expired sessions: %v", err)
+		} else if removed > 0 {
+			s.logger.Debug("Removed %d expired sessions", removed)
+		}
 	}
 }
 
+// newSessionInfo returns the request session of a persisted session.
+func newSessionInfo(session *models.Session) *SessionInfo {
+	return &SessionInfo{
+		UserID:    session.Subject,
+		Groups:    session.Groups,
+		Email:     session.Email,
+		ExpireAt:  session.ExpiresAt,
+		SessionID: session.ID,
+	}
+}
+
+// hashSessionID returns the hex SHA-256 hash a session ID is stored as.
+func hashSessionID(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:])
+}
+
 // generateSessionID generates a cryptographically secure random session ID.
 func generateSessionID() (string, error) {
 	b := make([]byte, 32)
@@ -0,0 +1,272 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+)
+
+// mockRefresher is a SessionRefresher returning a fixed identity or error.
+type mockRefresher struct {
+	identity     *SessionIdentity
+	err          error
+	delay        time.Duration // Duration of the renewal request
+	calls        int
+	refreshToken string // Refresh token of the last renewal
+	mu           sync.Mutex
+}
+
+func (m *mockRefresher) RenewIdentity(ctx context.Context, refreshToken string) (*SessionIdentity, error) {
+	time.Sleep(m.delay)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	m.refreshToken = refreshToken
+	return m.identity, m.err
+}
+
+// TestSessionService tests session persistence, sliding expiration and revocation
+func TestSessionService(t *testing.T) {
+	configDir := t.TempDir()
+	repo, err := repository.NewFileSessionRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	sessionService := NewSessionService(repo, time.Hour, 24*time.Hour, &mockLogger{})
+
+	identity := &SessionIdentity{Subject: "user-1", Email: "alice@example.com", Groups: []string{"dev"}}
+	sessionID, err := sessionService.CreateSession(identity, "10.0.0.1", "Firefox")
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	info, ok := sessionService.GetSessionInfo(sessionID)
+	if !ok || info.UserID != "user-1" || info.Email != "alice@example.com" || info.SessionID == "" {
+		t.Fatalf("Unexpected session: %+v", info)
+	}
+
+	// Sessions survive a restart
+	repo, _ = repository.NewFileSessionRepository(configDir)
+	sessionService = NewSessionService(repo, time.Hour, 24*time.Hour, &mockLogger{})
+	if _, ok := sessionService.GetSession(sessionID); !ok {
+		t.Fatal("Expected the session to survive a restart")
+	}
+
+	// Activity slides the expiration
+	stored, _ := repo.GetByID(info.SessionID)
+	stored.LastSeenAt = time.Now().Add(-30 * time.Minute)
+	stored.ExpiresAt = time.Now().Add(30 * time.Minute)
+	repo.Save(stored)
+	info, _ = sessionService.GetSessionInfo(sessionID)
+	if time.Until(info.ExpireAt) < 59*time.Minute {
+		t.Errorf("Expected the expiration to slide by the idle timeout, expires at %s", info.ExpireAt)
+	}
+
+	// Sessions are listed without the cookie hash and the refresh token
+	sessions, _ := sessionService.ListSessions("alice@example.com_user-1")
+	if len(sessions) != 1 || sessions[0].TokenHash != "" || sessions[0].IPAddress != "10.0.0.1" || sessions[0].UserAgent != "Firefox" {
+		t.Errorf("Unexpected session list: %+v", sessions)
+	}
+
+	// Expired sessions are rejected
+	stored, _ = repo.GetByID(info.SessionID)
+	stored.ExpiresAt = time.Now().Add(-time.Second)
+	repo.Save(stored)
+	if _, ok := sessionService.GetSession(sessionID); ok {
+		t.Error("Expected an expired session to be rejected")
+	}
+	if _, ok := sessionService.GetSession("unknown"); ok {
+		t.Error("Expected an unknown session to be rejected")
+	}
+
+	// Admins can revoke single sessions and all sessions of a user
+	first, _ := sessionService.CreateSession(identity, "", "")
+	second, _ := sessionService.CreateSession(identity, "", "")
+	firstInfo, _ := sessionService.GetSessionInfo(first)
+	if err := sessionService.RevokeSession(firstInfo.SessionID); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if _, ok := sessionService.GetSession(first); ok {
+		t.Error("Expected a revoked session to be rejected")
+	}
+	if err := sessionService.RevokeSession(firstInfo.SessionID); err != errors.ErrSessionNotFound {
+		t.Errorf("Expected not found when revoking twice, got %v", err)
+	}
+	if revoked, err := sessionService.RevokeUserSessions("alice@example.com_user-1"); err != nil || revoked != 2 {
+		t.Errorf("RevokeUserSessions() = %d, %v, want 2 revoked (including the expired session)", revoked, err)
+	}
+	if _, ok := sessionService.GetSession(second); ok {
+		t.Error("Expected the sessions of the user to be revoked")
+	}
+	if _, err := sessionService.RevokeUserSessions(""); err == nil {
+		t.Error("Expected error when revoking sessions without a user")
+	}
+
+	// Back-channel logout ends the sessions of a provider session or of a subject
+	identity.SessionID = "sid-1"
+	third, _ := sessionService.CreateSession(identity, "", "")
+	identity.SessionID = "sid-2"
+	fourth, _ := sessionService.CreateSession(identity, "", "")
+	if revoked, err := sessionService.RevokeOIDCSessions("", "sid-1"); err != nil || revoked != 1 {
+		t.Errorf("RevokeOIDCSessions(sid) = %d, %v, want 1 revoked", revoked, err)
+	}
+	if _, ok := sessionService.GetSession(third); ok {
+		t.Error("Expected the session of the provider session to end")
+	}
+	if revoked, err := sessionService.RevokeOIDCSessions("user-1", ""); err != nil || revoked != 1 {
+		t.Errorf("RevokeOIDCSessions(sub) = %d, %v, want 1 revoked", revoked, err)
+	}
+	if session := sessionService.DeleteSession(fourth); session != nil {
+		t.Errorf("Expected no session to delete after back-channel logout, got %+v", session)
+	}
+}
+
+// TestSessionServiceRenew tests renewing sessions that reached the maximum age
+func TestSessionServiceRenew(t *testing.T) {
+	tests := []struct {
+		name         string
+		refreshToken string
+		refresher    *mockRefresher
+		wantValid    bool
+	}{
+		{
+			name:         "Renewed with refresh token",
+			refreshToken: "refresh-1",
+			refresher:    &mockRefresher{identity: &SessionIdentity{Subject: "user-1", Email: "alice@example.com", Groups: []string{"ADMIN"}, RefreshToken: "refresh-2"}},
+			wantValid:    true,
+		},
+		{
+			name:      "No refresh token",
+			refresher: &mockRefresher{},
+		},
+		{
+			name:         "Refresh token rejected",
+			refreshToken: "refresh-1",
+			refresher:    &mockRefresher{err: fmt.Errorf("invalid_grant")},
+		},
+		{
+			name:         "Email changed",
+			refreshToken: "refresh-1",
+			refresher:    &mockRefresher{identity: &SessionIdentity{Subject: "user-1", Email: "bob@example.com"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := repository.NewFileSessionRepository(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create repository: %v", err)
+			}
+			sessionService := NewSessionService(repo, time.Hour, 24*time.Hour, &mockLogger{})
+			sessionService.SetRefresher(tt.refresher)
+
+			identity := &SessionIdentity{Subject: "user-1", Email: "alice@example.com", Groups: []string{"dev"}, RefreshToken: tt.refreshToken}
+			sessionID, _ := sessionService.CreateSession(identity, "", "")
+			info, _ := sessionService.GetSessionInfo(sessionID)
+
+			// Let the session reach the maximum age while it is still active
+			stored, _ := repo.GetByID(info.SessionID)
+			stored.AuthenticatedAt = time.Now().Add(-25 * time.Hour)
+			repo.Save(stored)
+
+			info, ok := sessionService.GetSessionInfo(sessionID)
+			if ok != tt.wantValid {
+				t.Fatalf("GetSession() valid = %v, want %v", ok, tt.wantValid)
+			}
+			if !ok {
+				if remaining, _ := repo.List(""); len(remaining) != 0 {
+					t.Errorf("Expected the ended session to be deleted, got %+v", remaining)
+				}
+				return
+			}
+
+			if len(info.Groups) != 1 || info.Groups[0] != "ADMIN" {
+				t.Errorf("Expected the groups to be updated, got %v", info.Groups)
+			}
+			stored, _ = repo.GetByID(info.SessionID)
+			if stored.RefreshToken != "refresh-2" || time.Since(stored.AuthenticatedAt) > time.Minute {
+				t.Errorf("Expected a rotated refresh token and a new authentication time, got %+v", stored)
+			}
+
+			// Renewed sessions are not renewed again until the next maximum age
+			sessionService.GetSession(sessionID)
+			if tt.refresher.calls != 1 {
+				t.Errorf("Expected one renewal, got %d", tt.refresher.calls)
+			}
+		})
+	}
+}
+
+// TestSessionServiceSecret tests encrypting the provider tokens of sessions and renewing sessions concurrently
+func TestSessionServiceSecret(t *testing.T) {
+	configDir := t.TempDir()
+	repo, err := repository.NewFileSessionRepository(configDir)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	refresher := &mockRefresher{
+		identity: &SessionIdentity{Subject: "user-1", Email: "alice@example.com", RefreshToken: "refresh-2"},
+		delay:    50 * time.Millisecond,
+	}
+	sessionService := NewSessionService(repo, time.Hour, 24*time.Hour, &mockLogger{})
+	sessionService.SetRefresher(refresher)
+	if err := sessionService.SetSecret("server-secret"); err != nil {
+		t.Fatalf("SetSecret() error = %v", err)
+	}
+
+	identity := &SessionIdentity{Subject: "user-1", Email: "alice@example.com", RefreshToken: "refresh-1", IDToken: "id-token-1"}
+	sessionID, _ := sessionService.CreateSession(identity, "", "")
+	data, _ := os.ReadFile(filepath.Join(configDir, "sessions.json"))
+	if strings.Contains(string(data), "refresh-1") || strings.Contains(string(data), "id-token-1") {
+		t.Errorf("Expected the provider tokens to be stored encrypted, got %s", data)
+	}
+
+	// Concurrent requests of a session reaching the maximum age share one renewal
+	info, _ := sessionService.GetSessionInfo(sessionID)
+	stored, _ := repo.GetByID(info.SessionID)
+	stored.AuthenticatedAt = time.Now().Add(-25 * time.Hour)
+	repo.Save(stored)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := sessionService.GetSession(sessionID); !ok {
+				t.Error("Expected the session to be renewed")
+			}
+		}()
+	}
+	wg.Wait()
+	if refresher.calls != 1 || refresher.refreshToken != "refresh-1" {
+		t.Errorf("Expected one renewal with the decrypted refresh token, got %d with %q", refresher.calls, refresher.refreshToken)
+	}
+
+	// Logging out returns the decrypted ID token for the provider logout
+	if session := sessionService.DeleteSession(sessionID); session == nil || session.IDToken != "id-token-1" {
+		t.Errorf("Expected the decrypted ID token, got %+v", session)
+	}
+
+	// Sessions whose tokens cannot be decrypted end at the maximum age
+	sessionID, _ = sessionService.CreateSession(identity, "", "")
+	other := NewSessionService(repo, time.Hour, 24*time.Hour, &mockLogger{})
+	other.SetRefresher(refresher)
+	other.SetSecret("another-secret")
+	info, _ = other.GetSessionInfo(sessionID)
+	stored, _ = repo.GetByID(info.SessionID)
+	stored.AuthenticatedAt = time.Now().Add(-25 * time.Hour)
+	repo.Save(stored)
+	if _, ok := other.GetSession(sessionID); ok || refresher.calls != 1 {
+		t.Errorf("Expected the session to end without renewal, got %d renewals", refresher.calls)
+	}
+}
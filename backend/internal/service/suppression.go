@@ -0,0 +1,324 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for vulnerability suppressions.
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+)
+
+const (
+	// suppressionsFileName stores the suppression rules of a user in the user's config
+	// directory. The file of the same name in the base config directory holds the global
+	// rules, which are maintained by the operator and apply to the scans of all users.
+	suppressionsFileName = "suppressions.json"
+
+	// maxSuppressions limits the number of suppression rules per user.
+	maxSuppressions = 500
+)
+
+// getSuppressionsPath returns the suppressions file of a user (the global file for an empty identifier).
+func (s *ConfigService) getSuppressionsPath(userIdentifier string) string {
+	return filepath.Join(s.getUserConfigDir(userIdentifier), suppressionsFileName)
+}
+
+// readSuppressionsNoLock reads the suppression rules of a user without locking.
+func (s *ConfigService) readSuppressionsNoLock(userIdentifier string) ([]*models.SuppressionRule, error) {
+	data, err := os.ReadFile(s.getSuppressionsPath(userIdentifier))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var rules []*models.SuppressionRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// readGlobalSuppressionsNoLock reads the global suppression rules, skipping invalid rules
+// of the hand-edited file instead of failing every scan.
+func (s *ConfigService) readGlobalSuppressionsNoLock() ([]*models.SuppressionRule, error) {
+	rules, err := s.readSuppressionsNoLock("")
+	if err != nil {
+		return nil, err
+	}
+
+	assignGlobalSuppressionIDs(rules)
+	valid := make([]*models.SuppressionRule, 0, len(rules))
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			s.logger.Error("Skipping global suppression rule %d: %v", i+1, err)
+			continue
+		}
+		rule.Scope = models.SuppressionScopeGlobal
+		valid = append(valid, rule)
+	}
+	return valid, nil
+}
+
+// ListSuppressions returns the global suppression rules and the rules of a user,
+// including expired rules, which are flagged but no longer applied.
+func (s *ConfigService) ListSuppressions(userIdentifier string) (*models.SuppressionListResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rules, err := s.allSuppressionsNoLock(userIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		rule.Expired = rule.IsExpired(now)
+	}
+	return &models.SuppressionListResponse{Suppressions: rules}, nil
+}
+
+// ActiveSuppressions returns the global and user suppression rules that have not expired at the given time.
+func (s *ConfigService) ActiveSuppressions(userIdentifier string, now time.Time) ([]*models.SuppressionRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rules, err := s.allSuppressionsNoLock(userIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []*models.SuppressionRule
+	for _, rule := range rules {
+		if !rule.IsExpired(now) {
+			active = append(active, rule)
+		}
+	}
+	return active, nil
+}
+
+// allSuppressionsNoLock returns the global rules followed by the rules of a user.
+func (s *ConfigService) allSuppressionsNoLock(userIdentifier string) ([]*models.SuppressionRule, error) {
+	rules, err := s.readGlobalSuppressionsNoLock()
+	if err != nil {
+		s.logger.Error("Failed to read global suppressions: %v", err)
+		return nil, errors.WrapInternal(err, "Failed to read global suppressions")
+	}
+
+	userRules, err := s.readSuppressionsNoLock(userIdentifier)
+	if err != nil {
+		s.logger.Error("Failed to read suppressions of user %s: %v", userIdentifier, err)
+		return nil, errors.WrapInternal(err, "Failed to read suppressions")
+	}
+	for _, rule := range userRules {
+		rule.Scope = models.SuppressionScopeUser
+	}
+
+	return append(rules, userRules...), nil
+}
+
+// AddSuppression validates and saves a new suppression rule for a user.
+func (s *ConfigService) AddSuppression(userIdentifier string, req *models.SuppressionRequest) (*models.SuppressionRule, error) {
+	return s.addSuppression(userIdentifier, models.SuppressionScopeUser, req)
+}
+
+// AddGlobalSuppression validates and saves a new global suppression rule, which applies to the scans of all users.
+func (s *ConfigService) AddGlobalSuppression(req *models.SuppressionRequest) (*models.SuppressionRule, error) {
+	return s.addSuppression("", models.SuppressionScopeGlobal, req)
+}
+
+// addSuppression validates and saves a new rule in the suppressions file of a user (the global file for an empty identifier).
+func (s *ConfigService) addSuppression(userIdentifier, scope string, req *models.SuppressionRequest) (*models.SuppressionRule, error) {
+	now := time.Now()
+	rule := &models.SuppressionRule{
+		ID:              uuid.New().String(),
+		VulnerabilityID: strings.TrimSpace(req.VulnerabilityID),
+		Package:         strings.TrimSpace(req.Package),
+		ExpiresAt:       req.ExpiresAt,
+		Justification:   strings.TrimSpace(req.Justification),
+		Scope:           scope,
+		CreatedAt:       now,
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, errors.WrapInvalidInput(err, fmt.Sprintf("Invalid suppression rule: %v", err))
+	}
+	if rule.IsExpired(now) {
+		return nil, errors.NewInvalidInput("Expiry time must be in the future")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules, err := s.readSuppressionsNoLock(userIdentifier)
+	if err != nil {
+		s.logger.Error("Failed to read suppressions of user %s: %v", userIdentifier, err)
+		return nil, errors.WrapInternal(err, "Failed to read suppressions")
+	}
+	if userIdentifier == "" {
+		assignGlobalSuppressionIDs(rules)
+	}
+	if len(rules) >= maxSuppressions {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Maximum number of suppression rules (%d) reached", maxSuppressions))
+	}
+
+	if err := s.writeSuppressionsNoLock(userIdentifier, append(rules, rule)); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Suppression of %s added for user %s", rule.VulnerabilityID, userIdentifier)
+	return rule, nil
+}
+
+// DeleteSuppression removes a suppression rule of a user. Global rules can only be deleted with DeleteGlobalSuppression.
+func (s *ConfigService) DeleteSuppression(userIdentifier, id string) error {
+	return s.deleteSuppression(userIdentifier, id)
+}
+
+// DeleteGlobalSuppression removes a global suppression rule.
+func (s *ConfigService) DeleteGlobalSuppression(id string) error {
+	return s.deleteSuppression("", id)
+}
+
+// deleteSuppression removes a rule from the suppressions file of a user (the global file for an empty identifier).
+func (s *ConfigService) deleteSuppression(userIdentifier, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules, err := s.readSuppressionsNoLock(userIdentifier)
+	if err != nil {
+		s.logger.Error("Failed to read suppressions of user %s: %v", userIdentifier, err)
+		return errors.WrapInternal(err, "Failed to read suppressions")
+	}
+	if userIdentifier == "" {
+		assignGlobalSuppressionIDs(rules)
+	}
+
+	for i, rule := range rules {
+		if rule.ID == id {
+			if err := s.writeSuppressionsNoLock(userIdentifier, append(rules[:i], rules[i+1:]...)); err != nil {
+				return err
+			}
+			s.logger.Info("Suppression %s deleted for user %s", id, userIdentifier)
+			return nil
+		}
+	}
+	return errors.ErrSuppressionNotFound
+}
+
+// assignGlobalSuppressionIDs gives hand-written global rules without an ID the positional
+// ID they are listed with, so the IDs stay stable once the file is rewritten.
+func assignGlobalSuppressionIDs(rules []*models.SuppressionRule) {
+	for i, rule := range rules {
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("global-%d", i+1)
+		}
+	}
+}
+
+// writeSuppressionsNoLock stores the suppression rules of a user without locking.
+func (s *ConfigService) writeSuppressionsNoLock(userIdentifier string, rules []*models.SuppressionRule) error {
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].CreatedAt.Before(rules[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		s.logger.Error("Failed to marshal suppressions: %v", err)
+		return errors.WrapInternal(err, "Failed to marshal suppressions")
+	}
+
+	path := s.getSuppressionsPath(userIdentifier)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		s.logger.Error("Failed to create config directory: %v", err)
+		return errors.WrapInternal(err, "Failed to create config directory")
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		s.logger.Error("Failed to write suppressions of user %s: %v", userIdentifier, err)
+		return errors.WrapInternal(err, "Failed to write suppressions")
+	}
+	return nil
+}
+
+// trivyIgnoreFile is the YAML ignore file read by trivy --ignorefile. It is written
+// as JSON, which is valid YAML, so no YAML encoder is needed.
+type trivyIgnoreFile struct {
+	Vulnerabilities []trivyIgnoreFinding `json:"vulnerabilities"`
+}
+
+// trivyIgnoreFinding is an entry of the ignore file. Expiry is not passed to Trivy
+// because expired rules are left out when the file is written.
+type trivyIgnoreFinding struct {
+	ID        string   `json:"id"`
+	PURLs     []string `json:"purls,omitempty"`
+	Statement string   `json:"statement,omitempty"`
+}
+
+// ignoreFilePath returns the path of the ignore file written for a task while it runs.
+func (s *scanServiceImpl) ignoreFilePath(task *models.ScanTask) string {
+	return filepath.Join(s.storageDir, "suppressions", task.ID+".trivyignore.yaml")
+}
+
+// prepareSuppressions records the suppression rules applying to a task and writes them to
+// the task's ignore file. Rules are resolved when the scan starts, so expired rules stop
+// applying without further action and rescans pick up the current rules.
+func (s *scanServiceImpl) prepareSuppressions(task *models.ScanTask) error {
+	task.ScanConfig.Suppressions = nil
+	if s.configService == nil {
+		return nil
+	}
+
+	rules, err := s.configService.ActiveSuppressions(task.UserID, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	ignoreFile := trivyIgnoreFile{Vulnerabilities: make([]trivyIgnoreFinding, 0, len(rules))}
+	for _, rule := range rules {
+		finding := trivyIgnoreFinding{ID: rule.VulnerabilityID, Statement: rule.Justification}
+		if rule.Package != "" {
+			finding.PURLs = []string{rule.Package}
+		}
+		ignoreFile.Vulnerabilities = append(ignoreFile.Vulnerabilities, finding)
+	}
+	data, err := json.MarshalIndent(ignoreFile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ignore file: %w", err)
+	}
+
+	path := s.ignoreFilePath(task)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create suppressions directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write ignore file: %w", err)
+	}
+
+	task.ScanConfig.Suppressions = rules
+	task.AddLog(fmt.Sprintf("Applying %d suppression rule(s)", len(rules)))
+	return nil
+}
+
+// removeIgnoreFile deletes the ignore file of a finished task.
+func (s *scanServiceImpl) removeIgnoreFile(task *models.ScanTask) {
+	if len(task.ScanConfig.Suppressions) == 0 {
+		return
+	}
+	if err := os.Remove(s.ignoreFilePath(task)); err != nil && !os.IsNotExist(err) {
+		s.logger.Error("Failed to delete ignore file of task %s: %v", task.ID, err)
+	}
+}
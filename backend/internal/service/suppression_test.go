@@ -0,0 +1,250 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+// TestSuppressions tests managing suppression rules and resolving the active ones
+func TestSuppressions(t *testing.T) {
+	configDir := t.TempDir()
+	configService := NewConfigService(configDir, false, 4096, 10, &mockLogger{})
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+	yesterday := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		req     *models.SuppressionRequest
+		wantErr bool
+	}{
+		{
+			name: "Vulnerability in all packages",
+			req:  &models.SuppressionRequest{VulnerabilityID: "CVE-2023-0001", Justification: "Not reachable"},
+		},
+		{
+			name: "Vulnerability in one package until tomorrow",
+			req:  &models.SuppressionRequest{VulnerabilityID: "GHSA-abcd-efgh-ijkl", Package: "pkg:npm/lodash", ExpiresAt: &tomorrow, Justification: "Fix scheduled"},
+		},
+		{
+			name:    "Missing justification",
+			req:     &models.SuppressionRequest{VulnerabilityID: "CVE-2023-0002", Justification: "  "},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid vulnerability ID",
+			req:     &models.SuppressionRequest{VulnerabilityID: "CVE 2023", Justification: "Not reachable"},
+			wantErr: true,
+		},
+		{
+			name:    "Package without package URL",
+			req:     &models.SuppressionRequest{VulnerabilityID: "CVE-2023-0003", Package: "lodash", Justification: "Not reachable"},
+			wantErr: true,
+		},
+		{
+			name:    "Expiry in the past",
+			req:     &models.SuppressionRequest{VulnerabilityID: "CVE-2023-0004", ExpiresAt: &yesterday, Justification: "Too late"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := configService.AddSuppression("user1", tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddSuppression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Global rules are read from the base config directory, invalid entries are skipped
+	global := `[
+  {"vulnerabilityId": "CVE-2020-9999", "justification": "Accepted company-wide"},
+  {"vulnerabilityId": "CVE-2020-8888", "justification": "Expired", "expiresAt": "2020-01-01T00:00:00Z"},
+  {"vulnerabilityId": "CVE-2020-7777"}
+]`
+	if err := os.WriteFile(filepath.Join(configDir, suppressionsFileName), []byte(global), 0600); err != nil {
+		t.Fatalf("Failed to write global suppressions: %v", err)
+	}
+
+	list, err := configService.ListSuppressions("user1")
+	if err != nil {
+		t.Fatalf("Failed to list suppressions: %v", err)
+	}
+	if len(list.Suppressions) != 4 {
+		t.Fatalf("Expected 2 global and 2 user rules, got %d", len(list.Suppressions))
+	}
+	if rule := list.Suppressions[0]; rule.Scope != models.SuppressionScopeGlobal || rule.ID == "" {
+		t.Errorf("Expected a global rule with an ID first, got %+v", rule)
+	}
+	if !list.Suppressions[1].Expired || list.Suppressions[2].Expired {
+		t.Errorf("Expected only the second global rule to be expired, got %+v", list.Suppressions)
+	}
+
+	// Expired rules stop applying
+	active, err := configService.ActiveSuppressions("user1", time.Now())
+	if err != nil {
+		t.Fatalf("Failed to resolve active suppressions: %v", err)
+	}
+	if len(active) != 3 {
+		t.Errorf("Expected 3 active rules, got %d", len(active))
+	}
+	active, _ = configService.ActiveSuppressions("user1", tomorrow.Add(time.Minute))
+	if len(active) != 2 {
+		t.Errorf("Expected 2 active rules after the user rule expired, got %d", len(active))
+	}
+
+	// Users only see and delete their own rules
+	list, _ = configService.ListSuppressions("user2")
+	if len(list.Suppressions) != 2 {
+		t.Errorf("Expected only the global rules for user2, got %d", len(list.Suppressions))
+	}
+	globalRule := list.Suppressions[0]
+	if err := configService.DeleteSuppression("user2", globalRule.ID); err != errors.ErrSuppressionNotFound {
+		t.Errorf("Expected not found when deleting a global rule, got %v", err)
+	}
+	list, _ = configService.ListSuppressions("user1")
+	if err := configService.DeleteSuppression("user2", list.Suppressions[2].ID); err != errors.ErrSuppressionNotFound {
+		t.Errorf("Expected not found when deleting another user's rule, got %v", err)
+	}
+	if err := configService.DeleteSuppression("user1", list.Suppressions[2].ID); err != nil {
+		t.Errorf("Failed to delete suppression: %v", err)
+	}
+	list, _ = configService.ListSuppressions("user1")
+	if len(list.Suppressions) != 3 {
+		t.Errorf("Expected 3 rules after deletion, got %d", len(list.Suppressions))
+	}
+
+	// Admins manage the global rules; hand-written rules keep their positional IDs
+	added, err := configService.AddGlobalSuppression(&models.SuppressionRequest{VulnerabilityID: "CVE-2020-6666", Justification: "Accepted by security team"})
+	if err != nil {
+		t.Fatalf("Failed to add global suppression: %v", err)
+	}
+	if added.Scope != models.SuppressionScopeGlobal {
+		t.Errorf("Expected a global rule, got scope %q", added.Scope)
+	}
+	if err := configService.DeleteGlobalSuppression(globalRule.ID); err != nil {
+		t.Errorf("Failed to delete global suppression %s: %v", globalRule.ID, err)
+	}
+	if err := configService.DeleteGlobalSuppression(globalRule.ID); err != errors.ErrSuppressionNotFound {
+		t.Errorf("Expected not found when deleting a global rule twice, got %v", err)
+	}
+	list, _ = configService.ListSuppressions("user2")
+	if len(list.Suppressions) != 2 || list.Suppressions[1].ID != added.ID {
+		t.Errorf("Expected the expired and the added global rule, got %+v", list.Suppressions)
+	}
+}
+
+// ignoreFileExecutor records the ignore file passed to trivy and reports one suppressed vulnerability.
+type ignoreFileExecutor struct {
+	args       []string
+	ignoreFile string
+}
+
+func (m *ignoreFileExecutor) ExecuteCommand(ctx context.Context, name string, args []string, logCallback func(string)) (string, string, error) {
+	m.args = args
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == "--ignorefile" {
+			data, _ := os.ReadFile(args[i+1])
+			m.ignoreFile = string(data)
+		}
+	}
+
+	output := map[string]interface{}{
+		"Results": []map[string]interface{}{
+			{
+				"Target":          "alpine:3.19 (alpine 3.19.1)",
+				"Vulnerabilities": []map[string]interface{}{{"VulnerabilityID": "CVE-2023-0002", "Severity": "HIGH"}},
+				"ExperimentalModifiedFindings": []map[string]interface{}{
+					{
+						"Type":      "vulnerability",
+						"Status":    "ignored",
+						"Statement": "Not reachable",
+						"Source":    ".trivyignore.yaml",
+						"Finding":   map[string]interface{}{"VulnerabilityID": "CVE-2023-0001", "PkgName": "openssl", "Severity": "CRITICAL"},
+					},
+				},
+			},
+		},
+	}
+	data, _ := json.Marshal(output)
+	return string(data), "", nil
+}
+
+// TestSuppressedFindings tests passing suppressions to trivy and marking suppressed findings
+func TestSuppressedFindings(t *testing.T) {
+	configService := NewConfigService(t.TempDir(), false, 4096, 10, &mockLogger{})
+	executor := &ignoreFileExecutor{}
+	service := NewScanServiceWithExecutor(repository.NewInMemoryScanRepository(), &types.TrivyConfig{Timeout: 600, MaxWorkers: 1},
+		t.TempDir(), &mockLogger{}, executor, WithConfigService(configService)).(*scanServiceImpl)
+	defer service.Stop()
+
+	if _, err := configService.AddSuppression("user1", &models.SuppressionRequest{
+		VulnerabilityID: "CVE-2023-0001",
+		Package:         "pkg:apk/alpine/openssl",
+		Justification:   "Not reachable",
+	}); err != nil {
+		t.Fatalf("Failed to add suppression: %v", err)
+	}
+
+	task, err := service.CreateScanTask("user1", &models.ScanRequest{Image: "alpine:3.19"})
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	task = waitForTask(t, service, task.ID)
+	if task.Status != models.ScanStatusCompleted {
+		t.Fatalf("Expected completed task, got %s", task.Status)
+	}
+
+	args := strings.Join(executor.args, " ")
+	if !strings.Contains(args, "--ignorefile "+service.ignoreFilePath(task)) || !strings.Contains(args, "--show-suppressed") {
+		t.Errorf("Expected ignore file arguments, got %s", args)
+	}
+	var ignoreFile trivyIgnoreFile
+	if err := json.Unmarshal([]byte(executor.ignoreFile), &ignoreFile); err != nil {
+		t.Fatalf("Failed to parse ignore file %q: %v", executor.ignoreFile, err)
+	}
+	if len(ignoreFile.Vulnerabilities) != 1 || ignoreFile.Vulnerabilities[0].ID != "CVE-2023-0001" ||
+		ignoreFile.Vulnerabilities[0].PURLs[0] != "pkg:apk/alpine/openssl" || ignoreFile.Vulnerabilities[0].Statement != "Not reachable" {
+		t.Errorf("Unexpected ignore file: %+v", ignoreFile)
+	}
+	if _, err := os.Stat(service.ignoreFilePath(task)); !os.IsNotExist(err) {
+		t.Error("Expected the ignore file to be removed after the scan")
+	}
+	if len(task.ScanConfig.Suppressions) != 1 {
+		t.Errorf("Expected the applied rule to be recorded with the task, got %d", len(task.ScanConfig.Suppressions))
+	}
+
+	// Suppressed vulnerabilities are not counted but still listed with their justification
+	if summary := task.Result.Summary; summary.Total != 1 || summary.Critical != 0 || summary.Suppressed != 1 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	findings, err := service.ListFindings(models.Principal{UserID: "user1"}, task.ID, &models.FindingListRequest{Kind: models.FindingKindVulnerability})
+	if err != nil {
+		t.Fatalf("Failed to list findings: %v", err)
+	}
+	if findings.Total != 2 {
+		t.Fatalf("Expected 2 findings, got %d", findings.Total)
+	}
+	first := findings.Findings[0]
+	if !first.Suppressed || first.Justification != "Not reachable" || first.Vulnerability.VulnerabilityID != "CVE-2023-0001" {
+		t.Errorf("Expected the suppressed CRITICAL finding first, got %+v", first)
+	}
+	findings, _ = service.ListFindings(models.Principal{UserID: "user1"}, task.ID, &models.FindingListRequest{Kind: models.FindingKindVulnerability, HideSuppressed: true})
+	if findings.Total != 1 || findings.Findings[0].Suppressed {
+		t.Errorf("Expected only the unsuppressed finding, got %+v", findings.Findings)
+	}
+}
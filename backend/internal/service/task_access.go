@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+)
+
+// authorizeTask loads a task and checks that the principal has the required permission on it.
+// Tasks the principal cannot read are reported as missing to avoid leaking their existence;
+// read-only access to a task that must be managed is forbidden.
+func authorizeTask(repo repository.ScanRepository, principal models.Principal, taskID string, required models.SharePermission) (*models.ScanTask, error) {
+	task, err := repo.GetByID(taskID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to get task")
+	}
+	if task == nil {
+		return nil, errors.ErrTaskNotFound
+	}
+
+	permission := task.Permission(principal)
+	if !permission.Allows(models.SharePermissionRead) {
+		return nil, errors.ErrTaskNotFound
+	}
+	if !permission.Allows(required) {
+		return nil, errors.ErrForbidden
+	}
+
+	return task, nil
+}
+
+// AuthorizeTask retrieves a scan task the principal owns or that is shared with
+// one of the principal's groups with at least the required permission.
+func (s *scanServiceImpl) AuthorizeTask(principal models.Principal, taskID string, required models.SharePermission) (*models.ScanTask, error) {
+	if _, err := authorizeTask(s.repo, principal, taskID, required); err != nil {
+		return nil, err
+	}
+	return s.GetTask(taskID)
+}
+
+// ShareTask replaces the groups a task is shared with. Only the owner can change sharing.
+func (s *scanServiceImpl) ShareTask(principal models.Principal, taskID string, req *models.ShareTaskRequest) (*models.ScanTask, error) {
+	if err := req.Validate(); err != nil {
+		return nil, errors.WrapInvalidInput(err, fmt.Sprintf("Invalid shares: %v", err))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := authorizeTask(s.repo, principal, taskID, models.SharePermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	if task.UserID != principal.UserID {
+		return nil, errors.NewForbidden("Only the task owner can change sharing")
+	}
+
+	task.Shares = req.Shares
+	if err := s.repo.Update(task); err != nil {
+		return nil, errors.WrapInternal(err, "Failed to update task")
+	}
+
+	s.logger.Info("Task %s shared with %d group(s) by user %s", taskID, len(task.Shares), principal.UserID)
+	return task, nil
+}
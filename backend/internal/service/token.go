@@ -0,0 +1,293 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for personal API tokens.
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+)
+
+const (
+	// maxTokensPerUser limits the number of API tokens a user can create.
+	maxTokensPerUser = 20
+
+	// maxTokenNameLength limits the length of a token name.
+	maxTokenNameLength = 100
+
+	// Token validity in days.
+	defaultTokenExpiryDays = 90
+	maxTokenExpiryDays     = 365
+
+	// tokenSecretPrefix starts every token secret, so leaked tokens are easy to recognize.
+	tokenSecretPrefix = "trivy_"
+
+	// tokenDisplayPrefixLength is the number of secret characters stored to recognize a token.
+	tokenDisplayPrefixLength = len(tokenSecretPrefix) + 6
+
+	// tokenLastUsedPrecision limits how often the last-used time of a token is written to disk.
+	tokenLastUsedPrecision = time.Minute
+)
+
+// TokenService defines the interface for personal API token management and validation.
+type TokenService interface {
+	// ListTokens returns the tokens of a user, without secret hashes.
+	ListTokens(userID string) ([]*models.APIToken, error)
+
+	// CreateToken creates a token acting as the owner of the session. The secret is
+	// returned once and only its hash is stored.
+	CreateToken(userID string, owner *SessionInfo, req *models.APITokenRequest) (*models.APITokenCreateResponse, error)
+
+	// RevokeToken deletes a token of a user.
+	RevokeToken(userID, tokenID string) error
+
+	// GetSession validates a token secret and returns the session it authenticates,
+	// recording the last-used time. Returns interface{} to satisfy middleware.SessionValidator.
+	GetSession(secret string) (interface{}, bool)
+
+	// IdentityListener keeps the groups of tokens up to date with the identity provider
+	// and revokes the tokens of users whose sessions are revoked.
+	IdentityListener
+}
+
+// tokenServiceImpl implements TokenService.
+type tokenServiceImpl struct {
+	repo   repository.TokenRepository
+	logger logger.Logger
+	mu     sync.Mutex // Serializes token changes (per-user limit, name uniqueness, revocation, last use)
+}
+
+// NewTokenService creates a new API token service.
+func NewTokenService(repo repository.TokenRepository, log logger.Logger) TokenService {
+	return &tokenServiceImpl{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// hashTokenSecret returns the hex SHA-256 hash a token secret is stored as.
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateTokenSecret generates a random token secret.
+func generateTokenSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return tokenSecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// publicToken returns a copy of a token suitable for API responses.
+func publicToken(token *models.APIToken) *models.APIToken {
+	clone := token.Clone()
+	clone.SecretHash = ""
+	clone.Subject = ""
+	clone.Email = ""
+	clone.Groups = nil
+	return clone
+}
+
+// ListTokens returns the tokens of a user.
+func (s *tokenServiceImpl) ListTokens(userID string) ([]*models.APIToken, error) {
+	tokens, err := s.repo.List(userID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to list API tokens")
+	}
+
+	for i, token := range tokens {
+		tokens[i] = publicToken(token)
+	}
+	return tokens, nil
+}
+
+// CreateToken creates a token acting as the owner of the session.
+func (s *tokenServiceImpl) CreateToken(userID string, owner *SessionInfo, req *models.APITokenRequest) (*models.APITokenCreateResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxTokenNameLength {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Token name must be 1-%d characters", maxTokenNameLength))
+	}
+
+	scope := req.Scope
+	if scope == "" {
+		scope = models.TokenScopeViewer
+	}
+	switch scope {
+	case models.TokenScopeViewer, models.TokenScopeScanner, models.TokenScopeAdmin:
+	default:
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Unsupported token scope: %s (must be viewer, scanner or admin)", scope))
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultTokenExpiryDays
+	}
+	if days < 1 || days > maxTokenExpiryDays {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("expiresInDays must be between 1 and %d", maxTokenExpiryDays))
+	}
+
+	secret, err := generateTokenSecret()
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to generate API token")
+	}
+
+	now := time.Now()
+	token := &models.APIToken{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Name:       name,
+		Scope:      scope,
+		Prefix:     secret[:tokenDisplayPrefixLength],
+		SecretHash: hashTokenSecret(secret),
+		Subject:    owner.UserID,
+		Email:      owner.Email,
+		Groups:     append([]string(nil), owner.Groups...),
+		CreatedAt:  now,
+		ExpiresAt:  now.AddDate(0, 0, days),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.repo.List(userID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to list API tokens")
+	}
+	if len(existing) >= maxTokensPerUser {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Maximum number of API tokens (%d) reached", maxTokensPerUser))
+	}
+	for _, other := range existing {
+		if other.Name == name {
+			return nil, errors.NewInvalidInput(fmt.Sprintf("An API token named %q already exists", name))
+		}
+	}
+
+	if err := s.repo.Save(token); err != nil {
+		return nil, errors.WrapInternal(err, "Failed to save API token")
+	}
+
+	s.logger.Info("API token %s (%s, scope %s) created for user %s, expires %s",
+		token.ID, token.Name, token.Scope, userID, token.ExpiresAt.Format(time.RFC3339))
+	return &models.APITokenCreateResponse{APIToken: publicToken(token), Token: secret}, nil
+}
+
+// RevokeToken deletes a token of a user. Tokens of other users are reported as missing.
+func (s *tokenServiceImpl) RevokeToken(userID, tokenID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.repo.GetByID(tokenID)
+	if err != nil {
+		return errors.WrapInternal(err, "Failed to get API token")
+	}
+	if token == nil || token.UserID != userID {
+		return errors.ErrTokenNotFound
+	}
+	if err := s.repo.Delete(tokenID); err != nil {
+		return errors.WrapInternal(err, "Failed to delete API token")
+	}
+
+	s.logger.Info("API token %s (%s) revoked by user %s", token.ID, token.Name, userID)
+	return nil
+}
+
+// IdentityUpdated replaces the groups of the tokens of a user with the groups the
+// identity provider reported, so that token roles follow the owner's current groups.
+func (s *tokenServiceImpl) IdentityUpdated(userID string, groups []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.repo.List(userID)
+	if err != nil {
+		s.logger.Error("Failed to list API tokens of user %s: %v", userID, err)
+		return
+	}
+	for _, token := range tokens {
+		if slices.Equal(token.Groups, groups) {
+			continue
+		}
+		token.Groups = append([]string(nil), groups...)
+		if err := s.repo.Save(token); err != nil {
+			s.logger.Error("Failed to update groups of API token %s: %v", token.ID, err)
+		}
+	}
+}
+
+// UserRevoked deletes the tokens of a user whose sessions were revoked.
+func (s *tokenServiceImpl) UserRevoked(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.repo.List(userID)
+	if err != nil {
+		s.logger.Error("Failed to list API tokens of user %s: %v", userID, err)
+		return
+	}
+	for _, token := range tokens {
+		if err := s.repo.Delete(token.ID); err != nil {
+			s.logger.Error("Failed to revoke API token %s: %v", token.ID, err)
+			continue
+		}
+		s.logger.Info("API token %s (%s) of user %s revoked with the user's sessions", token.ID, token.Name, userID)
+	}
+}
+
+// GetSession validates a token secret and returns the session it authenticates.
+func (s *tokenServiceImpl) GetSession(secret string) (interface{}, bool) {
+	if !strings.HasPrefix(secret, tokenSecretPrefix) {
+		return nil, false
+	}
+
+	token, err := s.repo.GetBySecretHash(hashTokenSecret(secret))
+	if err != nil {
+		s.logger.Error("Failed to look up API token: %v", err)
+		return nil, false
+	}
+	now := time.Now()
+	if token == nil || token.IsExpired(now) {
+		return nil, false
+	}
+
+	// Record the last use, at most once per tokenLastUsedPrecision. The token is reloaded
+	// under the lock so that a concurrent revocation is not undone by the save.
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= tokenLastUsedPrecision {
+		s.mu.Lock()
+		current, err := s.repo.GetByID(token.ID)
+		if err == nil && current != nil {
+			current.LastUsedAt = &now
+			err = s.repo.Save(current)
+		}
+		s.mu.Unlock()
+		if err != nil {
+			s.logger.Error("Failed to record last use of API token %s: %v", token.ID, err)
+		} else if current == nil {
+			return nil, false
+		}
+	}
+
+	return &SessionInfo{
+		UserID:   token.Subject,
+		Groups:   token.Groups,
+		Email:    token.Email,
+		ExpireAt: token.ExpiresAt,
+		TokenID:  token.ID,
+		Scope:    token.Scope,
+	}, true
+}
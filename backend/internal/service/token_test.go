@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+)
+
+// TestTokenService tests creating, validating and revoking personal API tokens
+func TestTokenService(t *testing.T) {
+	repo, err := repository.NewFileTokenRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	tokenService := NewTokenService(repo, &mockLogger{})
+
+	owner := &SessionInfo{UserID: "user-1", Email: "alice@example.com", Groups: []string{"dev"}}
+	userID := "alice@example.com_user-1"
+
+	tests := []struct {
+		name    string
+		req     *models.APITokenRequest
+		wantErr bool
+	}{
+		{name: "Default scope and expiry", req: &models.APITokenRequest{Name: "jenkins"}},
+		{name: "Scanner scope", req: &models.APITokenRequest{Name: "release", Scope: models.TokenScopeScanner, ExpiresInDays: 7}},
+		{name: "Duplicate name", req: &models.APITokenRequest{Name: "jenkins"}, wantErr: true},
+		{name: "Blank name", req: &models.APITokenRequest{Name: "  "}, wantErr: true},
+		{name: "Unknown scope", req: &models.APITokenRequest{Name: "root", Scope: "root"}, wantErr: true},
+		{name: "Expiry too long", req: &models.APITokenRequest{Name: "forever", ExpiresInDays: 1000}, wantErr: true},
+	}
+
+	created := make(map[string]*models.APITokenCreateResponse)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tokenService.CreateToken(userID, owner, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				created[resp.Name] = resp
+			}
+		})
+	}
+
+	jenkins := created["jenkins"]
+	if jenkins == nil {
+		t.Fatal("Expected the jenkins token to be created")
+	}
+	if jenkins.Scope != models.TokenScopeViewer || !strings.HasPrefix(jenkins.Token, jenkins.Prefix) {
+		t.Errorf("Unexpected token: %+v", jenkins)
+	}
+	if days := jenkins.ExpiresAt.Sub(jenkins.CreatedAt).Hours() / 24; days < 89 || days > 91 {
+		t.Errorf("Expected a 90 day default expiry, got %.1f days", days)
+	}
+
+	// Only the hash of the secret is stored
+	stored, _ := repo.GetByID(jenkins.ID)
+	if stored.SecretHash == "" || stored.SecretHash == jenkins.Token {
+		t.Errorf("Expected the secret to be stored hashed, got %q", stored.SecretHash)
+	}
+
+	// A valid secret authenticates as the owner with the token scope and records the last use
+	sessionInfo, ok := tokenService.GetSession(jenkins.Token)
+	if !ok {
+		t.Fatal("Expected the token to be valid")
+	}
+	session := sessionInfo.(*SessionInfo)
+	if session.UserID != "user-1" || session.Email != "alice@example.com" || session.Scope != models.TokenScopeViewer || session.TokenID != jenkins.ID {
+		t.Errorf("Unexpected token session: %+v", session)
+	}
+	tokens, _ := tokenService.ListTokens(userID)
+	if len(tokens) != 2 || tokens[0].LastUsedAt == nil || tokens[0].SecretHash != "" || tokens[0].Groups != nil {
+		t.Errorf("Unexpected token list: %+v", tokens)
+	}
+
+	if _, ok := tokenService.GetSession(jenkins.Token + "x"); ok {
+		t.Error("Expected an unknown secret to be rejected")
+	}
+
+	// Expired tokens are rejected
+	stored, _ = repo.GetByID(jenkins.ID)
+	stored.ExpiresAt = time.Now().Add(-time.Minute)
+	repo.Save(stored)
+	if _, ok := tokenService.GetSession(jenkins.Token); ok {
+		t.Error("Expected an expired token to be rejected")
+	}
+
+	// Users can only revoke their own tokens
+	release := created["release"]
+	if err := tokenService.RevokeToken("other-user", release.ID); err != errors.ErrTokenNotFound {
+		t.Errorf("Expected not found when revoking another user's token, got %v", err)
+	}
+	if err := tokenService.RevokeToken(userID, release.ID); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if _, ok := tokenService.GetSession(release.Token); ok {
+		t.Error("Expected a revoked token to be rejected")
+	}
+}
+
+// TestTokenServiceIdentityListener tests that tokens follow the groups of their owner and end with revoked users
+func TestTokenServiceIdentityListener(t *testing.T) {
+	tokenRepo, err := repository.NewFileTokenRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create token repository: %v", err)
+	}
+	sessionRepo, err := repository.NewFileSessionRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create session repository: %v", err)
+	}
+	tokenService := NewTokenService(tokenRepo, &mockLogger{})
+	sessionService := NewSessionService(sessionRepo, time.Hour, 24*time.Hour, &mockLogger{})
+	sessionService.SetIdentityListener(tokenService)
+
+	identity := &SessionIdentity{Subject: "user-1", Email: "alice@example.com", Groups: []string{"admins"}, SessionID: "sid-1"}
+	userID := "alice@example.com_user-1"
+	createToken := func(name string) string {
+		resp, err := tokenService.CreateToken(userID, &SessionInfo{UserID: identity.Subject, Email: identity.Email, Groups: identity.Groups},
+			&models.APITokenRequest{Name: name, Scope: models.TokenScopeAdmin})
+		if err != nil {
+			t.Fatalf("CreateToken() error = %v", err)
+		}
+		return resp.Token
+	}
+	tokenGroups := func(secret string) []string {
+		sessionInfo, ok := tokenService.GetSession(secret)
+		if !ok {
+			return nil
+		}
+		return sessionInfo.(*SessionInfo).Groups
+	}
+
+	// Logging in with fewer groups demotes the tokens of the user
+	if _, err := sessionService.CreateSession(identity, "", ""); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	secret := createToken("ci")
+	identity.Groups = []string{"dev"}
+	if _, err := sessionService.CreateSession(identity, "", ""); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	if groups := tokenGroups(secret); len(groups) != 1 || groups[0] != "dev" {
+		t.Errorf("Expected the token groups to follow the login, got %v", groups)
+	}
+
+	// Back-channel logout revokes the tokens of the users of the ended sessions
+	if _, err := sessionService.RevokeOIDCSessions("", "sid-1"); err != nil {
+		t.Fatalf("RevokeOIDCSessions() error = %v", err)
+	}
+	if _, ok := tokenService.GetSession(secret); ok {
+		t.Error("Expected the token to be revoked by the back-channel logout")
+	}
+
+	// So does revoking the sessions of the user as an admin, even without active sessions
+	secret = createToken("release")
+	if _, err := sessionService.RevokeUserSessions(userID); err != nil {
+		t.Fatalf("RevokeUserSessions() error = %v", err)
+	}
+	if _, ok := tokenService.GetSession(secret); ok {
+		t.Error("Expected the token to be revoked with the user's sessions")
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for uploaded scan targets.
+package service
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/validator"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+)
+
+const (
+	// defaultMaxUploadSize is used when no upload size limit is configured.
+	defaultMaxUploadSize = 512 * 1024 * 1024
+
+	// maxExtractRatio bounds the extracted size of a tarball relative to the upload limit.
+	maxExtractRatio = 4
+
+	// sbomSniffSize is the number of leading bytes inspected to detect the SBOM format.
+	sbomSniffSize = 64 * 1024
+
+	// uploadRootDir is the directory (inside the upload directory) tarballs are extracted to.
+	uploadRootDir = "root"
+)
+
+// errUploadTooLarge is returned when an upload exceeds the configured size limit.
+var errUploadTooLarge = fmt.Errorf("upload exceeds maximum size")
+
+// storageSizer is implemented by repositories that can report the storage used by a user
+// (see FileBasedScanRepository.GetUserStorageSize).
+type storageSizer interface {
+	GetUserStorageSize(userID string) (int64, error)
+}
+
+// uploadDir returns the directory holding the uploaded target of a task:
+// <storageDir>/uploads/users/{userID}/{taskID}
+func (s *scanServiceImpl) uploadDir(userID, taskID string) string {
+	return filepath.Join(s.storageDir, "uploads", "users", sanitizeUserIdentifier(userID), taskID)
+}
+
+// uploadTarget returns the path passed to trivy for an uploaded target.
+func (s *scanServiceImpl) uploadTarget(task *models.ScanTask) string {
+	dir := s.uploadDir(task.UserID, task.ID)
+	switch task.ScanConfig.Target() {
+	case models.TargetTypeFilesystem, models.TargetTypeRootfs:
+		return filepath.Join(dir, uploadRootDir)
+	default:
+		return filepath.Join(dir, task.Image)
+	}
+}
+
+// maxUploadSize returns the configured upload size limit in bytes.
+func (s *scanServiceImpl) maxUploadSize() int64 {
+	if s.config.MaxUploadSize > 0 {
+		return s.config.MaxUploadSize
+	}
+	return defaultMaxUploadSize
+}
+
+// UploadLimit returns the maximum size of an upload by a user, checked before the upload is received.
+func (s *scanServiceImpl) UploadLimit(userID string) (int64, error) {
+	if err := s.checkStorageQuota(userID); err != nil {
+		return 0, err
+	}
+	return s.maxUploadSize(), nil
+}
+
+// storeUpload validates and stores an uploaded target for a task.
+// Image archives, SBOMs and VM images are stored as-is; filesystem tarballs are extracted.
+func (s *scanServiceImpl) storeUpload(task *models.ScanTask, content io.Reader) error {
+	dir := s.uploadDir(task.UserID, task.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	limited := &io.LimitedReader{R: content, N: s.maxUploadSize() + 1}
+
+	switch task.ScanConfig.Target() {
+	case models.TargetTypeImage:
+		buffered := bufio.NewReader(limited)
+		head, _ := buffered.Peek(512)
+		if err := validator.ValidateImageArchive(head); err != nil {
+			return err
+		}
+		return writeUploadFile(s.uploadTarget(task), buffered, limited)
+
+	case models.TargetTypeSBOM:
+		buffered := bufio.NewReaderSize(limited, sbomSniffSize)
+		head, _ := buffered.Peek(sbomSniffSize)
+		if err := validator.ValidateSBOM(head); err != nil {
+			return err
+		}
+		return writeUploadFile(s.uploadTarget(task), buffered, limited)
+
+	case models.TargetTypeVM:
+		return writeUploadFile(s.uploadTarget(task), limited, limited)
+
+	case models.TargetTypeFilesystem, models.TargetTypeRootfs:
+		return extractTarball(limited, s.uploadTarget(task), s.maxUploadSize()*maxExtractRatio)
+
+	default:
+		return fmt.Errorf("target type %s does not support uploads", task.ScanConfig.Target())
+	}
+}
+
+// deleteUpload removes the uploaded target of a task, if any.
+// Returns the number of bytes freed.
+func (s *scanServiceImpl) deleteUpload(task *models.ScanTask) (int64, error) {
+	dir := s.uploadDir(task.UserID, task.ID)
+
+	size, err := dirSize(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// releaseUpload deletes the uploaded target of a finished task.
+// Uploads cannot be rescanned, so they are not kept once the scan is over.
+func (s *scanServiceImpl) releaseUpload(task *models.ScanTask) {
+	if task.ScanConfig == nil || !task.ScanConfig.HasUpload() {
+		return
+	}
+
+	size, err := s.deleteUpload(task)
+	if err != nil {
+		s.logger.Error("Failed to delete upload for task %s: %v", task.ID, err)
+		return
+	}
+	if size > 0 {
+		s.logger.Info("Deleted upload for task %s (%.2f MB)", task.ID, float64(size)/(1024*1024))
+	}
+}
+
+// userStorageUsage returns the storage used by a user in bytes: task metadata
+// (as reported by the repository), report files (as reported by the report store)
+// and pending uploads.
+func (s *scanServiceImpl) userStorageUsage(userID string) (int64, error) {
+	var total int64
+
+	if sizer, ok := s.repo.(storageSizer); ok {
+		size, err := sizer.GetUserStorageSize(userID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get task storage size: %w", err)
+		}
+		total += size
+	}
+
+	reportSize, err := s.reportStore.Usage(repository.UserReportPrefix(userID))
+	if err != nil {
+		return 0, err
+	}
+	total += reportSize
+
+	uploadSize, err := dirSize(filepath.Join(s.storageDir, "uploads", "users", sanitizeUserIdentifier(userID)))
+	if err != nil {
+		return 0, err
+	}
+	total += uploadSize
+
+	return total, nil
+}
+
+// checkStorageQuota returns a quota exceeded error when the user's storage
+// usage is over the configured quota. A zero quota means unlimited.
+func (s *scanServiceImpl) checkStorageQuota(userID string) error {
+	if s.config.UserStorageQuota <= 0 {
+		return nil
+	}
+
+	usage, err := s.userStorageUsage(userID)
+	if err != nil {
+		return errors.WrapInternal(err, "Failed to compute storage usage")
+	}
+	if usage > s.config.UserStorageQuota {
+		return errors.NewQuotaExceeded(fmt.Sprintf(
+			"Storage quota exceeded: %d of %d bytes used, delete old scans to free space",
+			usage, s.config.UserStorageQuota))
+	}
+
+	return nil
+}
+
+// dirSize returns the total size of the regular files under dir.
+// A missing directory has size zero.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return size, err
+}
+
+// writeUploadFile copies r to path. limited is the size-limiting reader
+// underlying r, used to detect uploads over the limit.
+func writeUploadFile(path string, r io.Reader, limited *io.LimitedReader) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create upload file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("failed to write upload file: %w", err)
+	}
+	if limited.N <= 0 {
+		return errUploadTooLarge
+	}
+
+	return f.Close()
+}
+
+// extractTarball extracts a (optionally gzip-compressed) tarball into destDir.
+// Only directories and regular files are extracted; links and device files are
+// skipped so that no entry can redirect writes outside destDir.
+func extractTarball(limited *io.LimitedReader, destDir string, maxExtractedSize int64) error {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create extraction directory: %w", err)
+	}
+
+	// Detect gzip compression from the magic bytes
+	var r io.Reader
+	buffered := bufio.NewReader(limited)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return fmt.Errorf("invalid gzip archive: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	tr := tar.NewReader(r)
+	var extracted int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if limited.N <= 0 {
+				return errUploadTooLarge
+			}
+			return fmt.Errorf("invalid tar archive: %w", err)
+		}
+
+		if err := validator.ValidateArchiveEntryPath(header.Name); err != nil {
+			return err
+		}
+		target := filepath.Join(destDir, filepath.FromSlash(filepath.Clean("/"+header.Name)))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+
+		case tar.TypeReg:
+			extracted += header.Size
+			if extracted > maxExtractedSize {
+				return errUploadTooLarge
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+			f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+			if err != nil {
+				return fmt.Errorf("failed to create file: %w", err)
+			}
+			_, err = io.Copy(f, io.LimitReader(tr, header.Size))
+			f.Close()
+			if err != nil {
+				if limited.N <= 0 {
+					return errUploadTooLarge
+				}
+				return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+			}
+
+		default:
+			// Symlinks, hard links and special files are not extracted
+		}
+	}
+
+	if limited.N <= 0 {
+		return errUploadTooLarge
+	}
+	return nil
+}
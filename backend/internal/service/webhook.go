@@ -0,0 +1,666 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package service provides business logic for webhook notifications.
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/logger"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+const (
+	// maxWebhooksPerUser limits the number of webhooks a user can create.
+	maxWebhooksPerUser = 20
+
+	// maxWebhookNameLength limits the length of a webhook display name.
+	maxWebhookNameLength = 100
+
+	// maxWebhookSecretLength limits the length of a webhook signing key.
+	maxWebhookSecretLength = 256
+
+	// Default delivery settings, used when the configuration leaves them unset.
+	defaultWebhookTimeout = 10 * time.Second
+
+	// Headers of webhook requests.
+	webhookEventHeader     = "X-Trivy-Webhook-Event"
+	webhookDeliveryHeader  = "X-Trivy-Webhook-Delivery"
+	webhookSignatureHeader = "X-Trivy-Webhook-Signature"
+)
+
+// errWebhookAddressBlocked is returned when a webhook resolves to a non-public address.
+var errWebhookAddressBlocked = stderrors.New("webhook address is not public")
+
+// nonPublicPrefixes are address ranges not covered by the netip predicates used in
+// isPublicAddr: "this network" and carrier-grade NAT (often used by overlay VPNs).
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// TaskNotifier is notified when a scan task completes or fails.
+type TaskNotifier interface {
+	// Notify sends the notifications of a finished task. It must not block the caller.
+	Notify(task *models.ScanTask)
+}
+
+// WebhookService defines the interface for webhook management and delivery.
+type WebhookService interface {
+	TaskNotifier
+
+	// ListWebhooks returns the webhooks of a user, without secrets and delivery logs.
+	ListWebhooks(userID string) ([]*models.Webhook, error)
+
+	// CreateWebhook validates and stores a new webhook for a user.
+	CreateWebhook(userID string, req *models.WebhookRequest) (*models.Webhook, error)
+
+	// SetWebhookPaused pauses or resumes a webhook of a user.
+	SetWebhookPaused(userID, webhookID string, paused bool) (*models.Webhook, error)
+
+	// DeleteWebhook removes a webhook of a user.
+	DeleteWebhook(userID, webhookID string) error
+
+	// ListDeliveries returns the most recent deliveries of a webhook, newest first.
+	ListDeliveries(userID, webhookID string) ([]*models.WebhookDelivery, error)
+
+	// TestWebhook sends a test event to a webhook once, without retries, and returns the delivery.
+	TestWebhook(userID, webhookID string) (*models.WebhookDelivery, error)
+
+	// Stop aborts pending retries and waits for in-flight deliveries.
+	Stop()
+}
+
+// webhookServiceImpl implements WebhookService.
+type webhookServiceImpl struct {
+	repo   repository.WebhookRepository
+	config *types.NotificationConfig
+	client *http.Client
+	logger logger.Logger
+
+	stopCh chan struct{}  // Aborts retry backoff on shutdown
+	wg     sync.WaitGroup // In-flight deliveries
+	mu     sync.Mutex     // Serializes webhook updates
+}
+
+// NewWebhookService creates a new webhook service instance.
+func NewWebhookService(repo repository.WebhookRepository, config *types.NotificationConfig, log logger.Logger) WebhookService {
+	timeout := time.Duration(config.WebhookTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	return &webhookServiceImpl{
+		repo:   repo,
+		config: config,
+		client: newWebhookClient(timeout, config.WebhookAllowPrivate),
+		logger: log,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// newWebhookClient creates the HTTP client of webhook deliveries. Redirects are not
+// followed. Unless allowPrivate is set, connections to non-public addresses are
+// refused; the check runs on the resolved address of every connection, so that
+// neither hostnames nor DNS rebinding can point a webhook at internal services.
+func newWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !isPublicAddr(addrPort.Addr()) {
+				return errWebhookAddressBlocked
+			}
+			return nil
+		}
+		// A proxy would connect to the webhook on our behalf, bypassing the check
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPublicAddr reports whether an address is a public unicast address, i.e. not
+// loopback, link-local, private (RFC 1918, IPv6 ULA), multicast or unspecified.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicWebhook returns a copy of a webhook suitable for API responses.
+func publicWebhook(webhook *models.Webhook) *models.Webhook {
+	clone := webhook.Clone()
+	clone.HasSecret = webhook.Secret != ""
+	clone.Secret = ""
+	clone.Deliveries = nil
+	return clone
+}
+
+// ListWebhooks returns the webhooks of a user.
+func (s *webhookServiceImpl) ListWebhooks(userID string) ([]*models.Webhook, error) {
+	webhooks, err := s.repo.List(userID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to list webhooks")
+	}
+
+	for i, webhook := range webhooks {
+		webhooks[i] = publicWebhook(webhook)
+	}
+	return webhooks, nil
+}
+
+// CreateWebhook validates and stores a new webhook for a user.
+func (s *webhookServiceImpl) CreateWebhook(userID string, req *models.WebhookRequest) (*models.Webhook, error) {
+	webhook := &models.Webhook{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		URL:       strings.TrimSpace(req.URL),
+		Format:    req.Format,
+		Secret:    req.Secret,
+		Threshold: strings.ToUpper(strings.TrimSpace(req.Threshold)),
+		CreatedAt: time.Now(),
+	}
+	if webhook.Format == "" {
+		webhook.Format = models.WebhookFormatGeneric
+	}
+	events := req.Events
+	if len(events) == 0 {
+		events = []string{models.WebhookEventCompleted, models.WebhookEventFailed}
+	}
+	for _, event := range events {
+		if !webhook.Subscribes(event) {
+			webhook.Events = append(webhook.Events, event)
+		}
+	}
+
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.repo.List(userID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to list webhooks")
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		return nil, errors.NewInvalidInput(fmt.Sprintf("Maximum number of webhooks (%d) reached", maxWebhooksPerUser))
+	}
+
+	if err := s.repo.Save(webhook); err != nil {
+		return nil, errors.WrapInternal(err, "Failed to save webhook")
+	}
+
+	s.logger.Info("Webhook %s created for user %s (%s, events: %s)", webhook.ID, userID, webhook.Format, strings.Join(webhook.Events, ","))
+	return publicWebhook(webhook), nil
+}
+
+// validateWebhook checks the user-supplied fields of a webhook.
+func validateWebhook(webhook *models.Webhook) error {
+	if len(webhook.Name) > maxWebhookNameLength {
+		return errors.NewInvalidInput(fmt.Sprintf("Webhook name exceeds maximum length of %d characters", maxWebhookNameLength))
+	}
+
+	endpoint, err := url.Parse(webhook.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" || len(webhook.URL) > 2048 {
+		return errors.NewInvalidInput("Webhook URL must be an absolute http or https URL")
+	}
+
+	switch webhook.Format {
+	case models.WebhookFormatGeneric, models.WebhookFormatSlack, models.WebhookFormatTeams:
+	default:
+		return errors.NewInvalidInput(fmt.Sprintf("Unsupported webhook format: %s", webhook.Format))
+	}
+
+	if len(webhook.Secret) > maxWebhookSecretLength {
+		return errors.NewInvalidInput(fmt.Sprintf("Webhook secret exceeds maximum length of %d characters", maxWebhookSecretLength))
+	}
+
+	for _, event := range webhook.Events {
+		switch event {
+		case models.WebhookEventCompleted, models.WebhookEventFailed, models.WebhookEventThreshold:
+		default:
+			return errors.NewInvalidInput(fmt.Sprintf("Unsupported webhook event: %s", event))
+		}
+	}
+
+	if webhook.Subscribes(models.WebhookEventThreshold) != (webhook.Threshold != "") {
+		return errors.NewInvalidInput("The threshold event requires a threshold severity and vice versa")
+	}
+	if _, ok := severityRank[webhook.Threshold]; webhook.Threshold != "" && !ok {
+		return errors.NewInvalidInput(fmt.Sprintf("Unsupported threshold severity: %s", webhook.Threshold))
+	}
+
+	return nil
+}
+
+// getOwnedWebhook returns a webhook of a user. Webhooks of other users are reported as missing.
+func (s *webhookServiceImpl) getOwnedWebhook(userID, webhookID string) (*models.Webhook, error) {
+	webhook, err := s.repo.GetByID(webhookID)
+	if err != nil {
+		return nil, errors.WrapInternal(err, "Failed to get webhook")
+	}
+	if webhook == nil || webhook.UserID != userID {
+		return nil, errors.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// SetWebhookPaused pauses or resumes a webhook of a user.
+func (s *webhookServiceImpl) SetWebhookPaused(userID, webhookID string, paused bool) (*models.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhook, err := s.getOwnedWebhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Paused = paused
+	if err := s.repo.Save(webhook); err != nil {
+		return nil, errors.WrapInternal(err, "Failed to save webhook")
+	}
+
+	return publicWebhook(webhook), nil
+}
+
+// DeleteWebhook removes a webhook of a user.
+func (s *webhookServiceImpl) DeleteWebhook(userID, webhookID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.getOwnedWebhook(userID, webhookID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(webhookID); err != nil {
+		return errors.WrapInternal(err, "Failed to delete webhook")
+	}
+
+	s.logger.Info("Webhook %s deleted for user %s", webhookID, userID)
+	return nil
+}
+
+// ListDeliveries returns the delivery log of a webhook of a user.
+func (s *webhookServiceImpl) ListDeliveries(userID, webhookID string) ([]*models.WebhookDelivery, error) {
+	webhook, err := s.getOwnedWebhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.Deliveries == nil {
+		return []*models.WebhookDelivery{}, nil
+	}
+	return webhook.Deliveries, nil
+}
+
+// TestWebhook sends a test event to a webhook of a user.
+func (s *webhookServiceImpl) TestWebhook(userID, webhookID string) (*models.WebhookDelivery, error) {
+	webhook, err := s.getOwnedWebhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &models.WebhookPayload{
+		Event:     models.WebhookEventTest,
+		Message:   "Test notification",
+		Timestamp: time.Now(),
+	}
+	return s.deliver(webhook, payload, 0), nil
+}
+
+// Notify sends the completed, failed or threshold event of a finished task to the
+// subscribed webhooks of the task's owner. Deliveries run in the background.
+func (s *webhookServiceImpl) Notify(task *models.ScanTask) {
+	var event string
+	switch task.Status {
+	case models.ScanStatusCompleted:
+		event = models.WebhookEventCompleted
+	case models.ScanStatusFailed:
+		event = models.WebhookEventFailed
+	default:
+		return
+	}
+
+	webhooks, err := s.repo.List(task.UserID)
+	if err != nil {
+		s.logger.Error("Failed to list webhooks of user %s: %v", task.UserID, err)
+		return
+	}
+
+	// Snapshot the task now: it may be deleted or rescanned while deliveries are retried
+	base := s.taskPayload(task)
+	for _, webhook := range webhooks {
+		if webhook.Paused {
+			continue
+		}
+
+		payload := *base
+		payload.Event = event
+		if event == models.WebhookEventCompleted && webhook.Subscribes(models.WebhookEventThreshold) &&
+			countAtOrAbove(base.Summary, webhook.Threshold) > 0 {
+			payload.Event = models.WebhookEventThreshold
+			payload.Threshold = webhook.Threshold
+		}
+		if !webhook.Subscribes(payload.Event) {
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(webhook *models.Webhook, payload *models.WebhookPayload) {
+			defer s.wg.Done()
+			s.deliver(webhook, payload, s.config.WebhookMaxRetries)
+		}(webhook, &payload)
+	}
+}
+
+// taskPayload builds the generic payload of a finished task.
+func (s *webhookServiceImpl) taskPayload(task *models.ScanTask) *models.WebhookPayload {
+	payload := &models.WebhookPayload{
+		TaskID:        task.ID,
+		Image:         task.Image,
+		Status:        task.Status,
+		Message:       task.Message,
+		Error:         task.ErrorOutput,
+		PolicyVerdict: task.PolicyVerdict,
+		StartTime:     task.StartTime,
+		EndTime:       task.EndTime,
+		Timestamp:     time.Now(),
+	}
+	if task.ScanConfig != nil {
+		payload.TargetType = task.ScanConfig.Target()
+	}
+	if task.Result != nil {
+		payload.Summary = task.Result.Summary
+	}
+	if task.Status == models.ScanStatusCompleted {
+		payload.ReportURL = taskReportURL(s.config.PublicURL, task)
+	}
+	return payload
+}
+
+// taskReportURL returns the report download link of a task, or an empty string
+// when no public URL is configured.
+func taskReportURL(publicURL string, task *models.ScanTask) string {
+	if publicURL == "" {
+		return ""
+	}
+	format := "json"
+	if task.ScanConfig != nil && task.ScanConfig.Format != "" {
+		format = task.ScanConfig.Format
+	}
+	return fmt.Sprintf("%s/api/v1/scan/%s/report/%s", strings.TrimRight(publicURL, "/"), task.ID, format)
+}
+
+// countAtOrAbove returns the number of vulnerabilities at or above a severity.
+func countAtOrAbove(summary *models.VulnerabilitySummary, severity string) int {
+	rank, ok := severityRank[severity]
+	if summary == nil || !ok {
+		return 0
+	}
+	counts := []int{summary.Critical, summary.High, summary.Medium, summary.Low}
+	total := 0
+	for _, count := range counts[:rank+1] {
+		total += count
+	}
+	return total
+}
+
+// deliver posts a payload to a webhook, retrying failed attempts with exponential
+// backoff, and records the delivery in the webhook's log.
+func (s *webhookServiceImpl) deliver(webhook *models.Webhook, payload *models.WebhookPayload, maxRetries int) *models.WebhookDelivery {
+	delivery := &models.WebhookDelivery{
+		ID:        uuid.New().String(),
+		Event:     payload.Event,
+		TaskID:    payload.TaskID,
+		CreatedAt: time.Now(),
+	}
+
+	body, err := renderWebhookBody(webhook.Format, payload)
+	if err != nil {
+		delivery.Error = fmt.Sprintf("failed to render payload: %v", err)
+	} else {
+		backoff := time.Duration(s.config.WebhookRetryBackoff) * time.Second
+	attempts:
+		for attempt := 1; attempt <= maxRetries+1; attempt++ {
+			delivery.Attempts = attempt
+			statusCode, err := s.post(webhook, delivery.ID, payload.Event, body)
+			delivery.StatusCode = statusCode
+			if err == nil {
+				delivery.Success = true
+				delivery.Error = ""
+				break
+			}
+			delivery.Error = err.Error()
+
+			// Redirects and client errors other than rate limiting will not succeed on retry
+			if statusCode >= 300 && statusCode < 500 && statusCode != http.StatusTooManyRequests {
+				break
+			}
+			if attempt <= maxRetries {
+				select {
+				case <-time.After(backoff):
+				case <-s.stopCh:
+					break attempts
+				}
+				backoff *= 2
+			}
+		}
+	}
+	delivery.Duration = time.Since(delivery.CreatedAt).Milliseconds()
+
+	if delivery.Success {
+		s.logger.Info("Webhook %s: delivered %s event of task %s", webhook.ID, delivery.Event, delivery.TaskID)
+	} else {
+		s.logger.Error("Webhook %s: failed to deliver %s event of task %s after %d attempt(s): %s",
+			webhook.ID, delivery.Event, delivery.TaskID, delivery.Attempts, delivery.Error)
+	}
+	if err := s.repo.AddDelivery(webhook.ID, delivery); err != nil {
+		s.logger.Error("Failed to record delivery of webhook %s: %v", webhook.ID, err)
+	}
+	return delivery
+}
+
+// post sends one webhook request. Requests are signed with HMAC-SHA256 of the body
+// when the webhook has a secret. Returns the response status (0 without response).
+// Connection errors are logged and returned generically: delivery logs are visible
+// to the webhook owner and must not reveal which internal hosts and ports respond.
+func (s *webhookServiceImpl) post(webhook *models.Webhook, deliveryID, event string, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Trivy-Web-UI-Webhook")
+	req.Header.Set(webhookEventHeader, event)
+	req.Header.Set(webhookDeliveryHeader, deliveryID)
+	if webhook.Secret != "" {
+		req.Header.Set(webhookSignatureHeader, signWebhookBody(webhook.Secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.logger.Info("Webhook %s: request failed: %v", webhook.ID, err)
+		var netErr net.Error
+		switch {
+		case stderrors.Is(err, errWebhookAddressBlocked):
+			return 0, fmt.Errorf("webhook address is not allowed")
+		case stderrors.As(err, &netErr) && netErr.Timeout():
+			return 0, fmt.Errorf("request timed out")
+		default:
+			return 0, fmt.Errorf("request failed")
+		}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// signWebhookBody returns the signature header value of a body: "sha256=" followed by
+// the hex-encoded HMAC-SHA256 of the body keyed with the webhook secret.
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// renderWebhookBody encodes a payload in the format of a webhook.
+func renderWebhookBody(format string, payload *models.WebhookPayload) ([]byte, error) {
+	switch format {
+	case models.WebhookFormatSlack:
+		return json.Marshal(slackMessage(payload))
+	case models.WebhookFormatTeams:
+		return json.Marshal(teamsMessage(payload))
+	default:
+		return json.Marshal(payload)
+	}
+}
+
+// webhookTitle returns the one-line description of an event.
+func webhookTitle(payload *models.WebhookPayload) string {
+	switch payload.Event {
+	case models.WebhookEventCompleted:
+		return fmt.Sprintf("Scan completed: %s", payload.Image)
+	case models.WebhookEventFailed:
+		return fmt.Sprintf("Scan failed: %s", payload.Image)
+	case models.WebhookEventThreshold:
+		return fmt.Sprintf("%s or higher vulnerabilities found: %s", payload.Threshold, payload.Image)
+	default:
+		return "Test notification from Trivy Web UI"
+	}
+}
+
+// webhookColor returns the hex color of an event for chat messages.
+func webhookColor(event string) string {
+	switch event {
+	case models.WebhookEventCompleted:
+		return "2EB67D"
+	case models.WebhookEventFailed, models.WebhookEventThreshold:
+		return "E01E5A"
+	default:
+		return "1D9BD1"
+	}
+}
+
+// webhookFacts returns the name/value pairs shown in chat messages.
+func webhookFacts(payload *models.WebhookPayload) [][2]string {
+	var facts [][2]string
+	if payload.TaskID != "" {
+		facts = append(facts, [2]string{"Task", payload.TaskID})
+	}
+	if summary := payload.Summary; summary != nil {
+		facts = append(facts, [2]string{"Vulnerabilities", fmt.Sprintf("%d (CRITICAL %d, HIGH %d, MEDIUM %d, LOW %d, UNKNOWN %d)",
+			summary.Total, summary.Critical, summary.High, summary.Medium, summary.Low, summary.Unknown)})
+	}
+	if verdict := payload.PolicyVerdict; verdict != nil {
+		result := "passed"
+		if !verdict.Passed {
+			result = "failed: " + strings.Join(verdict.Reasons, "; ")
+		}
+		facts = append(facts, [2]string{"Policy " + verdict.Policy, result})
+	}
+	if payload.Error != "" {
+		facts = append(facts, [2]string{"Error", payload.Error})
+	}
+	return facts
+}
+
+// slackMessage builds a Slack incoming webhook message.
+func slackMessage(payload *models.WebhookPayload) map[string]interface{} {
+	text := webhookTitle(payload)
+	if payload.ReportURL != "" {
+		text += fmt.Sprintf(" (<%s|report>)", payload.ReportURL)
+	}
+
+	fields := []map[string]interface{}{}
+	for _, fact := range webhookFacts(payload) {
+		fields = append(fields, map[string]interface{}{"title": fact[0], "value": fact[1], "short": false})
+	}
+
+	return map[string]interface{}{
+		"text": text,
+		"attachments": []map[string]interface{}{
+			{"color": "#" + webhookColor(payload.Event), "fields": fields},
+		},
+	}
+}
+
+// teamsMessage builds a Microsoft Teams incoming webhook message card.
+func teamsMessage(payload *models.WebhookPayload) map[string]interface{} {
+	title := webhookTitle(payload)
+
+	facts := []map[string]string{}
+	for _, fact := range webhookFacts(payload) {
+		facts = append(facts, map[string]string{"name": fact[0], "value": fact[1]})
+	}
+
+	message := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    title,
+		"title":      title,
+		"themeColor": webhookColor(payload.Event),
+		"sections":   []map[string]interface{}{{"facts": facts}},
+	}
+	if payload.ReportURL != "" {
+		message["potentialAction"] = []map[string]interface{}{
+			{
+				"@type":   "OpenUri",
+				"name":    "View report",
+				"targets": []map[string]string{{"os": "default", "uri": payload.ReportURL}},
+			},
+		}
+	}
+	return message
+}
+
+// Stop aborts pending retries and waits for in-flight deliveries.
+func (s *webhookServiceImpl) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
@@ -0,0 +1,322 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lazycatapps/trivy/backend/internal/models"
+	"github.com/lazycatapps/trivy/backend/internal/pkg/errors"
+	"github.com/lazycatapps/trivy/backend/internal/repository"
+	"github.com/lazycatapps/trivy/backend/internal/types"
+)
+
+// webhookRecorder is a test endpoint that records received requests and
+// fails the first failures requests with a 500.
+type webhookRecorder struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *webhookRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// newTestWebhookService creates a webhook service without retry backoff.
+func newTestWebhookService(t *testing.T) (*webhookServiceImpl, repository.WebhookRepository) {
+	repo, err := repository.NewFileWebhookRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	config := &types.NotificationConfig{
+		PublicURL:           "https://trivy.example.com/",
+		WebhookMaxRetries:   2,
+		WebhookRetryBackoff: 0,
+		WebhookTimeout:      5,
+		WebhookAllowPrivate: true, // Test servers listen on loopback
+	}
+	return NewWebhookService(repo, config, &mockLogger{}).(*webhookServiceImpl), repo
+}
+
+// TestCreateWebhook tests webhook validation
+func TestCreateWebhook(t *testing.T) {
+	service, _ := newTestWebhookService(t)
+	defer service.Stop()
+
+	tests := []struct {
+		name    string
+		req     *models.WebhookRequest
+		wantErr bool
+	}{
+		{
+			name: "Generic webhook with defaults",
+			req:  &models.WebhookRequest{URL: "https://hooks.example.com/trivy", Secret: "s3cret"},
+		},
+		{
+			name: "Slack webhook with threshold",
+			req:  &models.WebhookRequest{URL: "https://hooks.slack.com/services/x", Format: models.WebhookFormatSlack, Events: []string{"threshold"}, Threshold: "high"},
+		},
+		{
+			name:    "Relative URL",
+			req:     &models.WebhookRequest{URL: "/hooks/trivy"},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported scheme",
+			req:     &models.WebhookRequest{URL: "ftp://hooks.example.com/trivy"},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported format",
+			req:     &models.WebhookRequest{URL: "https://hooks.example.com/trivy", Format: "discord"},
+			wantErr: true,
+		},
+		{
+			name:    "Threshold event without severity",
+			req:     &models.WebhookRequest{URL: "https://hooks.example.com/trivy", Events: []string{"threshold"}},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported severity",
+			req:     &models.WebhookRequest{URL: "https://hooks.example.com/trivy", Events: []string{"threshold"}, Threshold: "UNKNOWN"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook, err := service.CreateWebhook("user1", tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && webhook.Secret != "" {
+				t.Error("Expected the secret to be omitted from the response")
+			}
+		})
+	}
+
+	webhooks, _ := service.ListWebhooks("user1")
+	if len(webhooks) != 2 {
+		t.Fatalf("Expected 2 webhooks, got %d", len(webhooks))
+	}
+	if first := webhooks[0]; !first.HasSecret || first.Format != models.WebhookFormatGeneric || len(first.Events) != 2 {
+		t.Errorf("Unexpected defaults: %+v", first)
+	}
+	if second := webhooks[1]; second.Threshold != "HIGH" {
+		t.Errorf("Expected the threshold to be normalized, got %s", second.Threshold)
+	}
+
+	// Webhooks of other users are not found
+	if err := service.DeleteWebhook("user2", webhooks[0].ID); err != errors.ErrWebhookNotFound {
+		t.Errorf("Expected not found when deleting another user's webhook, got %v", err)
+	}
+}
+
+// TestWebhookDelivery tests signing, retries, formats and the delivery log
+func TestWebhookDelivery(t *testing.T) {
+	service, repo := newTestWebhookService(t)
+	defer service.Stop()
+
+	generic := &webhookRecorder{failures: 1}
+	slack := &webhookRecorder{}
+	teams := &webhookRecorder{}
+	for _, recorder := range []*webhookRecorder{generic, slack, teams} {
+		server := httptest.NewServer(recorder)
+		defer server.Close()
+		format := map[*webhookRecorder]string{generic: "generic", slack: "slack", teams: "teams"}[recorder]
+		req := &models.WebhookRequest{URL: server.URL, Format: format}
+		if recorder == generic {
+			req.Secret = "s3cret"
+			req.Events = []string{"completed", "failed", "threshold"}
+			req.Threshold = "CRITICAL"
+		}
+		if _, err := service.CreateWebhook("user1", req); err != nil {
+			t.Fatalf("Failed to create %s webhook: %v", format, err)
+		}
+	}
+
+	startTime := time.Now()
+	task := &models.ScanTask{
+		ID:         "task-1",
+		UserID:     "user1",
+		Image:      "alpine:3.19",
+		Status:     models.ScanStatusCompleted,
+		StartTime:  startTime,
+		ScanConfig: &models.ScanConfig{Format: "json"},
+		Result:     &models.ScanResult{Summary: &models.VulnerabilitySummary{Total: 3, Critical: 1, High: 2}},
+	}
+	service.Notify(task)
+	// Notifications of other users' tasks are not delivered
+	service.Notify(&models.ScanTask{ID: "task-2", UserID: "user2", Status: models.ScanStatusFailed})
+	service.wg.Wait()
+
+	// The generic webhook is retried after the 500 and receives the threshold event
+	if generic.count() != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", generic.count())
+	}
+	body := generic.bodies[1]
+	if got, want := generic.requests[1].Header.Get(webhookSignatureHeader), signWebhookBody("s3cret", body); got != want {
+		t.Errorf("Expected signature %s, got %s", want, got)
+	}
+	if generic.requests[1].Header.Get(webhookEventHeader) != models.WebhookEventThreshold {
+		t.Errorf("Expected threshold event header, got %s", generic.requests[1].Header.Get(webhookEventHeader))
+	}
+	var payload models.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Failed to parse payload: %v", err)
+	}
+	if payload.Event != models.WebhookEventThreshold || payload.TaskID != "task-1" || payload.Summary.Critical != 1 ||
+		payload.ReportURL != "https://trivy.example.com/api/v1/scan/task-1/report/json" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+
+	webhooks, _ := repo.List("user1")
+	if deliveries := webhooks[0].Deliveries; len(deliveries) != 1 || !deliveries[0].Success || deliveries[0].Attempts != 2 {
+		t.Errorf("Unexpected delivery log: %+v", deliveries)
+	}
+
+	// Chat formats receive the completed event without signature
+	if slack.count() != 1 || slack.requests[0].Header.Get(webhookSignatureHeader) != "" {
+		t.Fatalf("Expected one unsigned Slack request, got %d", slack.count())
+	}
+	var slackBody struct {
+		Text        string `json:"text"`
+		Attachments []struct {
+			Fields []map[string]interface{} `json:"fields"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(slack.bodies[0], &slackBody); err != nil || slackBody.Text == "" || len(slackBody.Attachments[0].Fields) != 2 {
+		t.Errorf("Unexpected Slack message: %s", slack.bodies[0])
+	}
+	var teamsBody map[string]interface{}
+	if err := json.Unmarshal(teams.bodies[0], &teamsBody); err != nil || teamsBody["@type"] != "MessageCard" || teamsBody["potentialAction"] == nil {
+		t.Errorf("Unexpected Teams message: %s", teams.bodies[0])
+	}
+
+	// Client errors are not retried, paused webhooks are not called
+	generic.failures = 0
+	if _, err := service.SetWebhookPaused("user1", webhooks[1].ID, true); err != nil {
+		t.Fatalf("Failed to pause webhook: %v", err)
+	}
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+	rejected, _ := service.CreateWebhook("user1", &models.WebhookRequest{URL: rejecting.URL})
+
+	task.Status = models.ScanStatusFailed
+	task.Result = nil
+	service.Notify(task)
+	service.wg.Wait()
+
+	if slack.count() != 1 {
+		t.Errorf("Expected the paused webhook not to be called, got %d requests", slack.count())
+	}
+	deliveries, err := service.ListDeliveries("user1", rejected.ID)
+	if err != nil {
+		t.Fatalf("Failed to list deliveries: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Success || deliveries[0].Attempts != 1 || deliveries[0].StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected one failed attempt, got %+v", deliveries)
+	}
+
+	// Test deliveries are sent once and recorded
+	delivery, err := service.TestWebhook("user1", webhooks[2].ID)
+	if err != nil || !delivery.Success || delivery.Event != models.WebhookEventTest {
+		t.Errorf("Unexpected test delivery: %+v (%v)", delivery, err)
+	}
+}
+
+// TestWebhookAddressRestrictions tests that webhooks cannot reach internal addresses or follow redirects
+func TestWebhookAddressRestrictions(t *testing.T) {
+	addresses := map[string]bool{
+		"8.8.8.8":                true,
+		"2606:4700::1111":        true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"::ffff:127.0.0.1":       false,
+		"0.0.0.0":                false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"100.100.100.100":        false,
+		"fd00::1":                false,
+		"fe80::1":                false,
+		"ff02::1":                false,
+		"255.255.255.255":        false,
+		"::ffff:169.254.169.254": false,
+	}
+	for address, want := range addresses {
+		if got := isPublicAddr(netip.MustParseAddr(address)); got != want {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", address, got, want)
+		}
+	}
+
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	// Loopback webhooks are refused unless private addresses are allowed
+	repo, err := repository.NewFileWebhookRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	restricted := NewWebhookService(repo, &types.NotificationConfig{WebhookTimeout: 5}, &mockLogger{}).(*webhookServiceImpl)
+	defer restricted.Stop()
+	webhook, err := restricted.CreateWebhook("user1", &models.WebhookRequest{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create webhook: %v", err)
+	}
+	delivery, err := restricted.TestWebhook("user1", webhook.ID)
+	if err != nil || delivery.Success || delivery.Error != "webhook address is not allowed" {
+		t.Errorf("Expected the loopback delivery to be refused, got %+v (%v)", delivery, err)
+	}
+	if recorder.count() != 0 {
+		t.Errorf("Expected no request to reach the server, got %d", recorder.count())
+	}
+
+	// Redirects are not followed nor retried
+	service, _ := newTestWebhookService(t)
+	defer service.Stop()
+	redirecting := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusFound))
+	defer redirecting.Close()
+	redirected, _ := service.CreateWebhook("user1", &models.WebhookRequest{URL: redirecting.URL})
+	service.Notify(&models.ScanTask{ID: "task-1", UserID: "user1", Status: models.ScanStatusCompleted})
+	service.wg.Wait()
+
+	deliveries, _ := service.ListDeliveries("user1", redirected.ID)
+	if len(deliveries) != 1 || deliveries[0].Success || deliveries[0].Attempts != 1 || deliveries[0].StatusCode != http.StatusFound {
+		t.Errorf("Expected one failed attempt with the redirect status, got %+v", deliveries)
+	}
+	if recorder.count() != 0 {
+		t.Errorf("Expected the redirect not to be followed, got %d requests", recorder.count())
+	}
+}
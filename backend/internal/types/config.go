@@ -4,6 +4,8 @@
 // Package types defines configuration types for the Trivy Web UI application.
 package types
 
+import "time"
+
 // Config represents the complete application configuration.
 type Config struct {
 	Server  ServerConfig  // HTTP server configuration
@@ -11,6 +13,10 @@ type Config struct {
 	CORS    CORSConfig    // CORS policy configuration
 	Storage StorageConfig // Storage configuration
 	OIDC    OIDCConfig    // OIDC authentication configuration
+
+	Notification NotificationConfig // Outbound notification configuration
+	SMTP         SMTPConfig         // Email delivery configuration
+	Log          LogConfig          // Logging configuration
 }
 
 // ServerConfig defines HTTP server listening configuration.
@@ -30,6 +36,8 @@ type TrivyConfig struct {
 	MaxWorkers        int    // Maximum concurrent scan workers (default: 5)
 	ScanRetentionDays int    // Days to retain scan history (default: 90, 0 = forever)
 	EnableDockerScan  bool   // Enable Docker socket access for scanning local images (default: false, requires Docker socket mount)
+	MaxUploadSize     int64  // Maximum size of an uploaded scan target in bytes (default: 512 MiB)
+	UserStorageQuota  int64  // Maximum storage (reports, metadata, uploads) per user in bytes (default: 0, unlimited)
 }
 
 // CORSConfig defines Cross-Origin Resource Sharing policy.
@@ -41,6 +49,24 @@ type CORSConfig struct {
 type StorageConfig struct {
 	ConfigDir  string // Directory for storing configuration files (default: "/configs")
 	ReportsDir string // Directory for storing scan reports and results (default: "/lzcapp/reports")
+	Backend    string // Scan task storage backend: "file" or "sqlite" (default: "file")
+	SQLitePath string // SQLite database file of the sqlite backend (default: "{ReportsDir}/trivy.db")
+
+	ReportBackend string   // Report file storage: "file" (under ReportsDir) or "s3" (default: "file")
+	S3            S3Config // Object storage of the s3 report backend
+}
+
+// S3Config defines the S3-compatible object storage of report files.
+type S3Config struct {
+	Endpoint       string        // Host and optional port of the S3 API (e.g., "minio:9000")
+	Bucket         string        // Bucket storing the reports (created if missing)
+	Region         string        // Bucket region (default: "us-east-1")
+	AccessKey      string        // Access key ID
+	SecretKey      string        // Secret access key
+	UseSSL         bool          // Connect over HTTPS (default: true)
+	Prefix         string        // Optional key prefix of all reports
+	PresignMinSize int64         // Reports of at least this size are downloaded from presigned URLs (0 = never)
+	PresignExpiry  time.Duration // Validity of presigned download URLs
 }
 
 // OIDCConfig defines OIDC authentication configuration.
@@ -50,4 +76,43 @@ type OIDCConfig struct {
 	Issuer       string // OIDC issuer URL
 	RedirectURL  string // OIDC redirect URL after authentication
 	Enabled      bool   // Whether OIDC authentication is enabled
+
+	AdminGroups   []string // OIDC groups granted the admin role
+	ScannerGroups []string // OIDC groups granted the scanner role
+	ViewerGroups  []string // OIDC groups granted the viewer role
+	DefaultRole   string   // Role of users in none of the groups: viewer, scanner, admin or none
+
+	OfflineAccess      bool          // Request the offline_access scope, so sessions can be renewed with a refresh token (default: false)
+	SessionIdleTimeout time.Duration // Sessions end after this time without requests (default: 7 days)
+	SessionMaxAge      time.Duration // Sessions must be renewed with the provider after this time (default: 30 days, 0 = unlimited)
+	SessionSecret      string        // Secret encrypting the OIDC refresh and ID tokens stored with sessions (optional, plaintext if empty)
+
+	PostLogoutRedirectURL string // URL the provider redirects to after RP-initiated logout (optional, must be registered at the provider)
+}
+
+// NotificationConfig defines outbound notification (webhook, email digest) configuration.
+type NotificationConfig struct {
+	PublicURL           string // Externally reachable base URL used for report links (e.g., "https://trivy.example.com", empty = no links)
+	WebhookMaxRetries   int    // Retries of a failed webhook delivery (default: 3)
+	WebhookRetryBackoff int    // Delay before the first retry in seconds, doubled on every retry (default: 5)
+	WebhookTimeout      int    // Timeout of a single webhook request in seconds (default: 10)
+	WebhookAllowPrivate bool   // Allow webhooks to loopback, link-local and private network addresses (default: false)
+	DigestHour          int    // Hour of the day (0-23, server local time) email digests are sent at (default: 8)
+}
+
+// SMTPConfig defines the SMTP server email digests are sent through.
+type SMTPConfig struct {
+	Host     string // SMTP server host (e.g., "smtp.example.com")
+	Port     int    // SMTP server port (default: 587)
+	Username string // SMTP username (optional, PLAIN authentication)
+	Password string // SMTP password
+	From     string // Sender address (e.g., "Trivy <trivy@example.com>")
+	TLS      string // Transport security: starttls (default), tls (implicit TLS) or none
+	Enabled  bool   // Whether email delivery is configured (host and sender set)
+}
+
+// LogConfig defines the output of the structured logger.
+type LogConfig struct {
+	Format string // Output format: logfmt (default) or json
+	Level  string // Minimum level: debug, info (default) or error
 }
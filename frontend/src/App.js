@@ -21,6 +21,8 @@ import {
   Progress,
   Dropdown,
   Tabs,
+  Upload,
+  Switch,
 } from 'antd';
 import {
   BugOutlined,
@@ -33,6 +35,17 @@ import {
   FullscreenExitOutlined,
   ReloadOutlined,
   DeleteOutlined,
+  StopOutlined,
+  RedoOutlined,
+  UploadOutlined,
+  ClockCircleOutlined,
+  PauseCircleOutlined,
+  PlayCircleOutlined,
+  DiffOutlined,
+  UnorderedListOutlined,
+  BellOutlined,
+  SendOutlined,
+  MailOutlined,
 } from '@ant-design/icons';
 import 'antd/dist/reset.css';
 import './App.css';
@@ -79,6 +92,10 @@ const formatDateTime = (dateString) => {
   });
 };
 
+// Scan target types that require an uploaded file
+// ('image-archive' is an image tarball uploaded with targetType 'image')
+const UPLOAD_TARGET_TYPES = ['image-archive', 'fs', 'sbom', 'rootfs', 'vm'];
+
 function AppContent() {
   const { message, modal } = AntApp.useApp();
   const [form] = Form.useForm();
@@ -99,19 +116,51 @@ function AppContent() {
 
   // Scan form state
   const [loading, setLoading] = useState(false);
+  const [uploadFile, setUploadFile] = useState(null);
+  const targetType = Form.useWatch('targetType', form) || 'image';
+  const isUploadTarget = UPLOAD_TARGET_TYPES.includes(targetType);
 
   // Scan history state
   const [scanHistory, setScanHistory] = useState([]);
   const [historyLoading, setHistoryLoading] = useState(false);
+  const [selectedTaskIds, setSelectedTaskIds] = useState([]);
+
+  // Findings state
+  const [findingsTask, setFindingsTask] = useState(null);
+  const [findingsQuery, setFindingsQuery] = useState({ kind: 'vulnerability', severity: [], q: '', page: 1, pageSize: 20 });
+  const [findingsData, setFindingsData] = useState(null);
+  const [findingsLoading, setFindingsLoading] = useState(false);
+
+  // Scan diff state
+  const [diffModalVisible, setDiffModalVisible] = useState(false);
+  const [diffResult, setDiffResult] = useState(null);
+  const [diffLoading, setDiffLoading] = useState(false);
 
   // Queue state
   const [queueStatus, setQueueStatus] = useState(null);
 
+  // Schedule state
+  const [scheduleForm] = Form.useForm();
+  const [schedules, setSchedules] = useState([]);
+  const [schedulesLoading, setSchedulesLoading] = useState(false);
+
+  // Webhook state
+  const [webhookForm] = Form.useForm();
+  const [webhooks, setWebhooks] = useState([]);
+  const [webhooksLoading, setWebhooksLoading] = useState(false);
+
+  // Email digest state
+  const [digestForm] = Form.useForm();
+  const [digestSubscription, setDigestSubscription] = useState(null);
+  const [digestLoading, setDigestLoading] = useState(false);
+
   // Config management state
   const [configList, setConfigList] = useState([]);
   const [selectedConfig, setSelectedConfig] = useState('');
   const [saveConfigModalVisible, setSaveConfigModalVisible] = useState(false);
   const [configNameInput, setConfigNameInput] = useState('');
+  const [policyList, setPolicyList] = useState([]);
+  const [checkBundles, setCheckBundles] = useState([]);
 
   // Docker images state
   const [dockerImagesModalVisible, setDockerImagesModalVisible] = useState(false);
@@ -278,6 +327,78 @@ function AppContent() {
     }
   }, [addDebugLog]);
 
+  // Load schedules
+  const loadSchedules = useCallback(async () => {
+    setSchedulesLoading(true);
+    try {
+      addDebugLog('SCHEDULE', 'Loading schedules');
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/schedules`, {
+        credentials: 'include'
+      });
+
+      if (response.ok) {
+        const data = await response.json();
+        setSchedules(data.schedules || []);
+      } else {
+        const error = await response.json();
+        addDebugLog('ERROR', 'Failed to load schedules:', error);
+      }
+    } catch (error) {
+      addDebugLog('ERROR', 'Load schedules exception:', error.message);
+    } finally {
+      setSchedulesLoading(false);
+    }
+  }, [addDebugLog]);
+
+  // Load webhooks
+  const loadWebhooks = useCallback(async () => {
+    setWebhooksLoading(true);
+    try {
+      addDebugLog('WEBHOOK', 'Loading webhooks');
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/webhooks`, {
+        credentials: 'include'
+      });
+
+      if (response.ok) {
+        const data = await response.json();
+        setWebhooks(data.webhooks || []);
+      } else {
+        const error = await response.json();
+        addDebugLog('ERROR', 'Failed to load webhooks:', error);
+      }
+    } catch (error) {
+      addDebugLog('ERROR', 'Load webhooks exception:', error.message);
+    } finally {
+      setWebhooksLoading(false);
+    }
+  }, [addDebugLog]);
+
+  // Load email digest subscription
+  const loadDigestSubscription = useCallback(async () => {
+    setDigestLoading(true);
+    try {
+      addDebugLog('DIGEST', 'Loading digest subscription');
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/digest`, {
+        credentials: 'include'
+      });
+
+      if (response.ok) {
+        const data = await response.json();
+        setDigestSubscription(data);
+        digestForm.setFieldsValue({ email: data.email, frequency: data.frequency, enabled: data.enabled });
+      } else if (response.status === 404) {
+        setDigestSubscription(null);
+      } else {
+        const error = await response.json();
+        addDebugLog('ERROR', 'Failed to load digest subscription:', error);
+      }
+    } catch (error) {
+      addDebugLog('ERROR', 'Load digest subscription exception:', error.message);
+    } finally {
+      setDigestLoading(false);
+    }
+  }, [addDebugLog, digestForm]);
+
   // Load config list
   const loadConfigList = useCallback(async () => {
     try {
@@ -299,6 +420,78 @@ function AppContent() {
     }
   }, [addDebugLog]);
 
+  // Load policy list
+  const loadPolicyList = useCallback(async () => {
+    try {
+      addDebugLog('POLICY', 'Loading policy list');
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/policies`, {
+        credentials: 'include',
+      });
+
+      if (response.ok) {
+        const data = await response.json();
+        setPolicyList(data.policies || []);
+        addDebugLog('POLICY', 'Policy list loaded:', data.policies);
+      } else {
+        const error = await response.json();
+        addDebugLog('ERROR', 'Failed to load policy list:', error);
+      }
+    } catch (error) {
+      addDebugLog('ERROR', 'Load policy list exception:', error.message);
+    }
+  }, [addDebugLog]);
+
+  // Load custom check bundles
+  const loadCheckBundles = useCallback(async () => {
+    try {
+      addDebugLog('CHECKS', 'Loading check bundles');
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/checks`, {
+        credentials: 'include',
+      });
+
+      if (response.ok) {
+        const data = await response.json();
+        setCheckBundles(data.bundles || []);
+        addDebugLog('CHECKS', 'Check bundles loaded:', data.bundles);
+      } else {
+        const error = await response.json();
+        addDebugLog('ERROR', 'Failed to load check bundles:', error);
+      }
+    } catch (error) {
+      addDebugLog('ERROR', 'Load check bundles exception:', error.message);
+    }
+  }, [addDebugLog]);
+
+  // Upload a new version of a custom check bundle
+  const handleUploadCheckBundle = useCallback(async (file) => {
+    const name = form.getFieldValue('checkBundle')
+      || file.name.replace(/\.(rego|tar\.gz|tgz|tar)$/, '').replace(/[^a-zA-Z0-9._-]/g, '_');
+    addDebugLog('CHECKS', 'Uploading check bundle:', name, file.name);
+
+    try {
+      const formData = new FormData();
+      formData.append('file', file);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/checks/${encodeURIComponent(name)}`, {
+        method: 'POST',
+        credentials: 'include',
+        body: formData,
+      });
+
+      if (response.ok) {
+        const data = await response.json();
+        message.success(`已上传检查 ${name} 版本 v${data.version}`);
+        await loadCheckBundles();
+        form.setFieldsValue({ checkBundle: name });
+      } else {
+        const error = await response.json();
+        message.error(`上传检查失败: ${error.error || '未知错误'}`);
+      }
+    } catch (error) {
+      message.error(`上传检查失败: ${error.message}`);
+    }
+    return false;
+  }, [form, message, addDebugLog, loadCheckBundles]);
+
   // Load config by name
   const loadConfigByName = useCallback(async (name) => {
     try {
@@ -323,6 +516,8 @@ function AppContent() {
           detectionPriority: data.detectionPriority || 'precise',
           pkgTypes: data.pkgTypes || [],
           format: data.format || 'json',
+          policy: data.policy || undefined,
+          checkBundle: data.checkBundle || undefined,
         });
 
         message.success(`已加载配置: ${name}`);
@@ -392,6 +587,8 @@ function AppContent() {
         detectionPriority: values.detectionPriority || 'precise',
         pkgTypes: values.pkgTypes || [],
         format: values.format || 'json',
+        policy: values.policy || '',
+        checkBundle: values.checkBundle || '',
       };
 
       const response = await fetch(`${BACKEND_API_URL}/api/v1/config/${encodeURIComponent(name)}`, {
@@ -533,12 +730,17 @@ function AppContent() {
     if (!authChecking && (!oidcEnabled || isAuthenticated)) {
       loadScanHistory();
       loadQueueStatus();
+      loadSchedules();
+      loadWebhooks();
+      loadDigestSubscription();
+      loadPolicyList();
+      loadCheckBundles();
       // Load config list first, then load last used config
       loadConfigList().then(() => {
         loadLastUsedConfig();
       });
     }
-  }, [authChecking, oidcEnabled, isAuthenticated, loadScanHistory, loadQueueStatus, loadConfigList, loadLastUsedConfig]);
+  }, [authChecking, oidcEnabled, isAuthenticated, loadScanHistory, loadQueueStatus, loadSchedules, loadWebhooks, loadDigestSubscription, loadPolicyList, loadCheckBundles, loadConfigList, loadLastUsedConfig]);
 
   // Auto-scroll logs
   useEffect(() => {
@@ -597,7 +799,7 @@ function AppContent() {
           const data = await response.json();
           setTaskStatus(data);
           addDebugLog('LOG_STREAM', 'Status update:', data.status);
-          if (data.status === 'completed' || data.status === 'failed') {
+          if (data.status === 'completed' || data.status === 'failed' || data.status === 'cancelled') {
             addDebugLog('LOG_STREAM', 'Task finished, stopping polling');
             clearInterval(statusIntervalRef.current);
             statusIntervalRef.current = null;
@@ -629,17 +831,40 @@ function AppContent() {
     setLoading(true);
 
     try {
-      const url = `${BACKEND_API_URL}/api/v1/scan`;
-      addDebugLog('SCAN', 'Sending scan request to:', url);
+      let response;
+      if (UPLOAD_TARGET_TYPES.includes(values.targetType)) {
+        if (!uploadFile) {
+          message.error('请选择要上传的文件');
+          return;
+        }
 
-      const response = await fetch(url, {
-        method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
-        credentials: 'include',
-        body: JSON.stringify(values),
-      });
+        const url = `${BACKEND_API_URL}/api/v1/scan/upload`;
+        addDebugLog('SCAN', 'Uploading scan target to:', url);
+
+        const { image, username, password, targetType: uploadType, ...options } = values;
+        const formData = new FormData();
+        formData.append('file', uploadFile);
+        formData.append('targetType', uploadType === 'image-archive' ? 'image' : uploadType);
+        formData.append('options', JSON.stringify(options));
+
+        response = await fetch(url, {
+          method: 'POST',
+          credentials: 'include',
+          body: formData,
+        });
+      } else {
+        const url = `${BACKEND_API_URL}/api/v1/scan`;
+        addDebugLog('SCAN', 'Sending scan request to:', url);
+
+        response = await fetch(url, {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+          },
+          credentials: 'include',
+          body: JSON.stringify(values),
+        });
+      }
 
       addDebugLog('SCAN', 'Scan response received:', { status: response.status, ok: response.ok });
 
@@ -672,10 +897,16 @@ function AppContent() {
   // Handle logout
   const handleLogout = async () => {
     try {
-      await fetch(`${BACKEND_API_URL}/api/v1/auth/logout`, {
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/auth/logout`, {
         method: 'POST',
         credentials: 'include'
       });
+      const data = await response.json().catch(() => ({}));
+      if (data.logoutUrl) {
+        // End the session at the OIDC provider as well
+        window.location.href = data.logoutUrl;
+        return;
+      }
       setIsAuthenticated(false);
       setUserInfo(null);
       message.success('已退出登录');
@@ -710,6 +941,13 @@ function AppContent() {
     window.open(url, '_blank');
   };
 
+  // Export scan history (csv, json or xlsx)
+  const handleExportHistory = (format = 'csv') => {
+    addDebugLog('EXPORT', 'Exporting scan history:', format);
+    const url = `${BACKEND_API_URL}/api/v1/scan/export?format=${format}`;
+    window.open(url, '_blank');
+  };
+
   // Delete scan task
   const handleDeleteTask = async (taskId) => {
     console.log('handleDeleteTask called with taskId:', taskId);
@@ -739,164 +977,697 @@ function AppContent() {
     }
   };
 
-  // Delete all scan tasks
-  const handleDeleteAllTasks = async () => {
+  // Cancel queued or running scan task
+  const handleCancelTask = async (taskId) => {
     try {
-      addDebugLog('DELETE_ALL', 'Deleting all tasks');
-      const response = await fetch(`${BACKEND_API_URL}/api/v1/scan`, {
+      addDebugLog('CANCEL', 'Cancelling task:', taskId);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/scan/${taskId}/cancel`, {
         method: 'DELETE',
         credentials: 'include',
       });
 
       if (response.ok) {
-        message.success('已清空所有扫描历史');
-        addDebugLog('DELETE_ALL', 'All tasks deleted successfully');
+        message.success('已取消扫描');
+        addDebugLog('CANCEL', 'Task cancelled successfully:', taskId);
         loadScanHistory(); // Reload history
       } else {
         const error = await response.json();
-        message.error(`清空失败: ${error.error || '未知错误'}`);
-        addDebugLog('ERROR', 'Delete all failed:', error);
+        message.error(`取消失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Cancel failed:', error);
       }
     } catch (error) {
-      message.error(`清空失败: ${error.message}`);
-      addDebugLog('ERROR', 'Delete all exception:', error.message);
+      message.error(`取消失败: ${error.message}`);
+      addDebugLog('ERROR', 'Cancel exception:', error.message);
     }
   };
 
-  // Show loading while checking auth
-  if (authChecking) {
-    return (
-      <div className="App">
-        <div className="container">
-          <Card style={{ textAlign: 'center', padding: '50px' }}>
-            <Title level={3}>正在检查登录状态...</Title>
-          </Card>
-        </div>
-      </div>
-    );
-  }
+  // Rescan a task with its original scan parameters
+  const handleRescanTask = async (taskId) => {
+    try {
+      addDebugLog('RESCAN', 'Rescanning task:', taskId);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/scan/${taskId}/rescan`, {
+        method: 'POST',
+        credentials: 'include',
+      });
 
-  // Show login page if OIDC is enabled and user is not authenticated
-  if (oidcEnabled && !isAuthenticated) {
-    return (
-      <div className="App">
-        <div className="container">
-          <Title level={2}>Trivy Web UI - 容器镜像安全扫描</Title>
-          <Card style={{ textAlign: 'center', padding: '50px' }}>
-            <Title level={3}>请登录后使用</Title>
-            <Text type="secondary" style={{ display: 'block', marginBottom: '24px', fontSize: '16px' }}>
-              登录后您可以扫描镜像并查看漏洞报告
-            </Text>
-            <Button type="primary" size="large" onClick={handleLogin}>
-              登录
-            </Button>
-          </Card>
-          <div className="footer">
-            <Text type="secondary">
-              Trivy Web UI · v{APP_VERSION}
-              {GIT_COMMIT !== 'dev' && GIT_COMMIT_FULL !== 'development' && (
-                <>
-                  {' · '}
-                  <a
-                    href={`https://github.com/lazycatapps/trivy/commit/${GIT_COMMIT_FULL}`}
-                    target="_blank"
-                    rel="noopener noreferrer"
-                    title={`Commit: ${GIT_COMMIT_FULL}`}
-                  >
-                    {GIT_COMMIT}
-                  </a>
-                </>
-              )}
-              {' · '}
-              Copyright © {new Date().getFullYear()} Lazycat Apps
-              {' · '}
-              <a href="https://github.com/lazycatapps/trivy" target="_blank" rel="noopener noreferrer">
-                GitHub
-              </a>
-            </Text>
-          </div>
-        </div>
-      </div>
-    );
-  }
+      if (response.ok) {
+        const data = await response.json();
+        message.success('已重新提交扫描');
+        addDebugLog('RESCAN', 'Rescan task created:', data.id);
+        loadScanHistory(); // Reload history
+      } else {
+        const error = await response.json();
+        message.error(`重新扫描失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Rescan failed:', error);
+      }
+    } catch (error) {
+      message.error(`重新扫描失败: ${error.message}`);
+      addDebugLog('ERROR', 'Rescan exception:', error.message);
+    }
+  };
 
-  // Main application UI
-  return (
-    <div className="App">
-      <div className="container">
-        {/* Header */}
-        <div style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: '16px' }}>
-          <Title level={2} style={{ margin: 0 }}>Trivy Web UI - 容器镜像安全扫描</Title>
-          {oidcEnabled && (
-            <Space>
-              {userInfo && (
-                <Text type="secondary">
-                  {userInfo.email}
-                  {userInfo.is_admin && <span style={{ color: '#1890ff', marginLeft: '8px' }}>(管理员)</span>}
-                </Text>
-              )}
-              <Button onClick={handleLogout}>退出登录</Button>
-            </Space>
-          )}
-        </div>
+  // Load a page of structured findings
+  const loadFindings = useCallback(async (taskId, query) => {
+    setFindingsLoading(true);
+    try {
+      const params = new URLSearchParams({
+        kind: query.kind,
+        page: query.page,
+        pageSize: query.pageSize,
+      });
+      if (query.severity.length > 0) params.set('severity', query.severity.join(','));
+      if (query.q) params.set('q', query.q);
 
-        {/* Queue Status */}
-        {queueStatus && queueStatus.queueLength > 0 && (
-          <Alert
-            message={`队列中有 ${queueStatus.queueLength} 个任务等待扫描`}
-            description={`预计平均等待时间: ${Math.round(queueStatus.averageWaitTime || 0)} 秒`}
-            type="info"
-            showIcon
-            style={{ marginBottom: '16px' }}
-          />
-        )}
+      addDebugLog('FINDINGS', 'Loading findings:', taskId, params.toString());
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/scan/${taskId}/findings?${params}`, {
+        credentials: 'include',
+      });
 
-        {/* Scan Form */}
-        <Card title={<><ScanOutlined /> 扫描配置</>} style={{ marginBottom: '24px' }}>
-          <Form
-            form={form}
-            layout="vertical"
-            onFinish={onFinish}
-            initialValues={{
-              image: '',
-              tlsVerify: false,
-              severity: [],
-              ignoreUnfixed: false,
-              scanners: ['vuln'],
-              detectionPriority: 'precise',
-              pkgTypes: [],
-              format: 'json',
-            }}
-          >
-            {/* Image Information */}
-            <Form.Item
-              label="镜像地址"
-              name="image"
-              rules={[{ required: true, message: '请输入镜像地址' }]}
-            >
-              <Input
-                placeholder="例如: docker.io/library/nginx:latest"
-                addonAfter={
-                  systemConfig.enableDockerScan && (
-                    <Button
-                      type="text"
-                      icon={<ScanOutlined />}
-                      onClick={openDockerModal}
-                      loading={dockerImagesLoading || dockerContainersLoading}
-                      title="从宿主机选择镜像或容器"
-                      style={{ margin: -5, padding: '0 8px' }}
-                    >
-                      从宿主机选择
-                    </Button>
-                  )
-                }
-              />
-            </Form.Item>
+      if (response.ok) {
+        const data = await response.json();
+        setFindingsData(data);
+      } else {
+        const error = await response.json();
+        message.error(`加载扫描结果失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Load findings failed:', error);
+      }
+    } catch (error) {
+      message.error(`加载扫描结果失败: ${error.message}`);
+      addDebugLog('ERROR', 'Load findings exception:', error.message);
+    } finally {
+      setFindingsLoading(false);
+    }
+  }, [message, addDebugLog]);
 
-            <Space direction="horizontal" style={{ width: '100%' }} size="large">
-              <Form.Item
-                label="仓库用户名"
-                name="username"
+  // Reload findings when the task or the filters change
+  useEffect(() => {
+    if (findingsTask) {
+      loadFindings(findingsTask.id, findingsQuery);
+    }
+  }, [findingsTask, findingsQuery, loadFindings]);
+
+  // Open the findings viewer of a task
+  const handleViewFindings = (task) => {
+    setFindingsData(null);
+    setFindingsQuery({ kind: 'vulnerability', severity: [], q: '', page: 1, pageSize: 20 });
+    setFindingsTask(task);
+  };
+
+  // Accept the risk of a vulnerability with a suppression rule for the package (all versions)
+  const handleSuppressFinding = (record) => {
+    const vuln = record.vulnerability;
+    const purl = vuln.pkgIdentifier && vuln.pkgIdentifier.purl;
+    const pkg = purl ? purl.split(/[?#]/)[0].replace(/@[^/]*$/, '') : '';
+    let justification = '';
+    let expiresAt = '';
+
+    modal.confirm({
+      title: `忽略 ${vuln.vulnerabilityId}`,
+      content: (
+        <Space direction="vertical" style={{ width: '100%' }}>
+          <Text type="secondary">{pkg ? `仅忽略软件包 ${pkg}` : '忽略所有软件包中的该漏洞'}，下次扫描生效</Text>
+          <Input.TextArea
+            rows={3}
+            maxLength={1024}
+            placeholder="接受风险的理由（必填）"
+            onChange={(e) => { justification = e.target.value; }}
+          />
+          <Input
+            type="date"
+            addonBefore="过期时间"
+            onChange={(e) => { expiresAt = e.target.value; }}
+          />
+        </Space>
+      ),
+      okText: '忽略',
+      cancelText: '取消',
+      onOk: async () => {
+        if (!justification.trim()) {
+          message.error('请输入忽略理由');
+          return Promise.reject();
+        }
+        addDebugLog('SUPPRESS', 'Adding suppression:', vuln.vulnerabilityId, pkg);
+        try {
+          const response = await fetch(`${BACKEND_API_URL}/api/v1/suppressions`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            credentials: 'include',
+            body: JSON.stringify({
+              vulnerabilityId: vuln.vulnerabilityId,
+              package: pkg,
+              expiresAt: expiresAt ? new Date(`${expiresAt}T00:00:00`).toISOString() : undefined,
+              justification: justification.trim(),
+            }),
+          });
+          if (response.ok) {
+            message.success(`已忽略 ${vuln.vulnerabilityId}，下次扫描生效`);
+          } else {
+            const error = await response.json();
+            message.error(`添加忽略规则失败: ${error.error || '未知错误'}`);
+            return Promise.reject();
+          }
+        } catch (error) {
+          message.error(`添加忽略规则失败: ${error.message}`);
+          return Promise.reject();
+        }
+      },
+    });
+  };
+
+  // Compare the two selected tasks (the older one is the base)
+  const handleCompareTasks = async () => {
+    const [base, target] = scanHistory
+      .filter(task => selectedTaskIds.includes(task.id))
+      .sort((a, b) => new Date(a.startTime) - new Date(b.startTime));
+    if (!base || !target) return;
+
+    setDiffResult(null);
+    setDiffModalVisible(true);
+    setDiffLoading(true);
+    try {
+      addDebugLog('DIFF', 'Comparing tasks:', base.id, target.id);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/scan/diff?base=${base.id}&target=${target.id}`, {
+        credentials: 'include',
+      });
+
+      if (response.ok) {
+        const data = await response.json();
+        setDiffResult(data);
+      } else {
+        const error = await response.json();
+        message.error(`对比失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Compare tasks failed:', error);
+        setDiffModalVisible(false);
+      }
+    } catch (error) {
+      message.error(`对比失败: ${error.message}`);
+      addDebugLog('ERROR', 'Compare tasks exception:', error.message);
+      setDiffModalVisible(false);
+    } finally {
+      setDiffLoading(false);
+    }
+  };
+
+  // Create a scheduled scan
+  const handleCreateSchedule = async (values) => {
+    try {
+      addDebugLog('SCHEDULE', 'Creating schedule:', values);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/schedules`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        credentials: 'include',
+        body: JSON.stringify({
+          name: values.name || '',
+          image: values.image || '',
+          configName: values.configName || '',
+          cron: values.cron,
+        }),
+      });
+
+      if (response.ok) {
+        message.success('定时扫描已创建');
+        scheduleForm.resetFields();
+        loadSchedules();
+      } else {
+        const error = await response.json();
+        message.error(`创建定时扫描失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Create schedule failed:', error);
+      }
+    } catch (error) {
+      message.error(`创建定时扫描失败: ${error.message}`);
+      addDebugLog('ERROR', 'Create schedule exception:', error.message);
+    }
+  };
+
+  // Pause or resume a scheduled scan
+  const handleToggleSchedule = async (schedule) => {
+    const action = schedule.paused ? 'resume' : 'pause';
+    try {
+      addDebugLog('SCHEDULE', `Schedule ${action}:`, schedule.id);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/schedules/${schedule.id}/${action}`, {
+        method: 'POST',
+        credentials: 'include',
+      });
+
+      if (response.ok) {
+        message.success(schedule.paused ? '定时扫描已恢复' : '定时扫描已暂停');
+        loadSchedules();
+      } else {
+        const error = await response.json();
+        message.error(`操作失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Toggle schedule failed:', error);
+      }
+    } catch (error) {
+      message.error(`操作失败: ${error.message}`);
+      addDebugLog('ERROR', 'Toggle schedule exception:', error.message);
+    }
+  };
+
+  // Delete a scheduled scan
+  const handleDeleteSchedule = async (scheduleId) => {
+    try {
+      addDebugLog('SCHEDULE', 'Deleting schedule:', scheduleId);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/schedules/${scheduleId}`, {
+        method: 'DELETE',
+        credentials: 'include',
+      });
+
+      if (response.ok) {
+        message.success('定时扫描已删除');
+        loadSchedules();
+      } else {
+        const error = await response.json();
+        message.error(`删除失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Delete schedule failed:', error);
+      }
+    } catch (error) {
+      message.error(`删除失败: ${error.message}`);
+      addDebugLog('ERROR', 'Delete schedule exception:', error.message);
+    }
+  };
+
+  // Create a webhook
+  const handleCreateWebhook = async (values) => {
+    try {
+      addDebugLog('WEBHOOK', 'Creating webhook:', values.url);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/webhooks`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        credentials: 'include',
+        body: JSON.stringify({
+          name: values.name || '',
+          url: values.url,
+          format: values.format || 'generic',
+          secret: values.secret || '',
+          events: values.events || [],
+          threshold: (values.events || []).includes('threshold') ? values.threshold || '' : '',
+        }),
+      });
+
+      if (response.ok) {
+        message.success('Webhook 已创建');
+        webhookForm.resetFields();
+        loadWebhooks();
+      } else {
+        const error = await response.json();
+        message.error(`创建 Webhook 失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Create webhook failed:', error);
+      }
+    } catch (error) {
+      message.error(`创建 Webhook 失败: ${error.message}`);
+      addDebugLog('ERROR', 'Create webhook exception:', error.message);
+    }
+  };
+
+  // Pause or resume a webhook
+  const handleToggleWebhook = async (webhook) => {
+    const action = webhook.paused ? 'resume' : 'pause';
+    try {
+      addDebugLog('WEBHOOK', `Webhook ${action}:`, webhook.id);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/webhooks/${webhook.id}/${action}`, {
+        method: 'POST',
+        credentials: 'include',
+      });
+
+      if (response.ok) {
+        message.success(webhook.paused ? 'Webhook 已恢复' : 'Webhook 已暂停');
+        loadWebhooks();
+      } else {
+        const error = await response.json();
+        message.error(`操作失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Toggle webhook failed:', error);
+      }
+    } catch (error) {
+      message.error(`操作失败: ${error.message}`);
+      addDebugLog('ERROR', 'Toggle webhook exception:', error.message);
+    }
+  };
+
+  // Send a test event to a webhook
+  const handleTestWebhook = async (webhookId) => {
+    try {
+      addDebugLog('WEBHOOK', 'Testing webhook:', webhookId);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/webhooks/${webhookId}/test`, {
+        method: 'POST',
+        credentials: 'include',
+      });
+
+      const data = await response.json();
+      if (response.ok && data.success) {
+        message.success(`测试通知已送达 (HTTP ${data.statusCode})`);
+      } else if (response.ok) {
+        message.error(`测试通知发送失败: ${data.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Test webhook delivery failed:', data);
+      } else {
+        message.error(`测试失败: ${data.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Test webhook failed:', data);
+      }
+    } catch (error) {
+      message.error(`测试失败: ${error.message}`);
+      addDebugLog('ERROR', 'Test webhook exception:', error.message);
+    }
+  };
+
+  // Show the recent deliveries of a webhook
+  const handleShowDeliveries = async (webhook) => {
+    try {
+      addDebugLog('WEBHOOK', 'Loading deliveries:', webhook.id);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/webhooks/${webhook.id}/deliveries`, {
+        credentials: 'include',
+      });
+
+      if (!response.ok) {
+        const error = await response.json();
+        message.error(`加载投递记录失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Load deliveries failed:', error);
+        return;
+      }
+
+      const data = await response.json();
+      modal.info({
+        title: `投递记录 - ${webhook.name || webhook.url}`,
+        width: 800,
+        content: (
+          <Table
+            dataSource={data.deliveries || []}
+            rowKey="id"
+            size="small"
+            pagination={{ pageSize: 10 }}
+            locale={{ emptyText: '暂无投递记录' }}
+            columns={[
+              { title: '时间', dataIndex: 'createdAt', key: 'createdAt', render: (time) => formatDateTime(time) },
+              { title: '事件', dataIndex: 'event', key: 'event', render: (event) => <Tag>{event}</Tag> },
+              {
+                title: '结果',
+                key: 'result',
+                render: (_, record) => (
+                  <Space direction="vertical" size={0}>
+                    {record.success ? <Tag color="green">成功</Tag> : <Tag color="red">失败</Tag>}
+                    {record.error && <Text type="danger">{record.error}</Text>}
+                  </Space>
+                ),
+              },
+              { title: '状态码', dataIndex: 'statusCode', key: 'statusCode', render: (code) => code || '-' },
+              { title: '尝试次数', dataIndex: 'attempts', key: 'attempts' },
+            ]}
+          />
+        ),
+      });
+    } catch (error) {
+      message.error(`加载投递记录失败: ${error.message}`);
+      addDebugLog('ERROR', 'Load deliveries exception:', error.message);
+    }
+  };
+
+  // Delete a webhook
+  const handleDeleteWebhook = async (webhookId) => {
+    try {
+      addDebugLog('WEBHOOK', 'Deleting webhook:', webhookId);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/webhooks/${webhookId}`, {
+        method: 'DELETE',
+        credentials: 'include',
+      });
+
+      if (response.ok) {
+        message.success('Webhook 已删除');
+        loadWebhooks();
+      } else {
+        const error = await response.json();
+        message.error(`删除失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Delete webhook failed:', error);
+      }
+    } catch (error) {
+      message.error(`删除失败: ${error.message}`);
+      addDebugLog('ERROR', 'Delete webhook exception:', error.message);
+    }
+  };
+
+  // Subscribe to or update the email digest
+  const handleSaveDigest = async (values) => {
+    try {
+      addDebugLog('DIGEST', 'Saving digest subscription:', values);
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/digest`, {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        credentials: 'include',
+        body: JSON.stringify({
+          email: values.email,
+          frequency: values.frequency || 'daily',
+          enabled: values.enabled !== false,
+        }),
+      });
+
+      if (response.ok) {
+        message.success('邮件摘要设置已保存');
+        loadDigestSubscription();
+      } else {
+        const error = await response.json();
+        message.error(`保存失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Save digest subscription failed:', error);
+      }
+    } catch (error) {
+      message.error(`保存失败: ${error.message}`);
+      addDebugLog('ERROR', 'Save digest subscription exception:', error.message);
+    }
+  };
+
+  // Send the digest of the last period now
+  const handleSendDigest = async () => {
+    try {
+      addDebugLog('DIGEST', 'Sending digest now');
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/digest/send`, {
+        method: 'POST',
+        credentials: 'include',
+      });
+
+      if (response.ok) {
+        const data = await response.json();
+        message.success(`摘要已发送（${data.images.length} 个镜像，${data.failedScans.length} 个失败扫描）`);
+      } else {
+        const error = await response.json();
+        message.error(`发送失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Send digest failed:', error);
+      }
+    } catch (error) {
+      message.error(`发送失败: ${error.message}`);
+      addDebugLog('ERROR', 'Send digest exception:', error.message);
+    }
+  };
+
+  // Unsubscribe from the email digest
+  const handleDeleteDigest = async () => {
+    try {
+      addDebugLog('DIGEST', 'Deleting digest subscription');
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/digest`, {
+        method: 'DELETE',
+        credentials: 'include',
+      });
+
+      if (response.ok) {
+        message.success('已取消邮件摘要订阅');
+        digestForm.resetFields();
+        loadDigestSubscription();
+      } else {
+        const error = await response.json();
+        message.error(`取消订阅失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Delete digest subscription failed:', error);
+      }
+    } catch (error) {
+      message.error(`取消订阅失败: ${error.message}`);
+      addDebugLog('ERROR', 'Delete digest subscription exception:', error.message);
+    }
+  };
+
+  // Delete all scan tasks
+  const handleDeleteAllTasks = async () => {
+    try {
+      addDebugLog('DELETE_ALL', 'Deleting all tasks');
+      const response = await fetch(`${BACKEND_API_URL}/api/v1/scan`, {
+        method: 'DELETE',
+        credentials: 'include',
+      });
+
+      if (response.ok) {
+        message.success('已清空所有扫描历史');
+        addDebugLog('DELETE_ALL', 'All tasks deleted successfully');
+        loadScanHistory(); // Reload history
+      } else {
+        const error = await response.json();
+        message.error(`清空失败: ${error.error || '未知错误'}`);
+        addDebugLog('ERROR', 'Delete all failed:', error);
+      }
+    } catch (error) {
+      message.error(`清空失败: ${error.message}`);
+      addDebugLog('ERROR', 'Delete all exception:', error.message);
+    }
+  };
+
+  // Show loading while checking auth
+  if (authChecking) {
+    return (
+      <div className="App">
+        <div className="container">
+          <Card style={{ textAlign: 'center', padding: '50px' }}>
+            <Title level={3}>正在检查登录状态...</Title>
+          </Card>
+        </div>
+      </div>
+    );
+  }
+
+  // Show login page if OIDC is enabled and user is not authenticated
+  if (oidcEnabled && !isAuthenticated) {
+    return (
+      <div className="App">
+        <div className="container">
+          <Title level={2}>Trivy Web UI - 容器镜像安全扫描</Title>
+          <Card style={{ textAlign: 'center', padding: '50px' }}>
+            <Title level={3}>请登录后使用</Title>
+            <Text type="secondary" style={{ display: 'block', marginBottom: '24px', fontSize: '16px' }}>
+              登录后您可以扫描镜像并查看漏洞报告
+            </Text>
+            <Button type="primary" size="large" onClick={handleLogin}>
+              登录
+            </Button>
+          </Card>
+          <div className="footer">
+            <Text type="secondary">
+              Trivy Web UI · v{APP_VERSION}
+              {GIT_COMMIT !== 'dev' && GIT_COMMIT_FULL !== 'development' && (
+                <>
+                  {' · '}
+                  <a
+                    href={`https://github.com/lazycatapps/trivy/commit/${GIT_COMMIT_FULL}`}
+                    target="_blank"
+                    rel="noopener noreferrer"
+                    title={`Commit: ${GIT_COMMIT_FULL}`}
+                  >
+                    {GIT_COMMIT}
+                  </a>
+                </>
+              )}
+              {' · '}
+              Copyright © {new Date().getFullYear()} Lazycat Apps
+              {' · '}
+              <a href="https://github.com/lazycatapps/trivy" target="_blank" rel="noopener noreferrer">
+                GitHub
+              </a>
+            </Text>
+          </div>
+        </div>
+      </div>
+    );
+  }
+
+  // Main application UI
+  return (
+    <div className="App">
+      <div className="container">
+        {/* Header */}
+        <div style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: '16px' }}>
+          <Title level={2} style={{ margin: 0 }}>Trivy Web UI - 容器镜像安全扫描</Title>
+          {oidcEnabled && (
+            <Space>
+              {userInfo && (
+                <Text type="secondary">
+                  {userInfo.email}
+                  {userInfo.is_admin && <span style={{ color: '#1890ff', marginLeft: '8px' }}>(管理员)</span>}
+                </Text>
+              )}
+              <Button onClick={handleLogout}>退出登录</Button>
+            </Space>
+          )}
+        </div>
+
+        {/* Queue Status */}
+        {queueStatus && queueStatus.queueLength > 0 && (
+          <Alert
+            message={`队列中有 ${queueStatus.queueLength} 个任务等待扫描`}
+            description={`预计平均等待时间: ${Math.round(queueStatus.averageWaitTime || 0)} 秒`}
+            type="info"
+            showIcon
+            style={{ marginBottom: '16px' }}
+          />
+        )}
+
+        {/* Scan Form */}
+        <Card title={<><ScanOutlined /> 扫描配置</>} style={{ marginBottom: '24px' }}>
+          <Form
+            form={form}
+            layout="vertical"
+            onFinish={onFinish}
+            initialValues={{
+              targetType: 'image',
+              image: '',
+              tlsVerify: false,
+              severity: [],
+              ignoreUnfixed: false,
+              scanners: ['vuln'],
+              detectionPriority: 'precise',
+              pkgTypes: [],
+              format: 'json',
+            }}
+          >
+            {/* Scan Target */}
+            <Form.Item label="扫描目标" name="targetType">
+              <Select
+                options={[
+                  { label: '容器镜像', value: 'image' },
+                  { label: '镜像归档 (docker save)', value: 'image-archive' },
+                  { label: 'Git 仓库', value: 'repo' },
+                  { label: '文件系统 (tar 归档)', value: 'fs' },
+                  { label: '根文件系统 (tar 归档)', value: 'rootfs' },
+                  { label: 'SBOM 文件', value: 'sbom' },
+                  { label: '虚拟机镜像', value: 'vm' },
+                ]}
+              />
+            </Form.Item>
+
+            {/* Image Information */}
+            {isUploadTarget ? (
+              <Form.Item label="上传文件" required>
+                <Upload
+                  maxCount={1}
+                  beforeUpload={(file) => {
+                    setUploadFile(file);
+                    return false;
+                  }}
+                  onRemove={() => setUploadFile(null)}
+                  fileList={uploadFile ? [uploadFile] : []}
+                >
+                  <Button icon={<UploadOutlined />}>选择文件</Button>
+                </Upload>
+              </Form.Item>
+            ) : (
+              <Form.Item
+                label={targetType === 'repo' ? '仓库地址' : '镜像地址'}
+                name="image"
+                rules={[{ required: true, message: targetType === 'repo' ? '请输入仓库地址' : '请输入镜像地址' }]}
+              >
+                <Input
+                  placeholder={targetType === 'repo' ? '例如: https://github.com/org/repo' : '例如: docker.io/library/nginx:latest'}
+                  addonAfter={
+                    targetType === 'image' && systemConfig.enableDockerScan && (
+                      <Button
+                        type="text"
+                        icon={<ScanOutlined />}
+                        onClick={openDockerModal}
+                        loading={dockerImagesLoading || dockerContainersLoading}
+                        title="从宿主机选择镜像或容器"
+                        style={{ margin: -5, padding: '0 8px' }}
+                      >
+                        从宿主机选择
+                      </Button>
+                    )
+                  }
+                />
+              </Form.Item>
+            )}
+
+            <Space direction="horizontal" style={{ width: '100%' }} size="large">
+              <Form.Item
+                label="仓库用户名"
+                name="username"
                 style={{ flex: 1, minWidth: 200 }}
               >
                 <Input placeholder="选填（私有仓库需要）" />
@@ -967,6 +1738,37 @@ function AppContent() {
                         </Select>
                       </Form.Item>
 
+                      <Form.Item noStyle shouldUpdate={(prev, cur) => prev.scanners !== cur.scanners}>
+                        {({ getFieldValue }) => (getFieldValue('scanners') || []).includes('misconfig') && (
+                          <Form.Item
+                            label="自定义检查"
+                            extra="上传 .rego 文件或 tar 归档，配置错误扫描时与内置检查一起执行"
+                          >
+                            <Space.Compact style={{ width: '100%' }}>
+                              <Form.Item name="checkBundle" noStyle>
+                                <Select
+                                  placeholder="不使用自定义检查"
+                                  allowClear
+                                >
+                                  {checkBundles.map(bundle => (
+                                    <Option key={bundle.name} value={bundle.name}>
+                                      {bundle.name} (v{bundle.versions[bundle.versions.length - 1].version})
+                                    </Option>
+                                  ))}
+                                </Select>
+                              </Form.Item>
+                              <Upload
+                                accept=".rego,.tar,.tar.gz,.tgz"
+                                showUploadList={false}
+                                beforeUpload={handleUploadCheckBundle}
+                              >
+                                <Button icon={<UploadOutlined />}>上传新版本</Button>
+                              </Upload>
+                            </Space.Compact>
+                          </Form.Item>
+                        )}
+                      </Form.Item>
+
                       <Form.Item
                         label="检测优先级"
                         name="detectionPriority"
@@ -1003,6 +1805,21 @@ function AppContent() {
                           <Option value="spdx">SPDX SBOM</Option>
                         </Select>
                       </Form.Item>
+
+                      <Form.Item
+                        label="策略门禁"
+                        name="policy"
+                        extra="扫描完成后按所选策略给出通过/失败结论（仅 JSON 格式）"
+                      >
+                        <Select
+                          placeholder="不使用策略"
+                          allowClear
+                        >
+                          {policyList.map(name => (
+                            <Option key={name} value={name}>{name}</Option>
+                          ))}
+                        </Select>
+                      </Form.Item>
                     </Space>
                   ),
                 },
@@ -1082,34 +1899,388 @@ function AppContent() {
           </Form>
         </Card>
 
+        {/* Scheduled Scans */}
+        <Card
+          title={<><ClockCircleOutlined /> 定时扫描</>}
+          loading={schedulesLoading}
+          extra={
+            <Button size="small" icon={<ReloadOutlined />} onClick={loadSchedules}>
+              刷新
+            </Button>
+          }
+          style={{ marginBottom: '24px' }}
+        >
+          <Form form={scheduleForm} layout="inline" onFinish={handleCreateSchedule} style={{ marginBottom: '16px', rowGap: '8px' }}>
+            <Form.Item name="name">
+              <Input placeholder="名称（可选）" style={{ width: 140 }} />
+            </Form.Item>
+            <Form.Item name="image">
+              <Input placeholder="镜像（可选，覆盖配置）" style={{ width: 240 }} />
+            </Form.Item>
+            <Form.Item name="configName">
+              <Select placeholder="已保存配置（可选）" allowClear style={{ width: 180 }}>
+                {configList.map(name => (
+                  <Select.Option key={name} value={name}>{name}</Select.Option>
+                ))}
+              </Select>
+            </Form.Item>
+            <Form.Item
+              name="cron"
+              rules={[{ required: true, message: '请输入 Cron 表达式' }]}
+              tooltip="标准 5 段 Cron 表达式，例如 0 2 * * *，也支持 @daily、@every 6h"
+            >
+              <Input placeholder="Cron，例如 0 2 * * *" style={{ width: 160 }} />
+            </Form.Item>
+            <Form.Item>
+              <Button type="primary" htmlType="submit" icon={<ClockCircleOutlined />}>
+                添加
+              </Button>
+            </Form.Item>
+          </Form>
+
+          <Table
+            dataSource={schedules}
+            rowKey="id"
+            size="small"
+            pagination={false}
+            locale={{ emptyText: '暂无定时扫描' }}
+            columns={[
+              {
+                title: '名称',
+                dataIndex: 'name',
+                key: 'name',
+                render: (name) => name || '-',
+              },
+              {
+                title: '目标',
+                key: 'target',
+                render: (_, record) => (
+                  <Space direction="vertical" size={0}>
+                    {record.image && <Text code>{record.image}</Text>}
+                    {record.configName && <Text type="secondary">配置: {record.configName}</Text>}
+                  </Space>
+                ),
+              },
+              {
+                title: 'Cron',
+                dataIndex: 'cron',
+                key: 'cron',
+                render: (cron) => <Text code>{cron}</Text>,
+              },
+              {
+                title: '状态',
+                key: 'status',
+                render: (_, record) => (
+                  record.paused ? <Tag color="default">已暂停</Tag> : <Tag color="green">运行中</Tag>
+                ),
+              },
+              {
+                title: '上次执行',
+                key: 'lastRunAt',
+                render: (_, record) => (
+                  <Space direction="vertical" size={0}>
+                    <span>{formatDateTime(record.lastRunAt)}</span>
+                    {record.lastError && <Text type="danger">{record.lastError}</Text>}
+                  </Space>
+                ),
+              },
+              {
+                title: '下次执行',
+                dataIndex: 'nextRunAt',
+                key: 'nextRunAt',
+                render: (time) => formatDateTime(time),
+              },
+              {
+                title: '操作',
+                key: 'actions',
+                render: (_, record) => (
+                  <Space>
+                    <Button
+                      size="small"
+                      icon={record.paused ? <PlayCircleOutlined /> : <PauseCircleOutlined />}
+                      onClick={() => handleToggleSchedule(record)}
+                    >
+                      {record.paused ? '恢复' : '暂停'}
+                    </Button>
+                    <Button
+                      size="small"
+                      danger
+                      icon={<DeleteOutlined />}
+                      onClick={() => {
+                        modal.confirm({
+                          title: '确认删除',
+                          content: '确定要删除这个定时扫描吗？已执行的扫描记录会保留。',
+                          okText: '删除',
+                          okType: 'danger',
+                          cancelText: '取消',
+                          onOk: () => handleDeleteSchedule(record.id),
+                        });
+                      }}
+                    >
+                      删除
+                    </Button>
+                  </Space>
+                ),
+              },
+            ]}
+          />
+        </Card>
+
+        {/* Webhooks */}
+        <Card
+          title={<><BellOutlined /> Webhook 通知</>}
+          loading={webhooksLoading}
+          extra={
+            <Button size="small" icon={<ReloadOutlined />} onClick={loadWebhooks}>
+              刷新
+            </Button>
+          }
+          style={{ marginBottom: '24px' }}
+        >
+          <Form
+            form={webhookForm}
+            layout="inline"
+            onFinish={handleCreateWebhook}
+            initialValues={{ format: 'generic', events: ['completed', 'failed'] }}
+            style={{ marginBottom: '16px', rowGap: '8px' }}
+          >
+            <Form.Item name="name">
+              <Input placeholder="名称（可选）" style={{ width: 140 }} />
+            </Form.Item>
+            <Form.Item name="url" rules={[{ required: true, message: '请输入 Webhook 地址' }]}>
+              <Input placeholder="https://hooks.example.com/..." style={{ width: 260 }} />
+            </Form.Item>
+            <Form.Item name="format">
+              <Select style={{ width: 110 }}>
+                <Select.Option value="generic">通用 JSON</Select.Option>
+                <Select.Option value="slack">Slack</Select.Option>
+                <Select.Option value="teams">Teams</Select.Option>
+              </Select>
+            </Form.Item>
+            <Form.Item name="secret" tooltip="设置后请求带有 X-Trivy-Webhook-Signature 头（请求体的 HMAC-SHA256 签名）">
+              <Input.Password placeholder="签名密钥（可选）" style={{ width: 160 }} />
+            </Form.Item>
+            <Form.Item name="events" rules={[{ required: true, message: '请选择事件' }]}>
+              <Checkbox.Group
+                options={[
+                  { label: '完成', value: 'completed' },
+                  { label: '失败', value: 'failed' },
+                  { label: '超过阈值', value: 'threshold' },
+                ]}
+              />
+            </Form.Item>
+            <Form.Item noStyle shouldUpdate={(prev, cur) => prev.events !== cur.events}>
+              {({ getFieldValue }) => (getFieldValue('events') || []).includes('threshold') && (
+                <Form.Item name="threshold" rules={[{ required: true, message: '请选择阈值' }]}>
+                  <Select placeholder="阈值" style={{ width: 120 }}>
+                    <Select.Option value="CRITICAL">CRITICAL</Select.Option>
+                    <Select.Option value="HIGH">HIGH 及以上</Select.Option>
+                    <Select.Option value="MEDIUM">MEDIUM 及以上</Select.Option>
+                    <Select.Option value="LOW">LOW 及以上</Select.Option>
+                  </Select>
+                </Form.Item>
+              )}
+            </Form.Item>
+            <Form.Item>
+              <Button type="primary" htmlType="submit" icon={<BellOutlined />}>
+                添加
+              </Button>
+            </Form.Item>
+          </Form>
+
+          <Table
+            dataSource={webhooks}
+            rowKey="id"
+            size="small"
+            pagination={false}
+            locale={{ emptyText: '暂无 Webhook' }}
+            columns={[
+              {
+                title: '名称',
+                dataIndex: 'name',
+                key: 'name',
+                render: (name) => name || '-',
+              },
+              {
+                title: '地址',
+                key: 'url',
+                render: (_, record) => (
+                  <Space direction="vertical" size={0}>
+                    <Text code ellipsis style={{ maxWidth: 320 }}>{record.url}</Text>
+                    <Text type="secondary">
+                      {record.format}{record.hasSecret ? ' · 已签名' : ''}
+                    </Text>
+                  </Space>
+                ),
+              },
+              {
+                title: '事件',
+                key: 'events',
+                render: (_, record) => (
+                  <Space size={4} wrap>
+                    {(record.events || []).map(event => (
+                      <Tag key={event}>{event === 'threshold' ? `≥ ${record.threshold}` : event}</Tag>
+                    ))}
+                  </Space>
+                ),
+              },
+              {
+                title: '状态',
+                key: 'status',
+                render: (_, record) => (
+                  record.paused ? <Tag color="default">已暂停</Tag> : <Tag color="green">启用</Tag>
+                ),
+              },
+              {
+                title: '操作',
+                key: 'actions',
+                render: (_, record) => (
+                  <Space>
+                    <Button size="small" icon={<SendOutlined />} onClick={() => handleTestWebhook(record.id)}>
+                      测试
+                    </Button>
+                    <Button size="small" icon={<UnorderedListOutlined />} onClick={() => handleShowDeliveries(record)}>
+                      记录
+                    </Button>
+                    <Button
+                      size="small"
+                      icon={record.paused ? <PlayCircleOutlined /> : <PauseCircleOutlined />}
+                      onClick={() => handleToggleWebhook(record)}
+                    >
+                      {record.paused ? '恢复' : '暂停'}
+                    </Button>
+                    <Button
+                      size="small"
+                      danger
+                      icon={<DeleteOutlined />}
+                      onClick={() => {
+                        modal.confirm({
+                          title: '确认删除',
+                          content: '确定要删除这个 Webhook 吗？',
+                          okText: '删除',
+                          okType: 'danger',
+                          cancelText: '取消',
+                          onOk: () => handleDeleteWebhook(record.id),
+                        });
+                      }}
+                    >
+                      删除
+                    </Button>
+                  </Space>
+                ),
+              },
+            ]}
+          />
+        </Card>
+
+        {/* Email Digest */}
+        <Card
+          title={<><MailOutlined /> 邮件摘要</>}
+          loading={digestLoading}
+          style={{ marginBottom: '24px' }}
+        >
+          <Form
+            form={digestForm}
+            layout="inline"
+            onFinish={handleSaveDigest}
+            initialValues={{ email: userInfo?.email, frequency: 'daily', enabled: true }}
+            style={{ rowGap: '8px' }}
+          >
+            <Form.Item
+              name="email"
+              rules={[{ required: true, type: 'email', message: '请输入有效的邮件地址' }]}
+            >
+              <Input placeholder="收件地址" style={{ width: 240 }} />
+            </Form.Item>
+            <Form.Item name="frequency">
+              <Select style={{ width: 120 }}>
+                <Select.Option value="daily">每天</Select.Option>
+                <Select.Option value="weekly">每周一</Select.Option>
+              </Select>
+            </Form.Item>
+            <Form.Item name="enabled" valuePropName="checked" label="启用">
+              <Switch />
+            </Form.Item>
+            <Form.Item>
+              <Space>
+                <Button type="primary" htmlType="submit" icon={<MailOutlined />}>
+                  {digestSubscription ? '保存' : '订阅'}
+                </Button>
+                {digestSubscription && (
+                  <>
+                    <Button icon={<SendOutlined />} onClick={handleSendDigest}>
+                      立即发送
+                    </Button>
+                    <Button danger icon={<DeleteOutlined />} onClick={handleDeleteDigest}>
+                      取消订阅
+                    </Button>
+                  </>
+                )}
+              </Space>
+            </Form.Item>
+          </Form>
+          {digestSubscription && (
+            <Space direction="vertical" size={0} style={{ marginTop: '12px' }}>
+              <Text type="secondary">
+                上次发送: {formatDateTime(digestSubscription.lastSentAt)}
+                {digestSubscription.enabled && <>，下次发送: {formatDateTime(digestSubscription.nextSendAt)}</>}
+              </Text>
+              {digestSubscription.lastError && <Text type="danger">{digestSubscription.lastError}</Text>}
+            </Space>
+          )}
+        </Card>
+
         {/* Scan History */}
         <Card
           title={<><HistoryOutlined /> 扫描历史</>}
           loading={historyLoading}
           extra={
             scanHistory.length > 0 && (
-              <Button
-                danger
-                size="small"
-                onClick={() => {
-                  modal.confirm({
-                    title: '确认清空',
-                    content: `确定要清空所有扫描历史吗？此操作无法撤销。`,
-                    okText: '清空',
-                    okType: 'danger',
-                    cancelText: '取消',
-                    onOk() {
-                      return new Promise((resolve, reject) => {
-                        handleDeleteAllTasks()
-                          .then(() => resolve())
-                          .catch((err) => reject(err));
-                      });
-                    },
-                  });
-                }}
-              >
-                清空所有
-              </Button>
+              <Space>
+                <Button
+                  size="small"
+                  icon={<DiffOutlined />}
+                  disabled={selectedTaskIds.length !== 2}
+                  onClick={handleCompareTasks}
+                >
+                  对比所选
+                </Button>
+                <Dropdown
+                  menu={{
+                    items: [
+                      { key: 'csv', label: 'CSV', onClick: () => handleExportHistory('csv') },
+                      { key: 'xlsx', label: 'Excel', onClick: () => handleExportHistory('xlsx') },
+                      { key: 'json', label: 'JSON', onClick: () => handleExportHistory('json') },
+                    ],
+                  }}
+                >
+                  <Button size="small" icon={<DownloadOutlined />}>
+                    导出
+                  </Button>
+                </Dropdown>
+                <Button
+                  danger
+                  size="small"
+                  onClick={() => {
+                    modal.confirm({
+                      title: '确认清空',
+                      content: `确定要清空所有扫描历史吗？此操作无法撤销。`,
+                      okText: '清空',
+                      okType: 'danger',
+                      cancelText: '取消',
+                      onOk() {
+                        return new Promise((resolve, reject) => {
+                          handleDeleteAllTasks()
+                            .then(() => resolve())
+                            .catch((err) => reject(err));
+                        });
+                      },
+                    });
+                  }}
+                >
+                  清空所有
+                </Button>
+              </Space>
             )
           }
         >
@@ -1117,6 +2288,14 @@ function AppContent() {
             dataSource={scanHistory}
             rowKey="id"
             pagination={{ pageSize: 10 }}
+            rowSelection={{
+              selectedRowKeys: selectedTaskIds,
+              onChange: (keys) => setSelectedTaskIds(keys.slice(-2)),
+              getCheckboxProps: (record) => ({
+                disabled: record.status !== 'completed',
+              }),
+              hideSelectAll: true,
+            }}
             columns={[
               {
                 title: '镜像名称',
@@ -1153,6 +2332,7 @@ function AppContent() {
                     running: { color: 'processing', text: '扫描中' },
                     completed: { color: 'success', text: '已完成' },
                     failed: { color: 'error', text: '失败' },
+                    cancelled: { color: 'warning', text: '已取消' },
                   };
                   const config = statusMap[status] || { color: 'default', text: status };
                   return (
@@ -1163,6 +2343,14 @@ function AppContent() {
                           前面还有 {record.queuePosition} 个任务
                         </Text>
                       )}
+                      {record.policyVerdict && (
+                        <Tag
+                          color={record.policyVerdict.passed ? 'success' : 'error'}
+                          title={(record.policyVerdict.reasons || []).join('\n')}
+                        >
+                          策略 {record.policyVerdict.policy}: {record.policyVerdict.passed ? '通过' : '未通过'}
+                        </Tag>
+                      )}
                     </Space>
                   );
                 },
@@ -1179,6 +2367,19 @@ function AppContent() {
                       {summary.high > 0 && <Tag color="orange">HIGH: {summary.high}</Tag>}
                       {summary.medium > 0 && <Tag color="yellow">MEDIUM: {summary.medium}</Tag>}
                       {summary.low > 0 && <Tag color="blue">LOW: {summary.low}</Tag>}
+                      {summary.misconfigurations && summary.misconfigurations.failures > 0 && (
+                        <Tag color="purple">配置错误: {summary.misconfigurations.failures}</Tag>
+                      )}
+                      {summary.secrets && summary.secrets.total > 0 && (
+                        <Tag color="magenta" title={Object.entries(summary.secrets.categories || {}).map(([k, v]) => `${k}: ${v}`).join(', ')}>
+                          密钥: {summary.secrets.total}
+                        </Tag>
+                      )}
+                      {summary.licenses && summary.licenses.total > 0 && (
+                        <Tag color="cyan" title={Object.entries(summary.licenses.categories || {}).map(([k, v]) => `${k}: ${v}`).join(', ')}>
+                          许可证: {summary.licenses.total}
+                        </Tag>
+                      )}
                     </Space>
                   );
                 },
@@ -1195,6 +2396,15 @@ function AppContent() {
                     >
                       查看日志
                     </Button>
+                    {record.status === 'completed' && record.summary && (
+                      <Button
+                        size="small"
+                        icon={<UnorderedListOutlined />}
+                        onClick={() => handleViewFindings(record)}
+                      >
+                        查看结果
+                      </Button>
+                    )}
                     {record.status === 'completed' && (
                       <Dropdown
                         menu={{
@@ -1204,6 +2414,8 @@ function AppContent() {
                             { key: 'cyclonedx', label: 'CycloneDX', onClick: () => handleDownloadReport(record.id, 'cyclonedx') },
                             { key: 'spdx', label: 'SPDX', onClick: () => handleDownloadReport(record.id, 'spdx') },
                             { key: 'table', label: 'Table', onClick: () => handleDownloadReport(record.id, 'table') },
+                            { type: 'divider' },
+                            { key: 'archive', label: '全部格式 (ZIP)', onClick: () => handleDownloadReport(record.id, 'archive') },
                           ],
                         }}
                       >
@@ -1216,6 +2428,24 @@ function AppContent() {
                         </Button>
                       </Dropdown>
                     )}
+                    {(record.status === 'queued' || record.status === 'running') && (
+                      <Button
+                        size="small"
+                        icon={<StopOutlined />}
+                        onClick={() => handleCancelTask(record.id)}
+                      >
+                        取消
+                      </Button>
+                    )}
+                    {record.status !== 'queued' && record.status !== 'running' && (
+                      <Button
+                        size="small"
+                        icon={<RedoOutlined />}
+                        onClick={() => handleRescanTask(record.id)}
+                      >
+                        重新扫描
+                      </Button>
+                    )}
                     <Button
                       size="small"
                       danger
@@ -1348,6 +2578,20 @@ function AppContent() {
                 type={taskStatus.status === 'completed' ? 'success' : taskStatus.status === 'failed' ? 'error' : 'info'}
                 style={{ marginBottom: '16px' }}
               />
+              {taskStatus.policyVerdict && (
+                <Alert
+                  message={`策略 ${taskStatus.policyVerdict.policy}: ${taskStatus.policyVerdict.passed ? '通过' : '未通过'}`}
+                  description={taskStatus.policyVerdict.passed ? undefined : (
+                    <ul style={{ margin: 0, paddingLeft: '20px' }}>
+                      {(taskStatus.policyVerdict.reasons || []).map(reason => (
+                        <li key={reason}>{reason}</li>
+                      ))}
+                    </ul>
+                  )}
+                  type={taskStatus.policyVerdict.passed ? 'success' : 'error'}
+                  style={{ marginBottom: '16px' }}
+                />
+              )}
               {taskStatus.status === 'queued' && taskStatus.queuePosition && (
                 <Progress
                   percent={0}
@@ -1437,6 +2681,200 @@ function AppContent() {
           </div>
         </Modal>
 
+        {/* Findings Modal */}
+        <Modal
+          title={findingsTask ? `扫描结果 - ${findingsTask.image}` : '扫描结果'}
+          open={!!findingsTask}
+          onCancel={() => setFindingsTask(null)}
+          footer={null}
+          width={1100}
+        >
+          <Tabs
+            activeKey={findingsQuery.kind}
+            onChange={(kind) => setFindingsQuery({ ...findingsQuery, kind, page: 1 })}
+            items={[
+              { key: 'vulnerability', label: '漏洞' },
+              { key: 'misconfiguration', label: '配置错误' },
+              { key: 'secret', label: '密钥' },
+              { key: 'license', label: '许可证' },
+            ]}
+          />
+          <Space style={{ marginBottom: '16px' }} wrap>
+            <Select
+              mode="multiple"
+              allowClear
+              placeholder="严重等级"
+              style={{ minWidth: 240 }}
+              value={findingsQuery.severity}
+              onChange={(severity) => setFindingsQuery({ ...findingsQuery, severity, page: 1 })}
+              options={['CRITICAL', 'HIGH', 'MEDIUM', 'LOW', 'UNKNOWN'].map(s => ({ label: s, value: s }))}
+            />
+            <Input.Search
+              allowClear
+              placeholder="搜索 ID、软件包、标题或文件"
+              style={{ width: 300 }}
+              onSearch={(q) => setFindingsQuery({ ...findingsQuery, q: q.trim(), page: 1 })}
+            />
+          </Space>
+          <Table
+            dataSource={findingsData ? findingsData.findings : []}
+            rowKey={(record, index) => `${record.target}-${index}`}
+            size="small"
+            loading={findingsLoading}
+            pagination={{
+              current: findingsQuery.page,
+              pageSize: findingsQuery.pageSize,
+              total: findingsData ? findingsData.total : 0,
+              showSizeChanger: true,
+              onChange: (page, pageSize) => setFindingsQuery({ ...findingsQuery, page, pageSize }),
+            }}
+            expandable={{
+              rowExpandable: (record) => !!(record.vulnerability || record.misconfiguration),
+              expandedRowRender: (record) => {
+                const detail = record.vulnerability || record.misconfiguration;
+                return (
+                  <Space direction="vertical" size={4}>
+                    {record.suppressed && <Text type="warning">忽略理由: {record.justification}</Text>}
+                    {detail.description && <Text>{detail.description}</Text>}
+                    {detail.resolution && <Text type="secondary">修复建议: {detail.resolution}</Text>}
+                    {detail.primaryUrl && <a href={detail.primaryUrl} target="_blank" rel="noopener noreferrer">{detail.primaryUrl}</a>}
+                  </Space>
+                );
+              },
+            }}
+            columns={[
+              {
+                title: 'ID',
+                key: 'id',
+                render: (_, record) => {
+                  const detail = record.vulnerability || record.misconfiguration || record.secret || record.license;
+                  return (
+                    <Space size={4}>
+                      {detail.vulnerabilityId || detail.id || detail.ruleId || detail.name}
+                      {record.suppressed && <Tag title={record.justification}>已忽略</Tag>}
+                    </Space>
+                  );
+                },
+              },
+              {
+                title: '严重等级',
+                key: 'severity',
+                render: (_, record) => {
+                  const severity = (record.vulnerability || record.misconfiguration || record.secret || record.license).severity;
+                  const colors = { CRITICAL: 'red', HIGH: 'orange', MEDIUM: 'gold', LOW: 'blue' };
+                  return <Tag color={colors[severity] || 'default'}>{severity}</Tag>;
+                },
+              },
+              {
+                title: '详情',
+                key: 'detail',
+                render: (_, record) => {
+                  if (record.vulnerability) {
+                    const v = record.vulnerability;
+                    return (
+                      <Space direction="vertical" size={0}>
+                        <Text>{v.pkgName} {v.installedVersion}{v.fixedVersion && <Text type="success"> → {v.fixedVersion}</Text>}</Text>
+                        {v.title && <Text type="secondary">{v.title}</Text>}
+                      </Space>
+                    );
+                  }
+                  if (record.misconfiguration) {
+                    const m = record.misconfiguration;
+                    return (
+                      <Space direction="vertical" size={0}>
+                        <Text>{m.title} {m.status && <Tag>{m.status}</Tag>}</Text>
+                        {m.message && <Text type="secondary">{m.message}</Text>}
+                      </Space>
+                    );
+                  }
+                  if (record.secret) {
+                    const sec = record.secret;
+                    return (
+                      <Space direction="vertical" size={0}>
+                        <Text>{sec.title} ({sec.category})</Text>
+                        <Text code>{sec.match}</Text>
+                      </Space>
+                    );
+                  }
+                  const l = record.license;
+                  return <Text>{l.category} {l.pkgName || l.filePath}</Text>;
+                },
+              },
+              { title: '目标', dataIndex: 'target', key: 'target' },
+              {
+                title: '操作',
+                key: 'action',
+                render: (_, record) => record.vulnerability && !record.suppressed && (
+                  <Button size="small" onClick={() => handleSuppressFinding(record)}>忽略</Button>
+                ),
+              },
+            ]}
+          />
+        </Modal>
+
+        {/* Scan Diff Modal */}
+        <Modal
+          title="扫描结果对比"
+          open={diffModalVisible}
+          onCancel={() => setDiffModalVisible(false)}
+          footer={null}
+          width={1000}
+        >
+          {diffLoading && <Text type="secondary">加载中...</Text>}
+          {diffResult && (
+            <>
+              <Space direction="vertical" size={0} style={{ marginBottom: '16px' }}>
+                <Text>基准: <Text code>{diffResult.baseImage}</Text></Text>
+                <Text>对比: <Text code>{diffResult.targetImage}</Text></Text>
+              </Space>
+              <Tabs
+                items={[
+                  { key: 'added', label: '新增', color: 'red' },
+                  { key: 'removed', label: '已修复', color: 'green' },
+                  { key: 'unchanged', label: '未变化', color: 'default' },
+                ].map(({ key, label, color }) => ({
+                  key,
+                  label: <>{label} <Tag color={color}>{diffResult[key].summary.total}</Tag></>,
+                  children: (
+                    <>
+                      <Space size={[0, 4]} wrap style={{ marginBottom: '8px' }}>
+                        <Tag color="red">CRITICAL: {diffResult[key].summary.critical}</Tag>
+                        <Tag color="orange">HIGH: {diffResult[key].summary.high}</Tag>
+                        <Tag color="yellow">MEDIUM: {diffResult[key].summary.medium}</Tag>
+                        <Tag color="blue">LOW: {diffResult[key].summary.low}</Tag>
+                        <Tag>UNKNOWN: {diffResult[key].summary.unknown}</Tag>
+                      </Space>
+                      <Table
+                        dataSource={diffResult[key].vulnerabilities}
+                        rowKey={(record) => `${record.vulnerabilityId}-${record.pkgName}-${record.installedVersion}`}
+                        size="small"
+                        pagination={{ pageSize: 10 }}
+                        columns={[
+                          { title: '漏洞 ID', dataIndex: 'vulnerabilityId', key: 'vulnerabilityId' },
+                          {
+                            title: '严重等级',
+                            dataIndex: 'severity',
+                            key: 'severity',
+                            render: (severity, record) => (
+                              <>
+                                {severity}
+                                {record.previousSeverity && <Text type="secondary"> (原 {record.previousSeverity})</Text>}
+                              </>
+                            ),
+                          },
+                          { title: '软件包', dataIndex: 'pkgName', key: 'pkgName' },
+                          { title: '安装版本', dataIndex: 'installedVersion', key: 'installedVersion' },
+                          { title: '修复版本', dataIndex: 'fixedVersion', key: 'fixedVersion', render: (v) => v || '-' },
+                        ]}
+                      />
+                    </>
+                  ),
+                }))}
+              />
+            </>
+          )}
+        </Modal>
+
         {/* Save Config Modal */}
         <Modal
           title="保存配置"